@@ -0,0 +1,24 @@
+package main
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gritt/maskada/details"
+)
+
+const configUsage = "usage: maskada config print"
+
+// runConfig runs the config subcommand, printing the validated config with its secrets redacted.
+func runConfig(out io.Writer, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+
+	cfg, err := details.NewConfig()
+	if err != nil {
+		return err
+	}
+
+	return cfg.Print(out)
+}
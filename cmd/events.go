@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/events"
+)
+
+// publishing holds the publisher of the domain events, as configured, along with the relay of the outbox if any.
+type publishing struct {
+	Publisher core.Publisher
+	Relay     *events.Relay
+}
+
+// newPublishing initialize the publisher of the domain events: in process, logging them, and to the outbox the
+// webhooks are delivered from, as well as to NATS when configured, either at once or relayed from the outbox when
+// enabled. The cleanup closes the NATS connection.
+func newPublishing(cfg *details.Config, store events.Store) (*publishing, func(), error) {
+	bus := events.NewBus()
+	bus.Subscribe(func(ctx context.Context, event core.Event) {
+		slog.DebugContext(ctx, "event published", "event", event.EventName())
+	})
+	publishers := events.Publishers{bus, events.NewOutbox(store)}
+
+	if cfg.Events.NATSURL == "" {
+		return &publishing{Publisher: publishers}, func() {}, nil
+	}
+
+	// the connection is retried in the background, so the API starts while NATS is unreachable
+	conn, err := nats.Connect(cfg.Events.NATSURL,
+		nats.Name("maskada"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		if err := conn.Drain(); err != nil {
+			slog.Error("closing the NATS connection failed", "error", err.Error())
+		}
+	}
+
+	n := events.NewNATS(conn, cfg.Events.Subject)
+	if !cfg.Events.Outbox {
+		return &publishing{Publisher: append(publishers, n)}, cleanup, nil
+	}
+
+	return &publishing{
+		Publisher: publishers,
+		Relay:     events.NewRelay(store, n, cfg.Events.RelayInterval),
+	}, cleanup, nil
+}
+
+// newOutboxSweeper initialize the sweeper of the outbox, purging the events past the configured retention.
+func newOutboxSweeper(cfg *details.Config, store events.Store) *events.Sweeper {
+	return events.NewSweeper(store, cfg.Events.Retention, cfg.Events.SweepInterval)
+}
@@ -1,22 +1,134 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/cli"
+	"github.com/gritt/maskada/details/client"
+	"github.com/gritt/maskada/details/logging"
+	"github.com/gritt/maskada/details/tracing"
 )
 
+// configFlags override the config as their environment variables do, taking precedence over them.
+var configFlags = map[string]string{
+	"config":    "CONFIG_FILE",
+	"addr":      "SERVER_ADDRESS",
+	"log-level": "LOG_LEVEL",
+}
+
 func main() {
-	api, err := initAPI()
-	if err != nil {
-		log.Fatalln(err)
+	inMemory := flag.Bool("memory", false, "store transactions in memory, seeded with demo data, same as STORAGE_BACKEND=memory")
+	flag.String("config", "", "read the config from a YAML file, same as CONFIG_FILE")
+	flag.String("addr", "", "listen on the address, eg: :8888, same as SERVER_ADDRESS")
+	flag.String("log-level", "", "log at the level, one of debug, info, warn or error, same as LOG_LEVEL")
+	apiURL := flag.String("api", envOr("MASKADA_API", "http://localhost:8888"), "the API the commands talk to, same as MASKADA_API")
+	actor := flag.String("actor", envOr("MASKADA_ACTOR", os.Getenv("USER")), "who performs the changes made by the commands, same as MASKADA_ACTOR")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "%s\n  tui [-local]\n  migrate up | down [n] | status\n  config print\n\nwithout a command, serve the API:\n", cli.Usage)
+		flag.PrintDefaults()
 	}
+	flag.Parse()
 
-	server := &http.Server{
-		Addr:    ":8888",
-		Handler: api.Routes(),
+	if *inMemory {
+		if err := os.Setenv("STORAGE_BACKEND", details.Memory); err != nil {
+			log.Fatalln(err)
+		}
 	}
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	flag.Visit(func(f *flag.Flag) {
+		if key, ok := configFlags[f.Name]; ok {
+			if err := os.Setenv(key, f.Value.String()); err != nil {
+				log.Fatalln(err)
+			}
+		}
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch flag.Arg(0) {
+	case "":
+	case "migrate":
+		if err := runMigrate(ctx, os.Stdout, flag.Args()[1:]); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	case "config":
+		if err := runConfig(os.Stdout, flag.Args()[1:]); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	case "tui":
+		if err := runTUI(ctx, *apiURL, *actor, flag.Args()[1:]); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	default:
+		commands := cli.NewCommands(client.NewClient(*apiURL, *actor), os.Stdout)
+		if err := commands.Run(flag.Args()); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := serve(ctx); err != nil {
 		log.Fatalln(err)
 	}
 }
+
+// serve runs the API until ctx is done on SIGINT or SIGTERM, then drains it and closes the storage, logging to stderr
+// and exporting the traces as configured.
+func serve(ctx context.Context) error {
+	cfg, err := details.NewConfig()
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logging.NewLogger(os.Stderr, cfg.Log.Level))
+
+	shutdownTracing, err := tracing.Init(cfg, buildInfo().Commit)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("flushing the traces failed", "error", err.Error())
+		}
+	}()
+
+	if cfg.Storage.Backend == details.SQLite {
+		if err := migrateOnStart(ctx); err != nil {
+			return err
+		}
+	}
+
+	server, cleanup, err := initServer(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := server.run(ctx); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// envOr returns the value of the environment variable, or fallback when it is not set.
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"github.com/gritt/maskada/details/metrics"
+)
+
+// newMetrics initialize the metrics of the API, along with the ones of its storage.
+func newMetrics(s *storage) (*metrics.Metrics, error) {
+	m := metrics.NewMetrics()
+
+	if s.Pool != nil {
+		if err := m.Register(metrics.NewPoolCollector(s.Pool)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.Register(metrics.NewTransactionCollector(s.Repository)); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
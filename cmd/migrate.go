@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gritt/maskada/details/migrate"
+)
+
+const migrateUsage = "usage: maskada migrate up | down [n] | status"
+
+// runMigrate runs the migrate subcommand, eg: maskada migrate down 2.
+func runMigrate(ctx context.Context, out io.Writer, args []string) error {
+	if len(args) == 0 || len(args) > 2 || (len(args) == 2 && args[0] != "down") {
+		return errors.New(migrateUsage)
+	}
+
+	steps := 1
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid number of migrations to revert %s", args[1])
+		}
+		steps = n
+	}
+
+	migrator, cleanup, err := initMigrator(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if migrator == nil {
+		return errors.New("the memory storage backend has no schema to migrate")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		printMigrations(out, "applied", applied)
+		return err
+	case "down":
+		reverted, err := migrator.Down(steps)
+		printMigrations(out, "reverted", reverted)
+		return err
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		printStatus(out, statuses)
+		return nil
+	}
+
+	return errors.New(migrateUsage)
+}
+
+// migrateOnStart applies the pending migrations of the SQLite file as the server starts, as it is not shared by
+// instances deployed apart, unlike the MySQL and Postgres databases which are migrated with maskada migrate up.
+func migrateOnStart(ctx context.Context) error {
+	migrator, cleanup, err := initMigrator(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	applied, err := migrator.Up()
+	for _, m := range applied {
+		slog.Info("applied migration", "version", m.Version, "name", m.Name)
+	}
+	return err
+}
+
+func printMigrations(out io.Writer, action string, migrations []migrate.Migration) {
+	if len(migrations) == 0 {
+		_, _ = fmt.Fprintf(out, "no migrations %s\n", action)
+	}
+	for _, m := range migrations {
+		_, _ = fmt.Fprintf(out, "%s %04d %s\n", action, m.Version, m.Name)
+	}
+}
+
+func printStatus(out io.Writer, statuses []migrate.Status) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.Applied {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/certs"
+	"github.com/gritt/maskada/details/events"
+	graphqlapi "github.com/gritt/maskada/details/graphql"
+	grpcapi "github.com/gritt/maskada/details/grpc"
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/rest"
+	"github.com/gritt/maskada/details/webhook"
+)
+
+// certsReloadInterval is how often the TLS files are checked for changes.
+const certsReloadInterval = time.Minute
+
+// server serves the API over HTTP and gRPC until it is stopped, draining the in-flight requests, while dispatching
+// the webhooks, relaying the outbox of the domain events, if any, and sweeping the expired idempotency keys and the
+// outbox.
+type server struct {
+	http            *http.Server
+	grpc            *grpc.Server
+	grpcAddress     string
+	certs           *certs.Reloader
+	dispatcher      *webhook.Dispatcher
+	relay           *events.Relay
+	sweeper         *idempotency.Sweeper
+	outboxSweeper   *events.Sweeper
+	projection      projection
+	shutdownTimeout time.Duration
+}
+
+// newServer initialize the HTTP and gRPC servers of the API, as configured.
+func newServer(
+	cfg *details.Config,
+	api *rest.API,
+	service *grpcapi.Service,
+	graphql *graphqlapi.Handler,
+	webhooks rest.WebhookManager,
+	dispatcher *webhook.Dispatcher,
+	relay *events.Relay,
+	sweeper *idempotency.Sweeper,
+	outboxSweeper *events.Sweeper,
+	ledger rest.LedgerReader,
+	journal rest.Undoer,
+	projection projection,
+) (*server, error) {
+	api.GraphQL = graphql
+	api.Webhooks = webhooks
+	if cfg.Storage.EventSourced {
+		api.Ledger = ledger
+	}
+	api.Journal = journal
+	api.AllowedOrigins = cfg.Server.CORSOrigins
+	api.RequestTimeout = cfg.Server.WriteTimeout
+	api.Build = buildInfo()
+
+	s := &server{
+		http: &http.Server{
+			Addr:         cfg.Server.Address,
+			Handler:      api.Routes(),
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+			IdleTimeout:  cfg.Server.IdleTimeout,
+		},
+		grpcAddress:     cfg.GRPC.Address,
+		dispatcher:      dispatcher,
+		relay:           relay,
+		sweeper:         sweeper,
+		outboxSweeper:   outboxSweeper,
+		projection:      projection,
+		shutdownTimeout: cfg.Server.ShutdownTimeout,
+	}
+
+	if cfg.TLS() {
+		reloader, err := certs.NewReloader(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		s.certs = reloader
+		s.http.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+	}
+	s.grpc = grpcapi.NewServer(service, s.http.TLSConfig)
+
+	return s, nil
+}
+
+// run catches the event store up with the transactions, when event-sourced, then serves until ctx is done, then stops
+// accepting connections and waits for the in-flight requests and calls up to the shutdown timeout, and for the webhooks
+// being delivered, the events being relayed and the keys and the outbox being swept.
+func (s *server) run(ctx context.Context) error {
+	if s.projection != nil {
+		if err := s.projection.CatchUp(ctx); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("tcp", s.grpcAddress)
+	if err != nil {
+		return err
+	}
+
+	dispatchCtx, stopDispatching := context.WithCancel(ctx)
+	dispatched := make(chan struct{})
+	go func() {
+		defer close(dispatched)
+		s.dispatcher.Run(dispatchCtx)
+	}()
+	defer func() {
+		stopDispatching()
+		<-dispatched
+	}()
+
+	sweepCtx, stopSweeping := context.WithCancel(ctx)
+	swept := make(chan struct{}, 2)
+	go func() {
+		defer func() { swept <- struct{}{} }()
+		s.sweeper.Run(sweepCtx)
+	}()
+	go func() {
+		defer func() { swept <- struct{}{} }()
+		s.outboxSweeper.Run(sweepCtx)
+	}()
+	defer func() {
+		stopSweeping()
+		<-swept
+		<-swept
+	}()
+
+	if s.relay != nil {
+		relayCtx, stopRelaying := context.WithCancel(ctx)
+		relayed := make(chan struct{})
+		go func() {
+			defer close(relayed)
+			s.relay.Run(relayCtx)
+		}()
+		defer func() {
+			stopRelaying()
+			<-relayed
+		}()
+	}
+
+	slog.Info("serving", "address", s.http.Addr, "grpc_address", s.grpcAddress, "tls", s.certs != nil)
+
+	failed := make(chan error, 2)
+	go func() {
+		if s.certs == nil {
+			failed <- s.http.ListenAndServe()
+			return
+		}
+
+		go s.certs.Watch(ctx, certsReloadInterval, func(err error) {
+			slog.Error("reloading the TLS certificate failed", "error", err.Error())
+		})
+		failed <- s.http.ListenAndServeTLS("", "")
+	}()
+	go func() {
+		failed <- s.grpc.Serve(listener)
+	}()
+
+	select {
+	case err := <-failed:
+		s.grpc.Stop()
+		_ = s.http.Close()
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+
+	err = s.http.Shutdown(shutdownCtx)
+
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		s.grpc.Stop()
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/db"
+	"github.com/gritt/maskada/details/events"
+	"github.com/gritt/maskada/details/health"
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/memory"
+	"github.com/gritt/maskada/details/metrics"
+	"github.com/gritt/maskada/details/migrate"
+	"github.com/gritt/maskada/details/postgres"
+	"github.com/gritt/maskada/details/sqlite"
+	"github.com/gritt/maskada/details/sqlstore"
+	"github.com/gritt/maskada/details/webhook"
+)
+
+// storage holds the clients of the configured storage backend, the memory one has no Pinger, Pool nor Migrator.
+// The Projection is set when a SQL backend is event-sourced.
+type storage struct {
+	Repository       core.Repository
+	EventLog         core.EventLog
+	Projection       projection
+	Journal          core.Journal
+	IdempotencyStore idempotency.Store
+	WebhookStore     webhook.Store
+	EventStore       events.Store
+	Pinger           health.Pinger
+	Pool             metrics.Pool
+	Migrator         *migrate.Migrator
+}
+
+// projection is an event-sourced repository, appending the events of the changes made while it was not.
+type projection interface {
+	CatchUp(ctx context.Context) error
+}
+
+// newStorage initialize the clients of the storage backend chosen by config, waiting for its database until ctx is
+// done, along with the cleanup closing its connections.
+func newStorage(ctx context.Context, cfg *details.Config) (*storage, func(), error) {
+	switch cfg.Storage.Backend {
+	case details.Memory:
+		repository := memory.NewRepository()
+		if _, err := repository.CreateBatch(ctx, memory.Demo(time.Now()), memory.DemoActor); err != nil {
+			return nil, nil, err
+		}
+
+		return &storage{
+			Repository:       repository,
+			EventLog:         repository,
+			Journal:          repository,
+			IdempotencyStore: memory.NewIdempotencyStore(),
+			WebhookStore:     memory.NewWebhookStore(repository),
+			EventStore:       memory.NewEventStore(repository, cfg.Relay()),
+		}, func() {}, nil
+	case details.Postgres:
+		return newSQLStorage(ctx, cfg, postgres.NewRepository, postgres.NewMigrator)
+	case details.SQLite:
+		return newSQLStorage(ctx, cfg, sqlite.NewRepository, sqlite.NewMigrator)
+	}
+
+	return newSQLStorage(ctx, cfg, db.NewRepository, db.NewMigrator)
+}
+
+// newMigrationStorage initialize the clients of the storage backend chosen by config as newStorage does, the MySQL
+// ones on connections running several statements per query, as the migration scripts do.
+func newMigrationStorage(ctx context.Context, cfg *details.Config) (*storage, func(), error) {
+	if cfg.Storage.Backend == details.MySQL {
+		return newSQLStorage(ctx, cfg, db.NewMigrationRepository, db.NewMigrator)
+	}
+
+	return newStorage(ctx, cfg)
+}
+
+// newSQLStorage initialize the clients of a SQL storage backend, opened as its dialect is, along with the cleanup
+// closing its connections, which are closed already when it fails.
+func newSQLStorage(
+	ctx context.Context,
+	cfg *details.Config,
+	open func(*details.Config) (*sqlstore.Repository, error),
+	newMigrator func(*sqlstore.Repository) (*migrate.Migrator, error),
+) (_ *storage, _ func(), err error) {
+	repository, err := open(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			closer(repository)()
+		}
+	}()
+
+	if err := health.Wait(ctx, repository, cfg.Database.ConnectTimeout); err != nil {
+		return nil, nil, err
+	}
+
+	migrator, err := newMigrator(repository)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := &storage{
+		Repository:       repository,
+		EventLog:         repository,
+		Journal:          repository,
+		IdempotencyStore: sqlstore.NewIdempotencyStore(repository),
+		WebhookStore:     sqlstore.NewWebhookStore(repository),
+		EventStore:       sqlstore.NewEventStore(repository, cfg.Relay()),
+		Pinger:           repository,
+		Pool:             repository,
+		Migrator:         migrator,
+	}
+	if cfg.Storage.EventSourced {
+		s.Projection = repository
+	}
+
+	return s, closer(repository), nil
+}
+
+// closer returns a cleanup closing c, logging its failure as there is nothing left to do on exit.
+func closer(c io.Closer) func() {
+	return func() {
+		if err := c.Close(); err != nil {
+			slog.Error("closing the storage failed", "error", err.Error())
+		}
+	}
+}
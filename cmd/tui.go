@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"time"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/client"
+	"github.com/gritt/maskada/details/tui"
+)
+
+const tuiUsage = "usage: maskada tui [-local]"
+
+// local holds the repository of the configured storage backend, along with the publisher of the events of its
+// changes.
+type local struct {
+	Repository core.Repository
+	Publisher  core.Publisher
+}
+
+// runTUI runs the tui subcommand, against the API unless told to use the configured storage backend directly.
+func runTUI(ctx context.Context, apiURL, actor string, args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	local := fs.Bool("local", false, "use the configured storage backend directly, instead of the API")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		return errors.New(tuiUsage)
+	}
+
+	var store tui.Store = client.NewClient(apiURL, actor)
+	if *local {
+		l, cleanup, err := initLocal(ctx)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		store = tui.NewRepositoryStore(l.Repository, l.Publisher, actor)
+	}
+
+	return tui.NewApp(store, time.Now()).Run()
+}
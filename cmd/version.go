@@ -0,0 +1,39 @@
+package main
+
+import (
+	"runtime/debug"
+
+	"github.com/gritt/maskada/details/rest"
+)
+
+// commit and buildDate identify release builds, set with:
+// go build -ldflags "-X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	commit    string
+	buildDate string
+)
+
+// buildInfo identifies the running build, falling back to the version control info stamped by go build, if any.
+func buildInfo() rest.BuildInfo {
+	build := rest.BuildInfo{Commit: commit, Date: buildDate}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && build.Commit == "":
+				build.Commit = setting.Value
+			case setting.Key == "vcs.time" && build.Date == "":
+				build.Date = setting.Value
+			}
+		}
+	}
+
+	if build.Commit == "" {
+		build.Commit = "unknown"
+	}
+	if build.Date == "" {
+		build.Date = "unknown"
+	}
+
+	return build
+}
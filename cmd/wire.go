@@ -1,37 +1,188 @@
+//go:build wireinject
 // +build wireinject
 
 package main
 
 import (
+	"context"
+
 	"github.com/google/wire"
 
 	"github.com/gritt/maskada/core"
 	"github.com/gritt/maskada/details"
-	"github.com/gritt/maskada/details/db"
+	graphqlapi "github.com/gritt/maskada/details/graphql"
+	grpcapi "github.com/gritt/maskada/details/grpc"
+	"github.com/gritt/maskada/details/health"
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/metrics"
+	"github.com/gritt/maskada/details/migrate"
 	"github.com/gritt/maskada/details/rest"
+	"github.com/gritt/maskada/details/tracing"
+	"github.com/gritt/maskada/details/webhook"
 )
 
 var repositorySet = wire.NewSet(
-	details.NewConfig,
-	wire.Bind(new(core.Repository), new(*db.Repository)),
-	db.NewRepository,
+	newStorage,
+	wire.FieldsOf(new(*storage), "Repository", "EventLog", "Projection", "Journal", "IdempotencyStore", "WebhookStore", "EventStore", "Pinger", "Migrator"),
 )
 
 var createTransactionSet = wire.NewSet(
-	wire.Bind(new(rest.TransactionCreator), new(*core.CreateTransactionUseCase)),
+	wire.Bind(new(rest.TransactionCreator), new(*metrics.TransactionCreator)),
+	wire.Bind(new(grpcapi.TransactionCreator), new(*metrics.TransactionCreator)),
+	wire.Bind(new(metrics.Creator), new(*tracing.TransactionCreator)),
+	wire.Bind(new(tracing.Creator), new(*core.CreateTransactionUseCase)),
+	metrics.NewTransactionCreator,
+	tracing.NewTransactionCreator,
 	core.NewCreateTransactionUseCase,
 )
 
+var createTransactionBatchSet = wire.NewSet(
+	wire.Bind(new(rest.TransactionBatchCreator), new(*tracing.TransactionBatchCreator)),
+	wire.Bind(new(tracing.BatchCreator), new(*core.CreateTransactionBatchUseCase)),
+	tracing.NewTransactionBatchCreator,
+	core.NewCreateTransactionBatchUseCase,
+)
+
 var listTransactionSet = wire.NewSet(
-	wire.Bind(new(rest.TransactionLister), new(*core.ListTransactionUseCase)),
+	wire.Bind(new(rest.TransactionLister), new(*tracing.TransactionLister)),
+	wire.Bind(new(grpcapi.TransactionLister), new(*tracing.TransactionLister)),
+	wire.Bind(new(graphqlapi.TransactionLister), new(*tracing.TransactionLister)),
+	wire.Bind(new(tracing.Lister), new(*core.ListTransactionUseCase)),
+	tracing.NewTransactionLister,
 	core.NewListTransactionUseCase,
 )
 
-func initAPI() (*rest.API, error) {
+var getTransactionSet = wire.NewSet(
+	wire.Bind(new(rest.TransactionGetter), new(*tracing.TransactionGetter)),
+	wire.Bind(new(graphqlapi.TransactionGetter), new(*tracing.TransactionGetter)),
+	wire.Bind(new(tracing.Getter), new(*core.GetTransactionUseCase)),
+	tracing.NewTransactionGetter,
+	core.NewGetTransactionUseCase,
+)
+
+var updateTransactionSet = wire.NewSet(
+	wire.Bind(new(rest.TransactionUpdater), new(*tracing.TransactionUpdater)),
+	wire.Bind(new(tracing.Updater), new(*core.UpdateTransactionUseCase)),
+	tracing.NewTransactionUpdater,
+	core.NewUpdateTransactionUseCase,
+)
+
+var deleteTransactionSet = wire.NewSet(
+	wire.Bind(new(rest.TransactionDeleter), new(*tracing.TransactionDeleter)),
+	wire.Bind(new(tracing.Deleter), new(*core.DeleteTransactionUseCase)),
+	tracing.NewTransactionDeleter,
+	core.NewDeleteTransactionUseCase,
+)
+
+var listTransactionHistorySet = wire.NewSet(
+	wire.Bind(new(rest.TransactionHistoryLister), new(*tracing.TransactionHistoryLister)),
+	wire.Bind(new(tracing.HistoryLister), new(*core.ListTransactionHistoryUseCase)),
+	tracing.NewTransactionHistoryLister,
+	core.NewListTransactionHistoryUseCase,
+)
+
+var listCategorySet = wire.NewSet(
+	wire.Bind(new(graphqlapi.CategoryLister), new(*tracing.CategoryLister)),
+	wire.Bind(new(tracing.CategoriesLister), new(*core.ListCategoryUseCase)),
+	tracing.NewCategoryLister,
+	core.NewListCategoryUseCase,
+)
+
+var totalCategorySet = wire.NewSet(
+	wire.Bind(new(graphqlapi.CategoryTotaler), new(*tracing.CategoryTotaler)),
+	wire.Bind(new(tracing.Totaler), new(*core.TotalCategoryUseCase)),
+	tracing.NewCategoryTotaler,
+	core.NewTotalCategoryUseCase,
+)
+
+var ledgerSet = wire.NewSet(
+	wire.Bind(new(rest.LedgerReader), new(*tracing.Ledger)),
+	wire.Bind(new(tracing.LedgerReader), new(*core.LedgerUseCase)),
+	tracing.NewLedger,
+	core.NewLedgerUseCase,
+)
+
+var undoSet = wire.NewSet(
+	wire.Bind(new(rest.Undoer), new(*tracing.Undo)),
+	wire.Bind(new(tracing.Undoer), new(*core.UndoUseCase)),
+	tracing.NewUndo,
+	core.NewUndoUseCase,
+)
+
+var graphqlSet = wire.NewSet(
+	graphqlapi.NewHandler,
+)
+
+var grpcSet = wire.NewSet(
+	grpcapi.NewService,
+)
+
+var webhookSet = wire.NewSet(
+	wire.Bind(new(rest.WebhookManager), new(*webhook.Service)),
+	webhook.NewService,
+	webhook.NewDispatcher,
+)
+
+var eventsSet = wire.NewSet(
+	newPublishing,
+	wire.FieldsOf(new(*publishing), "Publisher", "Relay"),
+	newOutboxSweeper,
+)
+
+var idempotencySet = wire.NewSet(
+	idempotency.NewMiddleware,
+	idempotency.NewSweeper,
+)
+
+var metricsSet = wire.NewSet(
+	newMetrics,
+)
+
+var healthSet = wire.NewSet(
+	wire.Bind(new(rest.HealthChecker), new(*health.Checker)),
+	health.NewChecker,
+)
+
+func initServer(ctx context.Context, cfg *details.Config) (*server, func(), error) {
 	panic(wire.Build(
 		repositorySet,
 		createTransactionSet,
+		createTransactionBatchSet,
 		listTransactionSet,
+		getTransactionSet,
+		updateTransactionSet,
+		deleteTransactionSet,
+		listTransactionHistorySet,
+		listCategorySet,
+		totalCategorySet,
+		ledgerSet,
+		undoSet,
+		idempotencySet,
+		webhookSet,
+		eventsSet,
+		healthSet,
+		metricsSet,
+		grpcSet,
+		graphqlSet,
 		rest.NewAPI,
+		newServer,
+	))
+}
+
+func initMigrator(ctx context.Context) (*migrate.Migrator, func(), error) {
+	panic(wire.Build(
+		details.NewConfig,
+		newMigrationStorage,
+		wire.FieldsOf(new(*storage), "Migrator"),
+	))
+}
+
+func initLocal(ctx context.Context) (*local, func(), error) {
+	panic(wire.Build(
+		details.NewConfig,
+		newStorage,
+		wire.FieldsOf(new(*storage), "Repository", "EventStore"),
+		eventsSet,
+		wire.Struct(new(local), "Repository", "Publisher"),
 	))
 }
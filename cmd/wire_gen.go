@@ -1,39 +1,184 @@
 // Code generated by Wire. DO NOT EDIT.
 
 //go:generate wire
-//+build !wireinject
+//go:build !wireinject
+// +build !wireinject
 
 package main
 
 import (
+	"context"
 	"github.com/google/wire"
 	"github.com/gritt/maskada/core"
 	"github.com/gritt/maskada/details"
-	"github.com/gritt/maskada/details/db"
+	"github.com/gritt/maskada/details/graphql"
+	"github.com/gritt/maskada/details/grpc"
+	"github.com/gritt/maskada/details/health"
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/metrics"
+	"github.com/gritt/maskada/details/migrate"
 	"github.com/gritt/maskada/details/rest"
+	"github.com/gritt/maskada/details/tracing"
+	"github.com/gritt/maskada/details/webhook"
 )
 
 // Injectors from wire.go:
 
-func initAPI() (*rest.API, error) {
-	config, err := details.NewConfig()
+func initServer(ctx context.Context, cfg *details.Config) (*server, func(), error) {
+	mainStorage, cleanup, err := newStorage(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	repository := mainStorage.Repository
+	store := mainStorage.EventStore
+	mainPublishing, cleanup2, err := newPublishing(cfg, store)
 	if err != nil {
-		return nil, err
+		cleanup()
+		return nil, nil, err
 	}
-	repository, err := db.NewRepository(config)
+	publisher := mainPublishing.Publisher
+	createTransactionUseCase := core.NewCreateTransactionUseCase(repository, publisher)
+	transactionCreator := tracing.NewTransactionCreator(createTransactionUseCase)
+	metricsMetrics, err := newMetrics(mainStorage)
 	if err != nil {
-		return nil, err
+		cleanup2()
+		cleanup()
+		return nil, nil, err
 	}
-	createTransactionUseCase := core.NewCreateTransactionUseCase(repository)
+	metricsTransactionCreator := metrics.NewTransactionCreator(transactionCreator, metricsMetrics)
+	createTransactionBatchUseCase := core.NewCreateTransactionBatchUseCase(repository, publisher)
+	transactionBatchCreator := tracing.NewTransactionBatchCreator(createTransactionBatchUseCase)
 	listTransactionUseCase := core.NewListTransactionUseCase(repository)
-	api := rest.NewAPI(createTransactionUseCase, listTransactionUseCase)
-	return api, nil
+	transactionLister := tracing.NewTransactionLister(listTransactionUseCase)
+	getTransactionUseCase := core.NewGetTransactionUseCase(repository)
+	transactionGetter := tracing.NewTransactionGetter(getTransactionUseCase)
+	updateTransactionUseCase := core.NewUpdateTransactionUseCase(repository, publisher)
+	transactionUpdater := tracing.NewTransactionUpdater(updateTransactionUseCase)
+	deleteTransactionUseCase := core.NewDeleteTransactionUseCase(repository, publisher)
+	transactionDeleter := tracing.NewTransactionDeleter(deleteTransactionUseCase)
+	listTransactionHistoryUseCase := core.NewListTransactionHistoryUseCase(repository)
+	transactionHistoryLister := tracing.NewTransactionHistoryLister(listTransactionHistoryUseCase)
+	idempotencyStore := mainStorage.IdempotencyStore
+	middleware := idempotency.NewMiddleware(idempotencyStore, cfg)
+	pinger := mainStorage.Pinger
+	migrator := mainStorage.Migrator
+	checker := health.NewChecker(pinger, migrator)
+	api := rest.NewAPI(metricsTransactionCreator, transactionBatchCreator, transactionLister, transactionGetter, transactionUpdater, transactionDeleter, transactionHistoryLister, middleware, checker, metricsMetrics)
+	service := grpc.NewService(metricsTransactionCreator, transactionLister)
+	listCategoryUseCase := core.NewListCategoryUseCase(repository)
+	categoryLister := tracing.NewCategoryLister(listCategoryUseCase)
+	totalCategoryUseCase := core.NewTotalCategoryUseCase(repository)
+	categoryTotaler := tracing.NewCategoryTotaler(totalCategoryUseCase)
+	handler := graphql.NewHandler(transactionLister, transactionGetter, categoryLister, categoryTotaler)
+	webhookStore := mainStorage.WebhookStore
+	webhookService := webhook.NewService(webhookStore)
+	dispatcher := webhook.NewDispatcher(webhookStore, cfg)
+	relay := mainPublishing.Relay
+	sweeper := idempotency.NewSweeper(idempotencyStore, cfg)
+	eventsSweeper := newOutboxSweeper(cfg, store)
+	eventLog := mainStorage.EventLog
+	ledgerUseCase := core.NewLedgerUseCase(eventLog)
+	ledger := tracing.NewLedger(ledgerUseCase)
+	journal := mainStorage.Journal
+	undoUseCase := core.NewUndoUseCase(journal, publisher)
+	undo := tracing.NewUndo(undoUseCase)
+	mainProjection := mainStorage.Projection
+	mainServer, err := newServer(cfg, api, service, handler, webhookService, dispatcher, relay, sweeper, eventsSweeper, ledger, undo, mainProjection)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	return mainServer, func() {
+		cleanup2()
+		cleanup()
+	}, nil
+}
+
+func initMigrator(ctx context.Context) (*migrate.Migrator, func(), error) {
+	config, err := details.NewConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	mainStorage, cleanup, err := newMigrationStorage(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	migrator := mainStorage.Migrator
+	return migrator, func() {
+		cleanup()
+	}, nil
+}
+
+func initLocal(ctx context.Context) (*local, func(), error) {
+	config, err := details.NewConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	mainStorage, cleanup, err := newStorage(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	repository := mainStorage.Repository
+	store := mainStorage.EventStore
+	mainPublishing, cleanup2, err := newPublishing(config, store)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	publisher := mainPublishing.Publisher
+	mainLocal := &local{
+		Repository: repository,
+		Publisher:  publisher,
+	}
+	return mainLocal, func() {
+		cleanup2()
+		cleanup()
+	}, nil
 }
 
 // wire.go:
 
-var repositorySet = wire.NewSet(details.NewConfig, wire.Bind(new(core.Repository), new(*db.Repository)), db.NewRepository)
+var repositorySet = wire.NewSet(
+	newStorage, wire.FieldsOf(new(*storage), "Repository", "EventLog", "Projection", "Journal", "IdempotencyStore", "WebhookStore", "EventStore", "Pinger", "Migrator"),
+)
+
+var createTransactionSet = wire.NewSet(wire.Bind(new(rest.TransactionCreator), new(*metrics.TransactionCreator)), wire.Bind(new(grpc.TransactionCreator), new(*metrics.TransactionCreator)), wire.Bind(new(metrics.Creator), new(*tracing.TransactionCreator)), wire.Bind(new(tracing.Creator), new(*core.CreateTransactionUseCase)), metrics.NewTransactionCreator, tracing.NewTransactionCreator, core.NewCreateTransactionUseCase)
+
+var createTransactionBatchSet = wire.NewSet(wire.Bind(new(rest.TransactionBatchCreator), new(*tracing.TransactionBatchCreator)), wire.Bind(new(tracing.BatchCreator), new(*core.CreateTransactionBatchUseCase)), tracing.NewTransactionBatchCreator, core.NewCreateTransactionBatchUseCase)
+
+var listTransactionSet = wire.NewSet(wire.Bind(new(rest.TransactionLister), new(*tracing.TransactionLister)), wire.Bind(new(grpc.TransactionLister), new(*tracing.TransactionLister)), wire.Bind(new(graphql.TransactionLister), new(*tracing.TransactionLister)), wire.Bind(new(tracing.Lister), new(*core.ListTransactionUseCase)), tracing.NewTransactionLister, core.NewListTransactionUseCase)
+
+var getTransactionSet = wire.NewSet(wire.Bind(new(rest.TransactionGetter), new(*tracing.TransactionGetter)), wire.Bind(new(graphql.TransactionGetter), new(*tracing.TransactionGetter)), wire.Bind(new(tracing.Getter), new(*core.GetTransactionUseCase)), tracing.NewTransactionGetter, core.NewGetTransactionUseCase)
+
+var updateTransactionSet = wire.NewSet(wire.Bind(new(rest.TransactionUpdater), new(*tracing.TransactionUpdater)), wire.Bind(new(tracing.Updater), new(*core.UpdateTransactionUseCase)), tracing.NewTransactionUpdater, core.NewUpdateTransactionUseCase)
+
+var deleteTransactionSet = wire.NewSet(wire.Bind(new(rest.TransactionDeleter), new(*tracing.TransactionDeleter)), wire.Bind(new(tracing.Deleter), new(*core.DeleteTransactionUseCase)), tracing.NewTransactionDeleter, core.NewDeleteTransactionUseCase)
+
+var listTransactionHistorySet = wire.NewSet(wire.Bind(new(rest.TransactionHistoryLister), new(*tracing.TransactionHistoryLister)), wire.Bind(new(tracing.HistoryLister), new(*core.ListTransactionHistoryUseCase)), tracing.NewTransactionHistoryLister, core.NewListTransactionHistoryUseCase)
+
+var listCategorySet = wire.NewSet(wire.Bind(new(graphql.CategoryLister), new(*tracing.CategoryLister)), wire.Bind(new(tracing.CategoriesLister), new(*core.ListCategoryUseCase)), tracing.NewCategoryLister, core.NewListCategoryUseCase)
 
-var createTransactionSet = wire.NewSet(wire.Bind(new(rest.TransactionCreator), new(*core.CreateTransactionUseCase)), core.NewCreateTransactionUseCase)
+var totalCategorySet = wire.NewSet(wire.Bind(new(graphql.CategoryTotaler), new(*tracing.CategoryTotaler)), wire.Bind(new(tracing.Totaler), new(*core.TotalCategoryUseCase)), tracing.NewCategoryTotaler, core.NewTotalCategoryUseCase)
+
+var ledgerSet = wire.NewSet(wire.Bind(new(rest.LedgerReader), new(*tracing.Ledger)), wire.Bind(new(tracing.LedgerReader), new(*core.LedgerUseCase)), tracing.NewLedger, core.NewLedgerUseCase)
+
+var undoSet = wire.NewSet(wire.Bind(new(rest.Undoer), new(*tracing.Undo)), wire.Bind(new(tracing.Undoer), new(*core.UndoUseCase)), tracing.NewUndo, core.NewUndoUseCase)
+
+var graphqlSet = wire.NewSet(graphql.NewHandler)
+
+var grpcSet = wire.NewSet(grpc.NewService)
+
+var webhookSet = wire.NewSet(wire.Bind(new(rest.WebhookManager), new(*webhook.Service)), webhook.NewService, webhook.NewDispatcher)
+
+var eventsSet = wire.NewSet(
+	newPublishing, wire.FieldsOf(new(*publishing), "Publisher", "Relay"), newOutboxSweeper,
+)
+
+var idempotencySet = wire.NewSet(idempotency.NewMiddleware, idempotency.NewSweeper)
+
+var metricsSet = wire.NewSet(
+	newMetrics,
+)
 
-var listTransactionSet = wire.NewSet(wire.Bind(new(rest.TransactionLister), new(*core.ListTransactionUseCase)), core.NewListTransactionUseCase)
+var healthSet = wire.NewSet(wire.Bind(new(rest.HealthChecker), new(*health.Checker)), health.NewChecker)
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// ListCategoryUseCase implements the business logic to find the categories.
+	ListCategoryUseCase struct {
+		repository Repository
+	}
+
+	// TotalCategoryUseCase implements the business logic to sum the transactions of categories.
+	TotalCategoryUseCase struct {
+		repository Repository
+	}
+)
+
+// NewListCategoryUseCase initialize the use case.
+func NewListCategoryUseCase(r Repository) *ListCategoryUseCase {
+	return &ListCategoryUseCase{repository: r}
+}
+
+// List the categories, ordered by name.
+func (uc *ListCategoryUseCase) List(ctx context.Context) ([]Category, error) {
+	categories, err := uc.repository.FindCategories(ctx)
+	if err != nil {
+		return []Category{}, errors.Wrap(err, "ListCategories failed")
+	}
+
+	return categories, nil
+}
+
+// NewTotalCategoryUseCase initialize the use case.
+func NewTotalCategoryUseCase(r Repository) *TotalCategoryUseCase {
+	return &TotalCategoryUseCase{repository: r}
+}
+
+// Totals sums the transactions of each named category at once, a category without transactions totaling zero.
+func (uc *TotalCategoryUseCase) Totals(ctx context.Context, names []string) (map[string]Totals, error) {
+	totals, err := uc.repository.SumByCategory(ctx, names)
+	if err != nil {
+		return map[string]Totals{}, errors.Wrap(err, "Totals failed")
+	}
+
+	for _, name := range names {
+		if _, ok := totals[name]; !ok {
+			totals[name] = Totals{}
+		}
+	}
+
+	return totals, nil
+}
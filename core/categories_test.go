@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListCategoryUseCase_List(t *testing.T) {
+	tests := map[string]func(t *testing.T, m *mockRepository){
+		"when repository fails to list categories": func(t *testing.T, m *mockRepository) {
+			// arrange
+			m.On("FindCategories").Return([]Category{}, errors.New("Repository.FindCategories: err"))
+			uc := NewListCategoryUseCase(m)
+
+			// act
+			got, gotErr := uc.List(context.Background())
+
+			// assert
+			assert.EqualError(t, gotErr, "ListCategories failed: Repository.FindCategories: err")
+			assert.Empty(t, got)
+		},
+		"when repository returns categories": func(t *testing.T, m *mockRepository) {
+			// arrange
+			m.On("FindCategories").Return([]Category{{Name: "Food"}, {Name: "Home"}}, nil)
+			uc := NewListCategoryUseCase(m)
+
+			// act
+			got, gotErr := uc.List(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []Category{{Name: "Food"}, {Name: "Home"}}, got)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockRepository)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTotalCategoryUseCase_Totals(t *testing.T) {
+	tests := map[string]func(t *testing.T, m *mockRepository){
+		"when repository fails to sum categories": func(t *testing.T, m *mockRepository) {
+			// arrange
+			m.On("SumByCategory", []string{"Food"}).Return(map[string]Totals{}, errors.New("Repository.SumByCategory: err"))
+			uc := NewTotalCategoryUseCase(m)
+
+			// act
+			got, gotErr := uc.Totals(context.Background(), []string{"Food"})
+
+			// assert
+			assert.EqualError(t, gotErr, "Totals failed: Repository.SumByCategory: err")
+			assert.Empty(t, got)
+		},
+		"when a category has no transactions, it totals zero": func(t *testing.T, m *mockRepository) {
+			// arrange
+			m.On("SumByCategory", []string{"Food", "Travel"}).Return(map[string]Totals{
+				"Food": {Debit: 200, Count: 2},
+			}, nil)
+			uc := NewTotalCategoryUseCase(m)
+
+			// act
+			got, gotErr := uc.Totals(context.Background(), []string{"Food", "Travel"})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, map[string]Totals{
+				"Food":   {Debit: 200, Count: 2},
+				"Travel": {},
+			}, got)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockRepository)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
 )
@@ -16,6 +17,61 @@ const (
 	Income = 3
 )
 
+// Uncategorized is the category of transactions yet to be categorized, eg: imported from a bank statement.
+const Uncategorized = "Uncategorized"
+
+const (
+	// AuditCreate is recorded when an entity is created.
+	AuditCreate = "create"
+
+	// AuditUpdate is recorded when an entity is changed.
+	AuditUpdate = "update"
+
+	// AuditDelete is recorded when an entity is removed.
+	AuditDelete = "delete"
+)
+
+const (
+	// AuditTransaction is the audited entity name of a Transaction.
+	AuditTransaction = "transaction"
+
+	// AuditCategory is the audited entity name of a Category.
+	AuditCategory = "category"
+)
+
+var (
+	// ErrNotFound is returned when the requested entity does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrStaleVersion is returned when changing an entity which was changed since it was read.
+	ErrStaleVersion = errors.New("stale version")
+
+	// ErrInvalidBatch is returned when a batch that must be created as a whole holds invalid transactions.
+	ErrInvalidBatch = errors.New("invalid transactions in batch")
+
+	// ErrInvalidTransaction is the cause of the errors returned by Transaction.Validate.
+	ErrInvalidTransaction = errors.New("invalid transaction")
+
+	// ErrNothingToUndo is returned when undoing while the journal of the actor has no operation left to undo.
+	ErrNothingToUndo = errors.New("nothing to undo")
+
+	// ErrNothingToRedo is returned when redoing while the journal of the actor has no undone operation, eg: as an
+	// operation made after an undo discards it.
+	ErrNothingToRedo = errors.New("nothing to redo")
+
+	// ErrBeforeHistory is returned when rebuilding the transactions as of a time before the first recorded event, as
+	// they are not known then.
+	ErrBeforeHistory = errors.New("as of before the history")
+)
+
+// validationError describes why a transaction is invalid, caused by ErrInvalidTransaction.
+type validationError string
+
+func (e validationError) Error() string { return string(e) }
+
+// Cause returns ErrInvalidTransaction, as errors.Cause of github.com/pkg/errors expects.
+func (e validationError) Cause() error { return ErrInvalidTransaction }
+
 type (
 	// Category is the general class of a Transaction (eg: Health, Food).
 	Category struct {
@@ -30,21 +86,86 @@ type (
 		Category Category
 		Date     time.Time
 		Name     string
+		Version  int
+	}
+
+	// Cursor is the position of a Transaction in the order they are listed, by date then by id, the zero one being
+	// before the first.
+	Cursor struct {
+		Date time.Time
+		ID   int
+	}
+
+	// Filter narrows the transactions to those matching each of its fields set, the bounds of their date being
+	// inclusive, the zero one matching every transaction.
+	Filter struct {
+		Category string
+		Type     int
+		From     time.Time
+		To       time.Time
+	}
+
+	// BatchResult is the outcome of creating a transaction of a batch, at the same index.
+	BatchResult struct {
+		Transaction Transaction
+		Err         error
+	}
+
+	// Totals sums the amounts of transactions by type, along with how many they are.
+	Totals struct {
+		Debit  int
+		Credit int
+		Income int
+		Count  int
+	}
+
+	// AuditEntry is an append-only record of a change made to a Transaction or Category.
+	AuditEntry struct {
+		ID       int
+		Entity   string
+		EntityID string
+		Action   string
+		Actor    string
+		Date     time.Time
+		Before   json.RawMessage
+		After    json.RawMessage
 	}
 )
 
+// Cursor is the position of the transaction in the order they are listed.
+func (t *Transaction) Cursor() Cursor {
+	return Cursor{Date: t.Date, ID: t.ID}
+}
+
+// Match tells whether the transaction matches the filter.
+func (f Filter) Match(t Transaction) bool {
+	if f.Category != "" && t.Category.Name != f.Category {
+		return false
+	}
+	if f.Type != 0 && t.Type != f.Type {
+		return false
+	}
+	if !f.From.IsZero() && t.Date.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && t.Date.After(f.To) {
+		return false
+	}
+	return true
+}
+
 // Validate whether a transaction has all it's required properties set.
 func (t *Transaction) Validate() error {
 	if t.Amount <= 0 {
-		return errors.New("Transaction.Validate: invalid amount")
+		return validationError("Transaction.Validate: invalid amount")
 	}
 
 	if t.Type != Debit && t.Type != Credit && t.Type != Income {
-		return errors.New("Transaction.Validate: invalid type")
+		return validationError("Transaction.Validate: invalid type")
 	}
 
 	if t.Category.Name == "" {
-		return errors.New("Transaction.Validate: invalid category")
+		return validationError("Transaction.Validate: invalid category")
 	}
 
 	return nil
@@ -3,6 +3,7 @@ package core
 import (
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/gritt/maskada/test"
@@ -23,6 +24,7 @@ func TestTransaction_validate(t *testing.T) {
 
 			// assert
 			assert.EqualError(t, gotErr, "Transaction.Validate: invalid amount")
+			assert.Equal(t, ErrInvalidTransaction, errors.Cause(errors.Wrap(gotErr, "Create failed")))
 		},
 		"when zero amount": func(t *testing.T) {
 			// arrange
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// EventTransactionCreated is the name of a TransactionCreated event.
+	EventTransactionCreated = "transaction.created"
+
+	// EventTransactionUpdated is the name of a TransactionUpdated event.
+	EventTransactionUpdated = "transaction.updated"
+
+	// EventTransactionDeleted is the name of a TransactionDeleted event.
+	EventTransactionDeleted = "transaction.deleted"
+
+	// EventCategoryCreated is the name of a CategoryCreated event.
+	EventCategoryCreated = "category.created"
+)
+
+// Events are the names of the events published by the use cases.
+var Events = []string{EventTransactionCreated, EventTransactionUpdated, EventTransactionDeleted, EventCategoryCreated}
+
+type (
+	// Event is a change made by a use case, published once it is saved.
+	Event interface {
+		EventName() string
+	}
+
+	// Publisher represents a client able to notify the subscribers of events, eg: in process, through the outbox or
+	// to NATS. The changes the events record are saved already, so publishing does not fail them: each adapter
+	// handles its own failures.
+	Publisher interface {
+		Publish(ctx context.Context, events ...Event)
+	}
+
+	// TransactionCreated is published once a transaction is created.
+	TransactionCreated struct {
+		Transaction Transaction
+		Actor       string
+		Date        time.Time
+	}
+
+	// TransactionUpdated is published once a transaction is changed, holding the transaction once changed.
+	TransactionUpdated struct {
+		Transaction Transaction
+		Actor       string
+		Date        time.Time
+	}
+
+	// TransactionDeleted is published once a transaction is removed, holding the transaction as it was removed.
+	TransactionDeleted struct {
+		Transaction Transaction
+		Actor       string
+		Date        time.Time
+	}
+
+	// CategoryCreated is published once a category which did not exist is saved, eg: along with a transaction.
+	CategoryCreated struct {
+		Category Category
+		Actor    string
+		Date     time.Time
+	}
+)
+
+// EventName is EventTransactionCreated.
+func (TransactionCreated) EventName() string { return EventTransactionCreated }
+
+// EventName is EventTransactionUpdated.
+func (TransactionUpdated) EventName() string { return EventTransactionUpdated }
+
+// EventName is EventTransactionDeleted.
+func (TransactionDeleted) EventName() string { return EventTransactionDeleted }
+
+// EventName is EventCategoryCreated.
+func (CategoryCreated) EventName() string { return EventCategoryCreated }
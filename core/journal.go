@@ -0,0 +1,159 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// JournalCreate is journaled when a transaction is created.
+	JournalCreate = "create"
+
+	// JournalCreateBatch is journaled when a batch of transactions is created.
+	JournalCreateBatch = "create_batch"
+
+	// JournalUpdate is journaled when a transaction is changed.
+	JournalUpdate = "update"
+
+	// JournalDelete is journaled when a transaction is removed.
+	JournalDelete = "delete"
+)
+
+// JournalSize is the number of operations journaled per actor, the older ones can no longer be undone.
+const JournalSize = 20
+
+type (
+	// Change is a change of a transaction: its Before is zero when it was created, and its After when it was removed.
+	Change struct {
+		Before Transaction
+		After  Transaction
+	}
+
+	// JournalEntry is an operation made by an actor, holding its Changes as last applied, in order: once undone, the
+	// ones applied by the undo, so the entry is redone by inverting them again.
+	JournalEntry struct {
+		ID        int
+		Actor     string
+		Operation string
+		Changes   []Change
+		Undone    bool
+		Date      time.Time
+	}
+
+	// Journal represents a client able to undo and redo the latest operations of an actor. Each inverse is applied
+	// atomically, and refused with ErrStaleVersion unless each transaction it changes is at the version the entry
+	// holds, eg: when it was changed or removed since, even back to the same values. The versions keep increasing, a
+	// restored transaction being given the next version of the removed one, and the entry holding the changes as
+	// applied.
+	Journal interface {
+		// Undo applies the inverse of the latest operation of the actor not undone yet, returning it undone.
+		Undo(ctx context.Context, actor string) (JournalEntry, error)
+
+		// Redo applies the inverse of the latest operation of the actor undone, returning it done again. An operation
+		// made after an undo discards the undone ones.
+		Redo(ctx context.Context, actor string) (JournalEntry, error)
+	}
+
+	// UndoUseCase implements the business logic to undo and redo the latest operations of an actor.
+	UndoUseCase struct {
+		journal   Journal
+		publisher Publisher
+	}
+)
+
+// Created tells whether the change created the transaction.
+func (c Change) Created() bool {
+	return c.Before.ID == 0
+}
+
+// Deleted tells whether the change removed the transaction.
+func (c Change) Deleted() bool {
+	return c.After.ID == 0
+}
+
+// Event returns the event recording the change, made by the actor at date.
+func (c Change) Event(actor string, date time.Time) Event {
+	switch {
+	case c.Created():
+		return TransactionCreated{Transaction: c.After, Actor: actor, Date: date}
+	case c.Deleted():
+		return TransactionDeleted{Transaction: c.Before, Actor: actor, Date: date}
+	default:
+		return TransactionUpdated{Transaction: c.After, Actor: actor, Date: date}
+	}
+}
+
+// Events returns the events recording the changes of the entry, as last applied by its actor.
+func (e JournalEntry) Events() []Event {
+	now := time.Now().UTC()
+
+	events := make([]Event, 0, len(e.Changes))
+	for _, c := range e.Changes {
+		events = append(events, c.Event(e.Actor, now))
+	}
+	return events
+}
+
+// Inverse returns the changes reverting the entry, in reverse order. A removed transaction is restored with its
+// former ID.
+func (e JournalEntry) Inverse() []Change {
+	inverse := make([]Change, 0, len(e.Changes))
+	for i := len(e.Changes) - 1; i >= 0; i-- {
+		inverse = append(inverse, Change{Before: e.Changes[i].After, After: e.Changes[i].Before})
+	}
+	return inverse
+}
+
+// Rebase gives the transactions of the entry at the version an inverse reverted them to the version they were applied
+// at, as an undo or redo of another entry bumps it, so the entry still expects them as they are, telling whether it
+// gave any.
+func (e *JournalEntry) Rebase(inverse, applied []Change) bool {
+	rebased := false
+	for i, c := range applied {
+		target := inverse[i].After
+		if c.Deleted() || target.Version == c.After.Version {
+			continue
+		}
+
+		for j := range e.Changes {
+			for _, t := range []*Transaction{&e.Changes[j].Before, &e.Changes[j].After} {
+				if t.ID == target.ID && t.Version == target.Version {
+					t.Version = c.After.Version
+					rebased = true
+				}
+			}
+		}
+	}
+	return rebased
+}
+
+// NewUndoUseCase initialize the use case.
+func NewUndoUseCase(j Journal, p Publisher) *UndoUseCase {
+	return &UndoUseCase{journal: j, publisher: p}
+}
+
+// Undo the latest operation of the actor.
+func (uc *UndoUseCase) Undo(ctx context.Context, actor string) (JournalEntry, error) {
+	entry, err := uc.journal.Undo(ctx, actor)
+	if err != nil {
+		return JournalEntry{}, errors.Wrap(err, "Undo failed")
+	}
+
+	uc.publisher.Publish(ctx, entry.Events()...)
+
+	return entry, nil
+}
+
+// Redo the latest operation of the actor undone.
+func (uc *UndoUseCase) Redo(ctx context.Context, actor string) (JournalEntry, error) {
+	entry, err := uc.journal.Redo(ctx, actor)
+	if err != nil {
+		return JournalEntry{}, errors.Wrap(err, "Redo failed")
+	}
+
+	uc.publisher.Publish(ctx, entry.Events()...)
+
+	return entry, nil
+}
@@ -0,0 +1,263 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestJournalEntry_Inverse(t *testing.T) {
+	lunch := Transaction{ID: 1, Amount: 100, Type: Debit, Category: Category{Name: "Food"}, Version: 1}
+	lunchChanged := lunch
+	lunchChanged.Amount = 150
+	lunchChanged.Version = 2
+	rent := Transaction{ID: 2, Amount: 900, Type: Debit, Category: Category{Name: "Rent"}, Version: 1}
+
+	tests := map[string]struct {
+		entry JournalEntry
+		want  []Change
+	}{
+		"when a transaction was created, it is removed": {
+			entry: JournalEntry{Changes: []Change{{After: lunch}}},
+			want:  []Change{{Before: lunch}},
+		},
+		"when a transaction was changed, it is changed back": {
+			entry: JournalEntry{Changes: []Change{{Before: lunch, After: lunchChanged}}},
+			want:  []Change{{Before: lunchChanged, After: lunch}},
+		},
+		"when a transaction was removed, it is restored": {
+			entry: JournalEntry{Changes: []Change{{Before: lunch}}},
+			want:  []Change{{After: lunch}},
+		},
+		"when many transactions were changed, they are reverted in reverse order": {
+			entry: JournalEntry{Changes: []Change{{After: lunch}, {After: rent}}},
+			want:  []Change{{Before: rent}, {Before: lunch}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			got := tt.entry.Inverse()
+
+			// assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestJournalEntry_Rebase(t *testing.T) {
+	lunch := Transaction{ID: 1, Amount: 100, Type: Debit, Category: Category{Name: "Food"}, Version: 1}
+	lunchChanged := lunch
+	lunchChanged.Amount = 150
+	lunchChanged.Version = 2
+	lunchReverted := lunch
+	lunchReverted.Version = 3
+	rent := Transaction{ID: 2, Amount: 900, Type: Debit, Category: Category{Name: "Rent"}, Version: 1}
+
+	tests := map[string]struct {
+		entry       JournalEntry
+		inverse     []Change
+		applied     []Change
+		want        []Change
+		wantRebased bool
+	}{
+		"when a transaction was changed back, its version is rebased": {
+			entry:       JournalEntry{Changes: []Change{{After: lunch}}},
+			inverse:     []Change{{Before: lunchChanged, After: lunch}},
+			applied:     []Change{{Before: lunchChanged, After: lunchReverted}},
+			want:        []Change{{After: lunchReverted}},
+			wantRebased: true,
+		},
+		"when a transaction was removed, nothing is rebased": {
+			entry:   JournalEntry{Changes: []Change{{Before: lunch, After: lunchChanged}}},
+			inverse: []Change{{Before: lunchChanged}},
+			applied: []Change{{Before: lunchChanged}},
+			want:    []Change{{Before: lunch, After: lunchChanged}},
+		},
+		"when another transaction, or version, was reverted, nothing is rebased": {
+			entry:   JournalEntry{Changes: []Change{{After: rent}, {Before: lunch, After: lunchChanged}}},
+			inverse: []Change{{After: lunchReverted}},
+			applied: []Change{{After: Transaction{ID: 1, Version: 4}}},
+			want:    []Change{{After: rent}, {Before: lunch, After: lunchChanged}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			gotRebased := tt.entry.Rebase(tt.inverse, tt.applied)
+
+			// assert
+			assert.Equal(t, tt.wantRebased, gotRebased)
+			assert.Equal(t, tt.want, tt.entry.Changes)
+		})
+	}
+}
+
+func TestChange_Event(t *testing.T) {
+	actor := "alice"
+	date := time.Now().UTC()
+	lunch := Transaction{ID: 1, Amount: 100, Type: Debit, Category: Category{Name: "Food"}, Version: 1}
+	lunchChanged := lunch
+	lunchChanged.Amount = 150
+	lunchChanged.Version = 2
+
+	tests := map[string]struct {
+		change Change
+		want   Event
+	}{
+		"when a transaction is created": {
+			change: Change{After: lunch},
+			want:   TransactionCreated{Transaction: lunch, Actor: actor, Date: date},
+		},
+		"when a transaction is changed": {
+			change: Change{Before: lunch, After: lunchChanged},
+			want:   TransactionUpdated{Transaction: lunchChanged, Actor: actor, Date: date},
+		},
+		"when a transaction is removed": {
+			change: Change{Before: lunch},
+			want:   TransactionDeleted{Transaction: lunch, Actor: actor, Date: date},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			got := tt.change.Event(actor, date)
+
+			// assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUndoUseCase_Undo(t *testing.T) {
+	actor := "alice"
+	lunch := Transaction{ID: 1, Amount: 100, Type: Debit, Category: Category{Name: "Food"}, Version: 2}
+	rent := Transaction{ID: 2, Amount: 900, Type: Debit, Category: Category{Name: "Rent"}, Version: 3}
+	lunchChanged := lunch
+	lunchChanged.Amount = 150
+	lunchChanged.Version = 3
+
+	tests := map[string]func(t *testing.T, m *mockJournal, p *mockPublisher){
+		"when journal fails to undo": func(t *testing.T, m *mockJournal, p *mockPublisher) {
+			// arrange
+			m.On("Undo", actor).Return(JournalEntry{}, ErrNothingToUndo)
+			uc := NewUndoUseCase(m, p)
+
+			// act
+			got, gotErr := uc.Undo(context.Background(), actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "Undo failed: nothing to undo")
+			assert.Empty(t, got)
+			assert.Empty(t, p.published)
+		},
+		"when journal undoes": func(t *testing.T, m *mockJournal, p *mockPublisher) {
+			// arrange
+			entry := JournalEntry{
+				ID:        4,
+				Actor:     actor,
+				Operation: JournalCreateBatch,
+				Changes:   []Change{{Before: rent}, {Before: lunch, After: lunchChanged}, {After: lunch}},
+				Undone:    true,
+			}
+			m.On("Undo", actor).Return(entry, nil)
+			uc := NewUndoUseCase(m, p)
+
+			// act
+			got, gotErr := uc.Undo(context.Background(), actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, entry, got)
+			assert.Equal(t, []Event{
+				TransactionDeleted{Transaction: rent, Actor: actor},
+				TransactionUpdated{Transaction: lunchChanged, Actor: actor},
+				TransactionCreated{Transaction: lunch, Actor: actor},
+			}, undated(p.published))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockJournal)
+			p := new(mockPublisher)
+
+			// act
+			run(t, m, p)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUndoUseCase_Redo(t *testing.T) {
+	actor := "alice"
+	lunch := Transaction{ID: 1, Amount: 100, Type: Debit, Category: Category{Name: "Food"}, Version: 3}
+
+	tests := map[string]func(t *testing.T, m *mockJournal, p *mockPublisher){
+		"when journal fails to redo": func(t *testing.T, m *mockJournal, p *mockPublisher) {
+			// arrange
+			m.On("Redo", actor).Return(JournalEntry{}, errors.New("Repository.Redo failed: err"))
+			uc := NewUndoUseCase(m, p)
+
+			// act
+			got, gotErr := uc.Redo(context.Background(), actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "Redo failed: Repository.Redo failed: err")
+			assert.Empty(t, got)
+			assert.Empty(t, p.published)
+		},
+		"when journal redoes": func(t *testing.T, m *mockJournal, p *mockPublisher) {
+			// arrange
+			entry := JournalEntry{ID: 4, Actor: actor, Operation: JournalDelete, Changes: []Change{{Before: lunch}}}
+			m.On("Redo", actor).Return(entry, nil)
+			uc := NewUndoUseCase(m, p)
+
+			// act
+			got, gotErr := uc.Redo(context.Background(), actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, entry, got)
+			assert.Equal(t, []Event{TransactionDeleted{Transaction: lunch, Actor: actor}}, undated(p.published))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockJournal)
+			p := new(mockPublisher)
+
+			// act
+			run(t, m, p)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+type mockJournal struct {
+	mock.Mock
+}
+
+func (m *mockJournal) Undo(_ context.Context, actor string) (JournalEntry, error) {
+	args := m.Called(actor)
+	return args.Get(0).(JournalEntry), args.Error(1)
+}
+
+func (m *mockJournal) Redo(_ context.Context, actor string) (JournalEntry, error) {
+	args := m.Called(actor)
+	return args.Get(0).(JournalEntry), args.Error(1)
+}
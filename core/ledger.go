@@ -0,0 +1,122 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// TransactionEvent is a change of a transaction, as appended to the event log: its Action is one of AuditCreate,
+	// AuditUpdate or AuditDelete, and its Transaction the one once changed, or as removed.
+	TransactionEvent struct {
+		ID          int
+		Action      string
+		Transaction Transaction
+		Actor       string
+		RecordedAt  time.Time
+	}
+
+	// EventLog represents a client able to find the transactions as they were known at a given time, from the events
+	// appended by each change of a transaction.
+	EventLog interface {
+		// FindAsOf finds the transactions as they were known at asOf, ordered by date then id.
+		FindAsOf(ctx context.Context, asOf time.Time) ([]Transaction, error)
+
+		// FindMonthAsOf finds the transactions dated in the month starting at from, as they were known at asOf,
+		// ordered by date then id.
+		FindMonthAsOf(ctx context.Context, from, asOf time.Time) ([]Transaction, error)
+
+		// FindStart finds when the first event was recorded, the zero time when none was.
+		FindStart(ctx context.Context) (time.Time, error)
+	}
+
+	// LedgerUseCase implements the business logic to rebuild the transactions as they were known at a given time.
+	LedgerUseCase struct {
+		log EventLog
+	}
+)
+
+// Rebuild replays the events in order, returning the transactions they leave, ordered by date then id, as Find does.
+// An update of a transaction without a prior event creates it, as it may predate the log.
+func Rebuild(events []TransactionEvent) []Transaction {
+	byID := map[int]Transaction{}
+	for _, e := range events {
+		switch e.Action {
+		case AuditCreate, AuditUpdate:
+			byID[e.Transaction.ID] = e.Transaction
+		case AuditDelete:
+			delete(byID, e.Transaction.ID)
+		}
+	}
+
+	ts := make([]Transaction, 0, len(byID))
+	for _, t := range byID {
+		ts = append(ts, t)
+	}
+	sort.Slice(ts, func(i, j int) bool {
+		if !ts[i].Date.Equal(ts[j].Date) {
+			return ts[i].Date.Before(ts[j].Date)
+		}
+		return ts[i].ID < ts[j].ID
+	})
+
+	return ts
+}
+
+// NewLedgerUseCase initialize the use case.
+func NewLedgerUseCase(l EventLog) *LedgerUseCase {
+	return &LedgerUseCase{log: l}
+}
+
+// Ledger rebuilds the transactions as they were known at asOf, eg: before some were back-filled.
+func (uc *LedgerUseCase) Ledger(ctx context.Context, asOf time.Time) ([]Transaction, error) {
+	if err := uc.since(ctx, asOf); err != nil {
+		return []Transaction{}, errors.Wrap(err, "Ledger failed")
+	}
+
+	ts, err := uc.log.FindAsOf(ctx, asOf)
+	if err != nil {
+		return []Transaction{}, errors.Wrap(err, "Ledger failed")
+	}
+
+	return ts, nil
+}
+
+// Summary totals the transactions of the month of the given date, as they were known at asOf, finding those of the
+// previous month as well for the credit due.
+func (uc *LedgerUseCase) Summary(ctx context.Context, month, asOf time.Time) (MonthlySummary, error) {
+	if err := uc.since(ctx, asOf); err != nil {
+		return MonthlySummary{}, errors.Wrap(err, "LedgerSummary failed")
+	}
+
+	from := StartOfMonth(month)
+
+	previous, err := uc.log.FindMonthAsOf(ctx, from.AddDate(0, -1, 0), asOf)
+	if err != nil {
+		return MonthlySummary{}, errors.Wrap(err, "LedgerSummary failed")
+	}
+
+	current, err := uc.log.FindMonthAsOf(ctx, from, asOf)
+	if err != nil {
+		return MonthlySummary{}, errors.Wrap(err, "LedgerSummary failed")
+	}
+
+	return Summarize(append(previous, current...), from), nil
+}
+
+// since fails with ErrBeforeHistory when asOf precedes the first event, eg: the transactions existing when the
+// events started being appended are recorded then, as they were.
+func (uc *LedgerUseCase) since(ctx context.Context, asOf time.Time) error {
+	start, err := uc.log.FindStart(ctx)
+	if err != nil {
+		return err
+	}
+	if asOf.Before(start) {
+		return ErrBeforeHistory
+	}
+
+	return nil
+}
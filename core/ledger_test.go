@@ -0,0 +1,240 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRebuild(t *testing.T) {
+	may := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	lunch := Transaction{ID: 1, Amount: 100, Type: Debit, Category: Category{Name: "Food"}, Date: may.AddDate(0, 0, 2), Version: 1}
+	lunchChanged := lunch
+	lunchChanged.Amount = 150
+	lunchChanged.Version = 2
+	rent := Transaction{ID: 2, Amount: 900, Type: Debit, Category: Category{Name: "Rent"}, Date: may, Version: 1}
+	dinner := Transaction{ID: 3, Amount: 200, Type: Debit, Category: Category{Name: "Food"}, Date: may, Version: 1}
+
+	tests := map[string]struct {
+		events []TransactionEvent
+		want   []Transaction
+	}{
+		"when there is no event": {
+			want: []Transaction{},
+		},
+		"when transactions are created, ordered by date then id": {
+			events: []TransactionEvent{
+				{ID: 1, Action: AuditCreate, Transaction: lunch},
+				{ID: 2, Action: AuditCreate, Transaction: dinner},
+				{ID: 3, Action: AuditCreate, Transaction: rent},
+			},
+			want: []Transaction{rent, dinner, lunch},
+		},
+		"when a transaction is updated, its last version is kept": {
+			events: []TransactionEvent{
+				{ID: 1, Action: AuditCreate, Transaction: lunch},
+				{ID: 2, Action: AuditUpdate, Transaction: lunchChanged},
+			},
+			want: []Transaction{lunchChanged},
+		},
+		"when a transaction is deleted, it is removed": {
+			events: []TransactionEvent{
+				{ID: 1, Action: AuditCreate, Transaction: lunch},
+				{ID: 2, Action: AuditCreate, Transaction: rent},
+				{ID: 3, Action: AuditDelete, Transaction: lunch},
+			},
+			want: []Transaction{rent},
+		},
+		"when a transaction predates the log, its update creates it": {
+			events: []TransactionEvent{
+				{ID: 1, Action: AuditUpdate, Transaction: lunchChanged},
+			},
+			want: []Transaction{lunchChanged},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			got := Rebuild(tt.events)
+
+			// assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLedgerUseCase_Ledger(t *testing.T) {
+	asOf := time.Date(2026, 6, 3, 0, 0, 0, 0, time.UTC)
+	lunch := Transaction{ID: 1, Amount: 100, Type: Debit, Category: Category{Name: "Food"}, Version: 1}
+
+	tests := map[string]func(t *testing.T, m *mockEventLog){
+		"when log fails to find its start": func(t *testing.T, m *mockEventLog) {
+			// arrange
+			m.On("FindStart").Return(time.Time{}, errors.New("Repository.FindStart failed: err"))
+			uc := NewLedgerUseCase(m)
+
+			// act
+			got, gotErr := uc.Ledger(context.Background(), asOf)
+
+			// assert
+			assert.EqualError(t, gotErr, "Ledger failed: Repository.FindStart failed: err")
+			assert.Empty(t, got)
+		},
+		"when as of is before the first event": func(t *testing.T, m *mockEventLog) {
+			// arrange
+			m.On("FindStart").Return(asOf.Add(time.Second), nil)
+			uc := NewLedgerUseCase(m)
+
+			// act
+			got, gotErr := uc.Ledger(context.Background(), asOf)
+
+			// assert
+			assert.EqualError(t, gotErr, "Ledger failed: as of before the history")
+			assert.Empty(t, got)
+			m.AssertNotCalled(t, "FindAsOf", asOf)
+		},
+		"when log fails to find the transactions": func(t *testing.T, m *mockEventLog) {
+			// arrange
+			m.On("FindStart").Return(asOf, nil)
+			m.On("FindAsOf", asOf).Return([]Transaction{}, errors.New("Repository.FindAsOf failed: err"))
+			uc := NewLedgerUseCase(m)
+
+			// act
+			got, gotErr := uc.Ledger(context.Background(), asOf)
+
+			// assert
+			assert.EqualError(t, gotErr, "Ledger failed: Repository.FindAsOf failed: err")
+			assert.Empty(t, got)
+		},
+		"when log finds the transactions": func(t *testing.T, m *mockEventLog) {
+			// arrange
+			m.On("FindStart").Return(asOf.Add(-time.Hour), nil)
+			m.On("FindAsOf", asOf).Return([]Transaction{lunch}, nil)
+			uc := NewLedgerUseCase(m)
+
+			// act
+			got, gotErr := uc.Ledger(context.Background(), asOf)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []Transaction{lunch}, got)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockEventLog)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLedgerUseCase_Summary(t *testing.T) {
+	april := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	may := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	asOf := time.Date(2026, 6, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]func(t *testing.T, m *mockEventLog){
+		"when as of is before the first event": func(t *testing.T, m *mockEventLog) {
+			// arrange
+			m.On("FindStart").Return(asOf.Add(time.Second), nil)
+			uc := NewLedgerUseCase(m)
+
+			// act
+			got, gotErr := uc.Summary(context.Background(), may, asOf)
+
+			// assert
+			assert.EqualError(t, gotErr, "LedgerSummary failed: as of before the history")
+			assert.Empty(t, got)
+		},
+		"when log fails to find the transactions of the previous month": func(t *testing.T, m *mockEventLog) {
+			// arrange
+			m.On("FindStart").Return(time.Time{}, nil)
+			m.On("FindMonthAsOf", april, asOf).Return([]Transaction{}, errors.New("Repository.FindMonthAsOf failed: err"))
+			uc := NewLedgerUseCase(m)
+
+			// act
+			got, gotErr := uc.Summary(context.Background(), may, asOf)
+
+			// assert
+			assert.EqualError(t, gotErr, "LedgerSummary failed: Repository.FindMonthAsOf failed: err")
+			assert.Empty(t, got)
+		},
+		"when log fails to find the transactions of the month": func(t *testing.T, m *mockEventLog) {
+			// arrange
+			m.On("FindStart").Return(time.Time{}, nil)
+			m.On("FindMonthAsOf", april, asOf).Return([]Transaction{}, nil)
+			m.On("FindMonthAsOf", may, asOf).Return([]Transaction{}, errors.New("Repository.FindMonthAsOf failed: err"))
+			uc := NewLedgerUseCase(m)
+
+			// act
+			got, gotErr := uc.Summary(context.Background(), may, asOf)
+
+			// assert
+			assert.EqualError(t, gotErr, "LedgerSummary failed: Repository.FindMonthAsOf failed: err")
+			assert.Empty(t, got)
+		},
+		"when log finds the transactions, total those of the month less the credit due": func(t *testing.T, m *mockEventLog) {
+			// arrange
+			card := Transaction{ID: 1, Amount: 50, Type: Credit, Category: Category{Name: "Food"}, Date: april, Version: 1}
+			salary := Transaction{ID: 2, Amount: 1000, Type: Income, Category: Category{Name: "Salary"}, Date: may, Version: 1}
+			lunch := Transaction{ID: 3, Amount: 100, Type: Debit, Category: Category{Name: "Food"}, Date: may, Version: 1}
+			m.On("FindStart").Return(april, nil)
+			m.On("FindMonthAsOf", april, asOf).Return([]Transaction{card}, nil)
+			m.On("FindMonthAsOf", may, asOf).Return([]Transaction{salary, lunch}, nil)
+			uc := NewLedgerUseCase(m)
+
+			// act
+			got, gotErr := uc.Summary(context.Background(), may.AddDate(0, 0, 14), asOf)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, Summarize([]Transaction{card, salary, lunch}, may), got)
+			assert.Equal(t, 850, got.Balance)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockEventLog)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+type mockEventLog struct {
+	mock.Mock
+}
+
+func (m *mockEventLog) FindAsOf(_ context.Context, asOf time.Time) ([]Transaction, error) {
+	args := m.Called(asOf)
+	return args.Get(0).([]Transaction), args.Error(1)
+}
+
+func (m *mockEventLog) FindMonthAsOf(_ context.Context, from, asOf time.Time) ([]Transaction, error) {
+	args := m.Called(from, asOf)
+	return args.Get(0).([]Transaction), args.Error(1)
+}
+
+func (m *mockEventLog) FindStart(context.Context) (time.Time, error) {
+	args := m.Called()
+	return args.Get(0).(time.Time), args.Error(1)
+}
@@ -0,0 +1,225 @@
+package repotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+)
+
+// EventsFactory returns an empty repository along with its event log, releasing them with t.Cleanup when needed.
+type EventsFactory func(t *testing.T) (core.Repository, core.EventLog)
+
+// RunEvents runs the conformance suite of the event log of a repository, each test against a new one.
+func RunEvents(t *testing.T, newRepository EventsFactory) {
+	tests := map[string]func(*testing.T, core.Repository, core.EventLog){
+		"FindAsOf finds the transactions as changed":                    testEvents,
+		"a failed change changes nothing as of now":                     testEventsFailure,
+		"FindAsOf skips the changes recorded after asOf":                testEventsAsOf,
+		"FindMonthAsOf finds the transactions dated in the month as of": testEventsMonth,
+		"FindAsOf finds the transactions as undone and redone":          testEventsUndo,
+		"FindStart finds when the first event was":                      testEventsStart,
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, l := newRepository(t)
+			run(t, r, l)
+		})
+	}
+}
+
+func testEvents(t *testing.T, r core.Repository, l core.EventLog) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+	create(t, r, transaction("Travel", day.Add(-time.Hour)))
+	create(t, r, transaction("Food", time.Time{}))
+
+	batch, err := r.CreateBatch(ctx, []core.Transaction{transaction("Travel", day)}, actor)
+	if err != nil {
+		t.Fatalf("CreateBatch failed: %s", err)
+	}
+
+	created.Amount = 300
+	if _, err := r.Update(ctx, created, actor); err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+	if _, err := r.Delete(ctx, batch[0].ID, batch[0].Version, actor); err != nil {
+		t.Fatalf("Delete failed: %s", err)
+	}
+
+	want, err := r.Find(ctx)
+	if err != nil {
+		t.Fatalf("Find failed: %s", err)
+	}
+
+	// act
+	got, gotErr := l.FindAsOf(ctx, time.Now().Add(time.Hour))
+
+	// assert
+	assert.NoError(t, gotErr)
+	assertTransactions(t, want, got)
+}
+
+func testEventsFailure(t *testing.T, r core.Repository, l core.EventLog) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+
+	// act
+	_, updateErr := r.Update(ctx, core.Transaction{
+		ID:       created.ID,
+		Amount:   200,
+		Type:     core.Debit,
+		Category: core.Category{Name: "Food"},
+		Version:  created.Version + 1,
+	}, actor)
+	_, deleteErr := r.Delete(ctx, created.ID+1, 1, actor)
+
+	// assert
+	assert.Equal(t, core.ErrStaleVersion, errors.Cause(updateErr))
+	assert.Equal(t, core.ErrNotFound, errors.Cause(deleteErr))
+
+	got, err := l.FindAsOf(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assertTransactions(t, []core.Transaction{created}, got)
+}
+
+func testEventsAsOf(t *testing.T, r core.Repository, l core.EventLog) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+
+	start, err := l.FindStart(ctx)
+	if err != nil {
+		t.Fatalf("FindStart failed: %s", err)
+	}
+
+	// the changes below are recorded after start, whatever the precision of the clock of the backend
+	time.Sleep(10 * time.Millisecond)
+
+	updated := created
+	updated.Amount = 200
+	updated, err = r.Update(ctx, updated, actor)
+	if err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+	create(t, r, transaction("Travel", day))
+
+	// act
+	before, beforeErr := l.FindAsOf(ctx, start.Add(-time.Millisecond))
+	first, firstErr := l.FindAsOf(ctx, start)
+	now, nowErr := l.FindAsOf(ctx, time.Now().Add(time.Hour))
+
+	// assert
+	assert.NoError(t, beforeErr)
+	assert.Empty(t, before)
+	assert.NoError(t, firstErr)
+	assertTransactions(t, []core.Transaction{created}, first)
+	assert.NoError(t, nowErr)
+	if assert.Len(t, now, 2) {
+		assertTransaction(t, updated, now[0])
+	}
+}
+
+func testEventsMonth(t *testing.T, r core.Repository, l core.EventLog) {
+	// arrange
+	from := core.StartOfMonth(day).AddDate(0, -1, 0)
+
+	stays := create(t, r, transaction("Food", from.AddDate(0, 0, 2)))
+	leaves := create(t, r, transaction("Food", from.AddDate(0, 0, 1)))
+	joins := create(t, r, transaction("Travel", from.AddDate(0, 1, 1)))
+
+	start, err := l.FindStart(ctx)
+	if err != nil {
+		t.Fatalf("FindStart failed: %s", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	asOf := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	// the transactions moved across months after asOf, and one back-filled
+	was := leaves
+	leaves.Date = from.AddDate(0, -1, 1)
+	if _, err := r.Update(ctx, leaves, actor); err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+	joins.Date = from.AddDate(0, 0, 3)
+	joined, err := r.Update(ctx, joins, actor)
+	if err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+	filled := create(t, r, transaction("Food", from))
+
+	// act
+	then, thenErr := l.FindMonthAsOf(ctx, from, asOf)
+	now, nowErr := l.FindMonthAsOf(ctx, from, time.Now().Add(time.Hour))
+	first, firstErr := l.FindMonthAsOf(ctx, from, start.Add(-time.Millisecond))
+
+	// assert
+	assert.NoError(t, thenErr)
+	assertTransactions(t, []core.Transaction{was, stays}, then)
+	assert.NoError(t, nowErr)
+	assertTransactions(t, []core.Transaction{filled, stays, joined}, now)
+	assert.NoError(t, firstErr)
+	assert.Empty(t, first)
+}
+
+func testEventsUndo(t *testing.T, r core.Repository, l core.EventLog) {
+	j, ok := r.(core.Journal)
+	if !ok {
+		t.Skip("the repository has no journal")
+	}
+
+	// arrange
+	kept := create(t, r, transaction("Food", day))
+	removed := create(t, r, transaction("Travel", day))
+	if _, err := r.Delete(ctx, removed.ID, removed.Version, actor); err != nil {
+		t.Fatalf("Delete failed: %s", err)
+	}
+
+	// act
+	_, undoErr := j.Undo(ctx, actor)
+	undone, undoneErr := l.FindAsOf(ctx, time.Now().Add(time.Hour))
+	_, redoErr := j.Redo(ctx, actor)
+	redone, redoneErr := l.FindAsOf(ctx, time.Now().Add(time.Hour))
+
+	// assert
+	assert.NoError(t, undoErr)
+	assert.NoError(t, undoneErr)
+	removed.Version++
+	assertTransactions(t, []core.Transaction{kept, removed}, undone)
+	assert.NoError(t, redoErr)
+	assert.NoError(t, redoneErr)
+	assertTransactions(t, []core.Transaction{kept}, redone)
+}
+
+func testEventsStart(t *testing.T, r core.Repository, l core.EventLog) {
+	// arrange
+	empty, emptyErr := l.FindStart(ctx)
+
+	before := time.Now().Add(-time.Second)
+	create(t, r, transaction("Food", day))
+	create(t, r, transaction("Food", day))
+
+	// act
+	got, gotErr := l.FindStart(ctx)
+
+	// assert
+	assert.NoError(t, emptyErr)
+	assert.True(t, empty.IsZero())
+	assert.NoError(t, gotErr)
+	assert.True(t, got.After(before), "start: %s", got)
+	assert.False(t, got.After(time.Now()), "start: %s", got)
+}
+
+func assertTransactions(t *testing.T, want, got []core.Transaction) {
+	t.Helper()
+
+	if assert.Len(t, got, len(want)) {
+		for i := range want {
+			assertTransaction(t, want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package repotest
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+)
+
+// JournalFactory returns an empty repository along with its journal, releasing them with t.Cleanup when needed.
+type JournalFactory func(t *testing.T) (core.Repository, core.Journal)
+
+// RunJournal runs the conformance suite of a journal, each test against a new repository.
+func RunJournal(t *testing.T, newRepository JournalFactory) {
+	tests := map[string]func(*testing.T, core.Repository, core.Journal){
+		"Undo removes a created transaction, Redo restores it":          testUndoCreate,
+		"Undo reverts a changed transaction, Redo changes it again":     testUndoUpdate,
+		"Undo restores a removed transaction with its id":               testUndoDelete,
+		"Undo removes a whole batch":                                    testUndoBatch,
+		"Undo fails with ErrStaleVersion when changed since":            testUndoStale,
+		"Undo fails with ErrStaleVersion when changed back since":       testUndoChangedBack,
+		"Undo and Redo fail when there is nothing left":                 testUndoNothing,
+		"an operation discards the undone ones":                         testUndoDiscarded,
+		"each actor undoes their own operations, not the failed ones":   testUndoActors,
+		"the journal keeps the latest operations of each actor":         testUndoSize,
+		"Undo and Redo record the history":                              testUndoHistory,
+		"successive undos revert the operations, newest first, in turn": testUndoSuccessive,
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, j := newRepository(t)
+			run(t, r, j)
+		})
+	}
+}
+
+func testUndoCreate(t *testing.T, r core.Repository, j core.Journal) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+
+	// act
+	undone, undoErr := j.Undo(ctx, actor)
+	_, findErr := r.FindByID(ctx, created.ID)
+	redone, redoErr := j.Redo(ctx, actor)
+
+	// assert
+	assert.NoError(t, undoErr)
+	assert.Equal(t, actor, undone.Actor)
+	assert.Equal(t, core.JournalCreate, undone.Operation)
+	assert.True(t, undone.Undone)
+	if assert.Len(t, undone.Changes, 1) {
+		assertTransaction(t, created, undone.Changes[0].Before)
+		assert.True(t, undone.Changes[0].Deleted())
+	}
+	assert.Equal(t, core.ErrNotFound, errors.Cause(findErr))
+
+	assert.NoError(t, redoErr)
+	assert.Equal(t, undone.ID, redone.ID)
+	assert.False(t, redone.Undone)
+
+	want := created
+	want.Version = created.Version + 1
+	if assert.Len(t, redone.Changes, 1) {
+		assert.True(t, redone.Changes[0].Created())
+		assertTransaction(t, want, redone.Changes[0].After)
+	}
+
+	got, err := r.FindByID(ctx, created.ID)
+	assert.NoError(t, err)
+	assertTransaction(t, want, got)
+}
+
+func testUndoUpdate(t *testing.T, r core.Repository, j core.Journal) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+	changed := created
+	changed.Amount = 200
+	changed.Category = core.Category{Name: "Travel"}
+	changed.Name = "train"
+	changed, err := r.Update(ctx, changed, actor)
+	if err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+
+	// act
+	undone, undoErr := j.Undo(ctx, actor)
+	afterUndo, _ := r.FindByID(ctx, created.ID)
+	redone, redoErr := j.Redo(ctx, actor)
+	afterRedo, _ := r.FindByID(ctx, created.ID)
+
+	// assert
+	assert.NoError(t, undoErr)
+	assert.Equal(t, core.JournalUpdate, undone.Operation)
+
+	want := created
+	want.Version = changed.Version + 1
+	assertTransaction(t, want, afterUndo)
+	if assert.Len(t, undone.Changes, 1) {
+		assertTransaction(t, changed, undone.Changes[0].Before)
+		assertTransaction(t, want, undone.Changes[0].After)
+	}
+
+	assert.NoError(t, redoErr)
+	want = changed
+	want.Version = changed.Version + 2
+	assertTransaction(t, want, afterRedo)
+	if assert.Len(t, redone.Changes, 1) {
+		assertTransaction(t, want, redone.Changes[0].After)
+	}
+}
+
+func testUndoDelete(t *testing.T, r core.Repository, j core.Journal) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+	if _, err := r.Delete(ctx, created.ID, created.Version, actor); err != nil {
+		t.Fatalf("Delete failed: %s", err)
+	}
+
+	// act
+	undone, gotErr := j.Undo(ctx, actor)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Equal(t, core.JournalDelete, undone.Operation)
+
+	want := created
+	want.Version = created.Version + 1
+
+	got, err := r.FindByID(ctx, created.ID)
+	assert.NoError(t, err)
+	assertTransaction(t, want, got)
+
+	all, err := r.Find(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{created.ID}, ids(all))
+}
+
+func testUndoBatch(t *testing.T, r core.Repository, j core.Journal) {
+	// arrange
+	kept := create(t, r, transaction("Food", day))
+	if _, err := r.CreateBatch(ctx, []core.Transaction{transaction("Food", day), transaction("Travel", day)}, actor); err != nil {
+		t.Fatalf("CreateBatch failed: %s", err)
+	}
+
+	// act
+	undone, gotErr := j.Undo(ctx, actor)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Equal(t, core.JournalCreateBatch, undone.Operation)
+	assert.Len(t, undone.Changes, 2)
+
+	got, err := r.Find(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{kept.ID}, ids(got))
+}
+
+func testUndoStale(t *testing.T, r core.Repository, j core.Journal) {
+	// arrange
+	batch, err := r.CreateBatch(ctx, []core.Transaction{transaction("Food", day), transaction("Travel", day)}, actor)
+	if err != nil {
+		t.Fatalf("CreateBatch failed: %s", err)
+	}
+
+	changed := batch[1]
+	changed.Amount = 200
+	changed, err = r.Update(ctx, changed, "someone else")
+	if err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+
+	// act
+	_, gotErr := j.Undo(ctx, actor)
+
+	// assert
+	assert.Equal(t, core.ErrStaleVersion, errors.Cause(gotErr))
+
+	got, err := r.Find(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, got, 2) {
+		assertTransaction(t, batch[0], got[0])
+		assertTransaction(t, changed, got[1])
+	}
+
+	if _, err := r.Delete(ctx, changed.ID, changed.Version, "someone else"); err != nil {
+		t.Fatalf("Delete failed: %s", err)
+	}
+
+	_, gotErr = j.Undo(ctx, actor)
+	assert.Equal(t, core.ErrStaleVersion, errors.Cause(gotErr))
+}
+
+func testUndoChangedBack(t *testing.T, r core.Repository, j core.Journal) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+
+	changed := created
+	changed.Amount = 200
+	changed, err := r.Update(ctx, changed, actor)
+	if err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+
+	// someone else changes it, then back to the values the undo expects
+	other := changed
+	other.Amount = 300
+	if other, err = r.Update(ctx, other, "someone else"); err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+	other.Amount = changed.Amount
+	if other, err = r.Update(ctx, other, "someone else"); err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+
+	// act
+	_, gotErr := j.Undo(ctx, actor)
+
+	// assert
+	assert.Equal(t, core.ErrStaleVersion, errors.Cause(gotErr))
+
+	got, err := r.FindByID(ctx, created.ID)
+	assert.NoError(t, err)
+	assertTransaction(t, other, got)
+}
+
+func testUndoNothing(t *testing.T, r core.Repository, j core.Journal) {
+	// act
+	_, undoErr := j.Undo(ctx, actor)
+	_, redoErr := j.Redo(ctx, actor)
+
+	// assert
+	assert.Equal(t, core.ErrNothingToUndo, errors.Cause(undoErr))
+	assert.Equal(t, core.ErrNothingToRedo, errors.Cause(redoErr))
+
+	create(t, r, transaction("Food", day))
+	if _, err := j.Undo(ctx, actor); err != nil {
+		t.Fatalf("Undo failed: %s", err)
+	}
+
+	_, undoErr = j.Undo(ctx, actor)
+	assert.Equal(t, core.ErrNothingToUndo, errors.Cause(undoErr))
+}
+
+func testUndoDiscarded(t *testing.T, r core.Repository, j core.Journal) {
+	// arrange
+	create(t, r, transaction("Food", day))
+	if _, err := j.Undo(ctx, actor); err != nil {
+		t.Fatalf("Undo failed: %s", err)
+	}
+	created := create(t, r, transaction("Travel", day))
+
+	// act
+	_, redoErr := j.Redo(ctx, actor)
+	undone, undoErr := j.Undo(ctx, actor)
+
+	// assert
+	assert.Equal(t, core.ErrNothingToRedo, errors.Cause(redoErr))
+	assert.NoError(t, undoErr)
+	if assert.Len(t, undone.Changes, 1) {
+		assert.Equal(t, created.ID, undone.Changes[0].Before.ID)
+	}
+
+	got, err := r.Find(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func testUndoActors(t *testing.T, r core.Repository, j core.Journal) {
+	// arrange
+	mine := create(t, r, transaction("Food", day))
+	if _, err := r.Create(ctx, transaction("Travel", day), "someone else"); err != nil {
+		t.Fatalf("Create failed: %s", err)
+	}
+
+	stale := mine
+	stale.Version++
+	if _, err := r.Update(ctx, stale, actor); err == nil {
+		t.Fatalf("Update of a stale version succeeded")
+	}
+
+	// act
+	undone, gotErr := j.Undo(ctx, actor)
+
+	// assert
+	assert.NoError(t, gotErr)
+	if assert.Len(t, undone.Changes, 1) {
+		assert.Equal(t, mine.ID, undone.Changes[0].Before.ID)
+	}
+
+	got, err := r.Find(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+	assert.NotEqual(t, mine.ID, got[0].ID)
+}
+
+func testUndoSize(t *testing.T, r core.Repository, j core.Journal) {
+	// arrange
+	for i := 0; i < core.JournalSize+1; i++ {
+		create(t, r, transaction("Food", day))
+	}
+
+	// act
+	var errs []error
+	for i := 0; i < core.JournalSize+1; i++ {
+		if _, err := j.Undo(ctx, actor); err != nil {
+			errs = append(errs, errors.Cause(err))
+		}
+	}
+
+	// assert
+	assert.Equal(t, []error{core.ErrNothingToUndo}, errs)
+
+	got, err := r.Find(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+}
+
+func testUndoHistory(t *testing.T, r core.Repository, j core.Journal) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+
+	// act
+	_, undoErr := j.Undo(ctx, actor)
+	_, redoErr := j.Redo(ctx, actor)
+
+	// assert
+	assert.NoError(t, undoErr)
+	assert.NoError(t, redoErr)
+
+	got, err := r.FindHistory(ctx, created.ID)
+	assert.NoError(t, err)
+
+	var actions []string
+	for _, entry := range got {
+		actions = append(actions, entry.Action)
+		assert.Equal(t, actor, entry.Actor)
+	}
+	assert.Equal(t, []string{core.AuditCreate, core.AuditDelete, core.AuditCreate}, actions)
+}
+
+func testUndoSuccessive(t *testing.T, r core.Repository, j core.Journal) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+	changed := created
+	changed.Amount = 200
+	changed, err := r.Update(ctx, changed, actor)
+	if err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+	if _, err := r.Delete(ctx, changed.ID, changed.Version, actor); err != nil {
+		t.Fatalf("Delete failed: %s", err)
+	}
+
+	// act
+	var operations []string
+	for i := 0; i < 3; i++ {
+		undone, err := j.Undo(ctx, actor)
+		if err != nil {
+			t.Fatalf("Undo failed: %s", err)
+		}
+		operations = append(operations, undone.Operation)
+	}
+
+	// assert
+	assert.Equal(t, []string{core.JournalDelete, core.JournalUpdate, core.JournalCreate}, operations)
+
+	got, err := r.Find(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	for i := 0; i < 3; i++ {
+		if _, err := j.Redo(ctx, actor); err != nil {
+			t.Fatalf("Redo failed: %s", err)
+		}
+	}
+
+	got, err = r.Find(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = j.Undo(ctx, actor)
+	assert.NoError(t, err)
+
+	restored, err := r.FindByID(ctx, created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, restored.Amount)
+}
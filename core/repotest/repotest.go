@@ -0,0 +1,605 @@
+// Package repotest defines what a correct core.Repository does, as a test suite any backend can run.
+package repotest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+)
+
+// Factory returns an empty repository, releasing it with t.Cleanup when needed.
+type Factory func(t *testing.T) core.Repository
+
+// actor is the actor of all changes made by the suite.
+const actor = "repotest"
+
+// ctx is the context of all calls made by the suite.
+var ctx = context.Background()
+
+// Run runs the conformance suite, each test against a new repository.
+func Run(t *testing.T, newRepository Factory) {
+	tests := map[string]func(*testing.T, core.Repository){
+		"Create assigns sequential ids and the first version":            testCreateIDs,
+		"Create keeps the given date":                                    testCreateDate,
+		"Create defaults a zero date to now":                             testCreateDefaultDate,
+		"Create creates a new category":                                  testCreateCategory,
+		"CreateCategory tells whether the category is new":               testCreateCategoryNew,
+		"Create records the history":                                     testCreateHistory,
+		"CreateBatch creates all transactions in order":                  testCreateBatch,
+		"CreateBatch creates none when a transaction fails":              testCreateBatchAtomic,
+		"Find returns nothing when empty":                                testFindEmpty,
+		"Find orders by date, then by id":                                testFindOrder,
+		"FindMonth finds the month's transactions, ordered by date":      testFindMonth,
+		"FindPage pages through the transactions in the order of Find":   testFindPage,
+		"FindPage pages through the transactions matching the filter":    testFindPageFilter,
+		"FindByID fails with ErrNotFound":                                testFindByIDNotFound,
+		"Update changes the current version":                             testUpdate,
+		"Update keeps the date when a zero one is given":                 testUpdateDefaultDate,
+		"Update fails with ErrStaleVersion":                              testUpdateStale,
+		"Update fails with ErrNotFound":                                  testUpdateNotFound,
+		"Delete removes the current version":                             testDelete,
+		"Delete fails with ErrStaleVersion":                              testDeleteStale,
+		"Delete fails with ErrNotFound":                                  testDeleteNotFound,
+		"FindHistory returns the changes of a transaction, oldest first": testFindHistory,
+		"FindCategories returns the categories, ordered by name":         testFindCategories,
+		"SumByCategory sums the transactions of each category by type":   testSumByCategory,
+		"SumByCategory returns nothing for no categories":                testSumByCategoryEmpty,
+		"CountByType counts the transactions of each type":               testCountByType,
+		"Count counts the transactions matching the filter":              testCount,
+		"concurrent writes are all created with distinct ids":            testConcurrentWrites,
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, newRepository(t))
+		})
+	}
+}
+
+// day is a date every backend stores without losing precision.
+var day = time.Now().UTC().Truncate(time.Hour * 24).Add(-time.Hour * 24)
+
+func testCreateIDs(t *testing.T, r core.Repository) {
+	// act
+	first, firstErr := r.Create(ctx, transaction("Food", day), actor)
+	second, secondErr := r.Create(ctx, transaction("Food", day), actor)
+
+	// assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.True(t, first.ID > 0)
+	assert.Equal(t, first.ID+1, second.ID)
+	assert.Equal(t, 1, first.Version)
+	assert.Equal(t, 1, second.Version)
+}
+
+func testCreateDate(t *testing.T, r core.Repository) {
+	// arrange
+	given := transaction("Food", day)
+	given.Name = "Lunch"
+
+	// act
+	got, gotErr := r.Create(ctx, given, actor)
+
+	want := given
+	want.ID = got.ID
+	want.Version = 1
+
+	// assert
+	assert.NoError(t, gotErr)
+	assertTransaction(t, want, got)
+
+	found, err := r.FindByID(ctx, got.ID)
+	assert.NoError(t, err)
+	assertTransaction(t, got, found)
+}
+
+func testCreateDefaultDate(t *testing.T, r core.Repository) {
+	// act
+	got, gotErr := r.Create(ctx, transaction("Food", time.Time{}), actor)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.WithinDuration(t, time.Now(), got.Date, 2*time.Second)
+
+	found, err := r.FindByID(ctx, got.ID)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, got.Date, found.Date, time.Second)
+}
+
+func testCreateCategory(t *testing.T, r core.Repository) {
+	// act
+	got, gotErr := r.Create(ctx, transaction("Travel", day), actor)
+
+	// assert
+	assert.NoError(t, gotErr)
+
+	found, err := r.FindByID(ctx, got.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, core.Category{Name: "Travel"}, found.Category)
+}
+
+func testCreateCategoryNew(t *testing.T, r core.Repository) {
+	// act
+	first, firstErr := r.CreateCategory(ctx, core.Category{Name: "Travel"}, actor)
+	second, secondErr := r.CreateCategory(ctx, core.Category{Name: "Travel"}, actor)
+
+	// assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.True(t, first)
+	assert.False(t, second)
+
+	categories, err := r.FindCategories(ctx)
+	assert.NoError(t, err)
+	assert.Contains(t, categories, core.Category{Name: "Travel"})
+}
+
+func testCreateHistory(t *testing.T, r core.Repository) {
+	// act
+	got, gotErr := r.Create(ctx, transaction("Food", day), actor)
+
+	// assert
+	assert.NoError(t, gotErr)
+
+	history, err := r.FindHistory(ctx, got.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, core.AuditTransaction, history[0].Entity)
+		assert.Equal(t, core.AuditCreate, history[0].Action)
+		assert.Equal(t, actor, history[0].Actor)
+		assert.Empty(t, history[0].Before)
+		assert.NotEmpty(t, history[0].After)
+	}
+}
+
+func testCreateBatch(t *testing.T, r core.Repository) {
+	// arrange
+	given := []core.Transaction{
+		transaction("Food", day),
+		transaction("Travel", day.Add(time.Hour)),
+		transaction("Travel", time.Time{}),
+	}
+
+	// act
+	got, gotErr := r.CreateBatch(ctx, given, actor)
+
+	// assert
+	assert.NoError(t, gotErr)
+	if !assert.Len(t, got, len(given)) {
+		return
+	}
+	for i, gotTrs := range got {
+		if i > 0 {
+			assert.Equal(t, got[i-1].ID+1, gotTrs.ID)
+		}
+		assert.Equal(t, 1, gotTrs.Version)
+
+		found, err := r.FindByID(ctx, gotTrs.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, given[i].Category, found.Category)
+	}
+	assert.True(t, day.Equal(got[0].Date))
+	assert.False(t, got[2].Date.IsZero())
+}
+
+func testCreateBatchAtomic(t *testing.T, r core.Repository) {
+	// arrange
+	invalid := transaction("Food", day)
+	invalid.Name = strings.Repeat("n", 81)
+
+	// act
+	_, gotErr := r.CreateBatch(ctx, []core.Transaction{transaction("Food", day), invalid}, actor)
+
+	// assert
+	assert.Error(t, gotErr)
+
+	found, err := r.Find(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func testFindEmpty(t *testing.T, r core.Repository) {
+	// act
+	got, gotErr := r.Find(ctx)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Empty(t, got)
+}
+
+func testFindOrder(t *testing.T, r core.Repository) {
+	// arrange
+	latest := create(t, r, transaction("Food", day.Add(2*time.Hour)))
+	first := create(t, r, transaction("Food", day))
+	tied := create(t, r, transaction("Food", day))
+
+	// act
+	got, gotErr := r.Find(ctx)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Equal(t, []int{first.ID, tied.ID, latest.ID}, ids(got))
+}
+
+func testFindMonth(t *testing.T, r core.Repository) {
+	// arrange
+	from := core.StartOfMonth(day)
+	last := create(t, r, transaction("Food", from.AddDate(0, 1, 0).Add(-time.Hour)))
+	first := create(t, r, transaction("Food", from))
+	create(t, r, transaction("Food", from.Add(-time.Hour)))
+	create(t, r, transaction("Food", from.AddDate(0, 1, 0)))
+
+	// act
+	got, gotErr := r.FindMonth(ctx, from)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Equal(t, []int{first.ID, last.ID}, ids(got))
+}
+
+func testFindPage(t *testing.T, r core.Repository) {
+	// arrange
+	latest := create(t, r, transaction("Food", day.Add(2*time.Hour)))
+	first := create(t, r, transaction("Food", day))
+	tied := create(t, r, transaction("Food", day))
+
+	// act
+	firstPage, firstErr := r.FindPage(ctx, core.Filter{}, core.Cursor{}, 2)
+	lastPage, lastErr := r.FindPage(ctx, core.Filter{}, tied.Cursor(), 2)
+	none, noneErr := r.FindPage(ctx, core.Filter{}, latest.Cursor(), 2)
+
+	// assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, lastErr)
+	assert.NoError(t, noneErr)
+	assert.Equal(t, []int{first.ID, tied.ID}, ids(firstPage))
+	assert.Equal(t, []int{latest.ID}, ids(lastPage))
+	assert.Empty(t, none)
+}
+
+func testFindPageFilter(t *testing.T, r core.Repository) {
+	// arrange
+	income := transaction("Food", day.Add(time.Hour))
+	income.Type = core.Income
+
+	create(t, r, transaction("Food", day.Add(-time.Hour)))
+	first := create(t, r, transaction("Food", day))
+	create(t, r, transaction("Travel", day))
+	create(t, r, income)
+	last := create(t, r, transaction("Food", day.Add(2*time.Hour)))
+	create(t, r, transaction("Food", day.Add(3*time.Hour)))
+
+	f := core.Filter{Category: "Food", Type: core.Debit, From: day, To: day.Add(2 * time.Hour)}
+
+	// act
+	firstPage, firstErr := r.FindPage(ctx, f, core.Cursor{}, 1)
+	lastPage, lastErr := r.FindPage(ctx, f, first.Cursor(), 2)
+
+	// assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, lastErr)
+	assert.Equal(t, []int{first.ID}, ids(firstPage))
+	assert.Equal(t, []int{last.ID}, ids(lastPage))
+}
+
+func testFindByIDNotFound(t *testing.T, r core.Repository) {
+	// act
+	_, gotErr := r.FindByID(ctx, 1000)
+
+	// assert
+	assert.Equal(t, core.ErrNotFound, errors.Cause(gotErr))
+}
+
+func testUpdate(t *testing.T, r core.Repository) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+
+	given := created
+	given.Amount = 200
+	given.Category = core.Category{Name: "Travel"}
+	given.Date = day.Add(-time.Hour * 24)
+	given.Name = "Flight"
+
+	// act
+	got, gotErr := r.Update(ctx, given, actor)
+
+	want := given
+	want.Version = 2
+
+	// assert
+	assert.NoError(t, gotErr)
+	assertTransaction(t, want, got)
+
+	found, err := r.FindByID(ctx, created.ID)
+	assert.NoError(t, err)
+	assertTransaction(t, want, found)
+}
+
+func testUpdateDefaultDate(t *testing.T, r core.Repository) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+
+	given := created
+	given.Amount = 200
+	given.Date = time.Time{}
+
+	// act
+	got, gotErr := r.Update(ctx, given, actor)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.True(t, day.Equal(got.Date))
+}
+
+func testUpdateStale(t *testing.T, r core.Repository) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+
+	given := created
+	given.Version = 2
+
+	// act
+	_, gotErr := r.Update(ctx, given, actor)
+
+	// assert
+	assert.Equal(t, core.ErrStaleVersion, errors.Cause(gotErr))
+
+	found, err := r.FindByID(ctx, created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, found.Version)
+}
+
+func testUpdateNotFound(t *testing.T, r core.Repository) {
+	// arrange
+	given := transaction("Food", day)
+	given.ID = 1000
+	given.Version = 1
+
+	// act
+	_, gotErr := r.Update(ctx, given, actor)
+
+	// assert
+	assert.Equal(t, core.ErrNotFound, errors.Cause(gotErr))
+}
+
+func testDelete(t *testing.T, r core.Repository) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+
+	// act
+	got, gotErr := r.Delete(ctx, created.ID, created.Version, actor)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Equal(t, created, got)
+
+	_, err := r.FindByID(ctx, created.ID)
+	assert.Equal(t, core.ErrNotFound, errors.Cause(err))
+}
+
+func testDeleteStale(t *testing.T, r core.Repository) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+
+	// act
+	_, gotErr := r.Delete(ctx, created.ID, created.Version+1, actor)
+
+	// assert
+	assert.Equal(t, core.ErrStaleVersion, errors.Cause(gotErr))
+
+	_, err := r.FindByID(ctx, created.ID)
+	assert.NoError(t, err)
+}
+
+func testDeleteNotFound(t *testing.T, r core.Repository) {
+	// act
+	_, gotErr := r.Delete(ctx, 1000, 1, actor)
+
+	// assert
+	assert.Equal(t, core.ErrNotFound, errors.Cause(gotErr))
+}
+
+func testFindHistory(t *testing.T, r core.Repository) {
+	// arrange
+	created := create(t, r, transaction("Food", day))
+
+	updated := created
+	updated.Amount = 200
+	updated, err := r.Update(ctx, updated, actor)
+	if err != nil {
+		t.Fatalf("Update failed: %s", err)
+	}
+
+	if _, err := r.Delete(ctx, updated.ID, updated.Version, actor); err != nil {
+		t.Fatalf("Delete failed: %s", err)
+	}
+
+	// act
+	got, gotErr := r.FindHistory(ctx, created.ID)
+
+	// assert
+	assert.NoError(t, gotErr)
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, core.AuditCreate, got[0].Action)
+		assert.Equal(t, core.AuditUpdate, got[1].Action)
+		assert.NotEmpty(t, got[1].Before)
+		assert.NotEmpty(t, got[1].After)
+		assert.Equal(t, core.AuditDelete, got[2].Action)
+		assert.NotEmpty(t, got[2].Before)
+		assert.Empty(t, got[2].After)
+		assert.True(t, got[0].ID < got[1].ID && got[1].ID < got[2].ID)
+	}
+}
+
+func testFindCategories(t *testing.T, r core.Repository) {
+	// arrange
+	create(t, r, transaction("Travel", day))
+	create(t, r, transaction("Food", day))
+	create(t, r, transaction("Travel", day))
+
+	// act
+	got, gotErr := r.FindCategories(ctx)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Equal(t, []core.Category{{Name: "Food"}, {Name: "Travel"}}, got)
+}
+
+func testSumByCategory(t *testing.T, r core.Repository) {
+	// arrange
+	income := transaction("Food", day)
+	income.Type = core.Income
+	income.Amount = 50
+
+	create(t, r, transaction("Food", day))
+	create(t, r, transaction("Food", day))
+	create(t, r, income)
+	create(t, r, transaction("Travel", day))
+	create(t, r, transaction("Home", day))
+
+	// act
+	got, gotErr := r.SumByCategory(ctx, []string{"Food", "Travel", "Unknown"})
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Equal(t, map[string]core.Totals{
+		"Food":   {Debit: 200, Income: 50, Count: 3},
+		"Travel": {Debit: 100, Count: 1},
+	}, got)
+}
+
+func testCountByType(t *testing.T, r core.Repository) {
+	// arrange
+	empty, emptyErr := r.CountByType(ctx)
+
+	income := transaction("Food", day)
+	income.Type = core.Income
+
+	create(t, r, transaction("Food", day))
+	create(t, r, transaction("Travel", day))
+	create(t, r, income)
+
+	// act
+	got, gotErr := r.CountByType(ctx)
+
+	// assert
+	assert.NoError(t, emptyErr)
+	assert.Empty(t, empty)
+	assert.NoError(t, gotErr)
+	assert.Equal(t, map[int]int{core.Debit: 2, core.Income: 1}, got)
+}
+
+func testCount(t *testing.T, r core.Repository) {
+	// arrange
+	empty, emptyErr := r.Count(ctx, core.Filter{})
+
+	income := transaction("Food", day)
+	income.Type = core.Income
+
+	create(t, r, transaction("Food", day.Add(-time.Hour)))
+	create(t, r, transaction("Food", day))
+	create(t, r, transaction("Travel", day))
+	create(t, r, income)
+
+	// act
+	all, allErr := r.Count(ctx, core.Filter{})
+	got, gotErr := r.Count(ctx, core.Filter{Category: "Food", Type: core.Debit, From: day})
+
+	// assert
+	assert.NoError(t, emptyErr)
+	assert.Zero(t, empty)
+	assert.NoError(t, allErr)
+	assert.Equal(t, 4, all)
+	assert.NoError(t, gotErr)
+	assert.Equal(t, 1, got)
+}
+
+func testSumByCategoryEmpty(t *testing.T, r core.Repository) {
+	// arrange
+	create(t, r, transaction("Food", day))
+
+	// act
+	got, gotErr := r.SumByCategory(ctx, nil)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Empty(t, got)
+}
+
+func testConcurrentWrites(t *testing.T, r core.Repository) {
+	// arrange
+	const writers = 20
+
+	// act
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.Create(ctx, transaction("Food", day), actor); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// assert
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	got, err := r.Find(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, got, writers)
+
+	seen := map[int]bool{}
+	for _, id := range ids(got) {
+		assert.False(t, seen[id], "duplicated id %d", id)
+		seen[id] = true
+	}
+}
+
+func transaction(category string, date time.Time) core.Transaction {
+	return core.Transaction{
+		Amount:   100,
+		Type:     core.Debit,
+		Category: core.Category{Name: category},
+		Date:     date,
+	}
+}
+
+func create(t *testing.T, r core.Repository, given core.Transaction) core.Transaction {
+	created, err := r.Create(ctx, given, actor)
+	if err != nil {
+		t.Fatalf("Create failed: %s", err)
+	}
+	return created
+}
+
+func ids(ts []core.Transaction) []int {
+	var ids []int
+	for _, t := range ts {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+// assertTransaction compares transactions, their dates as instants regardless of location.
+func assertTransaction(t *testing.T, want, got core.Transaction) {
+	t.Helper()
+
+	assert.True(t, want.Date.Equal(got.Date), "date: want %s, got %s", want.Date, got.Date)
+
+	want.Date, got.Date = time.Time{}, time.Time{}
+	assert.Equal(t, want, got)
+}
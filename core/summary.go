@@ -0,0 +1,96 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+type (
+	// MonthlySummary totals the transactions of a month, the credit being subtracted the next month.
+	MonthlySummary struct {
+		// Month is the first instant of the month, in UTC.
+		Month  time.Time
+		Totals Totals
+
+		// CreditDue is the credit of the previous month, which is due in this one.
+		CreditDue int
+
+		// Balance is the income minus the debit and the credit due.
+		Balance    int
+		Categories []CategoryTotals
+	}
+
+	// CategoryTotals sums the transactions of a category.
+	CategoryTotals struct {
+		Category Category
+		Totals   Totals
+	}
+)
+
+// Add sums a transaction to the totals, by its type.
+func (t *Totals) Add(trs Transaction) {
+	t.AddAll(trs.Type, trs.Amount, 1)
+}
+
+// AddAll sums count transactions of a type to the totals, amounting to amount altogether.
+func (t *Totals) AddAll(transactionType, amount, count int) {
+	switch transactionType {
+	case Debit:
+		t.Debit += amount
+	case Credit:
+		t.Credit += amount
+	case Income:
+		t.Income += amount
+	}
+	t.Count += count
+}
+
+// Summarize totals the transactions of the month of the given date, in UTC, by category ordered by name.
+func Summarize(ts []Transaction, month time.Time) MonthlySummary {
+	from := StartOfMonth(month)
+	previous := from.AddDate(0, -1, 0)
+
+	s := MonthlySummary{Month: from, Categories: []CategoryTotals{}}
+	byCategory := map[string]*Totals{}
+
+	for _, t := range ts {
+		if t.Type == Credit && InMonth(t, previous) {
+			s.CreditDue += t.Amount
+		}
+		if !InMonth(t, from) {
+			continue
+		}
+
+		ct, ok := byCategory[t.Category.Name]
+		if !ok {
+			ct = &Totals{}
+			byCategory[t.Category.Name] = ct
+		}
+
+		s.Totals.Add(t)
+		ct.Add(t)
+	}
+
+	for name, ct := range byCategory {
+		s.Categories = append(s.Categories, CategoryTotals{Category: Category{Name: name}, Totals: *ct})
+	}
+	sort.Slice(s.Categories, func(i, j int) bool {
+		return s.Categories[i].Category.Name < s.Categories[j].Category.Name
+	})
+
+	s.Balance = s.Totals.Income - s.Totals.Debit - s.CreditDue
+
+	return s
+}
+
+// StartOfMonth is the first instant of the month of t, in UTC.
+func StartOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// InMonth tells whether a transaction happened in the month starting at from, in UTC.
+func InMonth(t Transaction, from time.Time) bool {
+	date := t.Date.UTC()
+	return !date.Before(from) && date.Before(from.AddDate(0, 1, 0))
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	october := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	trs := func(typ, amount int, category string, date time.Time) Transaction {
+		return Transaction{Type: typ, Amount: amount, Category: Category{Name: category}, Date: date}
+	}
+
+	tests := map[string]struct {
+		ts    []Transaction
+		month time.Time
+		want  MonthlySummary
+	}{
+		"when the month has no transactions": {
+			ts:    []Transaction{trs(Debit, 100, "Food", october.AddDate(0, 1, 0))},
+			month: october,
+			want:  MonthlySummary{Month: october, Categories: []CategoryTotals{}},
+		},
+		"when the month has transactions, by category": {
+			ts: []Transaction{
+				trs(Income, 1000, "Salary", october.AddDate(0, 0, 4)),
+				trs(Debit, 100, "Food", october),
+				trs(Debit, 50, "Food", october.AddDate(0, 0, 10)),
+				trs(Credit, 300, "Travel", october.AddDate(0, 0, 20)),
+			},
+			month: october.AddDate(0, 0, 15),
+			want: MonthlySummary{
+				Month:   october,
+				Totals:  Totals{Debit: 150, Credit: 300, Income: 1000, Count: 4},
+				Balance: 850,
+				Categories: []CategoryTotals{
+					{Category: Category{Name: "Food"}, Totals: Totals{Debit: 150, Count: 2}},
+					{Category: Category{Name: "Salary"}, Totals: Totals{Income: 1000, Count: 1}},
+					{Category: Category{Name: "Travel"}, Totals: Totals{Credit: 300, Count: 1}},
+				},
+			},
+		},
+		"when the previous month has credit, it is due in this one": {
+			ts: []Transaction{
+				trs(Credit, 300, "Travel", october.AddDate(0, 0, -1)),
+				trs(Debit, 200, "Travel", october.AddDate(0, 0, -1)),
+				trs(Income, 1000, "Salary", october),
+			},
+			month: october,
+			want: MonthlySummary{
+				Month:     october,
+				Totals:    Totals{Income: 1000, Count: 1},
+				CreditDue: 300,
+				Balance:   700,
+				Categories: []CategoryTotals{
+					{Category: Category{Name: "Salary"}, Totals: Totals{Income: 1000, Count: 1}},
+				},
+			},
+		},
+		"when the date is not in UTC, its month is the UTC one": {
+			ts:    []Transaction{trs(Debit, 100, "Food", october)},
+			month: october.In(time.FixedZone("BRT", -3*60*60)),
+			want: MonthlySummary{
+				Month:      october,
+				Totals:     Totals{Debit: 100, Count: 1},
+				Balance:    -100,
+				Categories: []CategoryTotals{{Category: Category{Name: "Food"}, Totals: Totals{Debit: 100, Count: 1}}},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Summarize(tt.ts, tt.month))
+		})
+	}
+}
@@ -1,55 +1,332 @@
 package core
 
-import "github.com/pkg/errors"
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 type (
 	// Repository represents a client able to save and find a transaction.
 	Repository interface {
-		Create(Transaction) (Transaction, error)
-		Find() ([]Transaction, error)
+		Create(ctx context.Context, t Transaction, actor string) (Transaction, error)
+		CreateBatch(ctx context.Context, ts []Transaction, actor string) ([]Transaction, error)
+		Find(ctx context.Context) ([]Transaction, error)
+		// FindMonth finds the transactions dated in the month starting at from, in UTC, ordered by date.
+		FindMonth(ctx context.Context, from time.Time) ([]Transaction, error)
+		// FindPage finds up to limit transactions matching the filter listed after the cursor, ordered by date, then by id.
+		FindPage(ctx context.Context, f Filter, after Cursor, limit int) ([]Transaction, error)
+		// Count counts the transactions matching the filter.
+		Count(ctx context.Context, f Filter) (int, error)
+		FindByID(ctx context.Context, id int) (Transaction, error)
+		FindHistory(ctx context.Context, transactionID int) ([]AuditEntry, error)
+		Update(ctx context.Context, t Transaction, actor string) (Transaction, error)
+		// Delete removes a transaction, given the version is still the current one, returning it as removed.
+		Delete(ctx context.Context, id, version int, actor string) (Transaction, error)
+		// CreateCategory saves a category unless it exists, telling whether it did not.
+		CreateCategory(ctx context.Context, category Category, actor string) (bool, error)
+		FindCategories(ctx context.Context) ([]Category, error)
+		SumByCategory(ctx context.Context, names []string) (map[string]Totals, error)
+		// CountByType counts the transactions of each type, leaving out the types without any.
+		CountByType(ctx context.Context) (map[int]int, error)
 	}
 
 	// CreateTransactionUseCase implements the business logic to create a transaction.
 	CreateTransactionUseCase struct {
 		repository Repository
+		publisher  Publisher
+	}
+
+	// CreateTransactionBatchUseCase implements the business logic to create many transactions at once.
+	CreateTransactionBatchUseCase struct {
+		repository Repository
+		publisher  Publisher
 	}
 
 	// ListTransactionUseCase implements the business logic to find a transaction.
 	ListTransactionUseCase struct {
 		repository Repository
 	}
+
+	// GetTransactionUseCase implements the business logic to find a single transaction.
+	GetTransactionUseCase struct {
+		repository Repository
+	}
+
+	// UpdateTransactionUseCase implements the business logic to change a transaction.
+	UpdateTransactionUseCase struct {
+		repository Repository
+		publisher  Publisher
+	}
+
+	// DeleteTransactionUseCase implements the business logic to remove a transaction.
+	DeleteTransactionUseCase struct {
+		repository Repository
+		publisher  Publisher
+	}
+
+	// ListTransactionHistoryUseCase implements the business logic to find the changes made to a transaction.
+	ListTransactionHistoryUseCase struct {
+		repository Repository
+	}
 )
 
 // NewCreateTransactionUseCase initialize the use case.
-func NewCreateTransactionUseCase(r Repository) *CreateTransactionUseCase {
-	return &CreateTransactionUseCase{repository: r}
+func NewCreateTransactionUseCase(r Repository, p Publisher) *CreateTransactionUseCase {
+	return &CreateTransactionUseCase{repository: r, publisher: p}
 }
 
-// Create a transaction.
-func (uc *CreateTransactionUseCase) Create(t Transaction) (Transaction, error) {
+// Create a transaction on behalf of the actor.
+func (uc *CreateTransactionUseCase) Create(ctx context.Context, t Transaction, actor string) (Transaction, error) {
 	if err := t.Validate(); err != nil {
 		return Transaction{}, errors.Wrap(err, "Create failed")
 	}
 
-	transaction, err := uc.repository.Create(t)
+	events, err := createCategories(ctx, uc.repository, actor, t)
+	if err != nil {
+		return Transaction{}, errors.Wrap(err, "Create failed")
+	}
+
+	transaction, err := uc.repository.Create(ctx, t, actor)
 	if err != nil {
 		return Transaction{}, errors.Wrap(err, "Create failed")
 	}
 
+	uc.publisher.Publish(ctx, append(events, created([]Transaction{transaction}, actor)...)...)
+
 	return transaction, nil
 }
 
+// NewCreateTransactionBatchUseCase initialize the use case.
+func NewCreateTransactionBatchUseCase(r Repository, p Publisher) *CreateTransactionBatchUseCase {
+	return &CreateTransactionBatchUseCase{repository: r, publisher: p}
+}
+
+// CreateAll creates all the transactions or none of them, when any is invalid.
+func (uc *CreateTransactionBatchUseCase) CreateAll(ctx context.Context, ts []Transaction, actor string) ([]BatchResult, error) {
+	results, valid := validate(ts)
+	if len(valid) != len(ts) {
+		return results, errors.Wrap(ErrInvalidBatch, "CreateAll failed")
+	}
+
+	events, err := createCategories(ctx, uc.repository, actor, ts...)
+	if err != nil {
+		return []BatchResult{}, errors.Wrap(err, "CreateAll failed")
+	}
+
+	saved, err := uc.repository.CreateBatch(ctx, ts, actor)
+	if err != nil {
+		return []BatchResult{}, errors.Wrap(err, "CreateAll failed")
+	}
+
+	for i := range results {
+		results[i].Transaction = saved[i]
+	}
+
+	uc.publisher.Publish(ctx, append(events, created(saved, actor)...)...)
+
+	return results, nil
+}
+
+// CreateValid creates the valid transactions, the invalid ones are reported in their results.
+func (uc *CreateTransactionBatchUseCase) CreateValid(ctx context.Context, ts []Transaction, actor string) ([]BatchResult, error) {
+	results, valid := validate(ts)
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	batch := make([]Transaction, 0, len(valid))
+	for _, i := range valid {
+		batch = append(batch, ts[i])
+	}
+
+	events, err := createCategories(ctx, uc.repository, actor, batch...)
+	if err != nil {
+		return []BatchResult{}, errors.Wrap(err, "CreateValid failed")
+	}
+
+	saved, err := uc.repository.CreateBatch(ctx, batch, actor)
+	if err != nil {
+		return []BatchResult{}, errors.Wrap(err, "CreateValid failed")
+	}
+
+	for n, i := range valid {
+		results[i].Transaction = saved[n]
+	}
+
+	uc.publisher.Publish(ctx, append(events, created(saved, actor)...)...)
+
+	return results, nil
+}
+
+// validate each transaction, returning the results of the invalid ones and the indexes of the valid ones.
+func validate(ts []Transaction) ([]BatchResult, []int) {
+	results := make([]BatchResult, len(ts))
+	valid := make([]int, 0, len(ts))
+
+	for i, t := range ts {
+		if err := t.Validate(); err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	return results, valid
+}
+
+// createCategories saves the categories of the transactions which do not exist yet, once each, returning the events
+// of the ones it created, so they are published before the transactions adding them. The repository tells whether it
+// created each one, so concurrent writes publish a single event of a new category.
+func createCategories(ctx context.Context, r Repository, actor string, ts ...Transaction) ([]Event, error) {
+	var events []Event
+	seen := map[string]bool{}
+
+	for _, t := range ts {
+		if seen[t.Category.Name] {
+			continue
+		}
+		seen[t.Category.Name] = true
+
+		ok, err := r.CreateCategory(ctx, t.Category, actor)
+		if err != nil {
+			return []Event{}, err
+		}
+		if ok {
+			events = append(events, CategoryCreated{Category: t.Category, Actor: actor, Date: time.Now().UTC()})
+		}
+	}
+
+	return events, nil
+}
+
+// created returns the events of created transactions.
+func created(ts []Transaction, actor string) []Event {
+	now := time.Now().UTC()
+
+	events := make([]Event, 0, len(ts))
+	for _, t := range ts {
+		events = append(events, TransactionCreated{Transaction: t, Actor: actor, Date: now})
+	}
+	return events
+}
+
 // NewListTransactionUseCase initialize the use case.
 func NewListTransactionUseCase(r Repository) *ListTransactionUseCase {
 	return &ListTransactionUseCase{repository: r}
 }
 
 // List transaction(s).
-func (uc *ListTransactionUseCase) List() ([]Transaction, error) {
-	transactions, err := uc.repository.Find()
+func (uc *ListTransactionUseCase) List(ctx context.Context) ([]Transaction, error) {
+	transactions, err := uc.repository.Find(ctx)
 	if err != nil {
 		return []Transaction{}, errors.Wrap(err, "List failed")
 	}
 
 	return transactions, nil
 }
+
+// ListMonth lists the transactions dated in the month of the given date, in UTC.
+func (uc *ListTransactionUseCase) ListMonth(ctx context.Context, month time.Time) ([]Transaction, error) {
+	transactions, err := uc.repository.FindMonth(ctx, StartOfMonth(month))
+	if err != nil {
+		return []Transaction{}, errors.Wrap(err, "ListMonth failed")
+	}
+
+	return transactions, nil
+}
+
+// ListPage lists up to limit transactions matching the filter after the cursor, a page shorter than limit being the
+// last one.
+func (uc *ListTransactionUseCase) ListPage(ctx context.Context, f Filter, after Cursor, limit int) ([]Transaction, error) {
+	transactions, err := uc.repository.FindPage(ctx, f, after, limit)
+	if err != nil {
+		return []Transaction{}, errors.Wrap(err, "ListPage failed")
+	}
+
+	return transactions, nil
+}
+
+// Count counts the transactions matching the filter.
+func (uc *ListTransactionUseCase) Count(ctx context.Context, f Filter) (int, error) {
+	count, err := uc.repository.Count(ctx, f)
+	if err != nil {
+		return 0, errors.Wrap(err, "Count failed")
+	}
+
+	return count, nil
+}
+
+// NewGetTransactionUseCase initialize the use case.
+func NewGetTransactionUseCase(r Repository) *GetTransactionUseCase {
+	return &GetTransactionUseCase{repository: r}
+}
+
+// Get a transaction by its id.
+func (uc *GetTransactionUseCase) Get(ctx context.Context, id int) (Transaction, error) {
+	transaction, err := uc.repository.FindByID(ctx, id)
+	if err != nil {
+		return Transaction{}, errors.Wrap(err, "Get failed")
+	}
+
+	return transaction, nil
+}
+
+// NewUpdateTransactionUseCase initialize the use case.
+func NewUpdateTransactionUseCase(r Repository, p Publisher) *UpdateTransactionUseCase {
+	return &UpdateTransactionUseCase{repository: r, publisher: p}
+}
+
+// Update a transaction on behalf of the actor, given its Version is still the current one.
+func (uc *UpdateTransactionUseCase) Update(ctx context.Context, t Transaction, actor string) (Transaction, error) {
+	if err := t.Validate(); err != nil {
+		return Transaction{}, errors.Wrap(err, "Update failed")
+	}
+
+	events, err := createCategories(ctx, uc.repository, actor, t)
+	if err != nil {
+		return Transaction{}, errors.Wrap(err, "Update failed")
+	}
+
+	transaction, err := uc.repository.Update(ctx, t, actor)
+	if err != nil {
+		return Transaction{}, errors.Wrap(err, "Update failed")
+	}
+
+	uc.publisher.Publish(ctx, append(events, TransactionUpdated{Transaction: transaction, Actor: actor, Date: time.Now().UTC()})...)
+
+	return transaction, nil
+}
+
+// NewDeleteTransactionUseCase initialize the use case.
+func NewDeleteTransactionUseCase(r Repository, p Publisher) *DeleteTransactionUseCase {
+	return &DeleteTransactionUseCase{repository: r, publisher: p}
+}
+
+// Delete a transaction on behalf of the actor, given the version is still the current one.
+func (uc *DeleteTransactionUseCase) Delete(ctx context.Context, id, version int, actor string) error {
+	removed, err := uc.repository.Delete(ctx, id, version, actor)
+	if err != nil {
+		return errors.Wrap(err, "Delete failed")
+	}
+
+	uc.publisher.Publish(ctx, TransactionDeleted{Transaction: removed, Actor: actor, Date: time.Now().UTC()})
+
+	return nil
+}
+
+// NewListTransactionHistoryUseCase initialize the use case.
+func NewListTransactionHistoryUseCase(r Repository) *ListTransactionHistoryUseCase {
+	return &ListTransactionHistoryUseCase{repository: r}
+}
+
+// List the audit entries of a transaction, oldest first.
+func (uc *ListTransactionHistoryUseCase) List(ctx context.Context, transactionID int) ([]AuditEntry, error) {
+	entries, err := uc.repository.FindHistory(ctx, transactionID)
+	if err != nil {
+		return []AuditEntry{}, errors.Wrap(err, "ListHistory failed")
+	}
+
+	return entries, nil
+}
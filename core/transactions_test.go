@@ -1,7 +1,9 @@
 package core
 
 import (
+	"context"
 	"errors"
+	"strconv"
 	"testing"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 func TestCreateTransactionUseCase_CreateTransaction(t *testing.T) {
 	amount := test.RandomNumber()
 	name := test.RandomName()
+	actor := test.RandomUsername()
 
 	transaction := Transaction{
 		Amount: amount,
@@ -33,41 +36,75 @@ func TestCreateTransactionUseCase_CreateTransaction(t *testing.T) {
 		Date: time.Now(),
 	}
 
-	tests := map[string]func(t *testing.T, m *mockRepository){
-		"when invalid transaction": func(t *testing.T, m *mockRepository) {
+	tests := map[string]func(t *testing.T, m *mockRepository, p *mockPublisher){
+		"when invalid transaction": func(t *testing.T, m *mockRepository, p *mockPublisher) {
 			// arrange
-			uc := NewCreateTransactionUseCase(m)
+			uc := NewCreateTransactionUseCase(m, p)
 
 			// act
-			got, gotErr := uc.Create(Transaction{})
+			got, gotErr := uc.Create(context.Background(), Transaction{}, actor)
 
 			// assert
 			assert.EqualError(t, gotErr, "Create failed: Transaction.Validate: invalid amount")
 			assert.Empty(t, got)
+			assert.Empty(t, p.published)
+		},
+		"when repository fails to create category": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			m.On("CreateCategory", Category{Name: name}, actor).Return(false, errors.New("Repository.CreateCategory: err"))
+			uc := NewCreateTransactionUseCase(m, p)
+
+			// act
+			got, gotErr := uc.Create(context.Background(), transaction, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "Create failed: Repository.CreateCategory: err")
+			assert.Empty(t, got)
+			assert.Empty(t, p.published)
 		},
-		"when repository fails to create transaction": func(t *testing.T, m *mockRepository) {
+		"when repository fails to create transaction": func(t *testing.T, m *mockRepository, p *mockPublisher) {
 			// arrange
-			m.On("Create", transaction).Return(Transaction{}, errors.New("Repository.Create: err"))
-			uc := NewCreateTransactionUseCase(m)
+			m.On("CreateCategory", Category{Name: name}, actor).Return(false, nil)
+			m.On("Create", transaction, actor).Return(Transaction{}, errors.New("Repository.Create: err"))
+			uc := NewCreateTransactionUseCase(m, p)
 
 			// act
-			got, gotErr := uc.Create(transaction)
+			got, gotErr := uc.Create(context.Background(), transaction, actor)
 
 			// assert
 			assert.EqualError(t, gotErr, "Create failed: Repository.Create: err")
 			assert.Empty(t, got)
+			assert.Empty(t, p.published)
 		},
-		"when repository creates transaction": func(t *testing.T, m *mockRepository) {
+		"when repository creates transaction": func(t *testing.T, m *mockRepository, p *mockPublisher) {
 			// arrange
-			m.On("Create", transaction).Return(wantTransaction, nil)
-			uc := NewCreateTransactionUseCase(m)
+			m.On("CreateCategory", Category{Name: name}, actor).Return(false, nil)
+			m.On("Create", transaction, actor).Return(wantTransaction, nil)
+			uc := NewCreateTransactionUseCase(m, p)
 
 			// act
-			got, gotErr := uc.Create(transaction)
+			got, gotErr := uc.Create(context.Background(), transaction, actor)
 
 			// assert
 			assert.Equal(t, wantTransaction, got)
 			assert.NoError(t, gotErr)
+			assert.Equal(t, []Event{TransactionCreated{Transaction: wantTransaction, Actor: actor}}, undated(p.published))
+		},
+		"when repository creates transaction along with its category": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			m.On("CreateCategory", Category{Name: name}, actor).Return(true, nil)
+			m.On("Create", transaction, actor).Return(wantTransaction, nil)
+			uc := NewCreateTransactionUseCase(m, p)
+
+			// act
+			_, gotErr := uc.Create(context.Background(), transaction, actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []Event{
+				CategoryCreated{Category: Category{Name: name}, Actor: actor},
+				TransactionCreated{Transaction: wantTransaction, Actor: actor},
+			}, undated(p.published))
 		},
 	}
 
@@ -75,9 +112,158 @@ func TestCreateTransactionUseCase_CreateTransaction(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			// arrange
 			m := new(mockRepository)
+			p := new(mockPublisher)
 
 			// act
-			run(t, m)
+			run(t, m, p)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateTransactionBatchUseCase_CreateAll(t *testing.T) {
+	actor := test.RandomUsername()
+
+	valid := Transaction{Amount: test.RandomNumber() + 1, Type: Debit, Category: Category{Name: test.RandomName()}}
+	invalid := Transaction{Type: Debit, Category: Category{Name: test.RandomName()}}
+
+	created := valid
+	created.ID = test.RandomNumber()
+	created.Version = 1
+
+	tests := map[string]func(t *testing.T, m *mockRepository, p *mockPublisher){
+		"when a transaction is invalid, create none": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			uc := NewCreateTransactionBatchUseCase(m, p)
+
+			// act
+			got, gotErr := uc.CreateAll(context.Background(), []Transaction{valid, invalid}, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "CreateAll failed: invalid transactions in batch")
+			assert.Len(t, got, 2)
+			assert.NoError(t, got[0].Err)
+			assert.Empty(t, got[0].Transaction)
+			assert.EqualError(t, got[1].Err, "Transaction.Validate: invalid amount")
+			assert.Empty(t, p.published)
+		},
+		"when repository fails to create batch": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			m.On("CreateCategory", valid.Category, actor).Return(false, nil)
+			m.On("CreateBatch", []Transaction{valid, valid}, actor).Return([]Transaction{}, errors.New("Repository.CreateBatch: err"))
+			uc := NewCreateTransactionBatchUseCase(m, p)
+
+			// act
+			got, gotErr := uc.CreateAll(context.Background(), []Transaction{valid, valid}, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "CreateAll failed: Repository.CreateBatch: err")
+			assert.Empty(t, got)
+			assert.Empty(t, p.published)
+		},
+		"when repository creates batch": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			m.On("CreateCategory", valid.Category, actor).Return(true, nil).Once()
+			m.On("CreateBatch", []Transaction{valid, valid}, actor).Return([]Transaction{created, created}, nil)
+			uc := NewCreateTransactionBatchUseCase(m, p)
+
+			// act
+			got, gotErr := uc.CreateAll(context.Background(), []Transaction{valid, valid}, actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []BatchResult{{Transaction: created}, {Transaction: created}}, got)
+			assert.Equal(t, []Event{
+				CategoryCreated{Category: valid.Category, Actor: actor},
+				TransactionCreated{Transaction: created, Actor: actor},
+				TransactionCreated{Transaction: created, Actor: actor},
+			}, undated(p.published))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockRepository)
+			p := new(mockPublisher)
+
+			// act
+			run(t, m, p)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateTransactionBatchUseCase_CreateValid(t *testing.T) {
+	actor := test.RandomUsername()
+
+	valid := Transaction{Amount: test.RandomNumber() + 1, Type: Debit, Category: Category{Name: test.RandomName()}}
+	invalid := Transaction{Amount: test.RandomNumber() + 1, Category: Category{Name: test.RandomName()}}
+
+	created := valid
+	created.ID = test.RandomNumber()
+	created.Version = 1
+
+	tests := map[string]func(t *testing.T, m *mockRepository, p *mockPublisher){
+		"when all transactions are invalid, create none": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			uc := NewCreateTransactionBatchUseCase(m, p)
+
+			// act
+			got, gotErr := uc.CreateValid(context.Background(), []Transaction{invalid}, actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Len(t, got, 1)
+			assert.EqualError(t, got[0].Err, "Transaction.Validate: invalid type")
+			assert.Empty(t, p.published)
+		},
+		"when repository fails to create batch": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			m.On("CreateCategory", valid.Category, actor).Return(false, nil)
+			m.On("CreateBatch", []Transaction{valid}, actor).Return([]Transaction{}, errors.New("Repository.CreateBatch: err"))
+			uc := NewCreateTransactionBatchUseCase(m, p)
+
+			// act
+			got, gotErr := uc.CreateValid(context.Background(), []Transaction{invalid, valid}, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "CreateValid failed: Repository.CreateBatch: err")
+			assert.Empty(t, got)
+			assert.Empty(t, p.published)
+		},
+		"when repository creates the valid transactions": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			m.On("CreateCategory", valid.Category, actor).Return(false, nil)
+			m.On("CreateBatch", []Transaction{valid}, actor).Return([]Transaction{created}, nil)
+			uc := NewCreateTransactionBatchUseCase(m, p)
+
+			// act
+			got, gotErr := uc.CreateValid(context.Background(), []Transaction{invalid, valid}, actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Len(t, got, 2)
+			assert.EqualError(t, got[0].Err, "Transaction.Validate: invalid type")
+			assert.Empty(t, got[0].Transaction)
+			assert.NoError(t, got[1].Err)
+			assert.Equal(t, created, got[1].Transaction)
+			assert.Equal(t, []Event{TransactionCreated{Transaction: created, Actor: actor}}, undated(p.published))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockRepository)
+			p := new(mockPublisher)
+
+			// act
+			run(t, m, p)
 
 			// assert
 			m.AssertExpectations(t)
@@ -93,7 +279,7 @@ func TestListTransactionUseCase_List(t *testing.T) {
 			uc := NewListTransactionUseCase(m)
 
 			// act
-			got, gotErr := uc.List()
+			got, gotErr := uc.List(context.Background())
 
 			// assert
 			assert.EqualError(t, gotErr, "List failed: Repository.Find: err")
@@ -105,7 +291,7 @@ func TestListTransactionUseCase_List(t *testing.T) {
 			uc := NewListTransactionUseCase(m)
 
 			// act
-			got, gotErr := uc.List()
+			got, gotErr := uc.List(context.Background())
 
 			// assert
 			assert.Empty(t, got)
@@ -132,7 +318,7 @@ func TestListTransactionUseCase_List(t *testing.T) {
 			uc := NewListTransactionUseCase(m)
 
 			// act
-			got, gotErr := uc.List()
+			got, gotErr := uc.List(context.Background())
 
 			// assert
 			assert.ElementsMatch(t, got, []Transaction{transaction1, transaction2})
@@ -154,16 +340,468 @@ func TestListTransactionUseCase_List(t *testing.T) {
 	}
 }
 
+func TestListTransactionUseCase_ListMonth(t *testing.T) {
+	may := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]func(t *testing.T, m *mockRepository){
+		"when repository fails to find the transactions of the month": func(t *testing.T, m *mockRepository) {
+			// arrange
+			m.On("FindMonth", may).Return([]Transaction{}, errors.New("Repository.FindMonth: err"))
+			uc := NewListTransactionUseCase(m)
+
+			// act
+			got, gotErr := uc.ListMonth(context.Background(), may)
+
+			// assert
+			assert.EqualError(t, gotErr, "ListMonth failed: Repository.FindMonth: err")
+			assert.Empty(t, got)
+		},
+		"when given a date within the month, find the transactions from its start": func(t *testing.T, m *mockRepository) {
+			// arrange
+			lunch := Transaction{ID: 1, Amount: 2650, Type: Debit, Category: Category{Name: "Food"}, Date: may.AddDate(0, 0, 9)}
+			m.On("FindMonth", may).Return([]Transaction{lunch}, nil)
+			uc := NewListTransactionUseCase(m)
+
+			// act
+			got, gotErr := uc.ListMonth(context.Background(), time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC))
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []Transaction{lunch}, got)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockRepository)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListTransactionUseCase_ListPage(t *testing.T) {
+	after := Cursor{Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), ID: 7}
+	f := Filter{Category: "Food", Type: Debit}
+
+	tests := map[string]func(t *testing.T, m *mockRepository){
+		"when repository fails to find the page": func(t *testing.T, m *mockRepository) {
+			// arrange
+			m.On("FindPage", f, after, 2).Return([]Transaction{}, errors.New("Repository.FindPage: err"))
+			uc := NewListTransactionUseCase(m)
+
+			// act
+			got, gotErr := uc.ListPage(context.Background(), f, after, 2)
+
+			// assert
+			assert.EqualError(t, gotErr, "ListPage failed: Repository.FindPage: err")
+			assert.Empty(t, got)
+		},
+		"when succeed": func(t *testing.T, m *mockRepository) {
+			// arrange
+			lunch := Transaction{ID: 8, Amount: 2650, Type: Debit, Category: Category{Name: "Food"}, Date: after.Date}
+			m.On("FindPage", f, after, 2).Return([]Transaction{lunch}, nil)
+			uc := NewListTransactionUseCase(m)
+
+			// act
+			got, gotErr := uc.ListPage(context.Background(), f, after, 2)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []Transaction{lunch}, got)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockRepository)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListTransactionUseCase_Count(t *testing.T) {
+	f := Filter{Category: "Food"}
+
+	tests := map[string]func(t *testing.T, m *mockRepository){
+		"when repository fails to count": func(t *testing.T, m *mockRepository) {
+			// arrange
+			m.On("Count", f).Return(0, errors.New("Repository.Count: err"))
+			uc := NewListTransactionUseCase(m)
+
+			// act
+			got, gotErr := uc.Count(context.Background(), f)
+
+			// assert
+			assert.EqualError(t, gotErr, "Count failed: Repository.Count: err")
+			assert.Zero(t, got)
+		},
+		"when succeed": func(t *testing.T, m *mockRepository) {
+			// arrange
+			m.On("Count", f).Return(3, nil)
+			uc := NewListTransactionUseCase(m)
+
+			// act
+			got, gotErr := uc.Count(context.Background(), f)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, 3, got)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockRepository)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetTransactionUseCase_Get(t *testing.T) {
+	transaction := Transaction{
+		ID:       test.RandomNumber(),
+		Amount:   test.RandomNumber(),
+		Type:     Debit,
+		Category: Category{Name: test.RandomName()},
+		Date:     time.Now(),
+		Version:  1,
+	}
+
+	tests := map[string]func(t *testing.T, m *mockRepository){
+		"when repository fails to find transaction": func(t *testing.T, m *mockRepository) {
+			// arrange
+			m.On("FindByID", transaction.ID).Return(Transaction{}, errors.New("Repository.FindByID: err"))
+			uc := NewGetTransactionUseCase(m)
+
+			// act
+			got, gotErr := uc.Get(context.Background(), transaction.ID)
+
+			// assert
+			assert.EqualError(t, gotErr, "Get failed: Repository.FindByID: err")
+			assert.Empty(t, got)
+		},
+		"when repository finds transaction": func(t *testing.T, m *mockRepository) {
+			// arrange
+			m.On("FindByID", transaction.ID).Return(transaction, nil)
+			uc := NewGetTransactionUseCase(m)
+
+			// act
+			got, gotErr := uc.Get(context.Background(), transaction.ID)
+
+			// assert
+			assert.Equal(t, transaction, got)
+			assert.NoError(t, gotErr)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockRepository)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateTransactionUseCase_Update(t *testing.T) {
+	actor := test.RandomUsername()
+
+	transaction := Transaction{
+		ID:       test.RandomNumber(),
+		Amount:   test.RandomNumber() + 1,
+		Type:     Debit,
+		Category: Category{Name: test.RandomName()},
+		Version:  1,
+	}
+
+	wantTransaction := transaction
+	wantTransaction.Version = 2
+
+	tests := map[string]func(t *testing.T, m *mockRepository, p *mockPublisher){
+		"when invalid transaction": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			uc := NewUpdateTransactionUseCase(m, p)
+
+			// act
+			got, gotErr := uc.Update(context.Background(), Transaction{ID: transaction.ID}, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "Update failed: Transaction.Validate: invalid amount")
+			assert.Empty(t, got)
+			assert.Empty(t, p.published)
+		},
+		"when repository fails to update transaction": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			m.On("CreateCategory", transaction.Category, actor).Return(false, nil)
+			m.On("Update", transaction, actor).Return(Transaction{}, ErrStaleVersion)
+			uc := NewUpdateTransactionUseCase(m, p)
+
+			// act
+			got, gotErr := uc.Update(context.Background(), transaction, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "Update failed: stale version")
+			assert.Empty(t, got)
+			assert.Empty(t, p.published)
+		},
+		"when repository updates transaction": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			m.On("CreateCategory", transaction.Category, actor).Return(false, nil)
+			m.On("Update", transaction, actor).Return(wantTransaction, nil)
+			uc := NewUpdateTransactionUseCase(m, p)
+
+			// act
+			got, gotErr := uc.Update(context.Background(), transaction, actor)
+
+			// assert
+			assert.Equal(t, wantTransaction, got)
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []Event{TransactionUpdated{Transaction: wantTransaction, Actor: actor}}, undated(p.published))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockRepository)
+			p := new(mockPublisher)
+
+			// act
+			run(t, m, p)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteTransactionUseCase_Delete(t *testing.T) {
+	actor := test.RandomUsername()
+	id := test.RandomNumber()
+	version := test.RandomNumber()
+
+	removed := Transaction{ID: id, Amount: test.RandomNumber() + 1, Type: Debit, Category: Category{Name: test.RandomName()}, Version: version}
+
+	tests := map[string]func(t *testing.T, m *mockRepository, p *mockPublisher){
+		"when repository fails to delete transaction": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			m.On("Delete", id, version, actor).Return(Transaction{}, ErrNotFound)
+			uc := NewDeleteTransactionUseCase(m, p)
+
+			// act
+			gotErr := uc.Delete(context.Background(), id, version, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "Delete failed: not found")
+			assert.Empty(t, p.published)
+		},
+		"when repository deletes transaction": func(t *testing.T, m *mockRepository, p *mockPublisher) {
+			// arrange
+			m.On("Delete", id, version, actor).Return(removed, nil)
+			uc := NewDeleteTransactionUseCase(m, p)
+
+			// act
+			gotErr := uc.Delete(context.Background(), id, version, actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []Event{TransactionDeleted{Transaction: removed, Actor: actor}}, undated(p.published))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockRepository)
+			p := new(mockPublisher)
+
+			// act
+			run(t, m, p)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListTransactionHistoryUseCase_List(t *testing.T) {
+	transactionID := test.RandomNumber()
+
+	tests := map[string]func(t *testing.T, m *mockRepository){
+		"when repository fails to find history": func(t *testing.T, m *mockRepository) {
+			// arrange
+			m.On("FindHistory", transactionID).Return([]AuditEntry{}, errors.New("Repository.FindHistory: err"))
+			uc := NewListTransactionHistoryUseCase(m)
+
+			// act
+			got, gotErr := uc.List(context.Background(), transactionID)
+
+			// assert
+			assert.EqualError(t, gotErr, "ListHistory failed: Repository.FindHistory: err")
+			assert.Empty(t, got)
+		},
+		"when repository returns history": func(t *testing.T, m *mockRepository) {
+			// arrange
+			entry := AuditEntry{
+				ID:       test.RandomNumber(),
+				Entity:   AuditTransaction,
+				EntityID: strconv.Itoa(transactionID),
+				Action:   AuditCreate,
+				Actor:    test.RandomUsername(),
+				Date:     time.Now(),
+				After:    []byte(`{"id": 1}`),
+			}
+
+			m.On("FindHistory", transactionID).Return([]AuditEntry{entry}, nil)
+			uc := NewListTransactionHistoryUseCase(m)
+
+			// act
+			got, gotErr := uc.List(context.Background(), transactionID)
+
+			// assert
+			assert.Equal(t, []AuditEntry{entry}, got)
+			assert.NoError(t, gotErr)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockRepository)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
 type mockRepository struct {
 	mock.Mock
 }
 
-func (m *mockRepository) Create(t Transaction) (Transaction, error) {
-	args := m.Called(t)
+func (m *mockRepository) Create(_ context.Context, t Transaction, actor string) (Transaction, error) {
+	args := m.Called(t, actor)
 	return args.Get(0).(Transaction), args.Error(1)
 }
 
-func (m *mockRepository) Find() ([]Transaction, error) {
+func (m *mockRepository) CreateBatch(_ context.Context, ts []Transaction, actor string) ([]Transaction, error) {
+	args := m.Called(ts, actor)
+	return args.Get(0).([]Transaction), args.Error(1)
+}
+
+func (m *mockRepository) Find(context.Context) ([]Transaction, error) {
 	args := m.Called()
 	return args.Get(0).([]Transaction), args.Error(1)
 }
+
+func (m *mockRepository) FindMonth(_ context.Context, from time.Time) ([]Transaction, error) {
+	args := m.Called(from)
+	return args.Get(0).([]Transaction), args.Error(1)
+}
+
+func (m *mockRepository) FindPage(_ context.Context, f Filter, after Cursor, limit int) ([]Transaction, error) {
+	args := m.Called(f, after, limit)
+	return args.Get(0).([]Transaction), args.Error(1)
+}
+
+func (m *mockRepository) Count(_ context.Context, f Filter) (int, error) {
+	args := m.Called(f)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockRepository) FindHistory(_ context.Context, transactionID int) ([]AuditEntry, error) {
+	args := m.Called(transactionID)
+	return args.Get(0).([]AuditEntry), args.Error(1)
+}
+
+func (m *mockRepository) FindByID(_ context.Context, id int) (Transaction, error) {
+	args := m.Called(id)
+	return args.Get(0).(Transaction), args.Error(1)
+}
+
+func (m *mockRepository) Update(_ context.Context, t Transaction, actor string) (Transaction, error) {
+	args := m.Called(t, actor)
+	return args.Get(0).(Transaction), args.Error(1)
+}
+
+func (m *mockRepository) Delete(_ context.Context, id, version int, actor string) (Transaction, error) {
+	args := m.Called(id, version, actor)
+	return args.Get(0).(Transaction), args.Error(1)
+}
+
+func (m *mockRepository) CreateCategory(_ context.Context, category Category, actor string) (bool, error) {
+	args := m.Called(category, actor)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockRepository) FindCategories(context.Context) ([]Category, error) {
+	args := m.Called()
+	return args.Get(0).([]Category), args.Error(1)
+}
+
+func (m *mockRepository) SumByCategory(_ context.Context, names []string) (map[string]Totals, error) {
+	args := m.Called(names)
+	return args.Get(0).(map[string]Totals), args.Error(1)
+}
+
+func (m *mockRepository) CountByType(context.Context) (map[int]int, error) {
+	args := m.Called()
+	return args.Get(0).(map[int]int), args.Error(1)
+}
+
+// mockPublisher records the published events, whose dates are set by the use cases, see undated.
+type mockPublisher struct {
+	published []Event
+}
+
+func (p *mockPublisher) Publish(_ context.Context, events ...Event) {
+	p.published = append(p.published, events...)
+}
+
+// undated clears the dates of the events, so they can be compared.
+func undated(events []Event) []Event {
+	cleared := make([]Event, 0, len(events))
+	for _, event := range events {
+		switch e := event.(type) {
+		case TransactionCreated:
+			e.Date = time.Time{}
+			cleared = append(cleared, e)
+		case TransactionUpdated:
+			e.Date = time.Time{}
+			cleared = append(cleared, e)
+		case TransactionDeleted:
+			e.Date = time.Time{}
+			cleared = append(cleared, e)
+		case CategoryCreated:
+			e.Date = time.Time{}
+			cleared = append(cleared, e)
+		}
+	}
+	return cleared
+}
@@ -0,0 +1,97 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Reloader serves a TLS certificate loaded from a pair of files, reloading it when they change,
+// so renewed certificates are served without restarting.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	modified time.Time
+}
+
+// NewReloader initialize the reloader, loading the certificate and its key from PEM files.
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, as tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Reload loads the files again when any of them was modified since the last load, telling whether it did.
+// The current certificate is kept when they are invalid, eg: while being written.
+func (r *Reloader) Reload() (bool, error) {
+	modified, err := lastModified(r.certFile, r.keyFile)
+	if err != nil {
+		return false, errors.Wrap(err, "Reloader.Reload failed")
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil && modified.Equal(r.modified)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, errors.Wrap(err, "Reloader.Reload failed")
+	}
+
+	r.mu.Lock()
+	r.cert, r.modified = &cert, modified
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+// Watch checks the files every interval until ctx is done, reporting the failed reloads to onError.
+func (r *Reloader) Watch(ctx context.Context, every time.Duration, onError func(error)) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Reload(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// lastModified returns the latest modification time of the files.
+func lastModified(files ...string) (time.Time, error) {
+	var last time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	return last, nil
+}
@@ -0,0 +1,202 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReloader(t *testing.T) {
+	tests := map[string]func(*testing.T, string, string){
+		"when the files are valid": func(t *testing.T, certFile, keyFile string) {
+			// arrange
+			writeCert(t, certFile, keyFile, "first", time.Now())
+
+			// act
+			got, gotErr := NewReloader(certFile, keyFile)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, "first", commonName(t, got))
+		},
+		"when the files are missing": func(t *testing.T, certFile, keyFile string) {
+			// act
+			_, gotErr := NewReloader(certFile, keyFile)
+
+			// assert
+			assert.Error(t, gotErr)
+			assert.Contains(t, gotErr.Error(), "Reloader.Reload failed")
+		},
+		"when the key does not match": func(t *testing.T, certFile, keyFile string) {
+			// arrange
+			writeCert(t, certFile, keyFile, "first", time.Now())
+			writeCert(t, certFile, filepath.Join(filepath.Dir(keyFile), "other.pem"), "other", time.Now())
+
+			// act
+			_, gotErr := NewReloader(certFile, keyFile)
+
+			// assert
+			assert.Error(t, gotErr)
+			assert.Contains(t, gotErr.Error(), "private key does not match public key")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			run(t, filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+		})
+	}
+}
+
+func TestReloader_Reload(t *testing.T) {
+	tests := map[string]func(*testing.T, *Reloader, string, string){
+		"when the files are unchanged": func(t *testing.T, r *Reloader, certFile, keyFile string) {
+			// act
+			got, gotErr := r.Reload()
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.False(t, got)
+			assert.Equal(t, "first", commonName(t, r))
+		},
+		"when the files changed": func(t *testing.T, r *Reloader, certFile, keyFile string) {
+			// arrange
+			writeCert(t, certFile, keyFile, "renewed", time.Now().Add(time.Minute))
+
+			// act
+			got, gotErr := r.Reload()
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.True(t, got)
+			assert.Equal(t, "renewed", commonName(t, r))
+		},
+		"when the changed files are invalid, keep serving the previous one": func(t *testing.T, r *Reloader, certFile, keyFile string) {
+			// arrange
+			if err := os.WriteFile(certFile, []byte("half written"), 0o600); err != nil {
+				t.Fatalf("WriteFile failed: %s", err)
+			}
+
+			// act
+			got, gotErr := r.Reload()
+
+			// assert
+			assert.Error(t, gotErr)
+			assert.False(t, got)
+			assert.Equal(t, "first", commonName(t, r))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+			writeCert(t, certFile, keyFile, "first", time.Now())
+
+			r, err := NewReloader(certFile, keyFile)
+			if err != nil {
+				t.Fatalf("NewReloader failed: %s", err)
+			}
+
+			run(t, r, certFile, keyFile)
+		})
+	}
+}
+
+func TestReloader_Watch(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCert(t, certFile, keyFile, "first", time.Now())
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewReloader failed: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	// act
+	go func() {
+		// the files may be read while half written, which is retried on the next check
+		r.Watch(ctx, time.Millisecond, func(error) {})
+		close(done)
+	}()
+	writeCert(t, certFile, keyFile, "renewed", time.Now().Add(time.Minute))
+
+	// assert
+	assert.Eventually(t, func() bool {
+		cert, _ := r.GetCertificate(nil)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		return err == nil && leaf.Subject.CommonName == "renewed"
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+// writeCert writes a self-signed certificate for name and its key, modified at the given time.
+func writeCert(t *testing.T, certFile, keyFile, name string, modified time.Time) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %s", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey failed: %s", err)
+	}
+
+	for file, block := range map[string]*pem.Block{
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+		certFile: {Type: "CERTIFICATE", Bytes: der},
+	} {
+		if err := os.WriteFile(file, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatalf("WriteFile failed: %s", err)
+		}
+		if err := os.Chtimes(file, modified, modified); err != nil {
+			t.Fatalf("Chtimes failed: %s", err)
+		}
+	}
+}
+
+func commonName(t *testing.T, r *Reloader) string {
+	t.Helper()
+
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("GetCertificate failed: %s", err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("ParseCertificate failed: %s", err)
+	}
+	return leaf.Subject.CommonName
+}
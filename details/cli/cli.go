@@ -0,0 +1,245 @@
+// Package cli implements the commands to enter transactions and report on them from a terminal.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+)
+
+// Usage lists the commands and their arguments.
+const Usage = `usage: maskada [-api url] [-actor name] <command> [arguments]
+
+commands:
+  add <amount> <category> [name] [-credit | -income] [-date YYYY-MM-DD] [-json]
+  ls [-month YYYY-MM] [-category name] [-json]
+  summary [-month YYYY-MM] [-json]
+  import <file.csv> [-json]`
+
+// API represents a client able to create and list transactions.
+type API interface {
+	Create(t core.Transaction) (core.Transaction, error)
+	CreateBatch(ts []core.Transaction) ([]core.BatchResult, error)
+	List() ([]core.Transaction, error)
+	ListMonth(month time.Time) ([]core.Transaction, error)
+}
+
+// Commands runs the commands against the API, printing to Out.
+type Commands struct {
+	API API
+	Out io.Writer
+	Now func() time.Time
+}
+
+// NewCommands initialize the commands.
+func NewCommands(api API, out io.Writer) *Commands {
+	return &Commands{API: api, Out: out, Now: time.Now}
+}
+
+// Run runs the command named by the first argument, eg: add 26.50 Food "Family Flavor" -credit.
+func (c *Commands) Run(args []string) error {
+	if len(args) == 0 {
+		return errors.New(Usage)
+	}
+
+	switch args[0] {
+	case "add":
+		return c.Add(args[1:])
+	case "ls":
+		return c.List(args[1:])
+	case "summary":
+		return c.Summary(args[1:])
+	case "import":
+		return c.Import(args[1:])
+	}
+
+	return fmt.Errorf("unknown command %s\n%s", args[0], Usage)
+}
+
+// Add creates a transaction, a debit unless told otherwise.
+func (c *Commands) Add(args []string) error {
+	fs := newFlagSet("add")
+	credit := fs.Bool("credit", false, "the transaction is a credit, subtracted the next month")
+	income := fs.Bool("income", false, "the transaction is an income")
+	date := fs.String("date", "", "the date of the transaction, YYYY-MM-DD, defaults to now")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) < 2 || len(pos) > 3 {
+		return errors.New("usage: maskada add <amount> <category> [name] [-credit | -income] [-date YYYY-MM-DD] [-json]")
+	}
+	if *credit && *income {
+		return errors.New("a transaction is either a credit or an income")
+	}
+
+	amount, err := ParseAmount(pos[0])
+	if err != nil {
+		return err
+	}
+
+	t := core.Transaction{
+		Amount:   amount,
+		Type:     core.Debit,
+		Category: core.Category{Name: pos[1]},
+	}
+	if len(pos) == 3 {
+		t.Name = pos[2]
+	}
+	if *credit {
+		t.Type = core.Credit
+	}
+	if *income {
+		t.Type = core.Income
+	}
+	if *date != "" {
+		if t.Date, err = time.Parse(dateLayout, *date); err != nil {
+			return fmt.Errorf("invalid date %s, expected YYYY-MM-DD", *date)
+		}
+	}
+
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	created, err := c.API.Create(t)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(c.Out, newSkeleton(created))
+	}
+	printTransactions(c.Out, []core.Transaction{created})
+	return nil
+}
+
+// List prints the transactions, optionally of a single month and category, ordered by date.
+func (c *Commands) List(args []string) error {
+	fs := newFlagSet("ls")
+	month := fs.String("month", "", "only list transactions of a month, YYYY-MM")
+	category := fs.String("category", "", "only list transactions of a category, regardless of case")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return errors.New("usage: maskada ls [-month YYYY-MM] [-category name] [-json]")
+	}
+
+	var trsl []core.Transaction
+	if *month != "" {
+		from, err := parseMonth(*month)
+		if err != nil {
+			return err
+		}
+		if trsl, err = c.API.ListMonth(from); err != nil {
+			return err
+		}
+	} else if trsl, err = c.API.List(); err != nil {
+		return err
+	}
+
+	var filtered []core.Transaction
+	for _, t := range trsl {
+		if *category != "" && !strings.EqualFold(t.Category.Name, *category) {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+
+	if *asJSON {
+		res := []skeleton{}
+		for _, t := range filtered {
+			res = append(res, newSkeleton(t))
+		}
+		return printJSON(c.Out, res)
+	}
+	printTransactions(c.Out, filtered)
+	return nil
+}
+
+// Import creates the transactions of a CSV file, all or none of them.
+func (c *Commands) Import(args []string) error {
+	fs := newFlagSet("import")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return errors.New("usage: maskada import <file.csv> [-json]")
+	}
+
+	file, err := os.Open(pos[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	trsl, err := ReadCSV(file)
+	if err != nil {
+		return errors.Wrap(err, pos[0])
+	}
+	if len(trsl) > maxBatchSize {
+		return fmt.Errorf("%s: cannot import more than %d transactions at once", pos[0], maxBatchSize)
+	}
+
+	results, err := c.API.CreateBatch(trsl)
+	if err != nil {
+		for i, r := range results {
+			if r.Err != nil {
+				_, _ = fmt.Fprintf(c.Out, "transaction %d: %s\n", i+1, r.Err)
+			}
+		}
+		return err
+	}
+
+	for i := range trsl {
+		trsl[i].ID = results[i].Transaction.ID
+	}
+
+	if *asJSON {
+		res := []skeleton{}
+		for _, t := range trsl {
+			res = append(res, newSkeleton(t))
+		}
+		return printJSON(c.Out, res)
+	}
+	_, _ = fmt.Fprintf(c.Out, "imported %d transactions\n", len(trsl))
+	return nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	return fs
+}
+
+// parse parses the flags wherever they are among the arguments, returning the positional ones.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errors.Wrap(err, fs.Name())
+		}
+		if fs.NArg() == 0 {
+			return pos, nil
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
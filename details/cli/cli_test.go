@@ -0,0 +1,351 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gritt/maskada/core"
+)
+
+var (
+	testNow = time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+
+	testTrsList = []core.Transaction{
+		{ID: 1, Amount: 4000, Type: core.Credit, Category: core.Category{Name: "Food"}, Date: time.Date(2024, 4, 28, 0, 0, 0, 0, time.UTC), Name: "Market", Version: 1},
+		{ID: 2, Amount: 2650, Type: core.Credit, Category: core.Category{Name: "Food"}, Date: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), Name: "Family Flavor", Version: 1},
+		{ID: 3, Amount: 500000, Type: core.Income, Category: core.Category{Name: "Work"}, Date: time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC), Name: "Salary", Version: 1},
+		{ID: 4, Amount: 120000, Type: core.Debit, Category: core.Category{Name: "Home"}, Date: time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), Name: "Rent", Version: 2},
+		{ID: 5, Amount: 1230, Type: core.Debit, Category: core.Category{Name: "food"}, Date: time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC), Name: "Bakery", Version: 1},
+		{ID: 6, Amount: 900, Type: core.Debit, Category: core.Category{Name: "Food"}, Date: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Name: "Bakery", Version: 1},
+	}
+)
+
+func TestCommands_Run(t *testing.T) {
+	tests := map[string]func(*testing.T, *mockAPI, *Commands, *bytes.Buffer){
+		"when no command is given": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// act
+			gotErr := c.Run([]string{})
+
+			// assert
+			assert.EqualError(t, gotErr, Usage)
+		},
+		"when an unknown command is given": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// act
+			gotErr := c.Run([]string{"rm", "1"})
+
+			// assert
+			assert.EqualError(t, gotErr, "unknown command rm\n"+Usage)
+		},
+		"when a command is given": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("List").Return([]core.Transaction{}, nil)
+
+			// act
+			gotErr := c.Run([]string{"ls"})
+
+			// assert
+			assert.NoError(t, gotErr)
+			api.AssertExpectations(t)
+		},
+	}
+
+	runCommandTests(t, tests)
+}
+
+func TestCommands_Add(t *testing.T) {
+	created := testTrsList[1]
+
+	tests := map[string]func(*testing.T, *mockAPI, *Commands, *bytes.Buffer){
+		"when flags follow the arguments": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("Create", core.Transaction{
+				Amount:   2650,
+				Type:     core.Credit,
+				Category: core.Category{Name: "Food"},
+				Date:     created.Date,
+				Name:     "Family Flavor",
+			}).Return(created, nil)
+
+			// act
+			gotErr := c.Add([]string{"26.50", "Food", "Family Flavor", "--credit", "-date", "2024-05-02"})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, ""+
+				"ID  DATE        TYPE    CATEGORY  AMOUNT  NAME\n"+
+				"2   2024-05-02  credit  Food      26.50   Family Flavor\n", out.String())
+		},
+		"when no type is given, add a debit": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("Create", core.Transaction{Amount: 900, Type: core.Debit, Category: core.Category{Name: "Food"}}).Return(testTrsList[5], nil)
+
+			// act
+			gotErr := c.Add([]string{"9", "Food"})
+
+			// assert
+			assert.NoError(t, gotErr)
+		},
+		"when printing JSON": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("Create", mock.Anything).Return(created, nil)
+
+			// act
+			gotErr := c.Add([]string{"--income", "--json", "26.50", "Food"})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.JSONEq(t, `{"id": 2, "amount": 2650, "type": 2, "category": "Food", "date": "2024-05-02T00:00:00Z", "name": "Family Flavor", "version": 1}`, out.String())
+			assert.Equal(t, core.Income, api.Calls[0].Arguments.Get(0).(core.Transaction).Type)
+		},
+		"when invalid, fail before calling the api": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// act
+			gotErr := c.Add([]string{"0", "Food"})
+
+			// assert
+			assert.EqualError(t, gotErr, "Transaction.Validate: invalid amount")
+			api.AssertNotCalled(t, "Create", mock.Anything)
+		},
+		"when the amount is not a number": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// act
+			gotErr := c.Add([]string{"26,50", "Food"})
+
+			// assert
+			assert.EqualError(t, gotErr, "invalid amount 26,50")
+		},
+		"when the date is invalid": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// act
+			gotErr := c.Add([]string{"26.50", "Food", "-date", "02/05/2024"})
+
+			// assert
+			assert.EqualError(t, gotErr, "invalid date 02/05/2024, expected YYYY-MM-DD")
+		},
+		"when both credit and income": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// act
+			gotErr := c.Add([]string{"26.50", "Food", "-credit", "-income"})
+
+			// assert
+			assert.EqualError(t, gotErr, "a transaction is either a credit or an income")
+		},
+		"when the arguments are missing": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// act
+			gotErr := c.Add([]string{"26.50"})
+
+			// assert
+			assert.Error(t, gotErr)
+			assert.Contains(t, gotErr.Error(), "usage: maskada add")
+		},
+		"when the flag is unknown": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// act
+			gotErr := c.Add([]string{"26.50", "Food", "-debit"})
+
+			// assert
+			assert.EqualError(t, gotErr, "add: flag provided but not defined: -debit")
+		},
+		"when the api fails": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("Create", mock.Anything).Return(core.Transaction{}, errors.New("Client.Create failed"))
+
+			// act
+			gotErr := c.Add([]string{"26.50", "Food"})
+
+			// assert
+			assert.EqualError(t, gotErr, "Client.Create failed")
+		},
+	}
+
+	runCommandTests(t, tests)
+}
+
+func TestCommands_List(t *testing.T) {
+	tests := map[string]func(*testing.T, *mockAPI, *Commands, *bytes.Buffer){
+		"when filtered by month and category": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("ListMonth", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)).Return(testTrsList[1:5], nil)
+
+			// act
+			gotErr := c.List([]string{"--month", "2024-05", "--category", "Food"})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, ""+
+				"ID  DATE        TYPE    CATEGORY  AMOUNT  NAME\n"+
+				"2   2024-05-02  credit  Food      26.50   Family Flavor\n"+
+				"5   2024-05-31  debit   food      12.30   Bakery\n", out.String())
+		},
+		"when printing JSON": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("List").Return(testTrsList, nil)
+
+			// act
+			gotErr := c.List([]string{"-category", "Home", "-json"})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.JSONEq(t, `[{"id": 4, "amount": 120000, "type": 1, "category": "Home", "date": "2024-05-10T00:00:00Z", "name": "Rent", "version": 2}]`, out.String())
+		},
+		"when nothing matches, print an empty JSON list": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("ListMonth", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)).Return([]core.Transaction{}, nil)
+
+			// act
+			gotErr := c.List([]string{"-month", "2023-01", "-json"})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.JSONEq(t, `[]`, out.String())
+		},
+		"when the month is invalid, fail before calling the api": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// act
+			gotErr := c.List([]string{"-month", "May"})
+
+			// assert
+			assert.EqualError(t, gotErr, "invalid month May, expected YYYY-MM")
+			api.AssertNotCalled(t, "ListMonth", mock.Anything)
+		},
+		"when the api fails": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("List").Return([]core.Transaction{}, errors.New("Client.List failed"))
+
+			// act
+			gotErr := c.List([]string{})
+
+			// assert
+			assert.EqualError(t, gotErr, "Client.List failed")
+		},
+	}
+
+	runCommandTests(t, tests)
+}
+
+func TestCommands_Import(t *testing.T) {
+	csv := "date,amount,category,name,type\n" +
+		"2024-05-02,26.50,Food,Family Flavor,credit\n" +
+		"2024-05-05,5000,Work,Salary,income\n"
+
+	tests := map[string]func(*testing.T, *mockAPI, *Commands, *bytes.Buffer){
+		"when imported": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			file := writeFile(t, csv)
+			api.On("CreateBatch", []core.Transaction{
+				{Amount: 2650, Type: core.Credit, Category: core.Category{Name: "Food"}, Date: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), Name: "Family Flavor"},
+				{Amount: 500000, Type: core.Income, Category: core.Category{Name: "Work"}, Date: time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC), Name: "Salary"},
+			}).Return([]core.BatchResult{{Transaction: core.Transaction{ID: 7}}, {Transaction: core.Transaction{ID: 8}}}, nil)
+
+			// act
+			gotErr := c.Import([]string{file})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, "imported 2 transactions\n", out.String())
+		},
+		"when printing JSON": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			file := writeFile(t, csv)
+			api.On("CreateBatch", mock.Anything).Return([]core.BatchResult{{Transaction: core.Transaction{ID: 7}}, {Transaction: core.Transaction{ID: 8}}}, nil)
+
+			// act
+			gotErr := c.Import([]string{"-json", file})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.JSONEq(t, `[
+				{"id": 7, "amount": 2650, "type": 2, "category": "Food", "date": "2024-05-02T00:00:00Z", "name": "Family Flavor", "version": 0},
+				{"id": 8, "amount": 500000, "type": 3, "category": "Work", "date": "2024-05-05T00:00:00Z", "name": "Salary", "version": 0}
+			]`, out.String())
+		},
+		"when invalid, fail before calling the api": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			file := writeFile(t, "date,amount,category\n2024-05-02,0,Food\n")
+
+			// act
+			gotErr := c.Import([]string{file})
+
+			// assert
+			assert.EqualError(t, gotErr, file+": line 2: Transaction.Validate: invalid amount")
+			api.AssertNotCalled(t, "CreateBatch", mock.Anything)
+		},
+		"when the api refuses the batch": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			file := writeFile(t, csv)
+			api.On("CreateBatch", mock.Anything).Return(
+				[]core.BatchResult{{}, {Err: errors.New("Transaction.Validate: invalid type")}},
+				pkgerrors.Wrap(core.ErrInvalidBatch, "Client.CreateBatch failed"),
+			)
+
+			// act
+			gotErr := c.Import([]string{file})
+
+			// assert
+			assert.Equal(t, core.ErrInvalidBatch, pkgerrors.Cause(gotErr))
+			assert.Equal(t, "transaction 2: Transaction.Validate: invalid type\n", out.String())
+		},
+		"when the file does not exist": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// act
+			gotErr := c.Import([]string{filepath.Join(t.TempDir(), "missing.csv")})
+
+			// assert
+			assert.Error(t, gotErr)
+		},
+		"when no file is given": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// act
+			gotErr := c.Import([]string{})
+
+			// assert
+			assert.EqualError(t, gotErr, "usage: maskada import <file.csv> [-json]")
+		},
+	}
+
+	runCommandTests(t, tests)
+}
+
+func runCommandTests(t *testing.T, tests map[string]func(*testing.T, *mockAPI, *Commands, *bytes.Buffer)) {
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := new(mockAPI)
+			out := new(bytes.Buffer)
+			c := &Commands{API: api, Out: out, Now: func() time.Time { return testNow }}
+
+			run(t, api, c, out)
+		})
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "bank.csv")
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %s", err)
+	}
+	return file
+}
+
+type mockAPI struct {
+	mock.Mock
+}
+
+func (m *mockAPI) Create(t core.Transaction) (core.Transaction, error) {
+	args := m.Called(t)
+	return args.Get(0).(core.Transaction), args.Error(1)
+}
+
+func (m *mockAPI) CreateBatch(ts []core.Transaction) ([]core.BatchResult, error) {
+	args := m.Called(ts)
+	return args.Get(0).([]core.BatchResult), args.Error(1)
+}
+
+func (m *mockAPI) List() ([]core.Transaction, error) {
+	args := m.Called()
+	return args.Get(0).([]core.Transaction), args.Error(1)
+}
+
+func (m *mockAPI) ListMonth(month time.Time) ([]core.Transaction, error) {
+	args := m.Called(month)
+	return args.Get(0).([]core.Transaction), args.Error(1)
+}
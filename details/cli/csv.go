@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+)
+
+// ReadCSV reads and validates the transactions of a CSV file, which header names its columns:
+// date (YYYY-MM-DD), amount, and optionally category, name (or description) and type (debit, credit or income).
+// Without a type, a negative amount is a debit and a positive one an income, as in most bank statements.
+// Without a category, a transaction is core.Uncategorized, to be categorized later.
+func ReadCSV(r io.Reader) ([]core.Transaction, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("empty file")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "description" {
+			name = "name"
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"date", "amount"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing %s column", required)
+		}
+	}
+
+	var trsl []core.Transaction
+	var problems []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		t, err := readTransaction(record, columns)
+		if err == nil {
+			err = t.Validate()
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %s", line, err))
+			continue
+		}
+
+		trsl = append(trsl, t)
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "\n"))
+	}
+	if len(trsl) == 0 {
+		return nil, errors.New("no transactions")
+	}
+
+	return trsl, nil
+}
+
+func readTransaction(record []string, columns map[string]int) (core.Transaction, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	date, err := time.Parse(dateLayout, field("date"))
+	if err != nil {
+		return core.Transaction{}, fmt.Errorf("invalid date %s, expected YYYY-MM-DD", field("date"))
+	}
+
+	amount, err := ParseAmount(field("amount"))
+	if err != nil {
+		return core.Transaction{}, err
+	}
+
+	t := core.Transaction{
+		Amount:   amount,
+		Category: core.Category{Name: field("category")},
+		Date:     date,
+		Name:     field("name"),
+	}
+	if t.Category.Name == "" {
+		t.Category.Name = core.Uncategorized
+	}
+
+	switch kind := strings.ToLower(field("type")); kind {
+	case "":
+		t.Type = core.Income
+		if amount < 0 {
+			t.Type = core.Debit
+		}
+	default:
+		t.Type = 0
+		for typ, name := range typeNames {
+			if name == kind {
+				t.Type = typ
+			}
+		}
+		if t.Type == 0 {
+			return core.Transaction{}, fmt.Errorf("invalid type %s, expected debit, credit or income", kind)
+		}
+	}
+
+	if t.Amount < 0 {
+		t.Amount = -t.Amount
+	}
+
+	return t, nil
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+)
+
+func TestReadCSV(t *testing.T) {
+	date := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]func(*testing.T){
+		"when the types are given": func(t *testing.T) {
+			// arrange
+			given := "Date, Amount, Category, Name, Type\n" +
+				"2024-05-10,26.50,Food,Family Flavor,Credit\n" +
+				"2024-05-10,-12.3,Food,,debit\n"
+
+			// act
+			got, gotErr := ReadCSV(strings.NewReader(given))
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []core.Transaction{
+				{Amount: 2650, Type: core.Credit, Category: core.Category{Name: "Food"}, Date: date, Name: "Family Flavor"},
+				{Amount: 1230, Type: core.Debit, Category: core.Category{Name: "Food"}, Date: date},
+			}, got)
+		},
+		"when no type is given, tell them by the amount sign": func(t *testing.T) {
+			// arrange
+			given := "category,description,amount,date\n" +
+				"Food,Bakery,-12.30,2024-05-10\n" +
+				"Refund,Shop,100,2024-05-10\n"
+
+			// act
+			got, gotErr := ReadCSV(strings.NewReader(given))
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []core.Transaction{
+				{Amount: 1230, Type: core.Debit, Category: core.Category{Name: "Food"}, Date: date, Name: "Bakery"},
+				{Amount: 10000, Type: core.Income, Category: core.Category{Name: "Refund"}, Date: date, Name: "Shop"},
+			}, got)
+		},
+		"when no category is given, leave them uncategorized": func(t *testing.T) {
+			// arrange
+			given := "date,amount,category,name\n" +
+				"2024-05-10,-12.30,,Bakery\n"
+
+			// act
+			got, gotErr := ReadCSV(strings.NewReader(given))
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []core.Transaction{
+				{Amount: 1230, Type: core.Debit, Category: core.Category{Name: core.Uncategorized}, Date: date, Name: "Bakery"},
+			}, got)
+		},
+		"when there is no category column, leave them uncategorized": func(t *testing.T) {
+			// act
+			got, gotErr := ReadCSV(strings.NewReader("date,amount\n2024-05-10,1\n"))
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []core.Transaction{
+				{Amount: 100, Type: core.Income, Category: core.Category{Name: core.Uncategorized}, Date: date},
+			}, got)
+		},
+		"when lines are invalid, report all of them": func(t *testing.T) {
+			// arrange
+			given := "date,amount,category,type\n" +
+				"2024-05-10,1,Food,debit\n" +
+				"10/05/2024,1,Food,debit\n" +
+				"2024-05-10,0,Food,debit\n" +
+				"2024-05-10,1,Food,loan\n"
+
+			// act
+			got, gotErr := ReadCSV(strings.NewReader(given))
+
+			// assert
+			assert.EqualError(t, gotErr, ""+
+				"line 3: invalid date 10/05/2024, expected YYYY-MM-DD\n"+
+				"line 4: Transaction.Validate: invalid amount\n"+
+				"line 5: invalid type loan, expected debit, credit or income")
+			assert.Empty(t, got)
+		},
+		"when a column is missing": func(t *testing.T) {
+			// act
+			_, gotErr := ReadCSV(strings.NewReader("date,category\n2024-05-10,Food\n"))
+
+			// assert
+			assert.EqualError(t, gotErr, "missing amount column")
+		},
+		"when there are no transactions": func(t *testing.T) {
+			// act
+			_, gotErr := ReadCSV(strings.NewReader("date,amount,category\n"))
+
+			// assert
+			assert.EqualError(t, gotErr, "no transactions")
+		},
+		"when the file is empty": func(t *testing.T) {
+			// act
+			_, gotErr := ReadCSV(strings.NewReader(""))
+
+			// assert
+			assert.EqualError(t, gotErr, "empty file")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
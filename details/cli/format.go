@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gritt/maskada/core"
+)
+
+const (
+	dateLayout  = "2006-01-02"
+	monthLayout = "2006-01"
+
+	// maxBatchSize is the maximum number of transactions the API creates in a single batch.
+	maxBatchSize = 500
+)
+
+// typeNames are the names of the transaction types, as typed and printed.
+var typeNames = map[int]string{
+	core.Debit:  "debit",
+	core.Credit: "credit",
+	core.Income: "income",
+}
+
+// amountPattern matches decimal amounts with up to two fraction digits, eg: 26, 26.5, 26.50.
+var amountPattern = regexp.MustCompile(`^-?\d+(\.\d{1,2})?$`)
+
+// skeleton is how a transaction is printed as JSON, the same as the API does.
+type skeleton struct {
+	ID       int       `json:"id"`
+	Amount   int       `json:"amount"`
+	Type     int       `json:"type"`
+	Category string    `json:"category"`
+	Date     time.Time `json:"date"`
+	Name     string    `json:"name"`
+	Version  int       `json:"version"`
+}
+
+func newSkeleton(t core.Transaction) skeleton {
+	return skeleton{
+		ID:       t.ID,
+		Amount:   t.Amount,
+		Type:     t.Type,
+		Category: t.Category.Name,
+		Date:     t.Date,
+		Name:     t.Name,
+		Version:  t.Version,
+	}
+}
+
+// ParseAmount parses a decimal amount into cents, eg: 26.50 is 2650.
+func ParseAmount(s string) (int, error) {
+	if !amountPattern.MatchString(s) {
+		return 0, fmt.Errorf("invalid amount %s", s)
+	}
+
+	digits := strings.TrimPrefix(s, "-")
+
+	units, cents := digits, "00"
+	if i := strings.Index(digits, "."); i >= 0 {
+		units, cents = digits[:i], (digits[i+1:] + "0")[:2]
+	}
+
+	amount, err := strconv.Atoi(units + cents)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %s", s)
+	}
+
+	if strings.HasPrefix(s, "-") {
+		return -amount, nil
+	}
+	return amount, nil
+}
+
+// FormatAmount formats cents as a decimal amount, eg: 2650 is 26.50.
+func FormatAmount(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+func parseMonth(s string) (time.Time, error) {
+	from, err := time.Parse(monthLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month %s, expected YYYY-MM", s)
+	}
+	return from, nil
+}
+
+func printTransactions(out io.Writer, trsl []core.Transaction) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tDATE\tTYPE\tCATEGORY\tAMOUNT\tNAME")
+	for _, t := range trsl {
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			t.ID, t.Date.UTC().Format(dateLayout), typeNames[t.Type], t.Category.Name, FormatAmount(t.Amount), t.Name)
+	}
+	_ = w.Flush()
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
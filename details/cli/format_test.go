@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := map[string]struct {
+		given   string
+		want    int
+		wantErr bool
+	}{
+		"when units":              {given: "26", want: 2600},
+		"when one fraction digit": {given: "26.5", want: 2650},
+		"when cents":              {given: "26.50", want: 2650},
+		"when negative":           {given: "-0.05", want: -5},
+		"when too many digits":    {given: "26.505", wantErr: true},
+		"when a comma":            {given: "26,50", wantErr: true},
+		"when not a number":       {given: "abc", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			got, gotErr := ParseAmount(tt.given)
+
+			// assert
+			if tt.wantErr {
+				assert.EqualError(t, gotErr, "invalid amount "+tt.given)
+				return
+			}
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "26.50", FormatAmount(2650))
+	assert.Equal(t, "0.05", FormatAmount(5))
+	assert.Equal(t, "-1200.00", FormatAmount(-120000))
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+)
+
+type (
+	// summary totals the transactions of a month, the credit being subtracted the next month.
+	summary struct {
+		Month      string            `json:"month"`
+		Income     int               `json:"income"`
+		Debit      int               `json:"debit"`
+		Credit     int               `json:"credit"`
+		CreditDue  int               `json:"credit_due"`
+		Balance    int               `json:"balance"`
+		Categories []categorySummary `json:"categories"`
+	}
+
+	categorySummary struct {
+		Category string `json:"category"`
+		Debit    int    `json:"debit"`
+		Credit   int    `json:"credit"`
+		Income   int    `json:"income"`
+	}
+)
+
+// Summary prints the totals of a month by category and type, along with its balance.
+func (c *Commands) Summary(args []string) error {
+	fs := newFlagSet("summary")
+	month := fs.String("month", "", "the month to summarize, YYYY-MM, defaults to the current one")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return errors.New("usage: maskada summary [-month YYYY-MM] [-json]")
+	}
+
+	from := core.StartOfMonth(c.Now())
+	if *month != "" {
+		if from, err = parseMonth(*month); err != nil {
+			return err
+		}
+	}
+
+	trsl, err := c.API.List()
+	if err != nil {
+		return err
+	}
+
+	s := newSummary(core.Summarize(trsl, from))
+
+	if *asJSON {
+		return printJSON(c.Out, s)
+	}
+	printSummary(c.Out, s)
+	return nil
+}
+
+func newSummary(ms core.MonthlySummary) summary {
+	s := summary{
+		Month:      ms.Month.Format(monthLayout),
+		Income:     ms.Totals.Income,
+		Debit:      ms.Totals.Debit,
+		Credit:     ms.Totals.Credit,
+		CreditDue:  ms.CreditDue,
+		Balance:    ms.Balance,
+		Categories: []categorySummary{},
+	}
+
+	for _, ct := range ms.Categories {
+		s.Categories = append(s.Categories, categorySummary{
+			Category: ct.Category.Name,
+			Debit:    ct.Totals.Debit,
+			Credit:   ct.Totals.Credit,
+			Income:   ct.Totals.Income,
+		})
+	}
+
+	return s
+}
+
+func printSummary(out io.Writer, s summary) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "CATEGORY\tDEBIT\tCREDIT\tINCOME")
+	for _, cs := range s.Categories {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", cs.Category, FormatAmount(cs.Debit), FormatAmount(cs.Credit), FormatAmount(cs.Income))
+	}
+	_ = w.Flush()
+
+	_, _ = fmt.Fprintln(out)
+
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "TOTAL\t%s\n", s.Month)
+	_, _ = fmt.Fprintf(w, "income\t%s\n", FormatAmount(s.Income))
+	_, _ = fmt.Fprintf(w, "debit\t%s\n", FormatAmount(s.Debit))
+	_, _ = fmt.Fprintf(w, "credit due\t%s\n", FormatAmount(s.CreditDue))
+	_, _ = fmt.Fprintf(w, "balance\t%s\n", FormatAmount(s.Balance))
+	_, _ = fmt.Fprintf(w, "credit due next month\t%s\n", FormatAmount(s.Credit))
+	_ = w.Flush()
+}
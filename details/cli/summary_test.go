@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+)
+
+func TestCommands_Summary(t *testing.T) {
+	tests := map[string]func(*testing.T, *mockAPI, *Commands, *bytes.Buffer){
+		"when no month is given, summarize the current one": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("List").Return(testTrsList, nil)
+
+			// act
+			gotErr := c.Summary([]string{})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, ""+
+				"CATEGORY  DEBIT    CREDIT  INCOME\n"+
+				"Food      0.00     26.50   0.00\n"+
+				"Home      1200.00  0.00    0.00\n"+
+				"Work      0.00     0.00    5000.00\n"+
+				"food      12.30    0.00    0.00\n"+
+				"\n"+
+				"TOTAL                  2024-05\n"+
+				"income                 5000.00\n"+
+				"debit                  1212.30\n"+
+				"credit due             40.00\n"+
+				"balance                3747.70\n"+
+				"credit due next month  26.50\n", out.String())
+		},
+		"when printing JSON": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("List").Return(testTrsList, nil)
+
+			// act
+			gotErr := c.Summary([]string{"-month", "2024-06", "-json"})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.JSONEq(t, `{
+				"month": "2024-06",
+				"income": 0,
+				"debit": 900,
+				"credit": 0,
+				"credit_due": 2650,
+				"balance": -3550,
+				"categories": [{"category": "Food", "debit": 900, "credit": 0, "income": 0}]
+			}`, out.String())
+		},
+		"when the month has no transactions": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("List").Return([]core.Transaction{}, nil)
+
+			// act
+			gotErr := c.Summary([]string{"-month", "2024-01", "-json"})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.JSONEq(t, `{"month": "2024-01", "income": 0, "debit": 0, "credit": 0, "credit_due": 0, "balance": 0, "categories": []}`, out.String())
+		},
+		"when the api fails": func(t *testing.T, api *mockAPI, c *Commands, out *bytes.Buffer) {
+			// arrange
+			api.On("List").Return([]core.Transaction{}, errors.New("Client.List failed"))
+
+			// act
+			gotErr := c.Summary([]string{})
+
+			// assert
+			assert.EqualError(t, gotErr, "Client.List failed")
+		},
+	}
+
+	runCommandTests(t, tests)
+}
@@ -0,0 +1,226 @@
+// Package client talks to the maskada REST API.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+)
+
+// actorHeader identifies who is performing a change, as expected by the API.
+const actorHeader = "X-Actor"
+
+// monthLayout is the layout of the month query parameter, eg: 2019-10.
+const monthLayout = "2006-01"
+
+type skeleton struct {
+	ID       int       `json:"id,omitempty"`
+	Amount   int       `json:"amount"`
+	Type     int       `json:"type"`
+	Category string    `json:"category"`
+	Date     time.Time `json:"date"`
+	Name     string    `json:"name"`
+	Version  int       `json:"version,omitempty"`
+}
+
+type batchResultSkeleton struct {
+	Index int    `json:"index"`
+	ID    int    `json:"id"`
+	Error string `json:"error"`
+}
+
+type errorSkeleton struct {
+	Error string `json:"error"`
+}
+
+// Client is able to create, change and list transactions through the API, on behalf of an actor.
+type Client struct {
+	baseURL string
+	actor   string
+	http    *http.Client
+}
+
+// NewClient initialize the client of the API served at baseURL, eg: http://localhost:8888.
+func NewClient(baseURL, actor string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		actor:   actor,
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Create a transaction.
+func (c *Client) Create(t core.Transaction) (core.Transaction, error) {
+	var res skeleton
+	if err := c.do(http.MethodPost, "/v1/transaction", nil, newSkeleton(t), &res, http.StatusCreated); err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Client.Create failed")
+	}
+
+	return res.transaction(), nil
+}
+
+// CreateBatch creates all the transactions or none of them, the results hold the ids of the created ones,
+// or why each invalid one was refused along with core.ErrInvalidBatch.
+func (c *Client) CreateBatch(ts []core.Transaction) ([]core.BatchResult, error) {
+	payload := make([]skeleton, 0, len(ts))
+	for _, t := range ts {
+		payload = append(payload, newSkeleton(t))
+	}
+
+	var res []batchResultSkeleton
+	err := c.do(http.MethodPost, "/v1/transactions:batch", nil, payload, &res, http.StatusCreated, http.StatusUnprocessableEntity)
+	if err != nil {
+		return []core.BatchResult{}, errors.Wrap(err, "Client.CreateBatch failed")
+	}
+
+	results := make([]core.BatchResult, len(ts))
+	invalid := false
+	for _, r := range res {
+		if r.Index < 0 || r.Index >= len(results) {
+			continue
+		}
+		results[r.Index].Transaction.ID = r.ID
+		if r.Error != "" {
+			results[r.Index].Err = errors.New(r.Error)
+			invalid = true
+		}
+	}
+
+	if invalid {
+		return results, errors.Wrap(core.ErrInvalidBatch, "Client.CreateBatch failed")
+	}
+
+	return results, nil
+}
+
+// List all transactions, ordered by date.
+func (c *Client) List() ([]core.Transaction, error) {
+	trsl, err := c.list("/v1/transaction")
+	if err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Client.List failed")
+	}
+
+	return trsl, nil
+}
+
+// ListMonth lists the transactions of the month of the given time, in UTC, ordered by date.
+func (c *Client) ListMonth(month time.Time) ([]core.Transaction, error) {
+	trsl, err := c.list("/v1/transaction?month=" + month.UTC().Format(monthLayout))
+	if err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Client.ListMonth failed")
+	}
+
+	return trsl, nil
+}
+
+func (c *Client) list(path string) ([]core.Transaction, error) {
+	var res []skeleton
+	if err := c.do(http.MethodGet, path, nil, nil, &res, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	trsl := make([]core.Transaction, 0, len(res))
+	for _, s := range res {
+		trsl = append(trsl, s.transaction())
+	}
+
+	return trsl, nil
+}
+
+// Update changes a transaction, given its Version is the current one.
+func (c *Client) Update(t core.Transaction) (core.Transaction, error) {
+	header := http.Header{}
+	header.Set("If-Match", fmt.Sprintf(`"%d"`, t.Version))
+
+	var res skeleton
+	path := fmt.Sprintf("/v1/transaction/%d", t.ID)
+	if err := c.do(http.MethodPut, path, header, newSkeleton(t), &res, http.StatusOK); err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Client.Update failed")
+	}
+
+	return res.transaction(), nil
+}
+
+// do sends the payload as JSON along with the header, decoding the response into res when its status is one of the expected ones.
+func (c *Client) do(method, path string, header http.Header, payload, res interface{}, expected ...int) error {
+	var body bytes.Buffer
+	if payload != nil {
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, &body)
+	if err != nil {
+		return err
+	}
+	for key := range header {
+		req.Header.Set(key, header.Get(key))
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.actor != "" {
+		req.Header.Set(actorHeader, c.actor)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			if err := json.Unmarshal(data, res); err != nil {
+				return errors.Wrap(err, "could not decode response")
+			}
+			return nil
+		}
+	}
+
+	apiErr := errorSkeleton{}
+	if err := json.Unmarshal(data, &apiErr); err != nil || apiErr.Error == "" {
+		return fmt.Errorf("unexpected response %s", resp.Status)
+	}
+
+	return fmt.Errorf("%s: %s", resp.Status, apiErr.Error)
+}
+
+func newSkeleton(t core.Transaction) skeleton {
+	return skeleton{
+		ID:       t.ID,
+		Amount:   t.Amount,
+		Type:     t.Type,
+		Category: t.Category.Name,
+		Date:     t.Date,
+		Name:     t.Name,
+		Version:  t.Version,
+	}
+}
+
+func (s skeleton) transaction() core.Transaction {
+	return core.Transaction{
+		ID:       s.ID,
+		Amount:   s.Amount,
+		Type:     s.Type,
+		Category: core.Category{Name: s.Category},
+		Date:     s.Date,
+		Name:     s.Name,
+		Version:  s.Version,
+	}
+}
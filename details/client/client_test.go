@@ -0,0 +1,313 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+)
+
+var testDate = time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+func TestClient_Create(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when created": func(t *testing.T) {
+			// arrange
+			var gotMethod, gotPath, gotActor, gotBody string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				gotMethod, gotPath, gotActor, gotBody = r.Method, r.URL.Path, r.Header.Get("X-Actor"), string(body)
+				respond(w, `{"id": 1, "amount": 2650, "type": 2, "category": "Food", "date": "2024-05-02T00:00:00Z", "name": "Family Flavor", "version": 1}`, http.StatusCreated)
+			})
+
+			// act
+			got, gotErr := c.Create(core.Transaction{
+				Amount:   2650,
+				Type:     core.Credit,
+				Category: core.Category{Name: "Food"},
+				Date:     testDate,
+				Name:     "Family Flavor",
+			})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, core.Transaction{
+				ID:       1,
+				Amount:   2650,
+				Type:     core.Credit,
+				Category: core.Category{Name: "Food"},
+				Date:     testDate,
+				Name:     "Family Flavor",
+				Version:  1,
+			}, got)
+			assert.Equal(t, http.MethodPost, gotMethod)
+			assert.Equal(t, "/v1/transaction", gotPath)
+			assert.Equal(t, "tester", gotActor)
+			assert.JSONEq(t, `{"amount": 2650, "type": 2, "category": "Food", "date": "2024-05-02T00:00:00Z", "name": "Family Flavor"}`, gotBody)
+		},
+		"when the api fails": func(t *testing.T) {
+			// arrange
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				respond(w, `{"error": "Create failed: Repository.Create failed"}`, http.StatusInternalServerError)
+			})
+
+			// act
+			_, gotErr := c.Create(core.Transaction{Amount: 1, Type: core.Debit, Category: core.Category{Name: "Food"}})
+
+			// assert
+			assert.EqualError(t, gotErr, "Client.Create failed: 500 Internal Server Error: Create failed: Repository.Create failed")
+		},
+		"when the api is unreachable": func(t *testing.T) {
+			// arrange
+			server := httptest.NewServer(http.NotFoundHandler())
+			server.Close()
+			c := NewClient(server.URL, "tester")
+
+			// act
+			_, gotErr := c.Create(core.Transaction{Amount: 1, Type: core.Debit, Category: core.Category{Name: "Food"}})
+
+			// assert
+			assert.Error(t, gotErr)
+			assert.Contains(t, gotErr.Error(), "Client.Create failed")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestClient_CreateBatch(t *testing.T) {
+	given := []core.Transaction{
+		{Amount: 2650, Type: core.Debit, Category: core.Category{Name: "Food"}, Date: testDate},
+		{Amount: 100, Type: core.Income, Category: core.Category{Name: "Refund"}, Date: testDate},
+	}
+
+	tests := map[string]func(*testing.T){
+		"when all are created": func(t *testing.T) {
+			// arrange
+			var gotPath, gotBody string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				gotPath, gotBody = r.URL.Path, string(body)
+				respond(w, `[{"index": 0, "id": 7}, {"index": 1, "id": 8}]`, http.StatusCreated)
+			})
+
+			// act
+			got, gotErr := c.CreateBatch(given)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []core.BatchResult{
+				{Transaction: core.Transaction{ID: 7}},
+				{Transaction: core.Transaction{ID: 8}},
+			}, got)
+			assert.Equal(t, "/v1/transactions:batch", gotPath)
+			assert.JSONEq(t, `[
+				{"amount": 2650, "type": 1, "category": "Food", "date": "2024-05-02T00:00:00Z", "name": ""},
+				{"amount": 100, "type": 3, "category": "Refund", "date": "2024-05-02T00:00:00Z", "name": ""}
+			]`, gotBody)
+		},
+		"when any is invalid": func(t *testing.T) {
+			// arrange
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				respond(w, `[{"index": 0}, {"index": 1, "error": "Transaction.Validate: invalid amount"}]`, http.StatusUnprocessableEntity)
+			})
+
+			// act
+			got, gotErr := c.CreateBatch(given)
+
+			// assert
+			assert.Equal(t, core.ErrInvalidBatch, errors.Cause(gotErr))
+			if assert.Len(t, got, 2) {
+				assert.NoError(t, got[0].Err)
+				assert.EqualError(t, got[1].Err, "Transaction.Validate: invalid amount")
+			}
+		},
+		"when the api fails": func(t *testing.T) {
+			// arrange
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				respond(w, `{"error": "HandleCreateTransactionBatch failed: invalid batch size"}`, http.StatusBadRequest)
+			})
+
+			// act
+			got, gotErr := c.CreateBatch(given)
+
+			// assert
+			assert.EqualError(t, gotErr, "Client.CreateBatch failed: 400 Bad Request: HandleCreateTransactionBatch failed: invalid batch size")
+			assert.Empty(t, got)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestClient_List(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when listed": func(t *testing.T) {
+			// arrange
+			var gotMethod, gotPath string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				gotMethod, gotPath = r.Method, r.URL.Path
+				respond(w, `[{"id": 1, "amount": 2650, "type": 1, "category": "Food", "date": "2024-05-02T00:00:00Z", "name": "", "version": 3}]`, http.StatusOK)
+			})
+
+			// act
+			got, gotErr := c.List()
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []core.Transaction{{
+				ID:       1,
+				Amount:   2650,
+				Type:     core.Debit,
+				Category: core.Category{Name: "Food"},
+				Date:     testDate,
+				Version:  3,
+			}}, got)
+			assert.Equal(t, http.MethodGet, gotMethod)
+			assert.Equal(t, "/v1/transaction", gotPath)
+		},
+		"when the response is not an api one": func(t *testing.T) {
+			// arrange
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "bad gateway", http.StatusBadGateway)
+			})
+
+			// act
+			_, gotErr := c.List()
+
+			// assert
+			assert.EqualError(t, gotErr, "Client.List failed: unexpected response 502 Bad Gateway")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestClient_ListMonth(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when listed": func(t *testing.T) {
+			// arrange
+			var gotPath, gotMonth string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotMonth = r.URL.Path, r.URL.Query().Get("month")
+				respond(w, `[{"id": 1, "amount": 2650, "type": 1, "category": "Food", "date": "2024-05-02T00:00:00Z", "name": "", "version": 3}]`, http.StatusOK)
+			})
+
+			// act
+			got, gotErr := c.ListMonth(testDate)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []core.Transaction{{
+				ID:       1,
+				Amount:   2650,
+				Type:     core.Debit,
+				Category: core.Category{Name: "Food"},
+				Date:     testDate,
+				Version:  3,
+			}}, got)
+			assert.Equal(t, "/v1/transaction", gotPath)
+			assert.Equal(t, "2024-05", gotMonth)
+		},
+		"when the response is not an api one": func(t *testing.T) {
+			// arrange
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "bad gateway", http.StatusBadGateway)
+			})
+
+			// act
+			_, gotErr := c.ListMonth(testDate)
+
+			// assert
+			assert.EqualError(t, gotErr, "Client.ListMonth failed: unexpected response 502 Bad Gateway")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestClient_Update(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when updated": func(t *testing.T) {
+			// arrange
+			var gotMethod, gotPath, gotIfMatch, gotBody string
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				gotMethod, gotPath, gotIfMatch, gotBody = r.Method, r.URL.Path, r.Header.Get("If-Match"), string(body)
+				respond(w, `{"id": 4, "amount": 2650, "type": 1, "category": "Food", "date": "2024-05-02T00:00:00Z", "name": "Bakery", "version": 3}`, http.StatusOK)
+			})
+
+			// act
+			got, gotErr := c.Update(core.Transaction{
+				ID:       4,
+				Amount:   2650,
+				Type:     core.Debit,
+				Category: core.Category{Name: "Food"},
+				Date:     testDate,
+				Name:     "Bakery",
+				Version:  2,
+			})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, 3, got.Version)
+			assert.Equal(t, http.MethodPut, gotMethod)
+			assert.Equal(t, "/v1/transaction/4", gotPath)
+			assert.Equal(t, `"2"`, gotIfMatch)
+			assert.JSONEq(t, `{"id": 4, "amount": 2650, "type": 1, "category": "Food", "date": "2024-05-02T00:00:00Z", "name": "Bakery", "version": 2}`, gotBody)
+		},
+		"when the version is stale": func(t *testing.T) {
+			// arrange
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				respond(w, `{"error": "Update failed: stale version"}`, http.StatusPreconditionFailed)
+			})
+
+			// act
+			_, gotErr := c.Update(core.Transaction{ID: 4, Version: 1})
+
+			// assert
+			assert.EqualError(t, gotErr, "Client.Update failed: 412 Precondition Failed: Update failed: stale version")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewClient(server.URL+"/", "tester")
+}
+
+func respond(w http.ResponseWriter, body string, status int) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(body))
+}
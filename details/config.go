@@ -2,34 +2,390 @@ package details
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
 )
 
+const (
+	// MySQL is the storage backend persisting to a MySQL server.
+	MySQL = "mysql"
+
+	// Memory is the storage backend keeping demo data in memory, which is lost on exit.
+	Memory = "memory"
+
+	// Postgres is the storage backend persisting to a PostgreSQL server.
+	Postgres = "postgres"
+
+	// SQLite is the storage backend persisting to a local SQLite file.
+	SQLite = "sqlite"
+)
+
+// redacted replaces the secrets of a printed config.
+const redacted = "REDACTED"
+
+// logLevels are the valid LOG_LEVEL values, from the most to the least verbose.
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// sslModes are the valid POSTGRES_SSLMODE values.
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
 // Config holds the app configuration (eg: ENV, Database, Network).
 type Config struct {
+	Server struct {
+		Address      string        `yaml:"address" envconfig:"SERVER_ADDRESS"`
+		ReadTimeout  time.Duration `yaml:"read_timeout" envconfig:"SERVER_READ_TIMEOUT"`
+		WriteTimeout time.Duration `yaml:"write_timeout" envconfig:"SERVER_WRITE_TIMEOUT"`
+		IdleTimeout  time.Duration `yaml:"idle_timeout" envconfig:"SERVER_IDLE_TIMEOUT"`
+		CORSOrigins  []string      `yaml:"cors_origins" envconfig:"SERVER_CORS_ORIGINS"`
+
+		// ShutdownTimeout is how long the in-flight requests are waited for on exit.
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout" envconfig:"SERVER_SHUTDOWN_TIMEOUT"`
+
+		// TLS serves HTTPS when both files are given, reloading them when they change.
+		TLS struct {
+			CertFile string `yaml:"cert_file" envconfig:"SERVER_TLS_CERT_FILE"`
+			KeyFile  string `yaml:"key_file" envconfig:"SERVER_TLS_KEY_FILE"`
+		} `yaml:"tls"`
+	} `yaml:"server"`
+
+	// GRPC serves the transactions over gRPC at Address, over TLS when the server is.
+	GRPC struct {
+		Address string `yaml:"address" envconfig:"GRPC_ADDRESS"`
+	} `yaml:"grpc"`
+	Log struct {
+		Level string `yaml:"level" envconfig:"LOG_LEVEL"`
+	} `yaml:"log"`
+
+	// Tracing exports the traces over OTLP/HTTP to the collector at Endpoint, eg: http://localhost:4318, when given.
+	Tracing struct {
+		Endpoint string `yaml:"endpoint" envconfig:"TRACING_ENDPOINT"`
+	} `yaml:"tracing"`
+	Storage struct {
+		Backend string `yaml:"backend" envconfig:"STORAGE_BACKEND"`
+
+		// EventSourced appends an event of each change of a transaction, the transactions being their projection,
+		// so they can be rebuilt as of any time.
+		EventSourced bool `yaml:"event_sourced" envconfig:"STORAGE_EVENT_SOURCED"`
+	} `yaml:"storage"`
 	Database struct {
-		Host     string `envconfig:"DATABASE_HOST" required:"true"`
-		Port     string `envconfig:"DATABASE_PORT" required:"true"`
-		Name     string `envconfig:"DATABASE_NAME" required:"true"`
-		User     string `envconfig:"DATABASE_USERNAME" required:"true"`
-		Password string `envconfig:"DATABASE_PASSWORD" required:"true"`
-	}
+		Host     string `yaml:"host" envconfig:"DATABASE_HOST"`
+		Port     string `yaml:"port" envconfig:"DATABASE_PORT"`
+		Name     string `yaml:"name" envconfig:"DATABASE_NAME"`
+		User     string `yaml:"username" envconfig:"DATABASE_USERNAME"`
+		Password string `yaml:"password" envconfig:"DATABASE_PASSWORD"`
+
+		// ConnectTimeout is how long the database is retried for on start, failing on the first attempt when 0.
+		ConnectTimeout time.Duration `yaml:"connect_timeout" envconfig:"DATABASE_CONNECT_TIMEOUT"`
+	} `yaml:"database"`
+	Postgres struct {
+		SSLMode string `yaml:"sslmode" envconfig:"POSTGRES_SSLMODE"`
+	} `yaml:"postgres"`
+	SQLite struct {
+		Path string `yaml:"path" envconfig:"SQLITE_PATH"`
+	} `yaml:"sqlite"`
+	// Idempotency keeps the keys for TTL, removing the expired ones every SweepInterval.
+	Idempotency struct {
+		TTL           time.Duration `yaml:"ttl" envconfig:"IDEMPOTENCY_TTL"`
+		SweepInterval time.Duration `yaml:"sweep_interval" envconfig:"IDEMPOTENCY_SWEEP_INTERVAL"`
+	} `yaml:"idempotency"`
+
+	// Webhook dispatches the outbox every Interval, retrying a failed delivery after Backoff, doubled each attempt,
+	// up to MaxAttempts in total.
+	Webhook struct {
+		Interval    time.Duration `yaml:"interval" envconfig:"WEBHOOK_INTERVAL"`
+		Timeout     time.Duration `yaml:"timeout" envconfig:"WEBHOOK_TIMEOUT"`
+		Backoff     time.Duration `yaml:"backoff" envconfig:"WEBHOOK_BACKOFF"`
+		MaxAttempts int           `yaml:"max_attempts" envconfig:"WEBHOOK_MAX_ATTEMPTS"`
+	} `yaml:"webhook"`
+
+	// Events publishes the domain events to the NATS server at NATSURL, eg: nats://localhost:4222, under Subject,
+	// when given. With Outbox, they are relayed from the outbox every RelayInterval, so none is lost while it is down.
+	// The events of the outbox are kept for Retention, removing the older ones every SweepInterval.
+	Events struct {
+		NATSURL       string        `yaml:"nats_url" envconfig:"EVENTS_NATS_URL"`
+		Subject       string        `yaml:"subject" envconfig:"EVENTS_SUBJECT"`
+		Outbox        bool          `yaml:"outbox" envconfig:"EVENTS_OUTBOX"`
+		RelayInterval time.Duration `yaml:"relay_interval" envconfig:"EVENTS_RELAY_INTERVAL"`
+		Retention     time.Duration `yaml:"retention" envconfig:"EVENTS_RETENTION"`
+		SweepInterval time.Duration `yaml:"sweep_interval" envconfig:"EVENTS_SWEEP_INTERVAL"`
+	} `yaml:"events"`
 }
 
-// NewConfig initialize the config.
+// NewConfig initialize the config from its defaults, overridden by the YAML file at CONFIG_FILE if given,
+// then by the environment variables, and validates it.
 func NewConfig() (*Config, error) {
-	c := Config{}
+	c := defaultConfig()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := c.readFile(path); err != nil {
+			return &c, err
+		}
+	}
+
 	if err := envconfig.Process("", &c); err != nil {
 		return &c, err
 	}
+
+	if err := c.Validate(); err != nil {
+		return &c, err
+	}
+
 	return &c, nil
 }
 
-// DatabaseDNS builds the DB data source name.
+func defaultConfig() Config {
+	c := Config{}
+	c.Server.Address = ":8888"
+	c.Server.ReadTimeout = 5 * time.Second
+	c.Server.WriteTimeout = 10 * time.Second
+	c.Server.IdleTimeout = 2 * time.Minute
+	c.Server.CORSOrigins = []string{"*"}
+	c.Server.ShutdownTimeout = 15 * time.Second
+	c.GRPC.Address = ":8889"
+	c.Log.Level = "info"
+	c.Storage.Backend = MySQL
+	c.Postgres.SSLMode = "disable"
+	c.SQLite.Path = "maskada.db"
+	c.Idempotency.TTL = 24 * time.Hour
+	c.Idempotency.SweepInterval = time.Hour
+	c.Webhook.Interval = 5 * time.Second
+	c.Webhook.Timeout = 10 * time.Second
+	c.Webhook.Backoff = 30 * time.Second
+	c.Webhook.MaxAttempts = 8
+	c.Events.Subject = "maskada"
+	c.Events.RelayInterval = time.Second
+	c.Events.Retention = 7 * 24 * time.Hour
+	c.Events.SweepInterval = time.Hour
+	return c
+}
+
+// readFile overrides the config with the keys set in a YAML file, refusing unknown keys.
+func (c *Config) readFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "invalid CONFIG_FILE")
+	}
+
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return errors.Wrapf(err, "invalid CONFIG_FILE %s", path)
+	}
+
+	return nil
+}
+
+// Validate whether the config is usable, the database variables are only required by the MySQL and Postgres backends.
+func (c *Config) Validate() error {
+	if _, _, err := net.SplitHostPort(c.Server.Address); err != nil {
+		return fmt.Errorf("invalid SERVER_ADDRESS %s", c.Server.Address)
+	}
+
+	if _, _, err := net.SplitHostPort(c.GRPC.Address); err != nil {
+		return fmt.Errorf("invalid GRPC_ADDRESS %s", c.GRPC.Address)
+	}
+
+	if c.GRPC.Address == c.Server.Address {
+		return errors.New("GRPC_ADDRESS and SERVER_ADDRESS must differ")
+	}
+
+	timeouts := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+		{"DATABASE_CONNECT_TIMEOUT", c.Database.ConnectTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value < 0 {
+			return fmt.Errorf("invalid %s %s", timeout.key, timeout.value)
+		}
+	}
+
+	if (c.Server.TLS.CertFile == "") != (c.Server.TLS.KeyFile == "") {
+		return errors.New("SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE must be given together")
+	}
+
+	if len(c.Server.CORSOrigins) == 0 {
+		return errors.New("required key SERVER_CORS_ORIGINS missing value")
+	}
+
+	if !oneOf(c.Log.Level, logLevels) {
+		return fmt.Errorf("invalid LOG_LEVEL %s", c.Log.Level)
+	}
+
+	if c.Tracing.Endpoint != "" {
+		u, err := url.Parse(c.Tracing.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid TRACING_ENDPOINT %s", c.Tracing.Endpoint)
+		}
+	}
+
+	if c.Idempotency.TTL <= 0 {
+		return fmt.Errorf("invalid IDEMPOTENCY_TTL %s", c.Idempotency.TTL)
+	}
+
+	if c.Idempotency.SweepInterval <= 0 {
+		return fmt.Errorf("invalid IDEMPOTENCY_SWEEP_INTERVAL %s", c.Idempotency.SweepInterval)
+	}
+
+	webhookDurations := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"WEBHOOK_INTERVAL", c.Webhook.Interval},
+		{"WEBHOOK_TIMEOUT", c.Webhook.Timeout},
+		{"WEBHOOK_BACKOFF", c.Webhook.Backoff},
+	}
+	for _, d := range webhookDurations {
+		if d.value <= 0 {
+			return fmt.Errorf("invalid %s %s", d.key, d.value)
+		}
+	}
+
+	if c.Webhook.MaxAttempts < 1 {
+		return fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS %d", c.Webhook.MaxAttempts)
+	}
+
+	if c.Events.NATSURL != "" {
+		u, err := url.Parse(c.Events.NATSURL)
+		if err != nil || (u.Scheme != "nats" && u.Scheme != "tls") || u.Host == "" {
+			return fmt.Errorf("invalid EVENTS_NATS_URL %s", redactURL(c.Events.NATSURL))
+		}
+	}
+
+	if c.Events.Subject == "" {
+		return errors.New("required key EVENTS_SUBJECT missing value")
+	}
+
+	if c.Events.Outbox && c.Events.NATSURL == "" {
+		return errors.New("EVENTS_OUTBOX requires EVENTS_NATS_URL")
+	}
+
+	if c.Events.RelayInterval <= 0 {
+		return fmt.Errorf("invalid EVENTS_RELAY_INTERVAL %s", c.Events.RelayInterval)
+	}
+
+	if c.Events.Retention <= 0 {
+		return fmt.Errorf("invalid EVENTS_RETENTION %s", c.Events.Retention)
+	}
+
+	if c.Events.SweepInterval <= 0 {
+		return fmt.Errorf("invalid EVENTS_SWEEP_INTERVAL %s", c.Events.SweepInterval)
+	}
+
+	switch c.Storage.Backend {
+	case MySQL, Postgres:
+		required := []struct {
+			key   string
+			value string
+		}{
+			{"DATABASE_HOST", c.Database.Host},
+			{"DATABASE_PORT", c.Database.Port},
+			{"DATABASE_NAME", c.Database.Name},
+			{"DATABASE_USERNAME", c.Database.User},
+			{"DATABASE_PASSWORD", c.Database.Password},
+		}
+		for _, r := range required {
+			if r.value == "" {
+				return fmt.Errorf("required key %s missing value", r.key)
+			}
+		}
+		if c.Storage.Backend == Postgres && !oneOf(c.Postgres.SSLMode, sslModes) {
+			return fmt.Errorf("invalid POSTGRES_SSLMODE %s", c.Postgres.SSLMode)
+		}
+	case SQLite:
+		if c.SQLite.Path == "" {
+			return errors.New("required key SQLITE_PATH missing value")
+		}
+	case Memory:
+	default:
+		return fmt.Errorf("invalid STORAGE_BACKEND %s", c.Storage.Backend)
+	}
+
+	return nil
+}
+
+// Relay tells whether the events of the outbox are relayed to NATS.
+func (c *Config) Relay() bool {
+	return c.Events.NATSURL != "" && c.Events.Outbox
+}
+
+// TLS tells whether the API is served over HTTPS.
+func (c *Config) TLS() bool {
+	return c.Server.TLS.CertFile != ""
+}
+
+// Print writes the config as YAML, as it would be read from a file, with its secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	printed := *c
+	if printed.Database.Password != "" {
+		printed.Database.Password = redacted
+	}
+	if printed.Events.NATSURL != "" {
+		printed.Events.NATSURL = redactURL(printed.Events.NATSURL)
+	}
+
+	data, err := yaml.Marshal(printable(reflect.ValueOf(printed)))
+	if err != nil {
+		return errors.Wrap(err, "Config.Print failed")
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// redactURL redacts the credentials of a URL, eg: nats://REDACTED@localhost:4222, or all of it when it is not one.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+	return u.String()
+}
+
+// printable maps the fields of a struct by their yaml keys, in order, formatting durations as they are typed, eg: 24h0m0s.
+func printable(v reflect.Value) interface{} {
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		return time.Duration(v.Int()).String()
+	case v.Kind() != reflect.Struct:
+		return v.Interface()
+	}
+
+	fields := yaml.MapSlice{}
+	for i := 0; i < v.NumField(); i++ {
+		fields = append(fields, yaml.MapItem{Key: v.Type().Field(i).Tag.Get("yaml"), Value: printable(v.Field(i))})
+	}
+	return fields
+}
+
+func oneOf(value string, values []string) bool {
+	for _, v := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// DatabaseDNS builds the DB data source name, running a single statement per query.
 func (c *Config) DatabaseDNS() string {
 	return fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?parseTime=true&multiStatements=true",
+		"%s:%s@tcp(%s:%s)/%s?parseTime=true",
 		c.Database.User,
 		c.Database.Password,
 		c.Database.Host,
@@ -37,3 +393,28 @@ func (c *Config) DatabaseDNS() string {
 		c.Database.Name,
 	)
 }
+
+// MigrationDNS builds the DB data source name of the migrations, whose scripts run several statements per query.
+func (c *Config) MigrationDNS() string {
+	return c.DatabaseDNS() + "&multiStatements=true"
+}
+
+// PostgresDNS builds the Postgres data source name.
+func (c *Config) PostgresDNS() string {
+	dns := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.Database.User, c.Database.Password),
+		Host:     net.JoinHostPort(c.Database.Host, c.Database.Port),
+		Path:     c.Database.Name,
+		RawQuery: url.Values{"sslmode": {c.Postgres.SSLMode}}.Encode(),
+	}
+	return dns.String()
+}
+
+// SQLiteDNS builds the SQLite data source name, write transactions lock the file as they begin.
+func (c *Config) SQLiteDNS() string {
+	return fmt.Sprintf(
+		"file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate",
+		c.SQLite.Path,
+	)
+}
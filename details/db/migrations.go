@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/details/migrate"
+	"github.com/gritt/maskada/details/sqlstore"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// lockName is the MySQL named lock held while migrating.
+const lockName = "maskada.migrate"
+
+// lockTimeout is how long an instance waits for another to finish migrating, in seconds.
+const lockTimeout = 600
+
+// NewMigrator initialize the migrator of the db schema, sharing the repository connection.
+func NewMigrator(r *sqlstore.Repository) (*migrate.Migrator, error) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, errors.Wrap(err, "NewMigrator failed")
+	}
+
+	return migrate.NewMigrator(r.DB(), fsys, locker{})
+}
+
+// locker holds a named lock, which MySQL releases as well when the connection closes.
+type locker struct{}
+
+func (locker) Lock(ctx context.Context, conn *sql.Conn) error {
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, lockName, lockTimeout).Scan(&acquired); err != nil {
+		return err
+	}
+	if acquired.Int64 != 1 {
+		return errors.New("timed out waiting for another instance to migrate")
+	}
+	return nil
+}
+
+func (locker) Unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `SELECT RELEASE_LOCK(?)`, lockName)
+	return err
+}
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS `audit_append_only_delete`;
+DROP TRIGGER IF EXISTS `audit_append_only_update`;
+DROP TABLE IF EXISTS `audit`;
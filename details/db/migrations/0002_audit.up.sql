@@ -0,0 +1,25 @@
+CREATE TABLE `audit`
+(
+    `id`        INTEGER(11) NOT NULL AUTO_INCREMENT,
+    `entity`    VARCHAR(20) NOT NULL,
+    `entity_id` VARCHAR(80) NOT NULL,
+    `action`    VARCHAR(20) NOT NULL,
+    `actor`     VARCHAR(80) NOT NULL,
+    `before`    JSON        NULL,
+    `after`     JSON        NULL,
+    `date`      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    INDEX `idx_audit_entity` (`entity`, `entity_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_unicode_ci;
+
+CREATE TRIGGER `audit_append_only_update`
+    BEFORE UPDATE
+    ON `audit`
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit is append-only';
+
+CREATE TRIGGER `audit_append_only_delete`
+    BEFORE DELETE
+    ON `audit`
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit is append-only';
@@ -0,0 +1,12 @@
+CREATE TABLE `idempotency_key`
+(
+    `key`          VARCHAR(255) NOT NULL,
+    `request_hash` CHAR(64)     NOT NULL,
+    `status`       INTEGER(11)  NOT NULL DEFAULT 0,
+    `body`         MEDIUMBLOB   NULL,
+    `expires_at`   TIMESTAMP    NOT NULL,
+    PRIMARY KEY (`key`),
+    INDEX `idx_idempotency_key_expires_at` (`expires_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_unicode_ci;
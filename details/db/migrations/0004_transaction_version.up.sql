@@ -0,0 +1,2 @@
+ALTER TABLE `transaction`
+    ADD COLUMN `version` INTEGER(11) NOT NULL DEFAULT 1 AFTER `date`;
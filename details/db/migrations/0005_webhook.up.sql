@@ -0,0 +1,52 @@
+CREATE TABLE IF NOT EXISTS `outbox`
+(
+    `id`            INTEGER(11) NOT NULL AUTO_INCREMENT,
+    `event`         VARCHAR(40) NOT NULL,
+    `payload`       JSON        NOT NULL,
+    `date`          TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `dispatched_at` TIMESTAMP   NULL,
+    `relayed_at`    TIMESTAMP   NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_outbox_dispatched_at` (`dispatched_at`, `id`),
+    INDEX `idx_outbox_relayed_at` (`relayed_at`, `id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS `webhook_subscription`
+(
+    `id`     INTEGER(11)   NOT NULL AUTO_INCREMENT,
+    `url`    VARCHAR(2048) NOT NULL,
+    `secret` VARCHAR(255)  NOT NULL,
+    `events` VARCHAR(255)  NOT NULL,
+    `date`   TIMESTAMP     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS `webhook_delivery`
+(
+    `id`              INTEGER(11)  NOT NULL AUTO_INCREMENT,
+    `subscription_id` INTEGER(11)  NOT NULL,
+    CONSTRAINT `fk_webhook_delivery_subscription`
+        FOREIGN KEY (`subscription_id`) REFERENCES `webhook_subscription` (`id`)
+            ON DELETE CASCADE,
+    `event_id`        INTEGER(11)  NOT NULL,
+    CONSTRAINT `fk_webhook_delivery_event`
+        FOREIGN KEY (`event_id`) REFERENCES `outbox` (`id`)
+            ON DELETE CASCADE,
+    `event`           VARCHAR(40)  NOT NULL,
+    `attempt`         INTEGER(11)  NOT NULL DEFAULT 1,
+    `status`          VARCHAR(20)  NOT NULL,
+    `response_code`   INTEGER(11)  NOT NULL DEFAULT 0,
+    `error`           VARCHAR(255) NOT NULL DEFAULT '',
+    `scheduled_at`    TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `attempted_at`    TIMESTAMP    NULL,
+    `lease_until`     TIMESTAMP    NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_webhook_delivery_due` (`status`, `scheduled_at`),
+    INDEX `idx_webhook_delivery_lease` (`status`, `lease_until`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_unicode_ci;
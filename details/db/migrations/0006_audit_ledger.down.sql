@@ -0,0 +1,2 @@
+-- the audit is append-only, so the entries recording the transactions created before it are kept
+DROP INDEX `idx_audit_entity_date` ON `audit`;
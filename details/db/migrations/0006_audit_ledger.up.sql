@@ -0,0 +1,23 @@
+CREATE INDEX `idx_audit_entity_date` ON `audit` (`entity`, `date`);
+
+INSERT INTO `audit` (`entity`, `entity_id`, `action`, `actor`, `before`, `after`)
+SELECT 'transaction',
+       CAST(t.`id` AS CHAR),
+       'create',
+       'migration',
+       NULL,
+       JSON_OBJECT(
+               'id', t.`id`,
+               'amount', t.`amount`,
+               'type', t.`type`,
+               'category', t.`category`,
+               'date', DATE_FORMAT(CONVERT_TZ(t.`date`, @@session.time_zone, '+00:00'), '%Y-%m-%dT%H:%i:%sZ'),
+               'name', COALESCE(t.`description`, ''),
+               'version', t.`version`
+       )
+FROM `transaction` t
+WHERE NOT EXISTS(SELECT 1
+                 FROM `audit` a
+                 WHERE a.`entity` = 'transaction'
+                   AND a.`entity_id` = CAST(t.`id` AS CHAR))
+ORDER BY t.`id`;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `journal`
+(
+    `id`        INTEGER(11) NOT NULL AUTO_INCREMENT,
+    `actor`     VARCHAR(80) NOT NULL,
+    `operation` VARCHAR(20) NOT NULL,
+    `changes`   JSON        NOT NULL,
+    `undone`    BOOLEAN     NOT NULL DEFAULT FALSE,
+    `date`      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    INDEX `idx_journal_actor` (`actor`, `id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_unicode_ci;
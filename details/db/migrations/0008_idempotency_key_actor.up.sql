@@ -0,0 +1,4 @@
+ALTER TABLE `idempotency_key`
+    ADD COLUMN `actor` VARCHAR(80) NOT NULL DEFAULT '' FIRST,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (`actor`, `key`);
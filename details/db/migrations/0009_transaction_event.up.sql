@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS `transaction_event`
+(
+    `id`             INTEGER(11)  NOT NULL AUTO_INCREMENT,
+    `transaction_id` INTEGER(11)  NOT NULL,
+    `action`         VARCHAR(20)  NOT NULL,
+    `amount`         INTEGER(11)  NOT NULL,
+    `type`           INTEGER(11)  NOT NULL,
+    `category`       VARCHAR(80)  NOT NULL,
+    `description`    VARCHAR(80)  NULL,
+    `date`           TIMESTAMP    NOT NULL,
+    `version`        INTEGER(11)  NOT NULL,
+    `actor`          VARCHAR(80)  NOT NULL,
+    `recorded_at`    TIMESTAMP(6) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_transaction_event_transaction_id` (`transaction_id`, `id`),
+    INDEX `idx_transaction_event_recorded_at` (`recorded_at`, `transaction_id`, `id`),
+    INDEX `idx_transaction_event_date` (`date`, `recorded_at`, `transaction_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_unicode_ci;
+
+CREATE TRIGGER `transaction_event_append_only_update`
+    BEFORE UPDATE
+    ON `transaction_event`
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'transaction_event is append-only';
+
+CREATE TRIGGER `transaction_event_append_only_delete`
+    BEFORE DELETE
+    ON `transaction_event`
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'transaction_event is append-only';
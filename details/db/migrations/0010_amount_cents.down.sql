@@ -0,0 +1,30 @@
+-- the amounts are back in whole units, eg: 2650 is 26, losing the cents
+INSERT INTO `audit` (`entity`, `entity_id`, `action`, `actor`, `before`, `after`)
+SELECT 'transaction',
+       CAST(t.`id` AS CHAR),
+       'update',
+       'migration',
+       JSON_OBJECT(
+               'id', t.`id`,
+               'amount', t.`amount`,
+               'type', t.`type`,
+               'category', t.`category`,
+               'date', DATE_FORMAT(CONVERT_TZ(t.`date`, @@session.time_zone, '+00:00'), '%Y-%m-%dT%H:%i:%sZ'),
+               'name', COALESCE(t.`description`, ''),
+               'version', t.`version`
+       ),
+       JSON_OBJECT(
+               'id', t.`id`,
+               'amount', t.`amount` DIV 100,
+               'type', t.`type`,
+               'category', t.`category`,
+               'date', DATE_FORMAT(CONVERT_TZ(t.`date`, @@session.time_zone, '+00:00'), '%Y-%m-%dT%H:%i:%sZ'),
+               'name', COALESCE(t.`description`, ''),
+               'version', t.`version` + 1
+       )
+FROM `transaction` t
+ORDER BY t.`id`;
+
+UPDATE `transaction`
+SET `amount`  = `amount` DIV 100,
+    `version` = `version` + 1;
@@ -0,0 +1,31 @@
+-- the amounts are in cents from now on, eg: 26 is 2600, each transaction being updated by the actor migration,
+-- so the older versions are not undone, and the event store catches up on start
+INSERT INTO `audit` (`entity`, `entity_id`, `action`, `actor`, `before`, `after`)
+SELECT 'transaction',
+       CAST(t.`id` AS CHAR),
+       'update',
+       'migration',
+       JSON_OBJECT(
+               'id', t.`id`,
+               'amount', t.`amount`,
+               'type', t.`type`,
+               'category', t.`category`,
+               'date', DATE_FORMAT(CONVERT_TZ(t.`date`, @@session.time_zone, '+00:00'), '%Y-%m-%dT%H:%i:%sZ'),
+               'name', COALESCE(t.`description`, ''),
+               'version', t.`version`
+       ),
+       JSON_OBJECT(
+               'id', t.`id`,
+               'amount', t.`amount` * 100,
+               'type', t.`type`,
+               'category', t.`category`,
+               'date', DATE_FORMAT(CONVERT_TZ(t.`date`, @@session.time_zone, '+00:00'), '%Y-%m-%dT%H:%i:%sZ'),
+               'name', COALESCE(t.`description`, ''),
+               'version', t.`version` + 1
+       )
+FROM `transaction` t
+ORDER BY t.`id`;
+
+UPDATE `transaction`
+SET `amount`  = `amount` * 100,
+    `version` = `version` + 1;
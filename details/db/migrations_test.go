@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/sqlstore"
+)
+
+func TestMigrator_Up(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	cfg, err := mockDBConfig()
+	if err != nil {
+		t.Fatalf("mockDBConfig failed: %s", err)
+	}
+
+	tests := map[string]func(*testing.T, *sqlstore.Repository){
+		"when db was created from the baseline schema, migrate its data": func(t *testing.T, r *sqlstore.Repository) {
+			// arrange
+			m, err := NewMigrator(r)
+			if err != nil {
+				t.Fatalf("NewMigrator failed: %s", err)
+			}
+			if _, err := m.Down(math.MaxInt32); err != nil {
+				t.Fatalf("Down failed: %s", err)
+			}
+
+			script, err := ioutil.ReadFile("test/baseline.sql")
+			if err != nil {
+				t.Fatalf("ReadFile failed: %s", err)
+			}
+			r.DB().MustExec(string(script))
+			r.DB().MustExec("INSERT INTO `category` (`name`) VALUES ('Food')")
+			r.DB().MustExec("INSERT INTO `transaction` (`amount`, `type`, `category`, `description`) VALUES (26, 1, 'Food', 'lunch')")
+
+			// act
+			applied, gotErr := m.Up()
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, 1, applied[0].Version)
+
+			pending, err := m.Pending(context.Background())
+			assert.NoError(t, err)
+			assert.Empty(t, pending)
+
+			got, err := r.Find(context.Background())
+			assert.NoError(t, err)
+			assert.Len(t, got, 1)
+			assert.Equal(t, 2600, got[0].Amount)
+			assert.Equal(t, 2, got[0].Version)
+
+			got[0].Amount = 3000
+			updated, err := r.Update(context.Background(), got[0], "alice")
+			assert.NoError(t, err)
+			assert.Equal(t, 3, updated.Version)
+
+			history, err := r.FindHistory(context.Background(), got[0].ID)
+			assert.NoError(t, err)
+			if assert.Len(t, history, 3) {
+				assert.Equal(t, core.AuditCreate, history[0].Action)
+				assert.Equal(t, "migration", history[0].Actor)
+				assert.Equal(t, core.AuditUpdate, history[1].Action)
+				assert.Equal(t, "migration", history[1].Actor)
+
+				var before, after struct{ Amount int }
+				assert.NoError(t, json.Unmarshal(history[1].Before, &before))
+				assert.NoError(t, json.Unmarshal(history[1].After, &after))
+				assert.Equal(t, 26, before.Amount)
+				assert.Equal(t, 2600, after.Amount)
+				assert.Equal(t, "alice", history[2].Actor)
+			}
+
+			es := sqlstore.NewEventSourcedRepository(r.DB(), dialect)
+			assert.NoError(t, es.CatchUp(context.Background()))
+
+			ledger, err := es.FindAsOf(context.Background(), time.Now().Add(time.Hour))
+			assert.NoError(t, err)
+			if assert.Len(t, ledger, 1) {
+				assert.Equal(t, 3000, ledger[0].Amount)
+				assert.Equal(t, "lunch", ledger[0].Name)
+				assert.Equal(t, 3, ledger[0].Version)
+				assert.Equal(t, got[0].Date.UTC(), ledger[0].Date)
+			}
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewMigrationRepository(&cfg)
+			if err != nil {
+				t.Fatalf("NewMigrationRepository failed: %s", err)
+			}
+			t.Cleanup(func() { _ = r.Close() })
+
+			run(t, r)
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package db
 
 import (
-	"database/sql"
 	"time"
 
 	// imports mysql db driver
@@ -10,102 +9,42 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 
-	"github.com/gritt/maskada/core"
 	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/sqlstore"
 )
 
-// Repository is able to save and find a transaction(s).
-type Repository struct {
-	db *sqlx.DB
+// dialect writes the statements as MySQL does, which returns the id of a single inserted row, the ids of a multi-row
+// insert depending on its auto_increment settings, and stores the dates to whole seconds.
+var dialect = sqlstore.Dialect{
+	Driver:       "mysql",
+	System:       "mysql",
+	Quote:        "`",
+	IDs:          sqlstore.RowInsertID,
+	InsertIgnore: true,
+	RowLocks:     true,
+	Precision:    time.Second,
 }
 
-// NewRepository initialize the repository.
-func NewRepository(cfg *details.Config) (repo *Repository, err error) {
-	dns := cfg.DatabaseDNS()
-
-	db, err := sqlx.Open("mysql", dns)
-	if err != nil {
-		return repo, errors.Wrap(err, "NewRepository failed")
-	}
-
-	return &Repository{db: db}, err
+// NewRepository initialize the repository of a MySQL server.
+func NewRepository(cfg *details.Config) (*sqlstore.Repository, error) {
+	return open(cfg, cfg.DatabaseDNS())
 }
 
-// Create persists a transaction in db.
-func (r *Repository) Create(t core.Transaction) (core.Transaction, error) {
-	if err := r.CreateCategory(t.Category); err != nil {
-		return core.Transaction{}, err
-	}
-
-	if t.Date.String() == "0001-01-01 00:00:00 +0000 UTC" {
-		t.Date = time.Now().UTC()
-	}
-
-	query := "INSERT INTO `transaction` (`amount`, `type`, `category`, `description`, `date`) VALUES (?, ?, ?, ?, ?)"
-
-	result, err := r.db.Exec(query, t.Amount, t.Type, t.Category.Name, t.Name, t.Date.UTC())
-	if err != nil {
-		return core.Transaction{}, errors.Wrap(err, "Repository.Create failed")
-	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return core.Transaction{}, errors.Wrap(err, "Repository.Create failed")
-	}
-	t.ID = int(id)
-
-	return t, nil
+// NewMigrationRepository initialize the repository of a MySQL server the migrations run on, on connections apart
+// from the ones of NewRepository, as they run several statements per query.
+func NewMigrationRepository(cfg *details.Config) (*sqlstore.Repository, error) {
+	return open(cfg, cfg.MigrationDNS())
 }
 
-// CreateCategory persists a category in db.
-func (r *Repository) CreateCategory(category core.Category) error {
-	query := "INSERT IGNORE INTO `category` (`name`) VALUES (?)"
-
-	_, err := r.db.Exec(query, category.Name)
+func open(cfg *details.Config, dns string) (*sqlstore.Repository, error) {
+	db, err := sqlx.Open("mysql", dns)
 	if err != nil {
-		return errors.Wrap(err, "Repository.CreateCategory failed")
-	}
-
-	return nil
-}
-
-// Find transactions in db.
-func (r *Repository) Find() ([]core.Transaction, error) {
-	type row struct {
-		ID       int            `db:"id"`
-		Amount   int            `db:"amount"`
-		Type     int            `db:"type"`
-		Category string         `db:"category"`
-		Date     time.Time      `db:"date"`
-		Name     sql.NullString `db:"name"`
-	}
-
-	query := `SELECT 
-				t.id "id", 
-				t.amount "amount", 
-				t.type "type", 
-				t.category "category",
-				t.date "date", 
-				t.description "name"
-				FROM transaction t
-				ORDER by t.date`
-
-	var rows []row
-	if err := r.db.Select(&rows, query); err != nil {
-		return []core.Transaction{}, errors.Wrap(err, "Repository.Find failed")
+		return nil, errors.Wrap(err, "NewRepository failed")
 	}
 
-	var trs []core.Transaction
-	for _, row := range rows {
-		trs = append(trs, core.Transaction{
-			ID:       row.ID,
-			Amount:   row.Amount,
-			Type:     row.Type,
-			Category: core.Category{Name: row.Category},
-			Date:     row.Date,
-			Name:     row.Name.String,
-		})
+	if cfg.Storage.EventSourced {
+		return sqlstore.NewEventSourcedRepository(db, dialect), nil
 	}
 
-	return trs, nil
+	return sqlstore.NewRepository(db, dialect), nil
 }
@@ -1,7 +1,9 @@
 package db
 
 import (
+	"context"
 	"io/ioutil"
+	"math"
 	"testing"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 
 	"github.com/gritt/maskada/core"
 	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/sqlstore"
 	"github.com/gritt/maskada/test"
 )
 
@@ -30,278 +33,49 @@ func TestNewRepository(t *testing.T) {
 
 	// assert
 	assert.NoError(t, gotErr)
-	assert.IsType(t, &sqlx.DB{}, gotRepo.db)
+	assert.IsType(t, &sqlx.DB{}, gotRepo.DB())
 }
 
-func TestRepository_Create(t *testing.T) {
+func TestRepository_Update(t *testing.T) {
 	if testing.Short() {
 		t.SkipNow()
 	}
 
-	amount := test.RandomNumber()
-	name := test.RandomName()
-	date := time.Now().UTC().Truncate(time.Hour * 24).Add(-time.Hour * 24)
+	actor := test.RandomUsername()
 
 	cfg, err := mockDBConfig()
 	if err != nil {
 		t.Fatalf("mockDBConfig failed: %s", err)
 	}
 
-	tests := map[string]func(*testing.T, *Repository){
-		"when connection is down": func(t *testing.T, r *Repository) {
+	tests := map[string]func(t *testing.T, r *sqlstore.Repository){
+		"when the row stays locked past the deadline": func(t *testing.T, r *sqlstore.Repository) {
 			// arrange
-			teardown := setupDBData(t, r.db)
-			teardown()
-
-			// act
-			_, gotErr := r.Create(core.Transaction{})
-
-			// assert
-			assert.EqualError(t, gotErr, "Repository.CreateCategory failed: sql: database is closed")
-		},
-		"when a date is given": func(t *testing.T, r *Repository) {
-			// arrange
-			teardown := setupDBData(t, r.db)
-			defer teardown()
-
-			given := core.Transaction{
-				Amount:   amount,
-				Type:     core.Credit,
-				Category: core.Category{Name: "Food"},
-				Date:     date,
-			}
-
-			// act
-			got, gotErr := r.Create(given)
-
-			want := core.Transaction{
-				ID:       7,
-				Amount:   amount,
-				Type:     core.Credit,
-				Category: core.Category{Name: "Food"},
-				Date:     date,
-			}
-
-			// assert
-			assert.NoError(t, gotErr)
-			assert.Equal(t, want, got)
-		},
-		"when no date is given, use current time": func(t *testing.T, r *Repository) {
-			// arrange
-			teardown := setupDBData(t, r.db)
-			defer teardown()
-
-			given := core.Transaction{
-				Amount:   amount,
-				Type:     core.Credit,
-				Category: core.Category{Name: "Food"},
-			}
-
-			// act
-			got, gotErr := r.Create(given)
-
-			want := core.Transaction{
-				ID:       7,
-				Amount:   amount,
-				Type:     core.Credit,
-				Category: core.Category{Name: "Food"},
-				Date:     time.Now().UTC(),
-			}
-
-			// assert
-			assert.NoError(t, gotErr)
-			assert.Equal(t, want.ID, got.ID)
-			assert.Equal(t, want.Amount, got.Amount)
-			assert.Equal(t, want.Type, got.Type)
-			assert.Equal(t, want.Category, got.Category)
-			assert.Equal(t, want.Date.Format(time.RFC3339), got.Date.Format(time.RFC3339))
-		},
-		"when a name is given": func(t *testing.T, r *Repository) {
-			// arrange
-			teardown := setupDBData(t, r.db)
-			defer teardown()
-
-			given := core.Transaction{
-				Amount:   amount,
-				Type:     core.Credit,
-				Category: core.Category{Name: "Food"},
-				Date:     date,
-				Name:     name,
-			}
-
-			// act
-			got, gotErr := r.Create(given)
-
-			want := core.Transaction{
-				ID:       7,
-				Amount:   amount,
-				Type:     core.Credit,
-				Category: core.Category{Name: "Food"},
-				Date:     date,
-				Name:     name,
-			}
-
-			// assert
-			assert.NoError(t, gotErr)
-			assert.Equal(t, want, got)
-		},
-	}
-
-	for name, run := range tests {
-		t.Run(name, func(t *testing.T) {
-			r, err := NewRepository(&cfg)
-			assert.NoError(t, err)
-
-			run(t, r)
-		})
-	}
-}
-
-func TestRepository_CreateCategory(t *testing.T) {
-	if testing.Short() {
-		t.SkipNow()
-	}
-
-	type row struct {
-		Name string `db:"name"`
-	}
-
-	cfg, err := mockDBConfig()
-	if err != nil {
-		t.Fatalf("mockDBConfig failed: %s", err)
-	}
-
-	tests := map[string]func(*testing.T, *Repository){
-		"when connection is down": func(t *testing.T, r *Repository) {
-			// arrange
-			teardown := setupDBData(t, r.db)
-			teardown()
-
-			// act
-			_, gotErr := r.Create(core.Transaction{})
-
-			// assert
-			assert.EqualError(t, gotErr, "Repository.CreateCategory failed: sql: database is closed")
-		},
-		"when category does not exists": func(t *testing.T, r *Repository) {
-			// arrange
-			teardown := setupDBData(t, r.db)
+			teardown := setupDBData(t, &cfg)
 			defer teardown()
 
-			testCategory := test.RandomName()
-			given := core.Category{Name: testCategory}
-
-			// act
-			gotErr := r.CreateCategory(given)
-
-			// assert
-			assert.NoError(t, gotErr)
-
-			var rows []row
-			if err := r.db.Select(&rows, `SELECT * FROM category WHERE name = (?)`, testCategory); err != nil {
-				t.Fail()
+			lock, err := r.DB().Beginx()
+			if err != nil {
+				t.Fatalf("when the row stays locked past the deadline failed: %s", err)
 			}
-			assert.Equal(t, testCategory, rows[0].Name)
-		},
-		"when category exists": func(t *testing.T, r *Repository) {
-			// arrange
-			teardown := setupDBData(t, r.db)
-			defer teardown()
-
-			testCategory := "Entertainment"
-			given := core.Category{Name: testCategory}
-
-			// act
-			gotErr := r.CreateCategory(given)
-
-			// assert
-			assert.NoError(t, gotErr)
+			defer func() { _ = lock.Rollback() }()
 
-			var rows []row
-			if err := r.db.Select(&rows, `SELECT * FROM category WHERE name = (?)`, testCategory); err != nil {
-				t.Fail()
+			if _, err := lock.Exec("SELECT `id` FROM `transaction` WHERE `id` = 1 FOR UPDATE"); err != nil {
+				t.Fatalf("when the row stays locked past the deadline failed: %s", err)
 			}
-			assert.Len(t, rows, 1)
-			assert.Equal(t, testCategory, rows[0].Name)
-		},
-	}
 
-	for name, run := range tests {
-		t.Run(name, func(t *testing.T) {
-			r, err := NewRepository(&cfg)
-			assert.NoError(t, err)
+			given := core.Transaction{ID: 1, Amount: 100, Type: core.Debit, Category: core.Category{Name: "Food"}, Version: 1}
 
-			run(t, r)
-		})
-	}
-}
-
-func TestRepository_Find(t *testing.T) {
-	if testing.Short() {
-		t.SkipNow()
-	}
-
-	transactions := []core.Transaction{
-		{ID: 1, Amount: 99, Type: core.Credit, Category: core.Category{Name: "Entertainment"}, Date: time.Now().UTC()},
-		{ID: 2, Amount: 11, Type: core.Credit, Category: core.Category{Name: "Food"}, Date: time.Now().UTC()},
-		{ID: 3, Amount: 32, Type: core.Credit, Category: core.Category{Name: "Food"}, Date: time.Now().UTC()},
-		{ID: 4, Amount: 5300, Type: core.Income, Category: core.Category{Name: "Work"}, Date: time.Now().UTC()},
-		{ID: 5, Amount: 129, Type: core.Debit, Category: core.Category{Name: "Home"}, Date: time.Now().UTC(), Name: "Internet"},
-		{ID: 6, Amount: 129, Type: core.Debit, Category: core.Category{Name: "Home"}, Date: time.Now().UTC(), Name: "Electricity"},
-	}
-
-	cfg, err := mockDBConfig()
-	if err != nil {
-		t.Fatalf("mockDBConfig failed: %s", err)
-	}
-
-	tests := map[string]func(t *testing.T, r *Repository){
-		"when connection is down": func(t *testing.T, r *Repository) {
-			// arrange
-			teardown := setupDBData(t, r.db)
-			teardown()
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			start := time.Now()
 
 			// act
-			_, gotErr := r.Find()
+			_, gotErr := r.Update(ctx, given, actor)
 
 			// assert
-			assert.EqualError(t, gotErr, "Repository.Find failed: sql: database is closed")
-		},
-		"when transactions are found": func(t *testing.T, r *Repository) {
-			// arrange
-			teardown := setupDBData(t, r.db)
-			defer teardown()
-
-			// act
-			got, gotErr := r.Find()
-
-			// assert
-			assert.NoError(t, gotErr)
-			for want, gotTrs := range got {
-				assert.Equal(t, transactions[want].ID, gotTrs.ID)
-				assert.Equal(t, transactions[want].Amount, gotTrs.Amount)
-				assert.Equal(t, transactions[want].Type, gotTrs.Type)
-				assert.Equal(t, transactions[want].Category, gotTrs.Category)
-				assert.Equal(t, transactions[want].Date.Format(time.RFC822), gotTrs.Date.Format(time.RFC822))
-				assert.Equal(t, transactions[want].Name, gotTrs.Name)
-			}
-		},
-		"when no transactions are found": func(t *testing.T, r *Repository) {
-			// arrange
-			teardown := setupDBData(t, r.db)
-			defer teardown()
-
-			_, err := r.db.Exec(`DELETE from transaction`)
-			if err != nil {
-				t.Fatalf("when no transactions are found failed: %s", err)
-			}
-
-			// act
-			got, gotErr := r.Find()
-
-			// assert
-			assert.Empty(t, got)
-			assert.NoError(t, gotErr)
+			assert.Error(t, gotErr)
+			assert.True(t, time.Since(start) < 5*time.Second, "the query was not aborted")
 		},
 	}
 
@@ -309,6 +83,7 @@ func TestRepository_Find(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			r, err := NewRepository(&cfg)
 			assert.NoError(t, err)
+			t.Cleanup(func() { _ = r.Close() })
 
 			run(t, r)
 		})
@@ -340,19 +115,13 @@ func mockDBConfig() (details.Config, error) {
 	return cfg, nil
 }
 
-func setupDBData(t *testing.T, db *sqlx.DB) func() {
-	// create db schema
-	script, err := ioutil.ReadFile("../../details/db/migrations/schema.sql")
-	if err != nil {
-		t.Fatalf("setupDBData failed: %s", err)
-	}
-	_, err = db.Exec(string(script))
-	if err != nil {
-		t.Fatal(err)
-	}
+func setupDBData(t *testing.T, cfg *details.Config) func() {
+	// create an empty db schema
+	migrateDB(t, cfg)
+	db := migrationDB(t, cfg)
 
 	// setup data
-	script, err = ioutil.ReadFile("../../details/db/test/setup.sql")
+	script, err := ioutil.ReadFile("../../details/db/test/setup.sql")
 	if err != nil {
 		t.Fatalf("setupDBData failed: %s", err)
 	}
@@ -371,7 +140,32 @@ func setupDBData(t *testing.T, db *sqlx.DB) func() {
 		if err != nil {
 			t.Fatalf("setupDBData failed: %s", err)
 		}
+	}
+}
 
-		db.Close()
+// migrateDB reverts all migrations, then applies them, so the db schema is empty.
+func migrateDB(t *testing.T, cfg *details.Config) {
+	m, err := NewMigrator(sqlstore.NewRepository(migrationDB(t, cfg), dialect))
+	if err != nil {
+		t.Fatalf("migrateDB failed: %s", err)
 	}
+
+	if _, err := m.Down(math.MaxInt32); err != nil {
+		t.Fatalf("migrateDB failed: %s", err)
+	}
+
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("migrateDB failed: %s", err)
+	}
+}
+
+// migrationDB opens the connections the migrations run on, closing them with t.Cleanup.
+func migrationDB(t *testing.T, cfg *details.Config) *sqlx.DB {
+	r, err := NewMigrationRepository(cfg)
+	if err != nil {
+		t.Fatalf("migrationDB failed: %s", err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+
+	return r.DB()
 }
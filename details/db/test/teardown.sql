@@ -1,2 +1,9 @@
+TRUNCATE TABLE `transaction_event`;
+DELETE FROM `journal`;
+DELETE FROM `webhook_delivery`;
+DELETE FROM `webhook_subscription`;
+DELETE FROM `outbox`;
+DELETE FROM `idempotency_key`;
+TRUNCATE TABLE `audit`;
 DELETE FROM `transaction`;
 DELETE FROM `category`;
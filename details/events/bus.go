@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gritt/maskada/core"
+)
+
+// Handler handles a published event, within the call publishing it.
+type Handler func(ctx context.Context, event core.Event)
+
+type subscription struct {
+	handler Handler
+	names   []string
+}
+
+// Bus publishes the events in process, calling the handlers subscribed to each, in the order they subscribed.
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+// NewBus initialize a bus with no subscriber.
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe a handler to the events of the given names, eg: core.EventTransactionCreated, or to all of them when
+// none is given.
+func (b *Bus) Subscribe(handler Handler, names ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscriptions = append(b.subscriptions, subscription{handler: handler, names: names})
+}
+
+// Publish the events to their handlers, one after the other.
+func (b *Bus) Publish(ctx context.Context, events ...core.Event) {
+	b.mu.RLock()
+	subscriptions := b.subscriptions
+	b.mu.RUnlock()
+
+	for _, event := range events {
+		for _, s := range subscriptions {
+			if s.subscribed(event.EventName()) {
+				s.handler(ctx, event)
+			}
+		}
+	}
+}
+
+func (s subscription) subscribed(name string) bool {
+	if len(s.names) == 0 {
+		return true
+	}
+	for _, n := range s.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
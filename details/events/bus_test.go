@@ -0,0 +1,55 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+)
+
+func TestBus_Publish(t *testing.T) {
+	// arrange
+	created := core.TransactionCreated{Transaction: core.Transaction{ID: 1}}
+	deleted := core.TransactionDeleted{Transaction: core.Transaction{ID: 1}}
+	category := core.CategoryCreated{Category: core.Category{Name: "Food"}}
+
+	var all, transactions []core.Event
+	b := NewBus()
+	b.Subscribe(func(_ context.Context, e core.Event) { all = append(all, e) })
+	b.Subscribe(func(_ context.Context, e core.Event) {
+		transactions = append(transactions, e)
+	}, core.EventTransactionCreated, core.EventTransactionDeleted)
+
+	// act
+	b.Publish(context.Background(), category, created, deleted)
+
+	// assert
+	assert.Equal(t, []core.Event{category, created, deleted}, all)
+	assert.Equal(t, []core.Event{created, deleted}, transactions)
+}
+
+func TestBus_Publish_noSubscriber(t *testing.T) {
+	// act & assert
+	assert.NotPanics(t, func() {
+		NewBus().Publish(context.Background(), core.TransactionDeleted{Transaction: core.Transaction{ID: 1}})
+	})
+}
+
+func TestPublishers_Publish(t *testing.T) {
+	// arrange
+	created := core.TransactionCreated{Transaction: core.Transaction{ID: 1}}
+
+	var first, second []core.Event
+	a, b := NewBus(), NewBus()
+	a.Subscribe(func(_ context.Context, e core.Event) { first = append(first, e) })
+	b.Subscribe(func(_ context.Context, e core.Event) { second = append(second, e) })
+
+	// act
+	Publishers{a, b}.Publish(context.Background(), created)
+
+	// assert
+	assert.Equal(t, []core.Event{created}, first)
+	assert.Equal(t, []core.Event{created}, second)
+}
@@ -0,0 +1,46 @@
+// Package events publishes the domain events of the use cases: in process, to the outbox the webhooks and the relay
+// read, or to a NATS server, either directly or relayed from the outbox, so an event is published at least once,
+// even when NATS is down while its change is saved.
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/outbox"
+)
+
+type (
+	// Store represents a client able to append messages to the outbox, to read them until they are relayed, and to
+	// purge them.
+	Store interface {
+		// Append appends the messages to the outbox, in order, as relayed already when the store does not relay them.
+		Append(ctx context.Context, messages ...outbox.Message) error
+
+		// FindPending finds up to limit messages yet to be relayed, oldest first.
+		FindPending(ctx context.Context, limit int) ([]outbox.Message, error)
+
+		// MarkRelayed marks the messages of the given ids relayed at now, so they are not found pending anymore.
+		MarkRelayed(ctx context.Context, ids []int, now time.Time) error
+
+		// Purge removes the messages appended before, once relayed and dispatched to the webhooks, along with their
+		// deliveries, unless one is yet to be attempted.
+		Purge(ctx context.Context, before time.Time) error
+	}
+
+	// Sender represents a client able to send a message to the subscribers, eg: NATS.
+	Sender interface {
+		Send(ctx context.Context, m outbox.Message) error
+	}
+)
+
+// Publishers publishes the events to each of its publishers, in order.
+type Publishers []core.Publisher
+
+// Publish the events to each publisher.
+func (ps Publishers) Publish(ctx context.Context, events ...core.Event) {
+	for _, p := range ps {
+		p.Publish(ctx, events...)
+	}
+}
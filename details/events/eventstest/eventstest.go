@@ -0,0 +1,145 @@
+// Package eventstest defines what a correct events.Store does, as a test suite any backend can run.
+package eventstest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/events"
+	"github.com/gritt/maskada/details/outbox"
+)
+
+// Factory returns an empty event store, relaying the events when relay is true, releasing it with t.Cleanup when
+// needed.
+type Factory func(t *testing.T, relay bool) events.Store
+
+// ctx is the context of all calls made by the suite.
+var ctx = context.Background()
+
+// now is a time every backend stores without losing precision.
+var now = time.Now().UTC().Truncate(time.Second)
+
+// Run runs the conformance suite, each test against a new store.
+func Run(t *testing.T, newStore Factory) {
+	relayed := map[string]func(*testing.T, events.Store){
+		"FindPending returns the appended events, oldest first": testFindPending,
+		"FindPending is limited":                                testFindPendingLimit,
+		"MarkRelayed skips the relayed events":                  testMarkRelayed,
+		"Purge keeps the pending events":                        testPurgePending,
+	}
+
+	for name, run := range relayed {
+		t.Run(name, func(t *testing.T) {
+			run(t, newStore(t, true))
+		})
+	}
+
+	t.Run("Append appends the events relayed when the store does not relay them", func(t *testing.T) {
+		testAppendUnrelayed(t, newStore(t, false))
+	})
+}
+
+func testFindPending(t *testing.T, s events.Store) {
+	// arrange
+	assert.NoError(t, s.Append(ctx, message(core.EventCategoryCreated), message(core.EventTransactionCreated)))
+	assert.NoError(t, s.Append(ctx, message(core.EventTransactionDeleted)))
+
+	// act
+	got, gotErr := s.FindPending(ctx, 10)
+
+	// assert
+	assert.NoError(t, gotErr)
+	if !assert.Len(t, got, 3) {
+		return
+	}
+
+	var names []string
+	for i, m := range got {
+		names = append(names, m.Event)
+		assert.NotZero(t, m.ID)
+		assert.True(t, m.Date.Equal(now), "date %s", m.Date)
+		assert.JSONEq(t, `{"actor":"eventstest"}`, string(m.Payload))
+		if i > 0 {
+			assert.Greater(t, m.ID, got[i-1].ID)
+		}
+	}
+	assert.Equal(t, []string{
+		core.EventCategoryCreated,
+		core.EventTransactionCreated,
+		core.EventTransactionDeleted,
+	}, names)
+}
+
+func testFindPendingLimit(t *testing.T, s events.Store) {
+	// arrange
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, s.Append(ctx, message(core.EventTransactionCreated)))
+	}
+
+	// act
+	got, gotErr := s.FindPending(ctx, 2)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Len(t, got, 2)
+}
+
+func testMarkRelayed(t *testing.T, s events.Store) {
+	// arrange
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, s.Append(ctx, message(core.EventTransactionCreated)))
+	}
+
+	pending, _ := s.FindPending(ctx, 10)
+	if !assert.Len(t, pending, 3) {
+		return
+	}
+
+	// act
+	gotErr := s.MarkRelayed(ctx, []int{pending[0].ID, pending[2].ID}, now)
+
+	// assert
+	assert.NoError(t, gotErr)
+
+	got, _ := s.FindPending(ctx, 10)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, pending[1].ID, got[0].ID)
+	}
+}
+
+func testPurgePending(t *testing.T, s events.Store) {
+	// arrange
+	old := message(core.EventTransactionCreated)
+	old.Date = now.Add(-48 * time.Hour)
+	assert.NoError(t, s.Append(ctx, old))
+
+	// act
+	gotErr := s.Purge(ctx, now)
+
+	// assert
+	assert.NoError(t, gotErr)
+
+	got, _ := s.FindPending(ctx, 10)
+	assert.Len(t, got, 1)
+}
+
+func testAppendUnrelayed(t *testing.T, s events.Store) {
+	// act
+	gotErr := s.Append(ctx, message(core.EventTransactionCreated))
+
+	// assert
+	assert.NoError(t, gotErr)
+
+	got, err := s.FindPending(ctx, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func message(event string) outbox.Message {
+	return outbox.Message{Event: event, Date: now, Payload: json.RawMessage(`{"actor":"eventstest"}`)}
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/outbox"
+)
+
+// flushTimeout is how long the server is waited for to acknowledge a sent message.
+const flushTimeout = 5 * time.Second
+
+// NATS publishes the events, or sends the messages of the outbox, to a NATS server, each to the subject
+// <prefix>.<event>, eg: maskada.transaction.created, as its encoded message.
+type NATS struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATS initialize the publisher, sharing the connection.
+func NewNATS(conn *nats.Conn, prefix string) *NATS {
+	return &NATS{conn: conn, prefix: prefix}
+}
+
+// Publish sends the events without waiting for the server, so a request is not held while it is unreachable,
+// logging the failures, as their changes are saved already.
+func (n *NATS) Publish(ctx context.Context, events ...core.Event) {
+	for _, event := range events {
+		m, err := outbox.Encode(event)
+		if err == nil {
+			err = n.publish(m)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "publishing the event failed", "event", event.EventName(), "error", err.Error())
+		}
+	}
+}
+
+// Send a message, waiting for the server to receive it. A message of the outbox carries its ID in the Nats-Msg-Id
+// header, so a JetStream stream drops the copies sent again.
+func (n *NATS) Send(ctx context.Context, m outbox.Message) error {
+	if err := n.publish(m); err != nil {
+		return errors.Wrap(err, "NATS.Send failed")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, flushTimeout)
+	defer cancel()
+
+	if err := n.conn.FlushWithContext(ctx); err != nil {
+		return errors.Wrap(err, "NATS.Send failed")
+	}
+
+	return nil
+}
+
+func (n *NATS) publish(m outbox.Message) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(n.prefix + "." + m.Event)
+	msg.Data = data
+	if m.ID != 0 {
+		msg.Header.Set(nats.MsgIdHdr, strconv.Itoa(m.ID))
+	}
+
+	return n.conn.PublishMsg(msg)
+}
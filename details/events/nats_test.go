@@ -0,0 +1,111 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/outbox"
+)
+
+// runServer starts an embedded NATS server on a random port, shut down with t.Cleanup, and connects to it.
+func runServer(t *testing.T) *nats.Conn {
+	s, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, NoLog: true, NoSigs: true})
+	if err != nil {
+		t.Fatalf("NewServer failed: %s", err)
+	}
+
+	go s.Start()
+	t.Cleanup(s.Shutdown)
+	if !s.ReadyForConnections(5 * time.Second) {
+		t.Fatal("the NATS server did not start")
+	}
+
+	conn, err := nats.Connect(s.ClientURL())
+	if err != nil {
+		t.Fatalf("Connect failed: %s", err)
+	}
+	t.Cleanup(conn.Close)
+
+	return conn
+}
+
+func TestNATS_Publish(t *testing.T) {
+	// arrange
+	conn := runServer(t)
+
+	received := make(chan *nats.Msg, 2)
+	sub, err := conn.ChanSubscribe("maskada.transaction.*", received)
+	if err != nil {
+		t.Fatalf("ChanSubscribe failed: %s", err)
+	}
+	defer sub.Unsubscribe()
+
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	n := NewNATS(conn, "maskada")
+
+	// act
+	n.Publish(context.Background(),
+		core.CategoryCreated{Category: core.Category{Name: "Food"}, Actor: "tester", Date: date},
+		core.TransactionDeleted{Transaction: core.Transaction{ID: 3, Version: 2}, Actor: "tester", Date: date},
+	)
+
+	// assert
+	select {
+	case msg := <-received:
+		assert.Equal(t, "maskada.transaction.deleted", msg.Subject)
+		assert.Empty(t, msg.Header.Get(nats.MsgIdHdr))
+
+		var got outbox.Message
+		assert.NoError(t, json.Unmarshal(msg.Data, &got))
+		assert.Equal(t, core.EventTransactionDeleted, got.Event)
+		assert.Equal(t, date, got.Date)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+	assert.Empty(t, received)
+}
+
+func TestNATS_Send(t *testing.T) {
+	// arrange
+	conn := runServer(t)
+
+	sub, err := conn.SubscribeSync("maskada.>")
+	if err != nil {
+		t.Fatalf("SubscribeSync failed: %s", err)
+	}
+	defer sub.Unsubscribe()
+
+	m := outbox.Message{ID: 7, Event: core.EventCategoryCreated, Payload: json.RawMessage(`{"category":{"name":"Food"}}`)}
+
+	// act
+	gotErr := NewNATS(conn, "maskada").Send(context.Background(), m)
+
+	// assert
+	assert.NoError(t, gotErr)
+
+	msg, err := sub.NextMsg(5 * time.Second)
+	if err != nil {
+		t.Fatalf("NextMsg failed: %s", err)
+	}
+	assert.Equal(t, "maskada.category.created", msg.Subject)
+	assert.Equal(t, "7", msg.Header.Get(nats.MsgIdHdr))
+}
+
+func TestNATS_Send_closed(t *testing.T) {
+	// arrange
+	conn := runServer(t)
+	conn.Close()
+
+	// act
+	gotErr := NewNATS(conn, "maskada").Send(context.Background(), outbox.Message{Event: core.EventCategoryCreated})
+
+	// assert
+	assert.EqualError(t, gotErr, "NATS.Send failed: nats: connection closed")
+}
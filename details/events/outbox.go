@@ -0,0 +1,42 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/outbox"
+)
+
+// Outbox publishes the events by appending them to the outbox of a store, from which the webhooks are delivered and
+// a Relay sends them.
+type Outbox struct {
+	store Store
+}
+
+// NewOutbox initialize the publisher.
+func NewOutbox(store Store) *Outbox {
+	return &Outbox{store: store}
+}
+
+// Publish appends the events, logging the failures, as their changes are saved already. The events are appended
+// even when the request is canceled meanwhile.
+func (o *Outbox) Publish(ctx context.Context, events ...core.Event) {
+	messages := make([]outbox.Message, 0, len(events))
+	for _, event := range events {
+		m, err := outbox.Encode(event)
+		if err != nil {
+			slog.ErrorContext(ctx, "publishing the event failed", "event", event.EventName(), "error", err.Error())
+			continue
+		}
+		messages = append(messages, m)
+	}
+
+	if len(messages) == 0 {
+		return
+	}
+
+	if err := o.store.Append(context.WithoutCancel(ctx), messages...); err != nil {
+		slog.ErrorContext(ctx, "publishing the events failed", "events", len(messages), "error", err.Error())
+	}
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/outbox"
+)
+
+func TestOutbox_Publish(t *testing.T) {
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	deleted := core.TransactionDeleted{Transaction: core.Transaction{ID: 3, Version: 2}, Actor: "tester", Date: date}
+	want, err := outbox.Encode(deleted)
+	if err != nil {
+		t.Fatalf("Encode failed: %s", err)
+	}
+
+	tests := map[string]func(t *testing.T, m *mockStore){
+		"when store fails": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Append", []outbox.Message{want}).Return(errors.New("EventStore.Append failed: err"))
+			o := NewOutbox(m)
+
+			// act & assert
+			assert.NotPanics(t, func() { o.Publish(context.Background(), deleted) })
+		},
+		"when an event is unknown": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Append", []outbox.Message{want}).Return(nil)
+			o := NewOutbox(m)
+
+			// act
+			o.Publish(context.Background(), unknownEvent{}, deleted)
+		},
+		"when no event is known": func(t *testing.T, m *mockStore) {
+			// arrange
+			o := NewOutbox(m)
+
+			// act
+			o.Publish(context.Background(), unknownEvent{})
+
+			// assert
+			m.AssertNotCalled(t, "Append", mock.Anything)
+		},
+		"when the request is canceled": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Append", []outbox.Message{want}).Return(nil)
+			o := NewOutbox(m)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			// act
+			o.Publish(ctx, deleted)
+
+			// assert
+			assert.NoError(t, m.ctx.Err())
+		},
+		"when events are appended": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Append", []outbox.Message{want, want}).Return(nil)
+			o := NewOutbox(m)
+
+			// act
+			o.Publish(context.Background(), deleted, deleted)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockStore)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+type unknownEvent struct{}
+
+func (unknownEvent) EventName() string { return "unknown" }
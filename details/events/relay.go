@@ -0,0 +1,83 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// batchSize is the maximum number of messages relayed by a single query.
+const batchSize = 100
+
+// Relay sends the pending messages of the outbox, marking them relayed once sent, so a message is sent at least
+// once, and again when marking it fails.
+type Relay struct {
+	store    Store
+	sender   Sender
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewRelay initialize the relay, sending every interval.
+func NewRelay(store Store, sender Sender, interval time.Duration) *Relay {
+	return &Relay{
+		store:    store,
+		sender:   sender,
+		interval: interval,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run relays the outbox every interval until ctx is done, logging the failed rounds.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Relay(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "relaying the events failed", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Relay sends the pending messages in order, until none is left or one fails, which is retried the next round.
+func (r *Relay) Relay(ctx context.Context) error {
+	for {
+		messages, err := r.store.FindPending(ctx, batchSize)
+		if err != nil {
+			return errors.Wrap(err, "Relay failed")
+		}
+
+		var (
+			sent    []int
+			sendErr error
+		)
+		for _, m := range messages {
+			if sendErr = r.sender.Send(ctx, m); sendErr != nil {
+				break
+			}
+			sent = append(sent, m.ID)
+		}
+
+		if len(sent) > 0 {
+			if err := r.store.MarkRelayed(ctx, sent, r.now()); err != nil {
+				return errors.Wrap(err, "Relay failed")
+			}
+		}
+
+		if sendErr != nil {
+			return errors.Wrap(sendErr, "Relay failed")
+		}
+		if len(messages) < batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/outbox"
+)
+
+func TestRelay_Relay(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := outbox.Message{ID: 1, Event: core.EventTransactionCreated}
+	second := outbox.Message{ID: 2, Event: core.EventTransactionDeleted}
+
+	newRelay := func(s *mockStore, sender *mockSender) *Relay {
+		r := NewRelay(s, sender, time.Second)
+		r.now = func() time.Time { return now }
+		return r
+	}
+
+	tests := map[string]func(t *testing.T, s *mockStore, sender *mockSender){
+		"when store fails": func(t *testing.T, s *mockStore, sender *mockSender) {
+			// arrange
+			s.On("FindPending", batchSize).Return([]outbox.Message{}, errors.New("EventStore.FindPending failed: err"))
+
+			// act
+			gotErr := newRelay(s, sender).Relay(context.Background())
+
+			// assert
+			assert.EqualError(t, gotErr, "Relay failed: EventStore.FindPending failed: err")
+		},
+		"when nothing is pending": func(t *testing.T, s *mockStore, sender *mockSender) {
+			// arrange
+			s.On("FindPending", batchSize).Return([]outbox.Message{}, nil)
+
+			// act
+			gotErr := newRelay(s, sender).Relay(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			s.AssertNotCalled(t, "MarkRelayed", mock.Anything, mock.Anything)
+		},
+		"when sending fails": func(t *testing.T, s *mockStore, sender *mockSender) {
+			// arrange
+			s.On("FindPending", batchSize).Return([]outbox.Message{first, second}, nil)
+			sender.On("Send", first).Return(nil)
+			sender.On("Send", second).Return(errors.New("NATS.Send failed: err"))
+			s.On("MarkRelayed", []int{1}, now).Return(nil)
+
+			// act
+			gotErr := newRelay(s, sender).Relay(context.Background())
+
+			// assert
+			assert.EqualError(t, gotErr, "Relay failed: NATS.Send failed: err")
+		},
+		"when marking fails": func(t *testing.T, s *mockStore, sender *mockSender) {
+			// arrange
+			s.On("FindPending", batchSize).Return([]outbox.Message{first}, nil)
+			sender.On("Send", first).Return(nil)
+			s.On("MarkRelayed", []int{1}, now).Return(errors.New("EventStore.MarkRelayed failed: err"))
+
+			// act
+			gotErr := newRelay(s, sender).Relay(context.Background())
+
+			// assert
+			assert.EqualError(t, gotErr, "Relay failed: EventStore.MarkRelayed failed: err")
+		},
+		"when messages are relayed": func(t *testing.T, s *mockStore, sender *mockSender) {
+			// arrange
+			s.On("FindPending", batchSize).Return([]outbox.Message{first, second}, nil)
+			sender.On("Send", first).Return(nil)
+			sender.On("Send", second).Return(nil)
+			s.On("MarkRelayed", []int{1, 2}, now).Return(nil)
+
+			// act
+			gotErr := newRelay(s, sender).Relay(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+		},
+		"when a full batch is relayed": func(t *testing.T, s *mockStore, sender *mockSender) {
+			// arrange
+			batch := make([]outbox.Message, batchSize)
+			ids := make([]int, batchSize)
+			for i := range batch {
+				batch[i] = outbox.Message{ID: i + 1, Event: core.EventTransactionCreated}
+				ids[i] = i + 1
+			}
+			s.On("FindPending", batchSize).Return(batch, nil).Once()
+			s.On("FindPending", batchSize).Return([]outbox.Message{}, nil).Once()
+			sender.On("Send", mock.Anything).Return(nil)
+			s.On("MarkRelayed", ids, now).Return(nil)
+
+			// act
+			gotErr := newRelay(s, sender).Relay(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			sender.AssertNumberOfCalls(t, "Send", batchSize)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, sender := new(mockStore), new(mockSender)
+			run(t, s, sender)
+			s.AssertExpectations(t)
+			sender.AssertExpectations(t)
+		})
+	}
+}
+
+type mockStore struct {
+	mock.Mock
+	ctx context.Context
+}
+
+func (m *mockStore) Append(ctx context.Context, messages ...outbox.Message) error {
+	m.ctx = ctx
+	args := m.Called(messages)
+	return args.Error(0)
+}
+
+func (m *mockStore) FindPending(_ context.Context, limit int) ([]outbox.Message, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]outbox.Message), args.Error(1)
+}
+
+func (m *mockStore) MarkRelayed(_ context.Context, ids []int, now time.Time) error {
+	args := m.Called(ids, now)
+	return args.Error(0)
+}
+
+func (m *mockStore) Purge(_ context.Context, before time.Time) error {
+	args := m.Called(before)
+	return args.Error(0)
+}
+
+type mockSender struct {
+	mock.Mock
+}
+
+func (m *mockSender) Send(_ context.Context, msg outbox.Message) error {
+	args := m.Called(msg)
+	return args.Error(0)
+}
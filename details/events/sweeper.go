@@ -0,0 +1,50 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sweeper purges the outbox periodically, removing the messages older than the retention once relayed and dispatched.
+type Sweeper struct {
+	store     Store
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// NewSweeper initialize the sweeper, keeping the messages for retention, sweeping every interval.
+func NewSweeper(store Store, retention, interval time.Duration) *Sweeper {
+	return &Sweeper{
+		store:     store,
+		retention: retention,
+		interval:  interval,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run sweeps the outbox every interval until ctx is done, logging the failed sweeps.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "sweeping the outbox failed", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep purges the messages appended before the retention.
+func (s *Sweeper) Sweep(ctx context.Context) error {
+	return errors.Wrap(s.store.Purge(ctx, s.now().Add(-s.retention)), "Sweep failed")
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSweeper_Sweep(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	before := now.Add(-24 * time.Hour)
+
+	newSweeper := func(s *mockStore) *Sweeper {
+		sw := NewSweeper(s, 24*time.Hour, time.Hour)
+		sw.now = func() time.Time { return now }
+		return sw
+	}
+
+	tests := map[string]func(t *testing.T, s *mockStore){
+		"when store fails to purge": func(t *testing.T, s *mockStore) {
+			// arrange
+			s.On("Purge", before).Return(errors.New("EventStore.Purge failed: err"))
+
+			// act
+			gotErr := newSweeper(s).Sweep(context.Background())
+
+			// assert
+			assert.EqualError(t, gotErr, "Sweep failed: EventStore.Purge failed: err")
+		},
+		"when store purges the messages older than the retention": func(t *testing.T, s *mockStore) {
+			// arrange
+			s.On("Purge", before).Return(nil)
+
+			// act
+			gotErr := newSweeper(s).Sweep(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			s := new(mockStore)
+
+			// act
+			run(t, s)
+
+			// assert
+			s.AssertExpectations(t)
+		})
+	}
+}
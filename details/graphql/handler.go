@@ -0,0 +1,63 @@
+package graphql
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	graphqllib "github.com/graph-gophers/graphql-go"
+)
+
+// Handler executes the GraphQL queries posted as JSON, eg: {"query": "{ categories { name } }", "variables": {}}.
+type Handler struct {
+	schema *graphqllib.Schema
+
+	TransactionLister TransactionLister
+	CategoryTotaler   CategoryTotaler
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewHandler initialize the handler, parsing the schema against its resolvers.
+func NewHandler(
+	transactionLister TransactionLister,
+	transactionGetter TransactionGetter,
+	categoryLister CategoryLister,
+	categoryTotaler CategoryTotaler,
+) *Handler {
+	r := &resolver{
+		transactionGetter: transactionGetter,
+		categoryLister:    categoryLister,
+	}
+
+	return &Handler{
+		schema:            graphqllib.MustParseSchema(schema, r),
+		TransactionLister: transactionLister,
+		CategoryTotaler:   categoryTotaler,
+	}
+}
+
+// ServeHTTP executes a query with loaders of its own, responding its data and errors as GraphQL does, with status 200.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": "Handler.ServeHTTP failed: could not decode payload"}`))
+		return
+	}
+
+	ctx := withLoaders(r.Context(), newLoaders(h.TransactionLister, h.CategoryTotaler))
+	res := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	for _, err := range res.Errors {
+		slog.WarnContext(ctx, "query failed", "operation", req.OperationName, "error", err.Message)
+	}
+
+	_ = json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,310 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gritt/maskada/core"
+)
+
+var (
+	testDate = time.Date(2019, 10, 25, 0, 26, 57, 0, time.UTC)
+
+	testTransactions = []core.Transaction{
+		{ID: 1, Amount: 100, Type: core.Debit, Category: core.Category{Name: "Food"}, Date: testDate.AddDate(0, -1, 0), Name: "Market", Version: 1},
+		{ID: 2, Amount: 300, Type: core.Credit, Category: core.Category{Name: "Travel"}, Date: testDate.AddDate(0, -1, 0), Name: "Flight", Version: 1},
+		{ID: 3, Amount: 1000, Type: core.Income, Category: core.Category{Name: "Salary"}, Date: testDate, Name: "Acme", Version: 1},
+		{ID: 4, Amount: 26, Type: core.Credit, Category: core.Category{Name: "Food"}, Date: testDate, Name: "Family Flavor", Version: 2},
+	}
+)
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when transactions are filtered": func(t *testing.T) {
+			// arrange
+			f := core.Filter{Category: "Food", Type: core.Credit, From: time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)}
+
+			l := new(mockTransactionLister)
+			l.On("ListPage", f, core.Cursor{}, 21).Return([]core.Transaction{testTransactions[3]}, nil)
+			l.On("Count", f).Return(1, nil)
+
+			// act
+			got := query(t, NewHandler(l, nil, nil, nil), `{
+				transactions(filter: {category: "Food", type: CREDIT, from: "2019-10-01T00:00:00Z"}) {
+					totalCount
+					edges { node { id amount type date name version category { name } } }
+				}
+			}`, nil)
+
+			// assert
+			assert.JSONEq(t, `{"data": {"transactions": {
+				"totalCount": 1,
+				"edges": [{"node": {
+					"id": "4", "amount": 26, "type": "CREDIT", "date": "2019-10-25T00:26:57Z",
+					"name": "Family Flavor", "version": 2, "category": {"name": "Food"}
+				}}]
+			}}}`, got)
+		},
+		"when transactions are paginated": func(t *testing.T) {
+			// arrange
+			l := new(mockTransactionLister)
+			l.On("ListPage", core.Filter{}, core.Cursor{}, 4).Return(testTransactions, nil)
+			l.On("ListPage", core.Filter{}, testTransactions[2].Cursor(), 4).Return(testTransactions[3:], nil)
+			h := NewHandler(l, nil, nil, nil)
+
+			// act
+			first := query(t, h, `{ transactions(first: 3) { pageInfo { hasNextPage endCursor } edges { node { id } } } }`, nil)
+
+			var page struct {
+				Data struct {
+					Transactions struct {
+						PageInfo struct{ EndCursor string }
+					}
+				}
+			}
+			assert.NoError(t, json.Unmarshal([]byte(first), &page))
+
+			second := query(t, h, `query Next($after: String) {
+				transactions(first: 3, after: $after) { pageInfo { hasNextPage } edges { node { id } } }
+			}`, map[string]interface{}{"after": page.Data.Transactions.PageInfo.EndCursor})
+
+			// assert
+			assert.Equal(t, encodeCursor(testTransactions[2].Cursor()), page.Data.Transactions.PageInfo.EndCursor)
+			assert.JSONEq(t, `{"data": {"transactions": {
+				"pageInfo": {"hasNextPage": true, "endCursor": "`+encodeCursor(testTransactions[2].Cursor())+`"},
+				"edges": [{"node": {"id": "1"}}, {"node": {"id": "2"}}, {"node": {"id": "3"}}]
+			}}}`, first)
+			assert.JSONEq(t, `{"data": {"transactions": {
+				"pageInfo": {"hasNextPage": false},
+				"edges": [{"node": {"id": "4"}}]
+			}}}`, second)
+		},
+		"when the cursor is invalid": func(t *testing.T) {
+			// arrange
+			l := new(mockTransactionLister)
+
+			// act
+			got := query(t, NewHandler(l, nil, nil, nil), `{ transactions(after: "bogus") { totalCount } }`, nil)
+
+			// assert
+			assert.Contains(t, got, "invalid cursor bogus")
+		},
+		"when the transactions of nested categories are totaled": func(t *testing.T) {
+			// arrange
+			l := new(mockTransactionLister)
+			l.On("ListPage", core.Filter{}, core.Cursor{}, 21).Return(testTransactions, nil)
+
+			tt := new(mockCategoryTotaler)
+			tt.On("Totals", []string{"Food", "Salary", "Travel"}).Return(map[string]core.Totals{
+				"Food":   {Debit: 100, Credit: 26, Count: 2},
+				"Salary": {Income: 1000, Count: 1},
+				"Travel": {Credit: 300, Count: 1},
+			}, nil).Once()
+
+			// act
+			got := query(t, NewHandler(l, nil, nil, tt), `{
+				transactions { edges { node { id category { name totals { debit credit income count } } } } }
+			}`, nil)
+
+			// assert
+			assert.NotContains(t, got, "errors")
+			assert.Contains(t, got, `{"id":"4","category":{"name":"Food","totals":{"debit":100,"credit":26,"income":0,"count":2}}}`)
+			tt.AssertExpectations(t)
+		},
+		"when categories are listed with their totals and transactions": func(t *testing.T) {
+			// arrange
+			l := new(mockTransactionLister)
+			l.On("ListPage", core.Filter{Category: "Food"}, core.Cursor{}, 2).Return([]core.Transaction{testTransactions[0], testTransactions[3]}, nil)
+			l.On("Count", core.Filter{Category: "Food"}).Return(2, nil)
+			l.On("ListPage", core.Filter{Category: "Home"}, core.Cursor{}, 2).Return([]core.Transaction{}, nil)
+			l.On("Count", core.Filter{Category: "Home"}).Return(0, nil)
+
+			cl := new(mockCategoryLister)
+			cl.On("List").Return([]core.Category{{Name: "Food"}, {Name: "Home"}}, nil)
+
+			tt := new(mockCategoryTotaler)
+			tt.On("Totals", []string{"Food", "Home"}).Return(map[string]core.Totals{
+				"Food": {Debit: 100, Credit: 26, Count: 2},
+				"Home": {},
+			}, nil).Once()
+
+			// act
+			got := query(t, NewHandler(l, nil, cl, tt), `{
+				categories { name totals { count } transactions(first: 1) { totalCount edges { node { id } } } }
+			}`, nil)
+
+			// assert
+			assert.JSONEq(t, `{"data": {"categories": [
+				{"name": "Food", "totals": {"count": 2}, "transactions": {"totalCount": 2, "edges": [{"node": {"id": "1"}}]}},
+				{"name": "Home", "totals": {"count": 0}, "transactions": {"totalCount": 0, "edges": []}}
+			]}}`, got)
+			l.AssertExpectations(t)
+			tt.AssertExpectations(t)
+		},
+		"when a category does not exist": func(t *testing.T) {
+			// arrange
+			cl := new(mockCategoryLister)
+			cl.On("List").Return([]core.Category{{Name: "Food"}}, nil)
+
+			// act
+			got := query(t, NewHandler(nil, nil, cl, nil), `{ category(name: "Home") { name } }`, nil)
+
+			// assert
+			assert.JSONEq(t, `{"data": {"category": null}}`, got)
+		},
+		"when a transaction is got": func(t *testing.T) {
+			// arrange
+			g := new(mockTransactionGetter)
+			g.On("Get", 4).Return(testTransactions[3], nil)
+
+			// act
+			got := query(t, NewHandler(nil, g, nil, nil), `{ transaction(id: "4") { name } }`, nil)
+
+			// assert
+			assert.JSONEq(t, `{"data": {"transaction": {"name": "Family Flavor"}}}`, got)
+		},
+		"when a transaction does not exist": func(t *testing.T) {
+			// arrange
+			g := new(mockTransactionGetter)
+			g.On("Get", 7).Return(core.Transaction{}, errors.Wrap(core.ErrNotFound, "Get failed"))
+
+			// act
+			got := query(t, NewHandler(nil, g, nil, nil), `{ transaction(id: "7") { name } }`, nil)
+
+			// assert
+			assert.JSONEq(t, `{"data": {"transaction": null}}`, got)
+		},
+		"when a month is summarized": func(t *testing.T) {
+			// arrange
+			l := new(mockTransactionLister)
+			l.On("ListMonth", time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC)).Return(testTransactions[:2], nil)
+			l.On("ListMonth", time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)).Return(testTransactions[2:], nil)
+
+			// act
+			got := query(t, NewHandler(l, nil, nil, nil), `{
+				summary(month: "2019-10") {
+					month creditDue balance
+					totals { debit credit income count }
+					categories { category { name } totals { credit income } }
+				}
+			}`, nil)
+
+			// assert
+			assert.JSONEq(t, `{"data": {"summary": {
+				"month": "2019-10", "creditDue": 300, "balance": 700,
+				"totals": {"debit": 0, "credit": 26, "income": 1000, "count": 2},
+				"categories": [
+					{"category": {"name": "Food"}, "totals": {"credit": 26, "income": 0}},
+					{"category": {"name": "Salary"}, "totals": {"credit": 0, "income": 1000}}
+				]
+			}}}`, got)
+		},
+		"when the month is invalid": func(t *testing.T) {
+			// act
+			got := query(t, NewHandler(nil, nil, nil, nil), `{ summary(month: "october") { balance } }`, nil)
+
+			// assert
+			assert.Contains(t, got, "invalid month october, expected YYYY-MM")
+		},
+		"when the use case fails": func(t *testing.T) {
+			// arrange
+			l := new(mockTransactionLister)
+			l.On("ListPage", core.Filter{}, core.Cursor{}, 21).Return([]core.Transaction{}, errors.New("ListPage failed: connection refused"))
+
+			// act
+			got := query(t, NewHandler(l, nil, nil, nil), `{ transactions { totalCount } }`, nil)
+
+			// assert
+			assert.Contains(t, got, "ListPage failed: connection refused")
+		},
+		"when the payload is invalid": func(t *testing.T) {
+			// arrange
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{"))
+
+			// act
+			NewHandler(nil, nil, nil, nil).ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.JSONEq(t, `{"error": "Handler.ServeHTTP failed: could not decode payload"}`, rr.Body.String())
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+// query posts a GraphQL query to the handler, returning its response body.
+func query(t *testing.T, h http.Handler, q string, variables map[string]interface{}) string {
+	body, err := json.Marshal(request{Query: q, Variables: variables})
+	if err != nil {
+		t.Fatalf("failed to: encode the query: %s", err)
+	}
+
+	rr := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+
+	h.ServeHTTP(rr, r)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	return rr.Body.String()
+}
+
+type mockTransactionLister struct {
+	mock.Mock
+}
+
+func (m *mockTransactionLister) ListMonth(_ context.Context, month time.Time) ([]core.Transaction, error) {
+	args := m.Called(month)
+	return args.Get(0).([]core.Transaction), args.Error(1)
+}
+
+func (m *mockTransactionLister) ListPage(_ context.Context, f core.Filter, after core.Cursor, limit int) ([]core.Transaction, error) {
+	args := m.Called(f, after, limit)
+	return args.Get(0).([]core.Transaction), args.Error(1)
+}
+
+func (m *mockTransactionLister) Count(_ context.Context, f core.Filter) (int, error) {
+	args := m.Called(f)
+	return args.Int(0), args.Error(1)
+}
+
+type mockTransactionGetter struct {
+	mock.Mock
+}
+
+func (m *mockTransactionGetter) Get(_ context.Context, id int) (core.Transaction, error) {
+	args := m.Called(id)
+	return args.Get(0).(core.Transaction), args.Error(1)
+}
+
+type mockCategoryLister struct {
+	mock.Mock
+}
+
+func (m *mockCategoryLister) List(_ context.Context) ([]core.Category, error) {
+	args := m.Called()
+	return args.Get(0).([]core.Category), args.Error(1)
+}
+
+type mockCategoryTotaler struct {
+	mock.Mock
+}
+
+func (m *mockCategoryTotaler) Totals(_ context.Context, names []string) (map[string]core.Totals, error) {
+	args := m.Called(names)
+	return args.Get(0).(map[string]core.Totals), args.Error(1)
+}
@@ -0,0 +1,79 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/gritt/maskada/core"
+)
+
+type loadersKey struct{}
+
+// loaders batch and cache what the resolvers of a single request read, so nested fields do not query once per parent,
+// along with the use case listing the transactions a page at a time.
+type loaders struct {
+	lister  TransactionLister
+	totaler CategoryTotaler
+
+	mu      sync.Mutex
+	pending map[string]bool
+	totals  map[string]core.Totals
+}
+
+func newLoaders(lister TransactionLister, totaler CategoryTotaler) *loaders {
+	return &loaders{
+		lister:  lister,
+		totaler: totaler,
+		pending: map[string]bool{},
+		totals:  map[string]core.Totals{},
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// prime queues the categories whose totals are likely to be loaded, eg: those of a page of transactions.
+func (l *loaders) prime(names ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, name := range names {
+		if _, ok := l.totals[name]; !ok {
+			l.pending[name] = true
+		}
+	}
+}
+
+// categoryTotals loads the totals of a category, along with those of every queued one, in a single use case call.
+func (l *loaders) categoryTotals(ctx context.Context, name string) (core.Totals, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if totals, ok := l.totals[name]; ok {
+		return totals, nil
+	}
+
+	l.pending[name] = true
+	names := make([]string, 0, len(l.pending))
+	for pending := range l.pending {
+		names = append(names, pending)
+	}
+	sort.Strings(names)
+
+	totals, err := l.totaler.Totals(ctx, names)
+	if err != nil {
+		return core.Totals{}, err
+	}
+
+	for _, pending := range names {
+		l.totals[pending] = totals[pending]
+		delete(l.pending, pending)
+	}
+	return l.totals[name], nil
+}
@@ -0,0 +1,422 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	graphqllib "github.com/graph-gophers/graphql-go"
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+)
+
+const (
+	maxFirst = 100
+
+	cursorPrefix    = "transaction:"
+	cursorSeparator = "/"
+	monthLayout     = "2006-01"
+)
+
+// transactionTypes names the transaction types in the schema, by their value.
+var transactionTypes = map[int]string{
+	core.Debit:  "DEBIT",
+	core.Credit: "CREDIT",
+	core.Income: "INCOME",
+}
+
+type (
+	// TransactionLister represents a use case able to list transactions by month, or a page at a time.
+	TransactionLister interface {
+		ListMonth(ctx context.Context, month time.Time) ([]core.Transaction, error)
+		ListPage(ctx context.Context, f core.Filter, after core.Cursor, limit int) ([]core.Transaction, error)
+		Count(ctx context.Context, f core.Filter) (int, error)
+	}
+
+	// TransactionGetter represents a use case able to get a single transaction.
+	TransactionGetter interface {
+		Get(ctx context.Context, id int) (core.Transaction, error)
+	}
+
+	// CategoryLister represents a use case able to list categories.
+	CategoryLister interface {
+		List(ctx context.Context) ([]core.Category, error)
+	}
+
+	// CategoryTotaler represents a use case able to sum the transactions of many categories at once.
+	CategoryTotaler interface {
+		Totals(ctx context.Context, names []string) (map[string]core.Totals, error)
+	}
+)
+
+// resolver resolves the queries of the schema with the use cases, reading through the loaders of the request.
+type resolver struct {
+	transactionGetter TransactionGetter
+	categoryLister    CategoryLister
+}
+
+type filterInput struct {
+	Category *string
+	Type     *string
+	From     *graphqllib.Time
+	To       *graphqllib.Time
+}
+
+// pageArgs pages transactions, first defaulting to 20 in the schema.
+type pageArgs struct {
+	First int32
+	After *string
+}
+
+// Transactions lists a page of the transactions matching the filter.
+func (r *resolver) Transactions(ctx context.Context, args struct {
+	Filter *filterInput
+	pageArgs
+}) (*connectionResolver, error) {
+	return newConnection(ctx, args.Filter.filter(), args.pageArgs)
+}
+
+// Transaction gets a transaction by its ID, nil when it does not exist.
+func (r *resolver) Transaction(ctx context.Context, args struct{ ID graphqllib.ID }) (*transactionResolver, error) {
+	id, err := strconv.Atoi(string(args.ID))
+	if err != nil {
+		return nil, errors.Errorf("invalid id %s", args.ID)
+	}
+
+	trs, err := r.transactionGetter.Get(ctx, id)
+	if errors.Cause(err) == core.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	loadersFrom(ctx).prime(trs.Category.Name)
+	return &transactionResolver{trs: trs}, nil
+}
+
+// Categories lists the categories, queuing their totals to be loaded at once.
+func (r *resolver) Categories(ctx context.Context) ([]*categoryResolver, error) {
+	categories, err := r.categoryLister.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*categoryResolver, 0, len(categories))
+	names := make([]string, 0, len(categories))
+	for _, c := range categories {
+		res = append(res, &categoryResolver{category: c})
+		names = append(names, c.Name)
+	}
+	loadersFrom(ctx).prime(names...)
+
+	return res, nil
+}
+
+// Category gets a category by its name, nil when it does not exist.
+func (r *resolver) Category(ctx context.Context, args struct{ Name string }) (*categoryResolver, error) {
+	categories, err := r.categoryLister.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range categories {
+		if c.Name == args.Name {
+			return &categoryResolver{category: c}, nil
+		}
+	}
+	return nil, nil
+}
+
+// Summary totals the transactions of a month, eg: 2019-10.
+func (r *resolver) Summary(ctx context.Context, args struct{ Month string }) (*summaryResolver, error) {
+	month, err := time.Parse(monthLayout, args.Month)
+	if err != nil {
+		return nil, errors.Errorf("invalid month %s, expected YYYY-MM", args.Month)
+	}
+
+	// the credit of the previous month is due in this one
+	lister := loadersFrom(ctx).lister
+	previous, err := lister.ListMonth(ctx, month.AddDate(0, -1, 0))
+	if err != nil {
+		return nil, err
+	}
+	current, err := lister.ListMonth(ctx, month)
+	if err != nil {
+		return nil, err
+	}
+
+	s := core.Summarize(append(previous, current...), month)
+
+	names := make([]string, 0, len(s.Categories))
+	for _, ct := range s.Categories {
+		names = append(names, ct.Category.Name)
+	}
+	loadersFrom(ctx).prime(names...)
+
+	return &summaryResolver{summary: s}, nil
+}
+
+// filter the transactions as the input does, the bounds of their date being inclusive.
+func (f *filterInput) filter() core.Filter {
+	if f == nil {
+		return core.Filter{}
+	}
+
+	var filter core.Filter
+	if f.Category != nil {
+		filter.Category = *f.Category
+	}
+	if f.Type != nil {
+		for value, name := range transactionTypes {
+			if name == *f.Type {
+				filter.Type = value
+			}
+		}
+	}
+	if f.From != nil {
+		filter.From = f.From.Time
+	}
+	if f.To != nil {
+		filter.To = f.To.Time
+	}
+	return filter
+}
+
+type connectionResolver struct {
+	filter      core.Filter
+	page        []core.Transaction
+	hasNextPage bool
+}
+
+// newConnection lists a page of the transactions matching the filter, along with the first of the next page, if any,
+// queuing the totals of their categories to be loaded at once.
+func newConnection(ctx context.Context, f core.Filter, args pageArgs) (*connectionResolver, error) {
+	first := int(args.First)
+	if first < 0 || first > maxFirst {
+		return nil, errors.Errorf("invalid first %d, expected from 0 to %d", first, maxFirst)
+	}
+
+	var after core.Cursor
+	if args.After != nil {
+		var err error
+		if after, err = decodeCursor(*args.After); err != nil {
+			return nil, err
+		}
+	}
+
+	l := loadersFrom(ctx)
+	page, err := l.lister.ListPage(ctx, f, after, first+1)
+	if err != nil {
+		return nil, err
+	}
+
+	hasNextPage := len(page) > first
+	if hasNextPage {
+		page = page[:first]
+	}
+
+	names := make([]string, 0, len(page))
+	for _, trs := range page {
+		names = append(names, trs.Category.Name)
+	}
+	l.prime(names...)
+
+	return &connectionResolver{filter: f, page: page, hasNextPage: hasNextPage}, nil
+}
+
+func (c *connectionResolver) Edges() []*edgeResolver {
+	edges := make([]*edgeResolver, 0, len(c.page))
+	for _, trs := range c.page {
+		edges = append(edges, &edgeResolver{trs: trs})
+	}
+	return edges
+}
+
+func (c *connectionResolver) PageInfo() *pageInfoResolver {
+	p := &pageInfoResolver{hasNextPage: c.hasNextPage}
+	if len(c.page) > 0 {
+		last := c.page[len(c.page)-1]
+		cursor := encodeCursor(last.Cursor())
+		p.endCursor = &cursor
+	}
+	return p
+}
+
+// TotalCount counts the transactions matching the filter, only when queried.
+func (c *connectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := loadersFrom(ctx).lister.Count(ctx, c.filter)
+	if err != nil {
+		return 0, err
+	}
+	return int32(count), nil
+}
+
+type edgeResolver struct {
+	trs core.Transaction
+}
+
+func (e *edgeResolver) Cursor() string {
+	return encodeCursor(e.trs.Cursor())
+}
+
+func (e *edgeResolver) Node() *transactionResolver {
+	return &transactionResolver{trs: e.trs}
+}
+
+type pageInfoResolver struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (p *pageInfoResolver) HasNextPage() bool {
+	return p.hasNextPage
+}
+
+func (p *pageInfoResolver) EndCursor() *string {
+	return p.endCursor
+}
+
+// encodeCursor makes an opaque cursor of the position of a transaction, its date and ID.
+func encodeCursor(c core.Cursor) string {
+	position := c.Date.UTC().Format(time.RFC3339Nano) + cursorSeparator + strconv.Itoa(c.ID)
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + position))
+}
+
+func decodeCursor(cursor string) (core.Cursor, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), cursorPrefix) {
+		return core.Cursor{}, errors.Errorf("invalid cursor %s", cursor)
+	}
+
+	date, id, ok := strings.Cut(strings.TrimPrefix(string(decoded), cursorPrefix), cursorSeparator)
+	if !ok {
+		return core.Cursor{}, errors.Errorf("invalid cursor %s", cursor)
+	}
+
+	c := core.Cursor{}
+	if c.Date, err = time.Parse(time.RFC3339Nano, date); err != nil {
+		return core.Cursor{}, errors.Errorf("invalid cursor %s", cursor)
+	}
+	if c.ID, err = strconv.Atoi(id); err != nil || c.ID <= 0 {
+		return core.Cursor{}, errors.Errorf("invalid cursor %s", cursor)
+	}
+	return c, nil
+}
+
+type transactionResolver struct {
+	trs core.Transaction
+}
+
+func (t *transactionResolver) ID() graphqllib.ID {
+	return graphqllib.ID(strconv.Itoa(t.trs.ID))
+}
+
+func (t *transactionResolver) Amount() int32 {
+	return int32(t.trs.Amount)
+}
+
+func (t *transactionResolver) Type() string {
+	return transactionTypes[t.trs.Type]
+}
+
+func (t *transactionResolver) Category() *categoryResolver {
+	return &categoryResolver{category: t.trs.Category}
+}
+
+func (t *transactionResolver) Date() graphqllib.Time {
+	return graphqllib.Time{Time: t.trs.Date}
+}
+
+func (t *transactionResolver) Name() string {
+	return t.trs.Name
+}
+
+func (t *transactionResolver) Version() int32 {
+	return int32(t.trs.Version)
+}
+
+type categoryResolver struct {
+	category core.Category
+}
+
+func (c *categoryResolver) Name() string {
+	return c.category.Name
+}
+
+// Totals loads the totals of the category along with those of the other categories of the request.
+func (c *categoryResolver) Totals(ctx context.Context) (*totalsResolver, error) {
+	totals, err := loadersFrom(ctx).categoryTotals(ctx, c.category.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &totalsResolver{totals: totals}, nil
+}
+
+// Transactions lists a page of the transactions of the category.
+func (c *categoryResolver) Transactions(ctx context.Context, args pageArgs) (*connectionResolver, error) {
+	return newConnection(ctx, core.Filter{Category: c.category.Name}, args)
+}
+
+type totalsResolver struct {
+	totals core.Totals
+}
+
+func (t *totalsResolver) Debit() int32 {
+	return int32(t.totals.Debit)
+}
+
+func (t *totalsResolver) Credit() int32 {
+	return int32(t.totals.Credit)
+}
+
+func (t *totalsResolver) Income() int32 {
+	return int32(t.totals.Income)
+}
+
+func (t *totalsResolver) Count() int32 {
+	return int32(t.totals.Count)
+}
+
+type summaryResolver struct {
+	summary core.MonthlySummary
+}
+
+func (s *summaryResolver) Month() string {
+	return s.summary.Month.Format(monthLayout)
+}
+
+func (s *summaryResolver) Totals() *totalsResolver {
+	return &totalsResolver{totals: s.summary.Totals}
+}
+
+func (s *summaryResolver) CreditDue() int32 {
+	return int32(s.summary.CreditDue)
+}
+
+func (s *summaryResolver) Balance() int32 {
+	return int32(s.summary.Balance)
+}
+
+func (s *summaryResolver) Categories() []*categoryTotalsResolver {
+	res := make([]*categoryTotalsResolver, 0, len(s.summary.Categories))
+	for _, ct := range s.summary.Categories {
+		res = append(res, &categoryTotalsResolver{categoryTotals: ct})
+	}
+	return res
+}
+
+type categoryTotalsResolver struct {
+	categoryTotals core.CategoryTotals
+}
+
+func (c *categoryTotalsResolver) Category() *categoryResolver {
+	return &categoryResolver{category: c.categoryTotals.Category}
+}
+
+func (c *categoryTotalsResolver) Totals() *totalsResolver {
+	return &totalsResolver{totals: c.categoryTotals.Totals}
+}
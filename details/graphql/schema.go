@@ -0,0 +1,103 @@
+// Package graphql serves the transactions, categories and monthly summaries over GraphQL, alongside the REST API.
+package graphql
+
+// schema describes what can be queried, the transactions being ordered by date then ID.
+const schema = `
+schema {
+	query: Query
+}
+
+scalar Time
+
+type Query {
+	# transactions lists the transactions matching the filter, first at a time, after the cursor of the previous page.
+	transactions(filter: TransactionFilter, first: Int = 20, after: String): TransactionConnection!
+
+	# transaction gets a transaction by its ID, null when it does not exist.
+	transaction(id: ID!): Transaction
+
+	# categories lists the categories, ordered by name.
+	categories: [Category!]!
+
+	# category gets a category by its name, null when it does not exist.
+	category(name: String!): Category
+
+	# summary totals the transactions of a month, eg: 2019-10, the credit being due the next month.
+	summary(month: String!): MonthlySummary!
+}
+
+enum TransactionType {
+	DEBIT
+	CREDIT
+	INCOME
+}
+
+# TransactionFilter narrows the transactions, from and to bounding their date inclusively.
+input TransactionFilter {
+	category: String
+	type: TransactionType
+	from: Time
+	to: Time
+}
+
+type Transaction {
+	id: ID!
+	"The amount in cents, eg: 2650 is 26.50."
+	amount: Int!
+	type: TransactionType!
+	category: Category!
+	date: Time!
+	name: String!
+	version: Int!
+}
+
+type TransactionConnection {
+	edges: [TransactionEdge!]!
+	pageInfo: PageInfo!
+	totalCount: Int!
+}
+
+type TransactionEdge {
+	cursor: String!
+	node: Transaction!
+}
+
+type PageInfo {
+	hasNextPage: Boolean!
+	endCursor: String
+}
+
+type Category {
+	name: String!
+
+	# totals sums every transaction of the category.
+	totals: Totals!
+	transactions(first: Int = 20, after: String): TransactionConnection!
+}
+
+type Totals {
+	debit: Int!
+	credit: Int!
+	income: Int!
+	count: Int!
+}
+
+type MonthlySummary {
+	# month is formatted as YYYY-MM.
+	month: String!
+	totals: Totals!
+
+	# creditDue is the credit of the previous month, which is due in this one.
+	creditDue: Int!
+
+	# balance is the income minus the debit and the credit due.
+	balance: Int!
+	categories: [CategoryTotals!]!
+}
+
+# CategoryTotals sums the transactions of a category in a month.
+type CategoryTotals {
+	category: Category!
+	totals: Totals!
+}
+`
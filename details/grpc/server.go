@@ -0,0 +1,104 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"time"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/gritt/maskada/details/grpc/maskadapb"
+	"github.com/gritt/maskada/details/logging"
+)
+
+// RequestIDMetadata holds the request ID, set by the client or generated by the API, as the X-Request-ID header does.
+const RequestIDMetadata = "x-request-id"
+
+// NewServer initialize a gRPC server of the service, logging each call, served over TLS when tlsConfig is given.
+// The server reflection is registered as well, so the service can be explored, eg: with grpcurl.
+func NewServer(service *Service, tlsConfig *tls.Config) *grpclib.Server {
+	opts := []grpclib.ServerOption{
+		grpclib.ChainUnaryInterceptor(logUnary),
+		grpclib.ChainStreamInterceptor(logStream),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpclib.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s := grpclib.NewServer(opts...)
+	maskadapb.RegisterTransactionServiceServer(s, service)
+	reflection.Register(s)
+	return s
+}
+
+// logUnary tags a call with a request ID, in its context and header, and logs it once handled.
+func logUnary(ctx context.Context, req interface{}, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+
+	ctx, id := tag(ctx)
+	_ = grpclib.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, id))
+
+	res, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, err)
+	return res, err
+}
+
+// logStream tags a streaming call with a request ID, in its context and header, and logs it once handled.
+func logStream(srv interface{}, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+	start := time.Now()
+
+	ctx, id := tag(ss.Context())
+	_ = ss.SetHeader(metadata.Pairs(RequestIDMetadata, id))
+
+	err := handler(srv, &taggedStream{ServerStream: ss, ctx: ctx})
+	logCall(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// tag adds to ctx the request ID sent by the client, or a generated one.
+func tag(ctx context.Context) (context.Context, string) {
+	sent := ""
+	if ids := metadata.ValueFromIncomingContext(ctx, RequestIDMetadata); len(ids) > 0 {
+		sent = ids[0]
+	}
+
+	id := logging.AcceptRequestID(sent)
+	return logging.WithRequestID(ctx, id), id
+}
+
+// logCall logs a handled call, eg: method, code and duration, as a warning when the client is at fault.
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+
+	level := slog.LevelInfo
+	attrs := []interface{}{
+		"method", method,
+		"code", code.String(),
+		"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		level = slog.LevelWarn
+		if code == codes.Internal || code == codes.Unknown {
+			level = slog.LevelError
+		}
+		attrs = append(attrs, "error", status.Convert(err).Message())
+	}
+
+	slog.Log(ctx, level, "call handled", attrs...)
+}
+
+// taggedStream overrides the context of a stream with the tagged one.
+type taggedStream struct {
+	grpclib.ServerStream
+	ctx context.Context
+}
+
+func (s *taggedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,117 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/grpc/maskadapb"
+	"github.com/gritt/maskada/details/logging"
+)
+
+func TestNewServer(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when a call is handled": func(t *testing.T) {
+			// arrange
+			logs := captureDefault(t)
+
+			l := new(mockTransactionLister)
+			l.On("List").Return([]core.Transaction{}, nil)
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), RequestIDMetadata, "client-id")
+			var header metadata.MD
+
+			// act
+			_, gotErr := dial(t, NewService(nil, l)).ListTransactions(ctx, &maskadapb.ListTransactionsRequest{}, grpclib.Header(&header))
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []string{"client-id"}, header.Get(RequestIDMetadata))
+
+			record := decode(t, logs)
+			assert.Equal(t, "INFO", record["level"])
+			assert.Equal(t, "call handled", record["msg"])
+			assert.Equal(t, maskadapb.TransactionService_ListTransactions_FullMethodName, record["method"])
+			assert.Equal(t, "OK", record["code"])
+			assert.Equal(t, "client-id", record["request_id"])
+		},
+		"when a call fails": func(t *testing.T) {
+			// arrange
+			logs := captureDefault(t)
+
+			l := new(mockTransactionLister)
+			l.On("List").Return([]core.Transaction{}, errors.New("List failed: connection refused"))
+
+			var header metadata.MD
+
+			// act
+			_, gotErr := dial(t, NewService(nil, l)).ListTransactions(context.Background(), &maskadapb.ListTransactionsRequest{}, grpclib.Header(&header))
+
+			// assert
+			assert.Error(t, gotErr)
+			assert.Len(t, header.Get(RequestIDMetadata), 1)
+
+			record := decode(t, logs)
+			assert.Equal(t, "ERROR", record["level"])
+			assert.Equal(t, "Internal", record["code"])
+			assert.Equal(t, "List failed: connection refused", record["error"])
+			assert.Equal(t, header.Get(RequestIDMetadata)[0], record["request_id"])
+		},
+		"when a streaming call is handled": func(t *testing.T) {
+			// arrange
+			logs := captureDefault(t)
+
+			l := new(mockTransactionLister)
+			l.On("ListPage", core.Filter{}, core.Cursor{}, streamPageSize).Return([]core.Transaction{testCreatedTrs}, nil)
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), RequestIDMetadata, "client-id")
+
+			// act
+			stream, _ := dial(t, NewService(nil, l)).StreamTransactions(ctx, &maskadapb.StreamTransactionsRequest{})
+			for {
+				if _, err := stream.Recv(); err != nil {
+					break
+				}
+			}
+
+			// assert
+			header, gotErr := stream.Header()
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []string{"client-id"}, header.Get(RequestIDMetadata))
+
+			record := decode(t, logs)
+			assert.Equal(t, maskadapb.TransactionService_StreamTransactions_FullMethodName, record["method"])
+			assert.Equal(t, "client-id", record["request_id"])
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+// captureDefault swaps the default logger for one writing to the returned buffer, for the duration of the test.
+func captureDefault(t *testing.T) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	previous := slog.Default()
+	slog.SetDefault(logging.NewLogger(buf, "debug"))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return buf
+}
+
+// decode returns the single JSON record written to buf.
+func decode(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
@@ -0,0 +1,156 @@
+// Package grpc serves the transaction use cases over gRPC, alongside the REST API, as defined by maskadapb.
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/grpc/maskadapb"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative maskadapb/transaction.proto
+
+const (
+	// ActorMetadata identifies who is performing a change, recorded in the audit log.
+	ActorMetadata = "x-actor"
+
+	anonymousActor = "anonymous"
+
+	// streamPageSize is how many transactions StreamTransactions holds in memory at once.
+	streamPageSize = 100
+)
+
+type (
+	// TransactionCreator represents a use case able to create a transaction.
+	TransactionCreator interface {
+		Create(ctx context.Context, t core.Transaction, actor string) (core.Transaction, error)
+	}
+
+	// TransactionLister represents a use case able to list transactions.
+	TransactionLister interface {
+		List(ctx context.Context) ([]core.Transaction, error)
+		ListPage(ctx context.Context, f core.Filter, after core.Cursor, limit int) ([]core.Transaction, error)
+	}
+)
+
+// Service implements the TransactionService of maskadapb with the use cases.
+type Service struct {
+	maskadapb.UnimplementedTransactionServiceServer
+
+	TransactionCreator TransactionCreator
+	TransactionLister  TransactionLister
+	StreamPageSize     int
+}
+
+// NewService initialize the service.
+func NewService(creator TransactionCreator, lister TransactionLister) *Service {
+	return &Service{
+		TransactionCreator: creator,
+		TransactionLister:  lister,
+		StreamPageSize:     streamPageSize,
+	}
+}
+
+// CreateTransaction calls the use case to create a transaction.
+func (s *Service) CreateTransaction(ctx context.Context, req *maskadapb.CreateTransactionRequest) (*maskadapb.Transaction, error) {
+	var date time.Time
+	if req.GetDate() != nil {
+		if err := req.GetDate().CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, errors.Wrap(err, "CreateTransaction failed: invalid date").Error())
+		}
+		date = req.GetDate().AsTime()
+	}
+
+	trs, err := s.TransactionCreator.Create(ctx, core.Transaction{
+		Amount:   int(req.GetAmount()),
+		Type:     int(req.GetType()),
+		Category: core.Category{Name: req.GetCategory()},
+		Date:     date,
+		Name:     req.GetName(),
+	}, actor(ctx))
+	if err != nil {
+		return nil, statusOf(err)
+	}
+
+	return newTransaction(trs), nil
+}
+
+// ListTransactions calls the use case to list transactions, responding them all at once.
+func (s *Service) ListTransactions(ctx context.Context, _ *maskadapb.ListTransactionsRequest) (*maskadapb.ListTransactionsResponse, error) {
+	trsl, err := s.TransactionLister.List(ctx)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+
+	res := &maskadapb.ListTransactionsResponse{Transactions: make([]*maskadapb.Transaction, 0, len(trsl))}
+	for _, trs := range trsl {
+		res.Transactions = append(res.Transactions, newTransaction(trs))
+	}
+	return res, nil
+}
+
+// StreamTransactions calls the use case to list transactions a page at a time, sending them one by one.
+func (s *Service) StreamTransactions(_ *maskadapb.StreamTransactionsRequest, stream maskadapb.TransactionService_StreamTransactionsServer) error {
+	var after core.Cursor
+	for {
+		page, err := s.TransactionLister.ListPage(stream.Context(), core.Filter{}, after, s.StreamPageSize)
+		if err != nil {
+			return statusOf(err)
+		}
+
+		for _, trs := range page {
+			if err := stream.Send(newTransaction(trs)); err != nil {
+				return err
+			}
+		}
+		if len(page) < s.StreamPageSize {
+			return nil
+		}
+		after = page[len(page)-1].Cursor()
+	}
+}
+
+func newTransaction(trs core.Transaction) *maskadapb.Transaction {
+	return &maskadapb.Transaction{
+		Id:       int64(trs.ID),
+		Amount:   int64(trs.Amount),
+		Type:     maskadapb.TransactionType(trs.Type),
+		Category: trs.Category.Name,
+		Date:     timestamppb.New(trs.Date),
+		Name:     trs.Name,
+		Version:  int64(trs.Version),
+	}
+}
+
+// statusOf maps the cause of a use case error to a gRPC status, as the REST API maps it to a status code.
+func statusOf(err error) error {
+	code := codes.Internal
+	switch errors.Cause(err) {
+	case core.ErrInvalidTransaction:
+		code = codes.InvalidArgument
+	case core.ErrNotFound:
+		code = codes.NotFound
+	case core.ErrStaleVersion:
+		code = codes.Aborted
+	case context.DeadlineExceeded:
+		code = codes.DeadlineExceeded
+	case context.Canceled:
+		code = codes.Canceled
+	}
+	return status.Error(code, err.Error())
+}
+
+// actor identifies who performs the call, anonymous unless the ActorMetadata is given.
+func actor(ctx context.Context) string {
+	if names := metadata.ValueFromIncomingContext(ctx, ActorMetadata); len(names) > 0 && names[0] != "" {
+		return names[0]
+	}
+	return anonymousActor
+}
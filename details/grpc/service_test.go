@@ -0,0 +1,323 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/grpc/maskadapb"
+)
+
+var (
+	testDate = time.Date(2019, 10, 25, 0, 26, 57, 0, time.UTC)
+
+	testTrs = core.Transaction{
+		Amount:   26,
+		Type:     core.Credit,
+		Category: core.Category{Name: "Food"},
+		Name:     "Family Flavor",
+	}
+
+	testCreatedTrs = core.Transaction{
+		ID:       11,
+		Amount:   26,
+		Type:     core.Credit,
+		Category: core.Category{Name: "Food"},
+		Date:     testDate,
+		Name:     "Family Flavor",
+		Version:  1,
+	}
+
+	testPbTrs = &maskadapb.Transaction{
+		Id:       11,
+		Amount:   26,
+		Type:     maskadapb.TransactionType_TRANSACTION_TYPE_CREDIT,
+		Category: "Food",
+		Date:     timestamppb.New(testDate),
+		Name:     "Family Flavor",
+		Version:  1,
+	}
+)
+
+func TestService_CreateTransaction(t *testing.T) {
+	req := &maskadapb.CreateTransactionRequest{
+		Amount:   26,
+		Type:     maskadapb.TransactionType_TRANSACTION_TYPE_CREDIT,
+		Category: "Food",
+		Name:     "Family Flavor",
+	}
+
+	tests := map[string]func(*testing.T){
+		"when the transaction is created": func(t *testing.T) {
+			// arrange
+			c := new(mockTransactionCreator)
+			c.On("Create", testTrs, "anonymous").Return(testCreatedTrs, nil)
+
+			// act
+			got, gotErr := dial(t, NewService(c, nil)).CreateTransaction(context.Background(), req)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.True(t, proto.Equal(testPbTrs, got), "got %v", got)
+			c.AssertExpectations(t)
+		},
+		"when the date and actor are given": func(t *testing.T) {
+			// arrange
+			trs := testTrs
+			trs.Date = testDate
+
+			c := new(mockTransactionCreator)
+			c.On("Create", trs, "gritt").Return(testCreatedTrs, nil)
+
+			dated := &maskadapb.CreateTransactionRequest{
+				Amount:   req.Amount,
+				Type:     req.Type,
+				Category: req.Category,
+				Date:     timestamppb.New(testDate),
+				Name:     req.Name,
+			}
+			ctx := metadata.AppendToOutgoingContext(context.Background(), ActorMetadata, "gritt")
+
+			// act
+			_, gotErr := dial(t, NewService(c, nil)).CreateTransaction(ctx, dated)
+
+			// assert
+			assert.NoError(t, gotErr)
+			c.AssertExpectations(t)
+		},
+		"when the date is invalid": func(t *testing.T) {
+			// arrange
+			c := new(mockTransactionCreator)
+
+			invalid := &maskadapb.CreateTransactionRequest{Date: &timestamppb.Timestamp{Nanos: -1}}
+
+			// act
+			_, gotErr := dial(t, NewService(c, nil)).CreateTransaction(context.Background(), invalid)
+
+			// assert
+			assert.Equal(t, codes.InvalidArgument, status.Code(gotErr))
+			c.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		},
+		"when the transaction is invalid": func(t *testing.T) {
+			// arrange
+			invalid := core.Transaction{Category: core.Category{Name: "Food"}}
+			c := new(mockTransactionCreator)
+			c.On("Create", invalid, "anonymous").Return(core.Transaction{}, errors.Wrap(invalid.Validate(), "Create failed"))
+
+			// act
+			_, gotErr := dial(t, NewService(c, nil)).CreateTransaction(context.Background(),
+				&maskadapb.CreateTransactionRequest{Category: "Food"})
+
+			// assert
+			assert.Equal(t, codes.InvalidArgument, status.Code(gotErr))
+			assert.Equal(t, "Create failed: Transaction.Validate: invalid amount", status.Convert(gotErr).Message())
+		},
+		"when the use case fails": func(t *testing.T) {
+			// arrange
+			c := new(mockTransactionCreator)
+			c.On("Create", testTrs, "anonymous").Return(core.Transaction{}, errors.New("Create failed: connection refused"))
+
+			// act
+			_, gotErr := dial(t, NewService(c, nil)).CreateTransaction(context.Background(), req)
+
+			// assert
+			assert.Equal(t, codes.Internal, status.Code(gotErr))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestService_ListTransactions(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when transactions are listed": func(t *testing.T) {
+			// arrange
+			l := new(mockTransactionLister)
+			l.On("List").Return([]core.Transaction{testCreatedTrs}, nil)
+
+			// act
+			got, gotErr := dial(t, NewService(nil, l)).ListTransactions(context.Background(), &maskadapb.ListTransactionsRequest{})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Len(t, got.Transactions, 1)
+			assert.True(t, proto.Equal(testPbTrs, got.Transactions[0]), "got %v", got.Transactions[0])
+		},
+		"when the use case fails": func(t *testing.T) {
+			// arrange
+			l := new(mockTransactionLister)
+			l.On("List").Return([]core.Transaction{}, errors.Wrap(context.DeadlineExceeded, "List failed"))
+
+			// act
+			_, gotErr := dial(t, NewService(nil, l)).ListTransactions(context.Background(), &maskadapb.ListTransactionsRequest{})
+
+			// assert
+			assert.Equal(t, codes.DeadlineExceeded, status.Code(gotErr))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestService_StreamTransactions(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when transactions are streamed a page at a time": func(t *testing.T) {
+			// arrange
+			second := testCreatedTrs
+			second.ID = 12
+			third := testCreatedTrs
+			third.ID = 13
+
+			l := new(mockTransactionLister)
+			l.On("ListPage", core.Filter{}, core.Cursor{}, 2).Return([]core.Transaction{testCreatedTrs, second}, nil)
+			l.On("ListPage", core.Filter{}, second.Cursor(), 2).Return([]core.Transaction{third}, nil)
+
+			s := NewService(nil, l)
+			s.StreamPageSize = 2
+
+			// act
+			stream, gotErr := dial(t, s).StreamTransactions(context.Background(), &maskadapb.StreamTransactionsRequest{})
+
+			// assert
+			assert.NoError(t, gotErr)
+
+			var ids []int64
+			for {
+				trs, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				assert.NoError(t, err)
+				ids = append(ids, trs.Id)
+			}
+			assert.Equal(t, []int64{11, 12, 13}, ids)
+			l.AssertExpectations(t)
+		},
+		"when the last page is full, stop at the next empty one": func(t *testing.T) {
+			// arrange
+			l := new(mockTransactionLister)
+			l.On("ListPage", core.Filter{}, core.Cursor{}, 1).Return([]core.Transaction{testCreatedTrs}, nil)
+			l.On("ListPage", core.Filter{}, testCreatedTrs.Cursor(), 1).Return([]core.Transaction{}, nil)
+
+			s := NewService(nil, l)
+			s.StreamPageSize = 1
+
+			// act
+			stream, _ := dial(t, s).StreamTransactions(context.Background(), &maskadapb.StreamTransactionsRequest{})
+			first, firstErr := stream.Recv()
+			_, lastErr := stream.Recv()
+
+			// assert
+			assert.NoError(t, firstErr)
+			assert.Equal(t, int64(11), first.Id)
+			assert.Equal(t, io.EOF, lastErr)
+			l.AssertExpectations(t)
+		},
+		"when the use case fails": func(t *testing.T) {
+			// arrange
+			l := new(mockTransactionLister)
+			l.On("ListPage", core.Filter{}, core.Cursor{}, streamPageSize).Return([]core.Transaction{}, errors.New("ListPage failed: connection refused"))
+
+			// act
+			stream, _ := dial(t, NewService(nil, l)).StreamTransactions(context.Background(), &maskadapb.StreamTransactionsRequest{})
+			_, gotErr := stream.Recv()
+
+			// assert
+			assert.Equal(t, codes.Internal, status.Code(gotErr))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestStatusOf(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want codes.Code
+	}{
+		"when the transaction is not found": {err: errors.Wrap(core.ErrNotFound, "Get failed"), want: codes.NotFound},
+		"when the version is stale":         {err: errors.Wrap(core.ErrStaleVersion, "Update failed"), want: codes.Aborted},
+		"when the call is canceled":         {err: errors.Wrap(context.Canceled, "List failed"), want: codes.Canceled},
+		"when the cause is unknown":         {err: errors.New("List failed"), want: codes.Internal},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := statusOf(tt.err)
+
+			assert.Equal(t, tt.want, status.Code(got))
+			assert.Equal(t, tt.err.Error(), status.Convert(got).Message())
+		})
+	}
+}
+
+// dial serves the service in memory, returning a client of it.
+func dial(t *testing.T, service *Service) maskadapb.TransactionServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(service, nil)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpclib.NewClient("passthrough:///bufnet",
+		grpclib.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpclib.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to: dial the server: %s", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return maskadapb.NewTransactionServiceClient(conn)
+}
+
+type mockTransactionCreator struct {
+	mock.Mock
+}
+
+func (m *mockTransactionCreator) Create(_ context.Context, t core.Transaction, actor string) (core.Transaction, error) {
+	args := m.Called(t, actor)
+	return args.Get(0).(core.Transaction), args.Error(1)
+}
+
+type mockTransactionLister struct {
+	mock.Mock
+}
+
+func (m *mockTransactionLister) List(_ context.Context) ([]core.Transaction, error) {
+	args := m.Called()
+	return args.Get(0).([]core.Transaction), args.Error(1)
+}
+
+func (m *mockTransactionLister) ListPage(_ context.Context, f core.Filter, after core.Cursor, limit int) ([]core.Transaction, error) {
+	args := m.Called(f, after, limit)
+	return args.Get(0).([]core.Transaction), args.Error(1)
+}
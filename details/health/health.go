@@ -0,0 +1,109 @@
+// Package health tells whether the API is able to serve requests, and waits for its database on start.
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/details/migrate"
+)
+
+const (
+	// firstBackoff is how long the first failed connection is waited for before retrying, doubled on each failure.
+	firstBackoff = 100 * time.Millisecond
+
+	// maxBackoff caps how long a failed connection is waited for before retrying.
+	maxBackoff = 5 * time.Second
+)
+
+type (
+	// Pinger represents a client able to tell whether its database is reachable.
+	Pinger interface {
+		Ping(ctx context.Context) error
+	}
+
+	// Checker tells whether the database is reachable and its schema current,
+	// the memory storage backend having neither a database nor a schema.
+	Checker struct {
+		pinger   Pinger
+		migrator *migrate.Migrator
+	}
+)
+
+// NewChecker initialize the checker, pinger and migrator are nil for the memory storage backend.
+func NewChecker(pinger Pinger, migrator *migrate.Migrator) *Checker {
+	return &Checker{pinger: pinger, migrator: migrator}
+}
+
+// Ready checks the database is reachable and no migration is pending.
+func (c *Checker) Ready(ctx context.Context) error {
+	if c.pinger != nil {
+		if err := c.pinger.Ping(ctx); err != nil {
+			return errors.Wrap(err, "Checker.Ready failed")
+		}
+	}
+
+	if c.migrator != nil {
+		pending, err := c.migrator.Pending(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Checker.Ready failed")
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("Checker.Ready failed: migration %d %s is pending", pending[0].Version, pending[0].Name)
+		}
+	}
+
+	return nil
+}
+
+// SchemaVersion finds the newest applied migration version, 0 without a schema.
+func (c *Checker) SchemaVersion(ctx context.Context) (int, error) {
+	if c.migrator == nil {
+		return 0, nil
+	}
+
+	version, err := c.migrator.Version(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "Checker.SchemaVersion failed")
+	}
+
+	return version, nil
+}
+
+// Wait pings until the database is reachable, retrying with an exponential backoff for up to timeout,
+// so it fails on the first attempt when timeout is 0, or until ctx is done.
+func Wait(ctx context.Context, p Pinger, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	backoff := firstBackoff
+
+	for {
+		err := p.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return errors.Wrap(err, "database unreachable")
+		}
+
+		if backoff > remaining {
+			backoff = remaining
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Wrap(ctx.Err(), "database unreachable")
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
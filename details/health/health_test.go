@@ -0,0 +1,175 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/details/migrate"
+
+	// imports sqlite db driver
+	_ "modernc.org/sqlite"
+)
+
+func TestChecker(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_category.up.sql":   {Data: []byte(`CREATE TABLE category (name VARCHAR(80) NOT NULL PRIMARY KEY);`)},
+		"0001_create_category.down.sql": {Data: []byte(`DROP TABLE category;`)},
+		"0002_add_color.up.sql":         {Data: []byte(`ALTER TABLE category ADD COLUMN color VARCHAR(7) NULL;`)},
+		"0002_add_color.down.sql":       {Data: []byte(`ALTER TABLE category DROP COLUMN color;`)},
+	}
+
+	tests := map[string]func(t *testing.T, m *migrate.Migrator){
+		"when migrated, it is ready": func(t *testing.T, m *migrate.Migrator) {
+			// arrange
+			if _, err := m.Up(); err != nil {
+				t.Fatalf("Up failed: %s", err)
+			}
+			c := NewChecker(&mockPinger{}, m)
+
+			// act
+			gotErr := c.Ready(context.Background())
+			gotVersion, gotVersionErr := c.SchemaVersion(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.NoError(t, gotVersionErr)
+			assert.Equal(t, 2, gotVersion)
+		},
+		"when a migration is pending, it is not ready": func(t *testing.T, m *migrate.Migrator) {
+			// arrange
+			if _, err := m.Up(); err != nil {
+				t.Fatalf("Up failed: %s", err)
+			}
+			if _, err := m.Down(1); err != nil {
+				t.Fatalf("Down failed: %s", err)
+			}
+			c := NewChecker(&mockPinger{}, m)
+
+			// act
+			gotErr := c.Ready(context.Background())
+
+			// assert
+			assert.EqualError(t, gotErr, "Checker.Ready failed: migration 2 add_color is pending")
+		},
+		"when the database is unreachable, it is not ready": func(t *testing.T, m *migrate.Migrator) {
+			// arrange
+			c := NewChecker(&mockPinger{failures: 1}, m)
+
+			// act
+			gotErr := c.Ready(context.Background())
+
+			// assert
+			assert.EqualError(t, gotErr, "Checker.Ready failed: connection refused")
+		},
+		"when there is no database, it is ready": func(t *testing.T, m *migrate.Migrator) {
+			// arrange
+			c := NewChecker(nil, nil)
+
+			// act
+			gotErr := c.Ready(context.Background())
+			gotVersion, gotVersionErr := c.SchemaVersion(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.NoError(t, gotVersionErr)
+			assert.Equal(t, 0, gotVersion)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, err := sqlx.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "health.db"))
+			if err != nil {
+				t.Fatalf("Open failed: %s", err)
+			}
+			defer db.Close()
+
+			m, err := migrate.NewMigrator(db, fsys, migrate.NoLock{})
+			if err != nil {
+				t.Fatalf("NewMigrator failed: %s", err)
+			}
+
+			run(t, m)
+		})
+	}
+}
+
+func TestWait(t *testing.T) {
+	tests := map[string]struct {
+		pinger    *mockPinger
+		timeout   time.Duration
+		canceled  bool
+		wantPings int
+		wantErr   string
+	}{
+		"when reachable, ping once": {
+			pinger:    &mockPinger{},
+			timeout:   time.Second,
+			wantPings: 1,
+		},
+		"when reachable after failing, retry": {
+			pinger:    &mockPinger{failures: 2},
+			timeout:   time.Second,
+			wantPings: 3,
+		},
+		"when failing fast, ping once": {
+			pinger:    &mockPinger{failures: 1},
+			wantPings: 1,
+			wantErr:   "database unreachable: connection refused",
+		},
+		"when unreachable until the timeout": {
+			pinger:    &mockPinger{failures: 100},
+			timeout:   150 * time.Millisecond,
+			wantPings: 3,
+			wantErr:   "database unreachable: connection refused",
+		},
+		"when canceled, stop retrying": {
+			pinger:    &mockPinger{failures: 100},
+			timeout:   time.Second,
+			canceled:  true,
+			wantPings: 1,
+			wantErr:   "database unreachable: context canceled",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.canceled {
+				cancel()
+			}
+
+			// act
+			gotErr := Wait(ctx, tt.pinger, tt.timeout)
+
+			// assert
+			if tt.wantErr == "" {
+				assert.NoError(t, gotErr)
+			} else {
+				assert.EqualError(t, gotErr, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantPings, tt.pinger.pings)
+		})
+	}
+}
+
+type mockPinger struct {
+	failures, pings int
+}
+
+func (m *mockPinger) Ping(context.Context) error {
+	m.pings++
+	if m.pings <= m.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
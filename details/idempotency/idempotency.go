@@ -0,0 +1,175 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/recorder"
+)
+
+const (
+	// Header is the request header holding the client generated idempotency key.
+	Header = "Idempotency-Key"
+
+	// ReplayedHeader is set in responses replayed from a previous request.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	// ActorHeader identifies who is performing the request, its keys being scoped by it, so clients choosing the same
+	// key do not collide. The requests without it share the scope of the anonymous actor.
+	ActorHeader = "X-Actor"
+
+	maxKeyLength = 255
+	// maxActorLength is the length of the actor columns, which the actor is saved in along with its keys.
+	maxActorLength = 80
+)
+
+type (
+	// Record is a request handled under an idempotency key of an actor, along with its response once completed.
+	Record struct {
+		Actor       string
+		Key         string
+		RequestHash string
+		Status      int
+		Body        []byte
+		ExpiresAt   time.Time
+	}
+
+	// Store represents a client able to reserve, complete, release and sweep idempotency keys.
+	Store interface {
+		// Reserve saves a pending record, unless an unexpired one exists for the same actor and key, which is
+		// returned instead.
+		Reserve(ctx context.Context, rec Record) (existing Record, reserved bool, err error)
+		Complete(ctx context.Context, rec Record) error
+		Release(ctx context.Context, rec Record) error
+		// Sweep removes the records expired at now, which Reserve ignores already.
+		Sweep(ctx context.Context, now time.Time) error
+	}
+
+	// Middleware replays the response of a request retried with the same idempotency key.
+	Middleware struct {
+		store Store
+		ttl   time.Duration
+	}
+)
+
+// NewMiddleware initialize the middleware.
+func NewMiddleware(store Store, cfg *details.Config) *Middleware {
+	return &Middleware{store: store, ttl: cfg.Idempotency.TTL}
+}
+
+// Handler wraps next, so requests carrying an idempotency key are handled at most once.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxKeyLength {
+			respond(w, `{"error": "Idempotency failed: invalid key"}`, http.StatusBadRequest)
+			return
+		}
+
+		actor := r.Header.Get(ActorHeader)
+		if len(actor) > maxActorLength {
+			respond(w, `{"error": "Idempotency failed: invalid actor"}`, http.StatusBadRequest)
+			return
+		}
+
+		var body []byte
+		if r.Body != nil {
+			b, err := ioutil.ReadAll(r.Body)
+			_ = r.Body.Close()
+			if err != nil {
+				respond(w, `{"error": "Idempotency failed: could not read body"}`, http.StatusBadRequest)
+				return
+			}
+			body = b
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		rec := Record{
+			Actor:       actor,
+			Key:         key,
+			RequestHash: hash(r, body),
+			ExpiresAt:   time.Now().UTC().Add(m.ttl),
+		}
+
+		existing, reserved, err := m.store.Reserve(r.Context(), rec)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "request failed", "error", err.Error(), "status", http.StatusInternalServerError)
+			msg, _ := json.Marshal(err.Error())
+			respond(w, `{"error": `+string(msg)+`}`, http.StatusInternalServerError)
+			return
+		}
+
+		if !reserved {
+			replay(w, rec, existing)
+			return
+		}
+
+		rr := recorder.WithBody(w)
+		next.ServeHTTP(rr, r)
+
+		// the key is completed or released even when the client is gone, rather than left reserved until it expires
+		done := context.WithoutCancel(r.Context())
+
+		// server errors are not replayed, so the client can retry them
+		if rr.Status() >= http.StatusInternalServerError {
+			m.release(done, rec)
+			return
+		}
+
+		rec.Status = rr.Status()
+		rec.Body = rr.Body()
+		if err := m.store.Complete(done, rec); err != nil {
+			// a key left reserved would be in progress until it expires, so it is released to be retried instead
+			slog.ErrorContext(done, "completing the idempotency key failed", "error", err.Error())
+			m.release(done, rec)
+		}
+	})
+}
+
+// release the key of the record, logging when it fails, as the response is sent already.
+func (m *Middleware) release(ctx context.Context, rec Record) {
+	if err := m.store.Release(ctx, rec); err != nil {
+		slog.ErrorContext(ctx, "releasing the idempotency key failed", "error", err.Error())
+	}
+}
+
+func replay(w http.ResponseWriter, rec, existing Record) {
+	if existing.RequestHash != rec.RequestHash {
+		respond(w, `{"error": "Idempotency failed: key already used with a different request"}`, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if existing.Status == 0 {
+		respond(w, `{"error": "Idempotency failed: request with the same key in progress"}`, http.StatusConflict)
+		return
+	}
+
+	w.Header().Set(ReplayedHeader, "true")
+	respond(w, string(existing.Body), existing.Status)
+}
+
+func hash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	_, _ = h.Write([]byte(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + "\n"))
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func respond(w http.ResponseWriter, msg string, status int) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(msg))
+}
@@ -0,0 +1,290 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/test"
+)
+
+func TestMiddleware_Handler(t *testing.T) {
+	key := test.RandomUsername()
+	actor := test.RandomUsername()
+
+	cfg := &details.Config{}
+	cfg.Idempotency.TTL = time.Hour
+
+	body := `{"amount": 10, "type": 1, "category": "Food"}`
+
+	created := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respond(w, `{"id": 1}`, http.StatusCreated)
+	})
+
+	failed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respond(w, `{"error": "err"}`, http.StatusInternalServerError)
+	})
+
+	newRequest := func(key, body string) *http.Request {
+		r, _ := http.NewRequest(http.MethodPost, "/v1/transaction", strings.NewReader(body))
+		if key != "" {
+			r.Header.Set(Header, key)
+		}
+		r.Header.Set(ActorHeader, actor)
+		return r
+	}
+
+	reserved := mock.MatchedBy(func(rec Record) bool {
+		return rec.Actor == actor && rec.Key == key && rec.Status == 0 && rec.ExpiresAt.After(time.Now())
+	})
+
+	tests := map[string]func(t *testing.T, m *mockStore){
+		"when no key is given": func(t *testing.T, m *mockStore) {
+			// arrange
+			mw := NewMiddleware(m, cfg)
+			rr := httptest.NewRecorder()
+
+			// act
+			mw.Handler(created).ServeHTTP(rr, newRequest("", body))
+
+			// assert
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			assert.Equal(t, `{"id": 1}`, rr.Body.String())
+		},
+		"when key is too long": func(t *testing.T, m *mockStore) {
+			// arrange
+			mw := NewMiddleware(m, cfg)
+			rr := httptest.NewRecorder()
+
+			// act
+			mw.Handler(created).ServeHTTP(rr, newRequest(strings.Repeat("k", 256), body))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "Idempotency failed: invalid key"}`, rr.Body.String())
+		},
+		"when actor is too long": func(t *testing.T, m *mockStore) {
+			// arrange
+			mw := NewMiddleware(m, cfg)
+			rr := httptest.NewRecorder()
+
+			r := newRequest(key, body)
+			r.Header.Set(ActorHeader, strings.Repeat("a", 81))
+
+			// act
+			mw.Handler(created).ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "Idempotency failed: invalid actor"}`, rr.Body.String())
+		},
+		"when store fails to reserve key": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Reserve", reserved).Return(Record{}, false, errors.New("IdempotencyStore.Reserve failed: err"))
+			mw := NewMiddleware(m, cfg)
+			rr := httptest.NewRecorder()
+
+			// act
+			mw.Handler(created).ServeHTTP(rr, newRequest(key, body))
+
+			// assert
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			assert.Equal(t, `{"error": "IdempotencyStore.Reserve failed: err"}`, rr.Body.String())
+		},
+		"when key is new, save the response": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Reserve", reserved).Return(Record{}, true, nil)
+			m.On("Complete", mock.MatchedBy(func(rec Record) bool {
+				return rec.Actor == actor && rec.Key == key && rec.Status == http.StatusCreated && string(rec.Body) == `{"id": 1}`
+			})).Return(nil)
+			mw := NewMiddleware(m, cfg)
+			rr := httptest.NewRecorder()
+
+			// act
+			mw.Handler(created).ServeHTTP(rr, newRequest(key, body))
+
+			// assert
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			assert.Equal(t, `{"id": 1}`, rr.Body.String())
+			assert.Empty(t, rr.Header().Get(ReplayedHeader))
+		},
+		"when handler fails, release the key": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Reserve", reserved).Return(Record{}, true, nil)
+			m.On("Release", reserved).Return(nil)
+			mw := NewMiddleware(m, cfg)
+			rr := httptest.NewRecorder()
+
+			// act
+			mw.Handler(failed).ServeHTTP(rr, newRequest(key, body))
+
+			// assert
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		},
+		"when store fails to complete key, release it": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Reserve", reserved).Return(Record{}, true, nil)
+			m.On("Complete", mock.Anything).Return(errors.New("IdempotencyStore.Complete failed: err"))
+			m.On("Release", mock.MatchedBy(func(rec Record) bool {
+				return rec.Actor == actor && rec.Key == key
+			})).Return(nil)
+			mw := NewMiddleware(m, cfg)
+			rr := httptest.NewRecorder()
+
+			// act
+			mw.Handler(created).ServeHTTP(rr, newRequest(key, body))
+
+			// assert
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			assert.Equal(t, `{"id": 1}`, rr.Body.String())
+		},
+		"when no actor is given, scope the key to the anonymous one": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Reserve", mock.MatchedBy(func(rec Record) bool {
+				return rec.Actor == "" && rec.Key == key
+			})).Return(Record{}, true, nil)
+			m.On("Complete", mock.MatchedBy(func(rec Record) bool {
+				return rec.Actor == "" && rec.Key == key
+			})).Return(nil)
+			mw := NewMiddleware(m, cfg)
+			rr := httptest.NewRecorder()
+
+			r := newRequest(key, body)
+			r.Header.Del(ActorHeader)
+
+			// act
+			mw.Handler(created).ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusCreated, rr.Code)
+		},
+		"when key is replayed with the same body, return the original response": func(t *testing.T, m *mockStore) {
+			// arrange
+			r := newRequest(key, body)
+			existing := Record{
+				Key:         key,
+				RequestHash: hash(r, []byte(body)),
+				Status:      http.StatusCreated,
+				Body:        []byte(`{"id": 1}`),
+			}
+
+			m.On("Reserve", reserved).Return(existing, false, nil)
+			mw := NewMiddleware(m, cfg)
+			rr := httptest.NewRecorder()
+
+			// act
+			mw.Handler(failed).ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			assert.Equal(t, `{"id": 1}`, rr.Body.String())
+			assert.Equal(t, "true", rr.Header().Get(ReplayedHeader))
+			assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+		},
+		"when key is reused with a different body": func(t *testing.T, m *mockStore) {
+			// arrange
+			existing := Record{
+				Key:         key,
+				RequestHash: hash(newRequest(key, body), []byte(body)),
+				Status:      http.StatusCreated,
+				Body:        []byte(`{"id": 1}`),
+			}
+
+			m.On("Reserve", reserved).Return(existing, false, nil)
+			mw := NewMiddleware(m, cfg)
+			rr := httptest.NewRecorder()
+
+			// act
+			mw.Handler(created).ServeHTTP(rr, newRequest(key, `{"amount": 20}`))
+
+			// assert
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Equal(t, `{"error": "Idempotency failed: key already used with a different request"}`, rr.Body.String())
+		},
+		"when key is reused with a different query": func(t *testing.T, m *mockStore) {
+			// arrange
+			existing := Record{
+				Key:         key,
+				RequestHash: hash(newRequest(key, body), []byte(body)),
+				Status:      http.StatusCreated,
+				Body:        []byte(`{"id": 1}`),
+			}
+
+			m.On("Reserve", reserved).Return(existing, false, nil)
+			mw := NewMiddleware(m, cfg)
+			rr := httptest.NewRecorder()
+
+			r := newRequest(key, body)
+			r.URL.RawQuery = "mode=valid"
+
+			// act
+			mw.Handler(created).ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		},
+		"when key is in progress": func(t *testing.T, m *mockStore) {
+			// arrange
+			existing := Record{
+				Key:         key,
+				RequestHash: hash(newRequest(key, body), []byte(body)),
+			}
+
+			m.On("Reserve", reserved).Return(existing, false, nil)
+			mw := NewMiddleware(m, cfg)
+			rr := httptest.NewRecorder()
+
+			// act
+			mw.Handler(created).ServeHTTP(rr, newRequest(key, body))
+
+			// assert
+			assert.Equal(t, http.StatusConflict, rr.Code)
+			assert.Equal(t, `{"error": "Idempotency failed: request with the same key in progress"}`, rr.Body.String())
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockStore)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+type mockStore struct {
+	mock.Mock
+}
+
+func (m *mockStore) Reserve(_ context.Context, rec Record) (Record, bool, error) {
+	args := m.Called(rec)
+	return args.Get(0).(Record), args.Bool(1), args.Error(2)
+}
+
+func (m *mockStore) Complete(_ context.Context, rec Record) error {
+	args := m.Called(rec)
+	return args.Error(0)
+}
+
+func (m *mockStore) Release(_ context.Context, rec Record) error {
+	args := m.Called(rec)
+	return args.Error(0)
+}
+
+func (m *mockStore) Sweep(_ context.Context, now time.Time) error {
+	args := m.Called(now)
+	return args.Error(0)
+}
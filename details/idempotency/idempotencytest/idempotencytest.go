@@ -0,0 +1,162 @@
+// Package idempotencytest defines what a correct idempotency.Store does as a test suite any backend can run.
+package idempotencytest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/details/idempotency"
+)
+
+// Factory returns an empty store, releasing it with t.Cleanup when needed.
+type Factory func(t *testing.T) idempotency.Store
+
+// ctx is the context of all calls made by the suite.
+var ctx = context.Background()
+
+// Run runs the conformance suite, each test against a new store.
+func Run(t *testing.T, newStore Factory) {
+	tests := map[string]func(*testing.T, idempotency.Store){
+		"Reserve reserves a new key":                      testReserveNew,
+		"Reserve returns the response of a completed key": testReserveCompleted,
+		"Reserve reserves a released key again":           testReserveReleased,
+		"Reserve reserves an expired key again":           testReserveExpired,
+		"Reserve scopes the keys by actor":                testReserveActor,
+		"Sweep removes the expired keys":                  testSweep,
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, newStore(t))
+		})
+	}
+}
+
+func testReserveNew(t *testing.T, s idempotency.Store) {
+	// arrange
+	rec := record("new")
+
+	// act
+	got, gotReserved, gotErr := s.Reserve(ctx, rec)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.True(t, gotReserved)
+	assert.Equal(t, rec, got)
+}
+
+func testReserveCompleted(t *testing.T, s idempotency.Store) {
+	// arrange
+	rec := record("completed")
+
+	completed := rec
+	completed.Status = http.StatusCreated
+	completed.Body = []byte(`{"id": 1}`)
+
+	_, _, _ = s.Reserve(ctx, rec)
+	assert.NoError(t, s.Complete(ctx, completed))
+
+	// act
+	got, gotReserved, gotErr := s.Reserve(ctx, rec)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.False(t, gotReserved)
+	assert.Equal(t, completed.RequestHash, got.RequestHash)
+	assert.Equal(t, completed.Status, got.Status)
+	assert.Equal(t, completed.Body, got.Body)
+}
+
+func testReserveReleased(t *testing.T, s idempotency.Store) {
+	// arrange
+	rec := record("released")
+
+	_, _, _ = s.Reserve(ctx, rec)
+	assert.NoError(t, s.Release(ctx, rec))
+
+	// act
+	_, gotReserved, gotErr := s.Reserve(ctx, rec)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.True(t, gotReserved)
+}
+
+func testReserveExpired(t *testing.T, s idempotency.Store) {
+	// arrange
+	rec := record("expired")
+
+	expired := rec
+	expired.ExpiresAt = time.Now().UTC().Add(-time.Hour)
+	_, _, _ = s.Reserve(ctx, expired)
+
+	// act
+	_, gotReserved, gotErr := s.Reserve(ctx, rec)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.True(t, gotReserved)
+}
+
+func testReserveActor(t *testing.T, s idempotency.Store) {
+	// arrange
+	rec := record("shared")
+
+	completed := rec
+	completed.Status = http.StatusCreated
+	completed.Body = []byte(`{"id": 1}`)
+
+	_, _, _ = s.Reserve(ctx, rec)
+	assert.NoError(t, s.Complete(ctx, completed))
+
+	other := rec
+	other.Actor = "bob"
+	other.RequestHash = "hash of another request"
+
+	// act
+	got, gotReserved, gotErr := s.Reserve(ctx, other)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.True(t, gotReserved)
+	assert.Equal(t, other, got)
+
+	existing, reserved, err := s.Reserve(ctx, rec)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, completed.Body, existing.Body)
+}
+
+func testSweep(t *testing.T, s idempotency.Store) {
+	// arrange
+	expired := record("expired")
+	expired.ExpiresAt = time.Now().UTC().Add(-time.Hour)
+	_, _, _ = s.Reserve(ctx, expired)
+
+	unexpired := record("unexpired")
+	_, _, _ = s.Reserve(ctx, unexpired)
+
+	// act
+	gotErr := s.Sweep(ctx, time.Now().UTC())
+
+	// assert
+	assert.NoError(t, gotErr)
+
+	_, reserved, err := s.Reserve(ctx, unexpired)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+}
+
+// record is a new record of the key of alice, expiring in an hour.
+func record(key string) idempotency.Record {
+	return idempotency.Record{
+		Actor:       "alice",
+		Key:         key,
+		RequestHash: "hash of " + key,
+		ExpiresAt:   time.Now().UTC().Add(time.Hour),
+	}
+}
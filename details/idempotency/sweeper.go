@@ -0,0 +1,50 @@
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/details"
+)
+
+// Sweeper removes the expired keys of the store periodically, rather than on each request.
+type Sweeper struct {
+	store    Store
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewSweeper initialize the sweeper, sweeping every configured interval.
+func NewSweeper(store Store, cfg *details.Config) *Sweeper {
+	return &Sweeper{
+		store:    store,
+		interval: cfg.Idempotency.SweepInterval,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run sweeps the store every interval until ctx is done, logging the failed sweeps.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "sweeping the idempotency keys failed", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep removes the keys expired by now.
+func (s *Sweeper) Sweep(ctx context.Context) error {
+	return errors.Wrap(s.store.Sweep(ctx, s.now()), "Sweep failed")
+}
@@ -0,0 +1,61 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/details"
+)
+
+func TestSweeper_Sweep(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cfg := &details.Config{}
+	cfg.Idempotency.SweepInterval = time.Hour
+
+	newSweeper := func(m *mockStore) *Sweeper {
+		s := NewSweeper(m, cfg)
+		s.now = func() time.Time { return now }
+		return s
+	}
+
+	tests := map[string]func(t *testing.T, m *mockStore){
+		"when store fails to sweep": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Sweep", now).Return(errors.New("IdempotencyStore.Sweep failed: err"))
+
+			// act
+			gotErr := newSweeper(m).Sweep(context.Background())
+
+			// assert
+			assert.EqualError(t, gotErr, "Sweep failed: IdempotencyStore.Sweep failed: err")
+		},
+		"when store sweeps the expired keys": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Sweep", now).Return(nil)
+
+			// act
+			gotErr := newSweeper(m).Sweep(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockStore)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
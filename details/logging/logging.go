@@ -0,0 +1,70 @@
+// Package logging writes structured JSON logs, tagging each record with the ID of the request it was made for.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+)
+
+type requestIDKey struct{}
+
+// contextHandler adds the request ID held by the context to the records.
+type contextHandler struct {
+	slog.Handler
+}
+
+// NewLogger initialize a logger writing JSON records to w, from the level on, one of debug, info, warn or error.
+func NewLogger(w io.Writer, level string) *slog.Logger {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		l = slog.LevelInfo
+	}
+
+	return slog.New(contextHandler{Handler: slog.NewJSONHandler(w, &slog.HandlerOptions{Level: l})})
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// WithRequestID returns a copy of ctx holding the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID held by ctx, empty when there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Failure logs the error of a storage operation along with the request ID held by ctx, so it can be traced back
+// to the request causing it. Errors telling a transaction is missing or stale are expected, and left out.
+func Failure(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	switch errors.Cause(err) {
+	case core.ErrNotFound, core.ErrStaleVersion:
+		return
+	}
+
+	slog.ErrorContext(ctx, "storage failed", "error", err.Error())
+}
@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when the context holds a request ID": func(t *testing.T) {
+			// arrange
+			buf := &bytes.Buffer{}
+			logger := NewLogger(buf, "info")
+
+			// act
+			logger.InfoContext(WithRequestID(context.Background(), "4f9c1e0b2a7d8e36"), "request handled", "status", 200)
+
+			// assert
+			record := decode(t, buf)
+			assert.Equal(t, "INFO", record["level"])
+			assert.Equal(t, "request handled", record["msg"])
+			assert.Equal(t, "4f9c1e0b2a7d8e36", record["request_id"])
+			assert.Equal(t, float64(200), record["status"])
+		},
+		"when the context holds no request ID": func(t *testing.T) {
+			// arrange
+			buf := &bytes.Buffer{}
+			logger := NewLogger(buf, "info").With("component", "server")
+
+			// act
+			logger.InfoContext(context.Background(), "listening")
+
+			// assert
+			record := decode(t, buf)
+			assert.NotContains(t, record, "request_id")
+			assert.Equal(t, "server", record["component"])
+		},
+		"when the record is below the level": func(t *testing.T) {
+			// arrange
+			buf := &bytes.Buffer{}
+			logger := NewLogger(buf, "warn")
+
+			// act
+			logger.Info("listening")
+
+			// assert
+			assert.Empty(t, buf.String())
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestFailure(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "4f9c1e0b2a7d8e36")
+
+	tests := map[string]func(*testing.T, *bytes.Buffer){
+		"when the storage fails": func(t *testing.T, buf *bytes.Buffer) {
+			// act
+			Failure(ctx, errors.New("Repository.Create failed: connection refused"))
+
+			// assert
+			record := decode(t, buf)
+			assert.Equal(t, "ERROR", record["level"])
+			assert.Equal(t, "Repository.Create failed: connection refused", record["error"])
+			assert.Equal(t, "4f9c1e0b2a7d8e36", record["request_id"])
+		},
+		"when the transaction is not found": func(t *testing.T, buf *bytes.Buffer) {
+			// act
+			Failure(ctx, errors.Wrap(core.ErrNotFound, "Repository.FindByID failed"))
+
+			// assert
+			assert.Empty(t, buf.String())
+		},
+		"when the version is stale": func(t *testing.T, buf *bytes.Buffer) {
+			// act
+			Failure(ctx, errors.Wrap(core.ErrStaleVersion, "Repository.Update failed"))
+
+			// assert
+			assert.Empty(t, buf.String())
+		},
+		"when there is no error": func(t *testing.T, buf *bytes.Buffer) {
+			// act
+			Failure(ctx, nil)
+
+			// assert
+			assert.Empty(t, buf.String())
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, captureDefault(t))
+		})
+	}
+}
+
+// captureDefault makes the default logger write to the returned buffer, restoring it with t.Cleanup.
+func captureDefault(t *testing.T) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	previous := slog.Default()
+	slog.SetDefault(NewLogger(buf, "debug"))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return buf
+}
+
+// decode returns the single JSON record written to buf.
+func decode(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	var record map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
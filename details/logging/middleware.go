@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gritt/maskada/details/recorder"
+)
+
+// RequestIDHeader is the header holding the request ID, set by the client or generated by the API.
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID matches the request IDs taken from clients, others being replaced so they can not forge log lines.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// Handler wraps next, tagging the request with an ID, in its context and response, and logging its access once
+// it is handled, eg: method, route, status and duration.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := AcceptRequestID(r.Header.Get(RequestIDHeader))
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := WithRequestID(r.Context(), id)
+		rec := recorder.New(w)
+
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		route := recorder.Route(r)
+
+		slog.InfoContext(ctx, "request handled",
+			"method", r.Method,
+			"route", route,
+			"status", rec.Status(),
+			"duration_ms", float64(time.Since(start).Microseconds())/1000,
+		)
+	})
+}
+
+// AcceptRequestID returns the request ID sent by a client when valid, or a generated one.
+func AcceptRequestID(id string) string {
+	if !validRequestID.MatchString(id) {
+		return newRequestID()
+	}
+	return id
+}
+
+// newRequestID generates a random request ID, eg: 4f9c1e0b2a7d8e36.
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
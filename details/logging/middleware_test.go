@@ -0,0 +1,113 @@
+package logging
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/details/recorder"
+)
+
+func TestHandler(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when the client sends a request ID": func(t *testing.T) {
+			// arrange
+			buf := captureDefault(t)
+
+			var seen string
+			router := chi.NewRouter()
+			router.Use(Handler)
+			router.Get("/v1/transaction/{id}", func(w http.ResponseWriter, r *http.Request) {
+				seen = RequestID(r.Context())
+				w.WriteHeader(http.StatusNotFound)
+			})
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/v1/transaction/7", nil)
+			r.Header.Set(RequestIDHeader, "client-id.1")
+
+			// act
+			router.ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, "client-id.1", seen)
+			assert.Equal(t, "client-id.1", rr.Header().Get(RequestIDHeader))
+
+			record := decode(t, buf)
+			assert.Equal(t, "request handled", record["msg"])
+			assert.Equal(t, "client-id.1", record["request_id"])
+			assert.Equal(t, http.MethodGet, record["method"])
+			assert.Equal(t, "/v1/transaction/{id}", record["route"])
+			assert.Equal(t, float64(http.StatusNotFound), record["status"])
+			assert.Contains(t, record, "duration_ms")
+		},
+		"when the client sends no request ID": func(t *testing.T) {
+			// arrange
+			buf := captureDefault(t)
+
+			var seen string
+			router := chi.NewRouter()
+			router.Use(Handler)
+			router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
+				seen = RequestID(r.Context())
+			})
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+
+			// act
+			router.ServeHTTP(rr, r)
+
+			// assert
+			assert.Regexp(t, `^[0-9a-f]{16}$`, seen)
+			assert.Equal(t, seen, rr.Header().Get(RequestIDHeader))
+			assert.Equal(t, seen, decode(t, buf)["request_id"])
+		},
+		"when the client sends an invalid request ID": func(t *testing.T) {
+			// arrange
+			captureDefault(t)
+
+			router := chi.NewRouter()
+			router.Use(Handler)
+			router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+			r.Header.Set(RequestIDHeader, "forged\nline")
+
+			// act
+			router.ServeHTTP(rr, r)
+
+			// assert
+			assert.Regexp(t, `^[0-9a-f]{16}$`, rr.Header().Get(RequestIDHeader))
+		},
+		"when the route is not found": func(t *testing.T) {
+			// arrange
+			buf := captureDefault(t)
+
+			router := chi.NewRouter()
+			router.Use(Handler)
+			router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/unknown", nil)
+
+			// act
+			router.ServeHTTP(rr, r)
+
+			// assert
+			record := decode(t, buf)
+			assert.Equal(t, recorder.UnmatchedRoute, record["route"])
+			assert.Equal(t, float64(http.StatusNotFound), record["status"])
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gritt/maskada/core"
+)
+
+// record appends an entry to the audit log, it must be called holding the lock.
+func (r *Repository) record(entry core.AuditEntry) {
+	entry.ID = len(r.audit) + 1
+	entry.Date = time.Now().UTC()
+	r.audit = append(r.audit, entry)
+}
+
+// snapshot is how a transaction is recorded in the audit log.
+type snapshot struct {
+	ID       int       `json:"id"`
+	Amount   int       `json:"amount"`
+	Type     int       `json:"type"`
+	Category string    `json:"category"`
+	Date     time.Time `json:"date"`
+	Name     string    `json:"name"`
+	Version  int       `json:"version"`
+}
+
+func transactionSnapshot(t core.Transaction) json.RawMessage {
+	encoded, _ := json.Marshal(snapshot{
+		ID:       t.ID,
+		Amount:   t.Amount,
+		Type:     t.Type,
+		Category: t.Category.Name,
+		Date:     t.Date.UTC(),
+		Name:     t.Name,
+		Version:  t.Version,
+	})
+	return encoded
+}
+
+// transactionFromSnapshot decodes a transaction as recorded in the audit log.
+func transactionFromSnapshot(encoded []byte) (core.Transaction, error) {
+	var s snapshot
+	if err := json.Unmarshal(encoded, &s); err != nil {
+		return core.Transaction{}, err
+	}
+
+	return core.Transaction{
+		ID:       s.ID,
+		Amount:   s.Amount,
+		Type:     s.Type,
+		Category: core.Category{Name: s.Category},
+		Date:     s.Date.UTC(),
+		Name:     s.Name,
+		Version:  s.Version,
+	}, nil
+}
+
+func categorySnapshot(c core.Category) json.RawMessage {
+	snapshot, _ := json.Marshal(struct {
+		Name string `json:"name"`
+	}{
+		Name: c.Name,
+	})
+	return snapshot
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/core/repotest"
+	"github.com/gritt/maskada/details/events"
+	"github.com/gritt/maskada/details/events/eventstest"
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/idempotency/idempotencytest"
+	"github.com/gritt/maskada/details/webhook"
+	"github.com/gritt/maskada/details/webhook/webhooktest"
+)
+
+func TestConformance(t *testing.T) {
+	suites := map[string]func(*testing.T){
+		"Repository": func(t *testing.T) {
+			repotest.Run(t, func(t *testing.T) core.Repository {
+				return NewRepository()
+			})
+		},
+		"EventLog": func(t *testing.T) {
+			repotest.RunEvents(t, func(t *testing.T) (core.Repository, core.EventLog) {
+				r := NewRepository()
+				return r, r
+			})
+		},
+		"Journal": func(t *testing.T) {
+			repotest.RunJournal(t, func(t *testing.T) (core.Repository, core.Journal) {
+				r := NewRepository()
+				return r, r
+			})
+		},
+		"IdempotencyStore": func(t *testing.T) {
+			idempotencytest.Run(t, func(t *testing.T) idempotency.Store {
+				return NewIdempotencyStore()
+			})
+		},
+		"EventStore": func(t *testing.T) {
+			eventstest.Run(t, func(t *testing.T, relay bool) events.Store {
+				return NewEventStore(NewRepository(), relay)
+			})
+		},
+		"WebhookStore": func(t *testing.T) {
+			webhooktest.Run(t, func(t *testing.T) (core.Repository, core.Publisher, webhook.Store) {
+				r := NewRepository()
+				return r, events.NewOutbox(NewEventStore(r, true)), NewWebhookStore(r)
+			})
+		},
+	}
+
+	for name, run := range suites {
+		t.Run(name, run)
+	}
+}
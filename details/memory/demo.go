@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/gritt/maskada/core"
+)
+
+// DemoActor is the actor recorded in the audit log of the demo data.
+const DemoActor = "demo"
+
+// Demo returns transactions of a typical month, dated from the first day of the month before now.
+func Demo(now time.Time) []core.Transaction {
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	day := func(d int) time.Time {
+		return month.AddDate(0, 0, d-1)
+	}
+
+	return []core.Transaction{
+		{Amount: 530000, Type: core.Income, Category: core.Category{Name: "Work"}, Date: day(1), Name: "Salary"},
+		{Amount: 120000, Type: core.Debit, Category: core.Category{Name: "Home"}, Date: day(5), Name: "Rent"},
+		{Amount: 12900, Type: core.Debit, Category: core.Category{Name: "Home"}, Date: day(10), Name: "Internet"},
+		{Amount: 12900, Type: core.Debit, Category: core.Category{Name: "Home"}, Date: day(10), Name: "Electricity"},
+		{Amount: 3200, Type: core.Credit, Category: core.Category{Name: "Food"}, Date: day(12), Name: "Groceries"},
+		{Amount: 1100, Type: core.Credit, Category: core.Category{Name: "Food"}, Date: day(14), Name: "Lunch"},
+		{Amount: 9900, Type: core.Credit, Category: core.Category{Name: "Entertainment"}, Date: day(18), Name: "Concert"},
+		{Amount: 4500, Type: core.Debit, Category: core.Category{Name: "Health"}, Date: day(21), Name: "Pharmacy"},
+		{Amount: 530000, Type: core.Income, Category: core.Category{Name: "Work"}, Date: day(1).AddDate(0, 1, 0), Name: "Salary"},
+		{Amount: 120000, Type: core.Debit, Category: core.Category{Name: "Home"}, Date: day(5).AddDate(0, 1, 0), Name: "Rent"},
+	}
+}
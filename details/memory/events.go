@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/gritt/maskada/details/outbox"
+)
+
+// EventStore is able to append the events to the outbox of the repository, and to read them until they are relayed,
+// sharing its lock.
+type EventStore struct {
+	r       *Repository
+	relay   bool
+	relayed map[int]time.Time
+}
+
+// NewEventStore initialize an empty store, reading the outbox of the repository, relay telling whether the events
+// are relayed.
+func NewEventStore(r *Repository, relay bool) *EventStore {
+	return &EventStore{r: r, relay: relay, relayed: map[int]time.Time{}}
+}
+
+// Append appends messages to the outbox in memory, in order, assigning their ids, as relayed at their date unless
+// the store relays them.
+func (s *EventStore) Append(_ context.Context, messages ...outbox.Message) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	for _, m := range messages {
+		m.ID = len(s.r.outbox) + 1
+		s.r.outbox = append(s.r.outbox, m)
+		if !s.relay {
+			s.relayed[m.ID] = m.Date
+		}
+	}
+
+	return nil
+}
+
+// FindPending finds up to limit events of the outbox in memory yet to be relayed, oldest first.
+func (s *EventStore) FindPending(_ context.Context, limit int) ([]outbox.Message, error) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	pending := []outbox.Message{}
+	for _, m := range s.r.outbox {
+		if len(pending) == limit {
+			break
+		}
+		if _, ok := s.relayed[m.ID]; !ok {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending, nil
+}
+
+// MarkRelayed marks the events of the outbox in memory relayed at now.
+func (s *EventStore) MarkRelayed(_ context.Context, ids []int, now time.Time) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	for _, id := range ids {
+		s.relayed[id] = now
+	}
+
+	return nil
+}
+
+// Purge keeps the events of the outbox in memory, which is lost on exit, as its webhook deliveries refer to them by
+// their position.
+func (s *EventStore) Purge(context.Context, time.Time) error {
+	return nil
+}
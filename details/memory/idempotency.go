@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gritt/maskada/details/idempotency"
+)
+
+// scopedKey is an idempotency key of an actor.
+type scopedKey struct {
+	actor string
+	key   string
+}
+
+// IdempotencyStore is able to reserve, complete, release and sweep idempotency keys in memory.
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	records map[scopedKey]idempotency.Record
+}
+
+// NewIdempotencyStore initialize an empty store.
+func NewIdempotencyStore() *IdempotencyStore {
+	return &IdempotencyStore{records: map[scopedKey]idempotency.Record{}}
+}
+
+// Reserve persists a pending key in memory, unless an unexpired one exists, which is returned instead.
+func (s *IdempotencyStore) Reserve(_ context.Context, rec idempotency.Record) (idempotency.Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := scopedKey{actor: rec.Actor, key: rec.Key}
+	if existing, ok := s.records[key]; ok && existing.ExpiresAt.After(time.Now()) {
+		return existing, false, nil
+	}
+
+	s.records[key] = rec
+
+	return rec, true, nil
+}
+
+// Complete persists the response of a reserved key in memory.
+func (s *IdempotencyStore) Complete(_ context.Context, rec idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := scopedKey{actor: rec.Actor, key: rec.Key}
+	if existing, ok := s.records[key]; ok {
+		existing.Status = rec.Status
+		existing.Body = rec.Body
+		s.records[key] = existing
+	}
+
+	return nil
+}
+
+// Release removes a reserved key from memory, so the request can be retried.
+func (s *IdempotencyStore) Release(_ context.Context, rec idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, scopedKey{actor: rec.Actor, key: rec.Key})
+
+	return nil
+}
+
+// Sweep removes the keys expired at now from memory.
+func (s *IdempotencyStore) Sweep(_ context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, rec := range s.records {
+		if !rec.ExpiresAt.After(now) {
+			delete(s.records, key)
+		}
+	}
+
+	return nil
+}
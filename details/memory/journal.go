@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+)
+
+// journal records an operation of an actor, discarding their undone ones and their oldest beyond the journal size, it
+// must be called holding the lock.
+func (r *Repository) journal(operation string, changes []core.Change, actor string, now time.Time) {
+	done := 0
+	for _, e := range r.entries {
+		if e.Actor == actor && !e.Undone {
+			done++
+		}
+	}
+
+	entries := make([]core.JournalEntry, 0, len(r.entries)+1)
+	for _, e := range r.entries {
+		if e.Actor == actor && e.Undone {
+			continue
+		}
+		if e.Actor == actor && done >= core.JournalSize {
+			done--
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	r.lastEntryID++
+	r.entries = append(entries, core.JournalEntry{
+		ID:        r.lastEntryID,
+		Actor:     actor,
+		Operation: operation,
+		Changes:   changes,
+		Date:      now,
+	})
+}
+
+// Undo applies the inverse of the latest operation of the actor journaled in memory not undone yet.
+func (r *Repository) Undo(_ context.Context, actor string) (core.JournalEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := -1
+	for j := len(r.entries) - 1; j >= 0; j-- {
+		if r.entries[j].Actor == actor && !r.entries[j].Undone {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return core.JournalEntry{}, errors.Wrap(core.ErrNothingToUndo, "Repository.Undo failed")
+	}
+
+	entry, err := r.revert(i, actor)
+	if err != nil {
+		return core.JournalEntry{}, errors.Wrap(err, "Repository.Undo failed")
+	}
+
+	return entry, nil
+}
+
+// Redo applies the inverse of the latest operation of the actor undone in memory.
+func (r *Repository) Redo(_ context.Context, actor string) (core.JournalEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := -1
+	for j, e := range r.entries {
+		if e.Actor == actor && e.Undone {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return core.JournalEntry{}, errors.Wrap(core.ErrNothingToRedo, "Repository.Redo failed")
+	}
+
+	entry, err := r.revert(i, actor)
+	if err != nil {
+		return core.JournalEntry{}, errors.Wrap(err, "Repository.Redo failed")
+	}
+
+	return entry, nil
+}
+
+// revert applies the inverse of a journal entry, or none of it when a transaction was changed since, then toggles the
+// entry and rebases the other entries of the actor, it must be called holding the lock.
+func (r *Repository) revert(i int, actor string) (core.JournalEntry, error) {
+	inverse := r.entries[i].Inverse()
+
+	for _, c := range inverse {
+		if c.Created() {
+			if _, ok := r.transactions[c.After.ID]; ok {
+				return core.JournalEntry{}, core.ErrStaleVersion
+			}
+			continue
+		}
+
+		current, ok := r.transactions[c.Before.ID]
+		if !ok || current.Version != c.Before.Version {
+			return core.JournalEntry{}, core.ErrStaleVersion
+		}
+	}
+
+	applied := make([]core.Change, 0, len(inverse))
+	for _, c := range inverse {
+		applied = append(applied, r.apply(c, actor))
+	}
+
+	for j := range r.entries {
+		if j != i && r.entries[j].Actor == actor {
+			r.entries[j].Rebase(inverse, applied)
+		}
+	}
+
+	r.entries[i].Changes = applied
+	r.entries[i].Undone = !r.entries[i].Undone
+
+	return r.entries[i], nil
+}
+
+// apply applies a change checked by revert, along with its audit entry, returning it as applied. A
+// removed transaction is restored with its id, at the next version. It must be called holding the lock.
+func (r *Repository) apply(c core.Change, actor string) core.Change {
+	if c.Created() {
+		t := c.After
+		t.Version++
+		r.createCategory(t.Category, actor)
+		r.transactions[t.ID] = t
+
+		r.record(core.AuditEntry{
+			Entity:   core.AuditTransaction,
+			EntityID: strconv.Itoa(t.ID),
+			Action:   core.AuditCreate,
+			Actor:    actor,
+			After:    transactionSnapshot(t),
+		})
+
+		return core.Change{After: t}
+	}
+
+	before := r.transactions[c.Before.ID]
+
+	if c.Deleted() {
+		delete(r.transactions, before.ID)
+
+		r.record(core.AuditEntry{
+			Entity:   core.AuditTransaction,
+			EntityID: strconv.Itoa(before.ID),
+			Action:   core.AuditDelete,
+			Actor:    actor,
+			Before:   transactionSnapshot(before),
+		})
+
+		return core.Change{Before: before}
+	}
+
+	t := c.After
+	t.Version = before.Version + 1
+	r.createCategory(t.Category, actor)
+	r.transactions[t.ID] = t
+
+	r.record(core.AuditEntry{
+		Entity:   core.AuditTransaction,
+		EntityID: strconv.Itoa(t.ID),
+		Action:   core.AuditUpdate,
+		Actor:    actor,
+		Before:   transactionSnapshot(before),
+		After:    transactionSnapshot(t),
+	})
+
+	return core.Change{Before: before, After: t}
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+)
+
+// FindAsOf rebuilds the transactions as they were known at asOf, replaying the audit log held in memory.
+func (r *Repository) FindAsOf(_ context.Context, asOf time.Time) ([]core.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events, err := r.events(asOf)
+	if err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Repository.FindAsOf failed")
+	}
+
+	return core.Rebuild(events), nil
+}
+
+// FindMonthAsOf rebuilds the transactions dated in the month starting at from as they were known at asOf, replaying
+// the audit log held in memory.
+func (r *Repository) FindMonthAsOf(_ context.Context, from, asOf time.Time) ([]core.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events, err := r.events(asOf)
+	if err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Repository.FindMonthAsOf failed")
+	}
+
+	ts := []core.Transaction{}
+	for _, t := range core.Rebuild(events) {
+		if core.InMonth(t, from) {
+			ts = append(ts, t)
+		}
+	}
+
+	return ts, nil
+}
+
+// events finds the changes of the transactions in the audit log, recorded at or before asOf, oldest first, it must be
+// called holding the lock.
+func (r *Repository) events(asOf time.Time) ([]core.TransactionEvent, error) {
+	events := []core.TransactionEvent{}
+	for _, entry := range r.audit {
+		if entry.Date.After(asOf) {
+			break
+		}
+		if entry.Entity != core.AuditTransaction {
+			continue
+		}
+
+		// a deletion records the transaction as it was removed, the other changes as it became
+		encoded := entry.After
+		if entry.Action == core.AuditDelete {
+			encoded = entry.Before
+		}
+
+		t, err := transactionFromSnapshot(encoded)
+		if err != nil {
+			return []core.TransactionEvent{}, errors.Wrapf(err, "audit entry %d", entry.ID)
+		}
+
+		events = append(events, core.TransactionEvent{
+			ID:          entry.ID,
+			Action:      entry.Action,
+			Transaction: t,
+			Actor:       entry.Actor,
+			RecordedAt:  entry.Date,
+		})
+	}
+
+	return events, nil
+}
+
+// FindStart finds when the first change of a transaction was recorded in the audit log, the zero time when none was.
+func (r *Repository) FindStart(context.Context) (time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, entry := range r.audit {
+		if entry.Entity == core.AuditTransaction {
+			return entry.Date, nil
+		}
+	}
+
+	return time.Time{}, nil
+}
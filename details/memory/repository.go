@@ -0,0 +1,385 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/outbox"
+)
+
+// maxLength is the size limit of names, as enforced by the db schemas.
+const maxLength = 80
+
+// Repository is able to save and find a transaction(s) in memory, it is safe for concurrent use.
+type Repository struct {
+	mu           sync.RWMutex
+	transactions map[int]core.Transaction
+	categories   map[string]bool
+	audit        []core.AuditEntry
+	outbox       []outbox.Message
+	entries      []core.JournalEntry
+	lastEntryID  int
+	lastID       int
+}
+
+// NewRepository initialize an empty repository.
+func NewRepository() *Repository {
+	return &Repository{
+		transactions: map[int]core.Transaction{},
+		categories:   map[string]bool{},
+	}
+}
+
+// Create persists a transaction in memory, along with its audit entry and journal entry.
+func (r *Repository) Create(ctx context.Context, t core.Transaction, actor string) (core.Transaction, error) {
+	if err := check(t); err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Create failed")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	created := r.create(t, now, actor)
+	r.journal(core.JournalCreate, []core.Change{{After: created}}, actor, now)
+
+	return created, nil
+}
+
+// CreateBatch persists all transactions in memory, or none when any of them fails, along with their audit entries
+// and journal entry.
+func (r *Repository) CreateBatch(ctx context.Context, ts []core.Transaction, actor string) ([]core.Transaction, error) {
+	for _, t := range ts {
+		if err := check(t); err != nil {
+			return []core.Transaction{}, errors.Wrap(err, "Repository.CreateBatch failed")
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	created := make([]core.Transaction, 0, len(ts))
+	changes := make([]core.Change, 0, len(ts))
+	for _, t := range ts {
+		t = r.create(t, now, actor)
+		created = append(created, t)
+		changes = append(changes, core.Change{After: t})
+	}
+	r.journal(core.JournalCreateBatch, changes, actor, now)
+
+	return created, nil
+}
+
+// CreateCategory persists a category in memory, along with its audit entry, when it did not exist, telling whether
+// it did not.
+func (r *Repository) CreateCategory(_ context.Context, category core.Category, actor string) (bool, error) {
+	if utf8.RuneCountInString(category.Name) > maxLength {
+		return false, errors.Wrap(errors.New("data too long for category"), "Repository.CreateCategory failed")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.createCategory(category, actor), nil
+}
+
+// create assigns the next id and a default date to a transaction, it must be called holding the lock.
+func (r *Repository) create(t core.Transaction, now time.Time, actor string) core.Transaction {
+	r.createCategory(t.Category, actor)
+
+	if t.Date.IsZero() {
+		t.Date = now
+	}
+	t.Date = t.Date.UTC()
+
+	r.lastID++
+	t.ID = r.lastID
+	t.Version = 1
+	r.transactions[t.ID] = t
+
+	r.record(core.AuditEntry{
+		Entity:   core.AuditTransaction,
+		EntityID: strconv.Itoa(t.ID),
+		Action:   core.AuditCreate,
+		Actor:    actor,
+		After:    transactionSnapshot(t),
+	})
+
+	return t
+}
+
+// createCategory saves a category unless it exists, telling whether it did not, it must be called holding the lock.
+func (r *Repository) createCategory(category core.Category, actor string) bool {
+	if r.categories[category.Name] {
+		return false
+	}
+	r.categories[category.Name] = true
+
+	r.record(core.AuditEntry{
+		Entity:   core.AuditCategory,
+		EntityID: category.Name,
+		Action:   core.AuditCreate,
+		Actor:    actor,
+		After:    categorySnapshot(category),
+	})
+
+	return true
+}
+
+// Find transactions in memory, ordered by date.
+func (r *Repository) Find(ctx context.Context) ([]core.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var trs []core.Transaction
+	for _, t := range r.transactions {
+		trs = append(trs, t)
+	}
+
+	sort.Slice(trs, func(i, j int) bool {
+		if trs[i].Date.Equal(trs[j].Date) {
+			return trs[i].ID < trs[j].ID
+		}
+		return trs[i].Date.Before(trs[j].Date)
+	})
+
+	return trs, nil
+}
+
+// FindMonth finds the transactions dated in the month starting at from in memory.
+func (r *Repository) FindMonth(ctx context.Context, from time.Time) ([]core.Transaction, error) {
+	trs, err := r.Find(ctx)
+	if err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Repository.FindMonth failed")
+	}
+
+	var month []core.Transaction
+	for _, t := range trs {
+		if core.InMonth(t, from) {
+			month = append(month, t)
+		}
+	}
+
+	return month, nil
+}
+
+// FindPage finds up to limit transactions matching the filter listed after the cursor in memory.
+func (r *Repository) FindPage(ctx context.Context, f core.Filter, after core.Cursor, limit int) ([]core.Transaction, error) {
+	trs, err := r.Find(ctx)
+	if err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Repository.FindPage failed")
+	}
+
+	var page []core.Transaction
+	for _, t := range trs {
+		if len(page) == limit {
+			break
+		}
+		if !f.Match(t) {
+			continue
+		}
+		if after.ID != 0 && (t.Date.Before(after.Date) || t.Date.Equal(after.Date) && t.ID <= after.ID) {
+			continue
+		}
+		page = append(page, t)
+	}
+
+	return page, nil
+}
+
+// Count counts the transactions in memory matching the filter.
+func (r *Repository) Count(_ context.Context, f core.Filter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, t := range r.transactions {
+		if f.Match(t) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// FindByID finds a transaction in memory.
+func (r *Repository) FindByID(ctx context.Context, id int) (core.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.transactions[id]
+	if !ok {
+		return core.Transaction{}, errors.Wrap(core.ErrNotFound, "Repository.FindByID failed")
+	}
+
+	return t, nil
+}
+
+// Update changes a transaction in memory, along with its audit entry and journal entry, given its Version
+// is the current one.
+func (r *Repository) Update(ctx context.Context, t core.Transaction, actor string) (core.Transaction, error) {
+	if err := check(t); err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Update failed")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.findVersion(t.ID, t.Version)
+	if err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Update failed")
+	}
+
+	r.createCategory(t.Category, actor)
+
+	if t.Date.IsZero() {
+		t.Date = before.Date
+	}
+	t.Date = t.Date.UTC()
+	t.Version++
+	r.transactions[t.ID] = t
+
+	r.record(core.AuditEntry{
+		Entity:   core.AuditTransaction,
+		EntityID: strconv.Itoa(t.ID),
+		Action:   core.AuditUpdate,
+		Actor:    actor,
+		Before:   transactionSnapshot(before),
+		After:    transactionSnapshot(t),
+	})
+	r.journal(core.JournalUpdate, []core.Change{{Before: before, After: t}}, actor, time.Now().UTC())
+
+	return t, nil
+}
+
+// Delete removes a transaction from memory, along with its audit entry and journal entry, given the version is the
+// current one, returning it as removed.
+func (r *Repository) Delete(ctx context.Context, id, version int, actor string) (core.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.findVersion(id, version)
+	if err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Delete failed")
+	}
+
+	delete(r.transactions, id)
+
+	r.record(core.AuditEntry{
+		Entity:   core.AuditTransaction,
+		EntityID: strconv.Itoa(id),
+		Action:   core.AuditDelete,
+		Actor:    actor,
+		Before:   transactionSnapshot(before),
+	})
+	r.journal(core.JournalDelete, []core.Change{{Before: before}}, actor, time.Now().UTC())
+
+	return before, nil
+}
+
+// findVersion finds a transaction given it is at the expected version, it must be called holding the lock.
+func (r *Repository) findVersion(id, version int) (core.Transaction, error) {
+	t, ok := r.transactions[id]
+	if !ok {
+		return core.Transaction{}, core.ErrNotFound
+	}
+
+	if t.Version != version {
+		return core.Transaction{}, core.ErrStaleVersion
+	}
+
+	return t, nil
+}
+
+// FindHistory finds the audit entries of a transaction in memory, oldest first.
+func (r *Repository) FindHistory(ctx context.Context, transactionID int) ([]core.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entityID := strconv.Itoa(transactionID)
+
+	var entries []core.AuditEntry
+	for _, entry := range r.audit {
+		if entry.Entity == core.AuditTransaction && entry.EntityID == entityID {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// FindCategories finds the categories in memory, ordered by name.
+func (r *Repository) FindCategories(ctx context.Context) ([]core.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var categories []core.Category
+	for name := range r.categories {
+		categories = append(categories, core.Category{Name: name})
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Name < categories[j].Name
+	})
+
+	return categories, nil
+}
+
+// SumByCategory sums the transactions of the named categories in memory, by category name,
+// the categories without transactions are left out.
+func (r *Repository) SumByCategory(ctx context.Context, names []string) (map[string]core.Totals, error) {
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	totals := map[string]core.Totals{}
+	for _, t := range r.transactions {
+		if !wanted[t.Category.Name] {
+			continue
+		}
+
+		total := totals[t.Category.Name]
+		total.Add(t)
+		totals[t.Category.Name] = total
+	}
+
+	return totals, nil
+}
+
+// CountByType counts the transactions in memory of each type.
+func (r *Repository) CountByType(context.Context) (map[int]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := map[int]int{}
+	for _, t := range r.transactions {
+		counts[t.Type]++
+	}
+
+	return counts, nil
+}
+
+// check enforces the size limits of the db schemas, so a transaction saved in memory would be saved in db.
+func check(t core.Transaction) error {
+	if utf8.RuneCountInString(t.Category.Name) > maxLength {
+		return errors.New("data too long for category")
+	}
+	if utf8.RuneCountInString(t.Name) > maxLength {
+		return errors.New("data too long for description")
+	}
+	return nil
+}
@@ -0,0 +1,500 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/test"
+)
+
+func TestRepository_Create(t *testing.T) {
+	amount := test.RandomNumber()
+	name := test.RandomName()
+	actor := test.RandomUsername()
+	date := time.Now().UTC().Truncate(time.Hour * 24).Add(-time.Hour * 24)
+
+	tests := map[string]func(*testing.T, *Repository){
+		"when a date is given": func(t *testing.T, r *Repository) {
+			// arrange
+			given := core.Transaction{
+				Amount:   amount,
+				Type:     core.Credit,
+				Category: core.Category{Name: "Food"},
+				Date:     date,
+				Name:     name,
+			}
+
+			// act
+			got, gotErr := r.Create(context.Background(), given, actor)
+
+			want := core.Transaction{
+				ID:       7,
+				Amount:   amount,
+				Type:     core.Credit,
+				Category: core.Category{Name: "Food"},
+				Date:     date,
+				Name:     name,
+				Version:  1,
+			}
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, want, got)
+
+			found, err := r.FindByID(context.Background(), 7)
+			assert.NoError(t, err)
+			assert.Equal(t, want, found)
+		},
+		"when no date is given, use current time": func(t *testing.T, r *Repository) {
+			// arrange
+			given := core.Transaction{
+				Amount:   amount,
+				Type:     core.Credit,
+				Category: core.Category{Name: "Food"},
+			}
+
+			// act
+			got, gotErr := r.Create(context.Background(), given, actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, 7, got.ID)
+			assert.WithinDuration(t, time.Now().UTC(), got.Date, time.Second)
+		},
+		"when transaction is created, record it in the audit log": func(t *testing.T, r *Repository) {
+			// arrange
+			given := core.Transaction{
+				Amount:   amount,
+				Type:     core.Credit,
+				Category: core.Category{Name: "Food"},
+				Date:     date,
+			}
+
+			// act
+			got, gotErr := r.Create(context.Background(), given, actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+
+			history, err := r.FindHistory(context.Background(), got.ID)
+			assert.NoError(t, err)
+			assert.Len(t, history, 1)
+			assert.Equal(t, core.AuditTransaction, history[0].Entity)
+			assert.Equal(t, "7", history[0].EntityID)
+			assert.Equal(t, core.AuditCreate, history[0].Action)
+			assert.Equal(t, actor, history[0].Actor)
+			assert.Empty(t, history[0].Before)
+			assert.JSONEq(t, string(transactionSnapshot(got)), string(history[0].After))
+		},
+		"when name is too long": func(t *testing.T, r *Repository) {
+			// arrange
+			given := core.Transaction{
+				Amount:   amount,
+				Type:     core.Credit,
+				Category: core.Category{Name: "Food"},
+				Name:     strings.Repeat("n", 81),
+			}
+
+			// act
+			_, gotErr := r.Create(context.Background(), given, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "Repository.Create failed: data too long for description")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, setupData(t))
+		})
+	}
+}
+
+func TestRepository_CreateBatch(t *testing.T) {
+	actor := test.RandomUsername()
+
+	tests := map[string]func(*testing.T, *Repository){
+		"when transactions are given": func(t *testing.T, r *Repository) {
+			// arrange
+			category := test.RandomName()
+			given := []core.Transaction{
+				{Amount: 10, Type: core.Debit, Category: core.Category{Name: "Food"}},
+				{Amount: 20, Type: core.Credit, Category: core.Category{Name: category}, Name: "Receipt"},
+				{Amount: 30, Type: core.Income, Category: core.Category{Name: category}},
+			}
+
+			// act
+			got, gotErr := r.CreateBatch(context.Background(), given, actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Len(t, got, 3)
+			for i, gotTrs := range got {
+				assert.Equal(t, 7+i, gotTrs.ID)
+				assert.Equal(t, given[i].Amount, gotTrs.Amount)
+				assert.Equal(t, 1, gotTrs.Version)
+				assert.False(t, gotTrs.Date.IsZero())
+			}
+		},
+		"when a transaction fails, create none": func(t *testing.T, r *Repository) {
+			// arrange
+			given := []core.Transaction{
+				{Amount: 10, Type: core.Debit, Category: core.Category{Name: "Food"}},
+				{Amount: 20, Type: core.Debit, Category: core.Category{Name: "Food"}, Name: strings.Repeat("n", 81)},
+			}
+
+			// act
+			_, gotErr := r.CreateBatch(context.Background(), given, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "Repository.CreateBatch failed: data too long for description")
+
+			found, err := r.Find(context.Background())
+			assert.NoError(t, err)
+			assert.Len(t, found, 6)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, setupData(t))
+		})
+	}
+}
+
+func TestRepository_CreateCategory(t *testing.T) {
+	actor := test.RandomUsername()
+
+	tests := map[string]func(*testing.T, *Repository){
+		"when category does not exists": func(t *testing.T, r *Repository) {
+			// arrange
+			given := core.Category{Name: test.RandomName()}
+
+			// act
+			got, gotErr := r.CreateCategory(context.Background(), given, actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.True(t, got)
+			assert.True(t, r.categories[given.Name])
+			assert.Equal(t, actor, r.audit[len(r.audit)-1].Actor)
+			assert.Equal(t, given.Name, r.audit[len(r.audit)-1].EntityID)
+		},
+		"when category exists": func(t *testing.T, r *Repository) {
+			// arrange
+			entries := len(r.audit)
+
+			// act
+			got, gotErr := r.CreateCategory(context.Background(), core.Category{Name: "Entertainment"}, actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.False(t, got)
+			assert.Len(t, r.audit, entries)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, setupData(t))
+		})
+	}
+}
+
+func TestRepository_Find(t *testing.T) {
+	tests := map[string]func(t *testing.T, r *Repository){
+		"when transactions are found, order them by date": func(t *testing.T, r *Repository) {
+			// arrange
+			_, err := r.Create(context.Background(), core.Transaction{
+				Amount:   1,
+				Type:     core.Debit,
+				Category: core.Category{Name: "Food"},
+				Date:     time.Now().UTC().Add(-time.Hour * 48),
+			}, test.RandomUsername())
+			assert.NoError(t, err)
+
+			// act
+			got, gotErr := r.Find(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Len(t, got, 7)
+			assert.Equal(t, 7, got[0].ID)
+			for i, gotTrs := range got[1:] {
+				assert.Equal(t, i+1, gotTrs.ID)
+			}
+		},
+		"when no transactions are found": func(t *testing.T, r *Repository) {
+			// act
+			got, gotErr := NewRepository().Find(context.Background())
+
+			// assert
+			assert.Empty(t, got)
+			assert.NoError(t, gotErr)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, setupData(t))
+		})
+	}
+}
+
+func TestRepository_FindByID(t *testing.T) {
+	tests := map[string]func(t *testing.T, r *Repository){
+		"when transaction is found": func(t *testing.T, r *Repository) {
+			// act
+			got, gotErr := r.FindByID(context.Background(), 5)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, 5, got.ID)
+			assert.Equal(t, "Internet", got.Name)
+		},
+		"when transaction is not found": func(t *testing.T, r *Repository) {
+			// act
+			_, gotErr := r.FindByID(context.Background(), 1000)
+
+			// assert
+			assert.EqualError(t, gotErr, "Repository.FindByID failed: not found")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, setupData(t))
+		})
+	}
+}
+
+func TestRepository_Update(t *testing.T) {
+	actor := test.RandomUsername()
+	date := time.Now().UTC().Truncate(time.Hour * 24).Add(-time.Hour * 24)
+
+	tests := map[string]func(t *testing.T, r *Repository){
+		"when transaction is not found": func(t *testing.T, r *Repository) {
+			// act
+			_, gotErr := r.Update(context.Background(), core.Transaction{ID: 1000, Version: 1}, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "Repository.Update failed: not found")
+		},
+		"when version is stale": func(t *testing.T, r *Repository) {
+			// arrange
+			given := core.Transaction{ID: 1, Amount: 100, Type: core.Debit, Category: core.Category{Name: "Food"}, Version: 2}
+
+			// act
+			_, gotErr := r.Update(context.Background(), given, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "Repository.Update failed: stale version")
+		},
+		"when version is current": func(t *testing.T, r *Repository) {
+			// arrange
+			given := core.Transaction{
+				ID:       1,
+				Amount:   100,
+				Type:     core.Debit,
+				Category: core.Category{Name: "Travel"},
+				Date:     date,
+				Name:     "Flight",
+				Version:  1,
+			}
+
+			// act
+			got, gotErr := r.Update(context.Background(), given, actor)
+
+			want := given
+			want.Version = 2
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, want, got)
+
+			found, err := r.FindByID(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, want, found)
+
+			history, err := r.FindHistory(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Len(t, history, 2)
+			assert.Equal(t, core.AuditUpdate, history[1].Action)
+			assert.Equal(t, actor, history[1].Actor)
+			assert.NotEmpty(t, history[1].Before)
+			assert.JSONEq(t, string(transactionSnapshot(got)), string(history[1].After))
+		},
+		"when no date is given, keep the current one": func(t *testing.T, r *Repository) {
+			// arrange
+			before, err := r.FindByID(context.Background(), 1)
+			assert.NoError(t, err)
+
+			given := before
+			given.Amount = 100
+			given.Date = time.Time{}
+
+			// act
+			got, gotErr := r.Update(context.Background(), given, actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, before.Date, got.Date)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, setupData(t))
+		})
+	}
+}
+
+func TestRepository_Delete(t *testing.T) {
+	actor := test.RandomUsername()
+
+	tests := map[string]func(t *testing.T, r *Repository){
+		"when transaction is not found": func(t *testing.T, r *Repository) {
+			// act
+			_, gotErr := r.Delete(context.Background(), 1000, 1, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "Repository.Delete failed: not found")
+		},
+		"when version is stale": func(t *testing.T, r *Repository) {
+			// act
+			_, gotErr := r.Delete(context.Background(), 1, 2, actor)
+
+			// assert
+			assert.EqualError(t, gotErr, "Repository.Delete failed: stale version")
+		},
+		"when version is current": func(t *testing.T, r *Repository) {
+			// act
+			got, gotErr := r.Delete(context.Background(), 1, 1, actor)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, 1, got.ID)
+			assert.Equal(t, 1, got.Version)
+
+			_, err := r.FindByID(context.Background(), 1)
+			assert.EqualError(t, err, "Repository.FindByID failed: not found")
+
+			history, err := r.FindHistory(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Len(t, history, 2)
+			assert.Equal(t, core.AuditDelete, history[1].Action)
+			assert.Empty(t, history[1].After)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, setupData(t))
+		})
+	}
+}
+
+func TestRepository_FindHistory(t *testing.T) {
+	tests := map[string]func(t *testing.T, r *Repository){
+		"when transaction has no history": func(t *testing.T, r *Repository) {
+			// act
+			got, gotErr := r.FindHistory(context.Background(), 1000)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Empty(t, got)
+		},
+		"when transaction has history, oldest first": func(t *testing.T, r *Repository) {
+			// arrange
+			before, err := r.FindByID(context.Background(), 2)
+			assert.NoError(t, err)
+
+			before.Amount = 12
+			if _, err := r.Update(context.Background(), before, "someone"); err != nil {
+				t.Fatalf("Update failed: %s", err)
+			}
+
+			// act
+			got, gotErr := r.FindHistory(context.Background(), 2)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Len(t, got, 2)
+			assert.Equal(t, core.AuditCreate, got[0].Action)
+			assert.Equal(t, core.AuditUpdate, got[1].Action)
+			assert.True(t, got[0].ID < got[1].ID)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, setupData(t))
+		})
+	}
+}
+
+func TestRepository_concurrent_use(t *testing.T) {
+	// arrange
+	r := NewRepository()
+	actor := test.RandomUsername()
+
+	// act
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = r.Create(context.Background(), core.Transaction{Amount: 1, Type: core.Debit, Category: core.Category{Name: "Food"}}, actor)
+			_, _ = r.Find(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	got, err := r.Find(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, got, 50)
+	for i, gotTrs := range got {
+		assert.Equal(t, i+1, gotTrs.ID)
+	}
+}
+
+func TestDemo(t *testing.T) {
+	// arrange
+	r := NewRepository()
+
+	// act
+	got, gotErr := r.CreateBatch(context.Background(), Demo(time.Now()), DemoActor)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.NotEmpty(t, got)
+	for _, gotTrs := range got {
+		assert.NoError(t, gotTrs.Validate())
+	}
+}
+
+// setupData creates the same transactions as the db test data, dated now.
+func setupData(t *testing.T) *Repository {
+	r := NewRepository()
+
+	_, err := r.CreateBatch(context.Background(), []core.Transaction{
+		{Amount: 99, Type: core.Credit, Category: core.Category{Name: "Entertainment"}},
+		{Amount: 11, Type: core.Credit, Category: core.Category{Name: "Food"}},
+		{Amount: 32, Type: core.Credit, Category: core.Category{Name: "Food"}},
+		{Amount: 5300, Type: core.Income, Category: core.Category{Name: "Work"}},
+		{Amount: 129, Type: core.Debit, Category: core.Category{Name: "Home"}, Name: "Internet"},
+		{Amount: 129, Type: core.Debit, Category: core.Category{Name: "Home"}, Name: "Electricity"},
+	}, "setup")
+	if err != nil {
+		t.Fatalf("setupData failed: %s", err)
+	}
+
+	return r
+}
@@ -0,0 +1,201 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/webhook"
+)
+
+// WebhookStore is able to persist the webhook subscriptions and deliveries in memory, and to read the outbox of
+// the repository, sharing its lock.
+type WebhookStore struct {
+	r                  *Repository
+	subscriptions      []webhook.Subscription
+	deliveries         []webhook.Delivery
+	leases             map[int]time.Time
+	dispatched         int
+	lastSubscriptionID int
+	lastDeliveryID     int
+}
+
+// NewWebhookStore initialize an empty store, reading the outbox of the repository.
+func NewWebhookStore(r *Repository) *WebhookStore {
+	return &WebhookStore{r: r, leases: map[int]time.Time{}}
+}
+
+// CreateSubscription persists a subscription in memory.
+func (s *WebhookStore) CreateSubscription(_ context.Context, sub webhook.Subscription) (webhook.Subscription, error) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	s.lastSubscriptionID++
+	sub.ID = s.lastSubscriptionID
+	sub.Date = time.Now().UTC()
+	sub.Events = append([]string{}, sub.Events...)
+	s.subscriptions = append(s.subscriptions, sub)
+
+	return sub, nil
+}
+
+// FindSubscriptions finds the subscriptions in memory, oldest first.
+func (s *WebhookStore) FindSubscriptions(_ context.Context) ([]webhook.Subscription, error) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	return append([]webhook.Subscription{}, s.subscriptions...), nil
+}
+
+// DeleteSubscription removes a subscription from memory, along with its deliveries.
+func (s *WebhookStore) DeleteSubscription(_ context.Context, id int) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	i := s.findSubscription(id)
+	if i < 0 {
+		return errors.Wrap(core.ErrNotFound, "WebhookStore.DeleteSubscription failed")
+	}
+	s.subscriptions = append(s.subscriptions[:i], s.subscriptions[i+1:]...)
+
+	deliveries := s.deliveries[:0]
+	for _, d := range s.deliveries {
+		if d.SubscriptionID != id {
+			deliveries = append(deliveries, d)
+		}
+	}
+	s.deliveries = deliveries
+
+	return nil
+}
+
+// FindDeliveries finds the latest deliveries of a subscription in memory, newest first.
+func (s *WebhookStore) FindDeliveries(_ context.Context, subscriptionID, limit int) ([]webhook.Delivery, error) {
+	s.r.mu.RLock()
+	defer s.r.mu.RUnlock()
+
+	if s.findSubscription(subscriptionID) < 0 {
+		return []webhook.Delivery{}, errors.Wrap(core.ErrNotFound, "WebhookStore.FindDeliveries failed")
+	}
+
+	deliveries := []webhook.Delivery{}
+	for i := len(s.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if s.deliveries[i].SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, s.deliveries[i])
+		}
+	}
+
+	return deliveries, nil
+}
+
+// Fanout schedules a delivery of the undispatched events in memory to their subscriptions, marking them dispatched.
+func (s *WebhookStore) Fanout(_ context.Context, now time.Time, limit int) (int, error) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	events := s.r.outbox[s.dispatched:]
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
+	for _, event := range events {
+		for _, sub := range s.subscriptions {
+			if !sub.Subscribed(event.Event) {
+				continue
+			}
+
+			s.insertDelivery(webhook.Delivery{
+				SubscriptionID: sub.ID,
+				EventID:        event.ID,
+				Event:          event.Event,
+				Attempt:        1,
+				Status:         webhook.Pending,
+				ScheduledAt:    now,
+			})
+		}
+	}
+	s.dispatched += len(events)
+
+	return len(events), nil
+}
+
+// ClaimDue claims the pending deliveries in memory scheduled at or before now, and the sending ones whose lease
+// ended, marking them sending until now+lease, along with their subscription and event.
+func (s *WebhookStore) ClaimDue(_ context.Context, now time.Time, lease time.Duration, limit int) ([]webhook.Job, error) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	var due []int
+	for i, d := range s.deliveries {
+		pending := d.Status == webhook.Pending && !d.ScheduledAt.After(now)
+		expired := d.Status == webhook.Sending && !s.leases[d.ID].After(now)
+		if pending || expired {
+			due = append(due, i)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return s.deliveries[due[i]].ScheduledAt.Before(s.deliveries[due[j]].ScheduledAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	jobs := make([]webhook.Job, 0, len(due))
+	for _, i := range due {
+		s.deliveries[i].Status = webhook.Sending
+		s.leases[s.deliveries[i].ID] = now.Add(lease)
+
+		d := s.deliveries[i]
+		jobs = append(jobs, webhook.Job{
+			Delivery:     d,
+			Subscription: s.subscriptions[s.findSubscription(d.SubscriptionID)],
+			Event:        s.r.outbox[d.EventID-1],
+		})
+	}
+
+	return jobs, nil
+}
+
+// RecordAttempt saves the outcome of a delivery in memory, along with its retry when given.
+func (s *WebhookStore) RecordAttempt(_ context.Context, attempted webhook.Delivery, retry *webhook.Delivery) error {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+
+	for i, d := range s.deliveries {
+		if d.ID == attempted.ID {
+			s.deliveries[i].Status = attempted.Status
+			s.deliveries[i].ResponseCode = attempted.ResponseCode
+			s.deliveries[i].Error = attempted.Error
+			s.deliveries[i].AttemptedAt = attempted.AttemptedAt.UTC()
+			delete(s.leases, d.ID)
+		}
+	}
+
+	if retry != nil {
+		s.insertDelivery(*retry)
+	}
+
+	return nil
+}
+
+// findSubscription returns the index of a subscription, or -1, it must be called holding the lock.
+func (s *WebhookStore) findSubscription(id int) int {
+	for i, sub := range s.subscriptions {
+		if sub.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertDelivery must be called holding the lock.
+func (s *WebhookStore) insertDelivery(d webhook.Delivery) {
+	s.lastDeliveryID++
+	d.ID = s.lastDeliveryID
+	d.ScheduledAt = d.ScheduledAt.UTC()
+	s.deliveries = append(s.deliveries, d)
+}
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gritt/maskada/core"
+)
+
+// countTimeout bounds the count of the transactions on each scrape, so a slow database fails the metric rather than
+// holding the scrape until its own timeout.
+const countTimeout = 2 * time.Second
+
+// typeNames label the transaction types.
+var typeNames = map[int]string{core.Debit: "debit", core.Credit: "credit", core.Income: "income"}
+
+type (
+	// Pool represents a database connection pool able to report its stats.
+	Pool interface {
+		Stats() sql.DBStats
+	}
+
+	// Counter represents a repository able to count the transactions of each type.
+	Counter interface {
+		CountByType(ctx context.Context) (map[int]int, error)
+	}
+
+	poolCollector struct {
+		pool          Pool
+		open          *prometheus.Desc
+		inUse         *prometheus.Desc
+		idle          *prometheus.Desc
+		maxOpen       *prometheus.Desc
+		waitCount     *prometheus.Desc
+		waitDuration  *prometheus.Desc
+		closedMaxIdle *prometheus.Desc
+		closedMaxLife *prometheus.Desc
+	}
+
+	transactionCollector struct {
+		counter      Counter
+		transactions *prometheus.Desc
+	}
+)
+
+// NewPoolCollector collects the stats of a database connection pool as it is scraped.
+func NewPoolCollector(pool Pool) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:          pool,
+		open:          desc("open_connections", "Established connections, in use or idle."),
+		inUse:         desc("in_use_connections", "Connections currently in use."),
+		idle:          desc("idle_connections", "Idle connections."),
+		maxOpen:       desc("max_open_connections", "Maximum number of open connections, 0 when unlimited."),
+		waitCount:     desc("wait_count_total", "Connections waited for."),
+		waitDuration:  desc("wait_duration_seconds_total", "How long connections were waited for."),
+		closedMaxIdle: desc("max_idle_closed_total", "Connections closed as the idle pool was full."),
+		closedMaxLife: desc("max_lifetime_closed_total", "Connections closed as they reached their maximum lifetime."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.maxOpen
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.closedMaxIdle
+	ch <- c.closedMaxLife
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.pool.Stats()
+
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.closedMaxIdle, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.closedMaxLife, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
+
+// NewTransactionCollector counts the transactions by type as it is scraped, a single grouped query each time.
+func NewTransactionCollector(counter Counter) prometheus.Collector {
+	return &transactionCollector{
+		counter: counter,
+		transactions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "transactions"),
+			"Stored transactions, by type.",
+			[]string{"type"},
+			nil,
+		),
+	}
+}
+
+func (c *transactionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.transactions
+}
+
+func (c *transactionCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+
+	counts, err := c.counter.CountByType(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.transactions, err)
+		return
+	}
+
+	for typ, name := range typeNames {
+		ch <- prometheus.MustNewConstMetric(c.transactions, prometheus.GaugeValue, float64(counts[typ]), name)
+	}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+)
+
+func TestPoolCollector(t *testing.T) {
+	// arrange
+	pool := mockPool{stats: sql.DBStats{
+		MaxOpenConnections: 10,
+		OpenConnections:    3,
+		InUse:              2,
+		Idle:               1,
+		WaitCount:          4,
+		WaitDuration:       1500 * time.Millisecond,
+	}}
+
+	// act
+	gotErr := testutil.CollectAndCompare(NewPoolCollector(pool), strings.NewReader(`
+# HELP maskada_db_in_use_connections Connections currently in use.
+# TYPE maskada_db_in_use_connections gauge
+maskada_db_in_use_connections 2
+# HELP maskada_db_open_connections Established connections, in use or idle.
+# TYPE maskada_db_open_connections gauge
+maskada_db_open_connections 3
+# HELP maskada_db_wait_count_total Connections waited for.
+# TYPE maskada_db_wait_count_total counter
+maskada_db_wait_count_total 4
+# HELP maskada_db_wait_duration_seconds_total How long connections were waited for.
+# TYPE maskada_db_wait_duration_seconds_total counter
+maskada_db_wait_duration_seconds_total 1.5
+`), "maskada_db_in_use_connections", "maskada_db_open_connections", "maskada_db_wait_count_total", "maskada_db_wait_duration_seconds_total")
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Equal(t, 8, testutil.CollectAndCount(NewPoolCollector(pool)))
+}
+
+func TestTransactionCollector(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when counted, report each type": func(t *testing.T) {
+			// arrange
+			counter := &mockCounter{counts: map[int]int{core.Debit: 2, core.Income: 1}}
+
+			// act
+			gotErr := testutil.CollectAndCompare(NewTransactionCollector(counter), strings.NewReader(`
+# HELP maskada_transactions Stored transactions, by type.
+# TYPE maskada_transactions gauge
+maskada_transactions{type="credit"} 0
+maskada_transactions{type="debit"} 2
+maskada_transactions{type="income"} 1
+`))
+
+			// assert
+			assert.NoError(t, gotErr)
+			deadline, ok := counter.ctx.Deadline()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(countTimeout), deadline, time.Second)
+		},
+		"when the count fails, report it": func(t *testing.T) {
+			// arrange
+			counter := &mockCounter{err: errors.New("Repository.CountByType failed: connection refused")}
+
+			// act
+			gotErr := testutil.CollectAndCompare(NewTransactionCollector(counter), strings.NewReader(""))
+
+			// assert
+			assert.Error(t, gotErr)
+			assert.Contains(t, gotErr.Error(), "Repository.CountByType failed: connection refused")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+type mockPool struct {
+	stats sql.DBStats
+}
+
+func (m mockPool) Stats() sql.DBStats {
+	return m.stats
+}
+
+// mockCounter records the context of the latest count.
+type mockCounter struct {
+	counts map[int]int
+	err    error
+	ctx    context.Context
+}
+
+func (m *mockCounter) CountByType(ctx context.Context) (map[int]int, error) {
+	m.ctx = ctx
+	return m.counts, m.err
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_Handler(t *testing.T) {
+	tests := map[string]func(*testing.T, *Metrics, http.Handler){
+		"when a route matches, count it by pattern": func(t *testing.T, m *Metrics, routes http.Handler) {
+			// act
+			serve(routes, http.MethodGet, "/v1/transaction/1")
+			serve(routes, http.MethodGet, "/v1/transaction/2")
+
+			// assert
+			assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, "/v1/transaction/{id}", "200")))
+			assert.Equal(t, 1, testutil.CollectAndCount(m.durations))
+		},
+		"when the handler fails, count its status": func(t *testing.T, m *Metrics, routes http.Handler) {
+			// act
+			serve(routes, http.MethodDelete, "/v1/transaction/1")
+
+			// assert
+			assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues(http.MethodDelete, "/v1/transaction/{id}", "412")))
+		},
+		"when no route matches, count it as unmatched": func(t *testing.T, m *Metrics, routes http.Handler) {
+			// act
+			serve(routes, http.MethodGet, "/unknown/1")
+			serve(routes, http.MethodGet, "/unknown/2")
+
+			// assert
+			assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues(http.MethodGet, "unmatched", "404")))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := NewMetrics()
+
+			r := chi.NewRouter()
+			r.Use(m.Handler)
+			r.Route("/v1", func(r chi.Router) {
+				r.Get("/transaction/{id}", func(w http.ResponseWriter, r *http.Request) {})
+				r.Delete("/transaction/{id}", func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusPreconditionFailed)
+				})
+			})
+
+			run(t, m, r)
+		})
+	}
+}
+
+func TestMetrics_Exposer(t *testing.T) {
+	// arrange
+	m := NewMetrics()
+	m.creates.WithLabelValues(outcomeCreated).Inc()
+
+	rr := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+
+	// act
+	m.Exposer().ServeHTTP(rr, r)
+
+	// assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, strings.HasPrefix(rr.Header().Get("Content-Type"), "text/plain"))
+	assert.Contains(t, rr.Body.String(), `maskada_transactions_created_total{outcome="created"} 1`)
+	assert.Contains(t, rr.Body.String(), "go_goroutines")
+}
+
+func serve(h http.Handler, method, path string) {
+	r, _ := http.NewRequest(method, path, nil)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+)
+
+// The outcomes of the use cases, by the class of their error.
+const (
+	outcomeCreated = "created"
+	outcomeInvalid = "invalid"
+	outcomeFailed  = "failed"
+)
+
+type (
+	// Creator represents a use case able to create a transaction.
+	Creator interface {
+		Create(ctx context.Context, t core.Transaction, actor string) (core.Transaction, error)
+	}
+
+	// TransactionCreator counts the outcomes of the transactions created by a use case.
+	TransactionCreator struct {
+		next    Creator
+		metrics *Metrics
+	}
+)
+
+// NewTransactionCreator initialize the use case decorator.
+func NewTransactionCreator(next Creator, m *Metrics) *TransactionCreator {
+	return &TransactionCreator{next: next, metrics: m}
+}
+
+// Create a transaction with the decorated use case, counting its outcome.
+func (c *TransactionCreator) Create(ctx context.Context, t core.Transaction, actor string) (core.Transaction, error) {
+	created, err := c.next.Create(ctx, t, actor)
+	c.metrics.creates.WithLabelValues(outcome(err)).Inc()
+	return created, err
+}
+
+// outcome classifies the error returned by a use case.
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return outcomeCreated
+	case errors.Cause(err) == core.ErrInvalidTransaction:
+		return outcomeInvalid
+	default:
+		return outcomeFailed
+	}
+}
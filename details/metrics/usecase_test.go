@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gritt/maskada/core"
+)
+
+func TestTransactionCreator_Create(t *testing.T) {
+	given := core.Transaction{Amount: 100, Type: core.Debit, Category: core.Category{Name: "Food"}}
+	_, invalidErr := core.NewCreateTransactionUseCase(nil, nil).Create(context.Background(), core.Transaction{}, "tester")
+
+	tests := map[string]struct {
+		err         error
+		wantOutcome string
+	}{
+		"when created": {
+			wantOutcome: outcomeCreated,
+		},
+		"when invalid": {
+			err:         invalidErr,
+			wantOutcome: outcomeInvalid,
+		},
+		"when the repository fails": {
+			err:         errors.New("Create failed: Repository.Create failed: connection refused"),
+			wantOutcome: outcomeFailed,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := NewMetrics()
+			next := new(mockCreator)
+			next.On("Create", given, "tester").Return(given, tt.err)
+
+			// act
+			_, gotErr := NewTransactionCreator(next, m).Create(context.Background(), given, "tester")
+
+			// assert
+			assert.Equal(t, tt.err, gotErr)
+			assert.Equal(t, float64(1), testutil.ToFloat64(m.creates.WithLabelValues(tt.wantOutcome)))
+			assert.Equal(t, 1, testutil.CollectAndCount(m.creates))
+			next.AssertExpectations(t)
+		})
+	}
+}
+
+type mockCreator struct {
+	mock.Mock
+}
+
+func (m *mockCreator) Create(_ context.Context, t core.Transaction, actor string) (core.Transaction, error) {
+	args := m.Called(t, actor)
+	return args.Get(0).(core.Transaction), args.Error(1)
+}
@@ -0,0 +1,336 @@
+// Package migrate applies and reverts numbered schema migrations, recording them in the schema_migrations table.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// Migration is a numbered schema change, along with the statements reverting it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status tells whether a migration is applied, and when.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Locker serializes migrations of instances sharing a database, holding a lock on conn.
+type Locker interface {
+	Lock(ctx context.Context, conn *sql.Conn) error
+	Unlock(ctx context.Context, conn *sql.Conn) error
+}
+
+// NoLock is the Locker of databases used by a single instance.
+type NoLock struct{}
+
+// Lock does nothing.
+func (NoLock) Lock(context.Context, *sql.Conn) error { return nil }
+
+// Unlock does nothing.
+func (NoLock) Unlock(context.Context, *sql.Conn) error { return nil }
+
+// Migrator is able to apply, revert and report the migrations of a database.
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+	locker     Locker
+}
+
+// fileName is the pattern of migration files, eg: 0001_init.up.sql and 0001_init.down.sql.
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// NewMigrator initialize the migrator, loading the migration files of fsys.
+func NewMigrator(db *sqlx.DB, fsys fs.FS, locker Locker) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewMigrator failed")
+	}
+
+	return &Migrator{db: db, migrations: migrations, locker: locker}, nil
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, file := range files {
+		match := fileName.FindStringSubmatch(file.Name())
+		if match == nil {
+			continue
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, m.Name, match[2])
+		}
+
+		script, err := fs.ReadFile(fsys, file.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if match[3] == "up" {
+			m.Up = string(script)
+		} else {
+			m.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d %s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies the pending migrations in order, returning the applied ones.
+func (m *Migrator) Up() ([]Migration, error) {
+	var applied []Migration
+
+	err := m.locked(func(ctx context.Context, conn *sql.Conn) error {
+		current, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := current[migration.Version]; ok {
+				continue
+			}
+
+			insert := m.db.Rebind(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`)
+			ran, err := m.run(ctx, conn, migration.Version, false, migration.Up, insert, migration.Version, migration.Name, time.Now().UTC())
+			if err != nil {
+				return errors.Wrapf(err, "migration %d %s", migration.Version, migration.Name)
+			}
+
+			if ran {
+				applied = append(applied, migration)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return applied, errors.Wrap(err, "Migrator.Up failed")
+	}
+
+	return applied, nil
+}
+
+// Down reverts the last n applied migrations, newest first, returning the reverted ones.
+func (m *Migrator) Down(n int) ([]Migration, error) {
+	var reverted []Migration
+
+	err := m.locked(func(ctx context.Context, conn *sql.Conn) error {
+		current, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < n; i-- {
+			migration := m.migrations[i]
+			if _, ok := current[migration.Version]; !ok {
+				continue
+			}
+
+			remove := m.db.Rebind(`DELETE FROM schema_migrations WHERE version = ?`)
+			ran, err := m.run(ctx, conn, migration.Version, true, migration.Down, remove, migration.Version)
+			if err != nil {
+				return errors.Wrapf(err, "migration %d %s", migration.Version, migration.Name)
+			}
+
+			if ran {
+				reverted = append(reverted, migration)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return reverted, errors.Wrap(err, "Migrator.Down failed")
+	}
+
+	return reverted, nil
+}
+
+// Status reports every known migration, oldest first, without waiting for running migrations.
+func (m *Migrator) Status() ([]Status, error) {
+	ctx := context.Background()
+
+	if err := m.prepare(ctx, m.db); err != nil {
+		return nil, errors.Wrap(err, "Migrator.Status failed")
+	}
+
+	current, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, errors.Wrap(err, "Migrator.Status failed")
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := current[migration.Version]
+		statuses = append(statuses, Status{Migration: migration, Applied: ok, AppliedAt: appliedAt})
+	}
+
+	return statuses, nil
+}
+
+// Pending finds the known migrations which are not applied, oldest first, without waiting for running migrations.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	current, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, errors.Wrap(err, "Migrator.Pending failed")
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := current[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// Version finds the newest applied migration version, 0 when none is.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	current, err := m.applied(ctx, m.db)
+	if err != nil {
+		return 0, errors.Wrap(err, "Migrator.Version failed")
+	}
+
+	version := 0
+	for v := range current {
+		if v > version {
+			version = v
+		}
+	}
+
+	return version, nil
+}
+
+// locked runs fn holding the lock, on a connection with the schema_migrations table.
+func (m *Migrator) locked(fn func(ctx context.Context, conn *sql.Conn) error) error {
+	ctx := context.Background()
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := m.locker.Lock(ctx, conn); err != nil {
+		return errors.Wrap(err, "lock")
+	}
+	defer func() { _ = m.locker.Unlock(ctx, conn) }()
+
+	if err := m.prepare(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(ctx, conn)
+}
+
+// queryer is either the database or one of its connections.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// prepare creates the schema_migrations table, unless it exists.
+func (m *Migrator) prepare(ctx context.Context, conn queryer) error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER      NOT NULL PRIMARY KEY,
+		name       VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP    NOT NULL
+	)`
+	_, err := conn.ExecContext(ctx, query)
+	return err
+}
+
+// applied finds the applied versions, along with when they were applied.
+func (m *Migrator) applied(ctx context.Context, conn queryer) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt.UTC()
+	}
+
+	return applied, rows.Err()
+}
+
+// run executes a migration script and records it within a transaction, unless the version is no longer as expected
+// once the transaction began, as another instance migrated meanwhile, telling whether it ran. MySQL commits the
+// transaction implicitly on schema changes, so a failing script may be partially applied there.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, version int, applied bool, script, record string, args ...interface{}) (bool, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var count int
+	if err := tx.QueryRowContext(ctx, m.db.Rebind(`SELECT COUNT(*) FROM schema_migrations WHERE version = ?`), version).Scan(&count); err != nil {
+		return false, err
+	}
+	if (count > 0) != applied {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
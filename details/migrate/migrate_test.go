@@ -0,0 +1,330 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	// imports sqlite db driver
+	_ "modernc.org/sqlite"
+)
+
+func TestNewMigrator(t *testing.T) {
+	tests := map[string]func(t *testing.T){
+		"when migrations are given, sort them by version": func(t *testing.T) {
+			// arrange
+			fsys := fstest.MapFS{
+				"0002_second.up.sql":   {Data: []byte("up 2")},
+				"0002_second.down.sql": {Data: []byte("down 2")},
+				"0001_first.up.sql":    {Data: []byte("up 1")},
+				"0001_first.down.sql":  {Data: []byte("down 1")},
+				"README.md":            {Data: []byte("ignored")},
+			}
+
+			// act
+			got, gotErr := NewMigrator(nil, fsys, NoLock{})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []Migration{
+				{Version: 1, Name: "first", Up: "up 1", Down: "down 1"},
+				{Version: 2, Name: "second", Up: "up 2", Down: "down 2"},
+			}, got.migrations)
+		},
+		"when a down migration is missing": func(t *testing.T) {
+			// arrange
+			fsys := fstest.MapFS{
+				"0001_first.up.sql": {Data: []byte("up 1")},
+			}
+
+			// act
+			_, gotErr := NewMigrator(nil, fsys, NoLock{})
+
+			// assert
+			assert.EqualError(t, gotErr, "NewMigrator failed: migration 1 first must have both up and down files")
+		},
+		"when a version has two names": func(t *testing.T) {
+			// arrange
+			fsys := fstest.MapFS{
+				"0001_first.up.sql":   {Data: []byte("up 1")},
+				"0001_other.down.sql": {Data: []byte("down 1")},
+			}
+
+			// act
+			_, gotErr := NewMigrator(nil, fsys, NoLock{})
+
+			// assert
+			assert.EqualError(t, gotErr, "NewMigrator failed: migration 1 is named both first and other")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_create_category.up.sql":   {Data: []byte(`CREATE TABLE category (name VARCHAR(80) NOT NULL PRIMARY KEY);`)},
+		"0001_create_category.down.sql": {Data: []byte(`DROP TABLE category;`)},
+		"0002_add_color.up.sql":         {Data: []byte(`ALTER TABLE category ADD COLUMN color VARCHAR(7) NULL;`)},
+		"0002_add_color.down.sql":       {Data: []byte(`ALTER TABLE category DROP COLUMN color;`)},
+	}
+
+	tests := map[string]func(t *testing.T, m *Migrator, db *sqlx.DB){
+		"when migrations are pending, apply them in order": func(t *testing.T, m *Migrator, db *sqlx.DB) {
+			// act
+			got, gotErr := m.Up()
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []int{1, 2}, versions(got))
+
+			_, err := db.Exec(`INSERT INTO category (name, color) VALUES ('Food', '#ff0000')`)
+			assert.NoError(t, err)
+		},
+		"when migrations are applied, apply none": func(t *testing.T, m *Migrator, db *sqlx.DB) {
+			// arrange
+			if _, err := m.Up(); err != nil {
+				t.Fatalf("Up failed: %s", err)
+			}
+
+			// act
+			got, gotErr := m.Up()
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Empty(t, got)
+		},
+		"when a migration fails, stop and keep the applied ones": func(t *testing.T, m *Migrator, db *sqlx.DB) {
+			// arrange
+			broken, err := NewMigrator(db, fstest.MapFS{
+				"0001_create_category.up.sql":   fsys["0001_create_category.up.sql"],
+				"0001_create_category.down.sql": fsys["0001_create_category.down.sql"],
+				"0002_broken.up.sql":            {Data: []byte(`ALTER TABLE missing ADD COLUMN color VARCHAR(7) NULL;`)},
+				"0002_broken.down.sql":          {Data: []byte(`SELECT 1;`)},
+			}, NoLock{})
+			if err != nil {
+				t.Fatalf("NewMigrator failed: %s", err)
+			}
+
+			// act
+			got, gotErr := broken.Up()
+
+			// assert
+			assert.Error(t, gotErr)
+			assert.Contains(t, gotErr.Error(), "Migrator.Up failed: migration 2 broken")
+			assert.Equal(t, []int{1}, versions(got))
+
+			statuses, err := broken.Status()
+			assert.NoError(t, err)
+			assert.True(t, statuses[0].Applied)
+			assert.False(t, statuses[1].Applied)
+		},
+		"when reverting, revert the newest first": func(t *testing.T, m *Migrator, db *sqlx.DB) {
+			// arrange
+			if _, err := m.Up(); err != nil {
+				t.Fatalf("Up failed: %s", err)
+			}
+
+			// act
+			got, gotErr := m.Down(1)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []int{2}, versions(got))
+
+			_, err := db.Exec(`INSERT INTO category (name, color) VALUES ('Food', '#ff0000')`)
+			assert.Error(t, err)
+		},
+		"when reverting more than applied, revert all": func(t *testing.T, m *Migrator, db *sqlx.DB) {
+			// arrange
+			if _, err := m.Up(); err != nil {
+				t.Fatalf("Up failed: %s", err)
+			}
+
+			// act
+			got, gotErr := m.Down(10)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []int{2, 1}, versions(got))
+
+			_, err := db.Exec(`SELECT * FROM category`)
+			assert.Error(t, err)
+		},
+		"when reporting status": func(t *testing.T, m *Migrator, db *sqlx.DB) {
+			// arrange
+			if _, err := m.Up(); err != nil {
+				t.Fatalf("Up failed: %s", err)
+			}
+			if _, err := m.Down(1); err != nil {
+				t.Fatalf("Down failed: %s", err)
+			}
+
+			// act
+			got, gotErr := m.Status()
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Len(t, got, 2)
+			assert.Equal(t, "create_category", got[0].Name)
+			assert.True(t, got[0].Applied)
+			assert.False(t, got[0].AppliedAt.IsZero())
+			assert.Equal(t, "add_color", got[1].Name)
+			assert.False(t, got[1].Applied)
+			assert.True(t, got[1].AppliedAt.IsZero())
+		},
+		"when migrations are pending, report them with the applied version": func(t *testing.T, m *Migrator, db *sqlx.DB) {
+			// arrange
+			if _, err := m.Up(); err != nil {
+				t.Fatalf("Up failed: %s", err)
+			}
+			if _, err := m.Down(1); err != nil {
+				t.Fatalf("Down failed: %s", err)
+			}
+
+			// act
+			got, gotErr := m.Pending(context.Background())
+			gotVersion, gotVersionErr := m.Version(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []int{2}, versions(got))
+			assert.NoError(t, gotVersionErr)
+			assert.Equal(t, 1, gotVersion)
+		},
+		"when another instance applied a migration meanwhile, skip it": func(t *testing.T, m *Migrator, db *sqlx.DB) {
+			// arrange
+			if _, err := m.Up(); err != nil {
+				t.Fatalf("Up failed: %s", err)
+			}
+			conn, err := db.Conn(context.Background())
+			if err != nil {
+				t.Fatalf("Conn failed: %s", err)
+			}
+			defer conn.Close()
+
+			// act
+			gotRan, gotErr := m.run(context.Background(), conn, 2, false, `ALTER TABLE category ADD COLUMN color VARCHAR(7) NULL;`,
+				`INSERT INTO schema_migrations (version, name, applied_at) VALUES (2, 'add_color', CURRENT_TIMESTAMP)`)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.False(t, gotRan)
+		},
+		"when another instance reverted a migration meanwhile, skip it": func(t *testing.T, m *Migrator, db *sqlx.DB) {
+			// arrange
+			if _, err := m.Up(); err != nil {
+				t.Fatalf("Up failed: %s", err)
+			}
+			if _, err := m.Down(1); err != nil {
+				t.Fatalf("Down failed: %s", err)
+			}
+			conn, err := db.Conn(context.Background())
+			if err != nil {
+				t.Fatalf("Conn failed: %s", err)
+			}
+			defer conn.Close()
+
+			// act
+			gotRan, gotErr := m.run(context.Background(), conn, 2, true, `ALTER TABLE category DROP COLUMN color;`,
+				`DELETE FROM schema_migrations WHERE version = 2`)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.False(t, gotRan)
+		},
+		"when never migrated, report every migration pending": func(t *testing.T, m *Migrator, db *sqlx.DB) {
+			// act
+			got, gotErr := m.Status()
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Len(t, got, 2)
+			assert.False(t, got[0].Applied)
+			assert.False(t, got[1].Applied)
+		},
+		"when never migrated, fail to report pending migrations": func(t *testing.T, m *Migrator, db *sqlx.DB) {
+			// act
+			_, gotErr := m.Pending(context.Background())
+
+			// assert
+			assert.Error(t, gotErr)
+			assert.Contains(t, gotErr.Error(), "Migrator.Pending failed")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, err := sqlx.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "migrate.db"))
+			if err != nil {
+				t.Fatalf("Open failed: %s", err)
+			}
+			defer db.Close()
+
+			m, err := NewMigrator(db, fsys, NoLock{})
+			if err != nil {
+				t.Fatalf("NewMigrator failed: %s", err)
+			}
+
+			run(t, m, db)
+		})
+	}
+}
+
+func TestMigrator_lock(t *testing.T) {
+	// arrange
+	db, err := sqlx.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+	defer db.Close()
+
+	locker := &mockLocker{}
+	m, err := NewMigrator(db, fstest.MapFS{}, locker)
+	if err != nil {
+		t.Fatalf("NewMigrator failed: %s", err)
+	}
+
+	// act
+	_, gotUpErr := m.Up()
+	_, gotDownErr := m.Down(1)
+	_, gotStatusErr := m.Status()
+
+	// assert
+	assert.NoError(t, gotUpErr)
+	assert.NoError(t, gotDownErr)
+	assert.NoError(t, gotStatusErr)
+	assert.Equal(t, 2, locker.locks, "Status must not wait for running migrations")
+	assert.Equal(t, 2, locker.unlocks)
+}
+
+type mockLocker struct {
+	locks, unlocks int
+}
+
+func (l *mockLocker) Lock(context.Context, *sql.Conn) error {
+	l.locks++
+	return nil
+}
+
+func (l *mockLocker) Unlock(context.Context, *sql.Conn) error {
+	l.unlocks++
+	return nil
+}
+
+func versions(migrations []Migration) []int {
+	var versions []int
+	for _, m := range migrations {
+		versions = append(versions, m.Version)
+	}
+	return versions
+}
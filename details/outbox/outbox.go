@@ -0,0 +1,89 @@
+// Package outbox defines the messages of the domain events appended to the outbox, from which they are delivered to
+// the webhooks and relayed at least once.
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+)
+
+// Message is an encoded event recorded in the outbox, its ID being assigned by the outbox.
+type Message struct {
+	ID      int             `json:"id,omitempty"`
+	Event   string          `json:"event"`
+	Date    time.Time       `json:"date"`
+	Payload json.RawMessage `json:"data"`
+}
+
+type transaction struct {
+	ID       int       `json:"id"`
+	Amount   int       `json:"amount"`
+	Type     int       `json:"type"`
+	Category string    `json:"category"`
+	Date     time.Time `json:"date"`
+	Name     string    `json:"name"`
+	Version  int       `json:"version"`
+}
+
+type category struct {
+	Name string `json:"name"`
+}
+
+type transactionPayload struct {
+	Transaction transaction `json:"transaction"`
+	Actor       string      `json:"actor"`
+}
+
+func newTransactionPayload(t core.Transaction, actor string) transactionPayload {
+	return transactionPayload{
+		Transaction: transaction{
+			ID:       t.ID,
+			Amount:   t.Amount,
+			Type:     t.Type,
+			Category: t.Category.Name,
+			Date:     t.Date.UTC(),
+			Name:     t.Name,
+			Version:  t.Version,
+		},
+		Actor: actor,
+	}
+}
+
+// Encode an event as a message, eg: {"event":"transaction.deleted","date":"...","data":{"transaction":{...},...}}.
+func Encode(event core.Event) (Message, error) {
+	var (
+		payload interface{}
+		date    time.Time
+	)
+
+	switch e := event.(type) {
+	case core.TransactionCreated:
+		payload = newTransactionPayload(e.Transaction, e.Actor)
+		date = e.Date
+	case core.TransactionUpdated:
+		payload = newTransactionPayload(e.Transaction, e.Actor)
+		date = e.Date
+	case core.TransactionDeleted:
+		payload = newTransactionPayload(e.Transaction, e.Actor)
+		date = e.Date
+	case core.CategoryCreated:
+		payload = struct {
+			Category category `json:"category"`
+			Actor    string   `json:"actor"`
+		}{category{Name: e.Category.Name}, e.Actor}
+		date = e.Date
+	default:
+		return Message{}, errors.Errorf("Encode failed: unknown event %T", event)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Message{}, errors.Wrap(err, "Encode failed")
+	}
+
+	return Message{Event: event.EventName(), Date: date.UTC(), Payload: data}, nil
+}
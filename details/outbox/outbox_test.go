@@ -0,0 +1,71 @@
+package outbox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+)
+
+func TestEncode(t *testing.T) {
+	date := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	transaction := core.Transaction{
+		ID:       3,
+		Amount:   1000,
+		Type:     core.Debit,
+		Category: core.Category{Name: "Food"},
+		Date:     date,
+		Name:     "Lunch",
+		Version:  2,
+	}
+
+	tests := map[string]struct {
+		event       core.Event
+		wantPayload string
+	}{
+		"when a transaction is created": {
+			event:       core.TransactionCreated{Transaction: transaction, Actor: "tester", Date: date},
+			wantPayload: `{"transaction":{"id":3,"amount":1000,"type":1,"category":"Food","date":"2026-01-02T03:04:05Z","name":"Lunch","version":2},"actor":"tester"}`,
+		},
+		"when a transaction is updated": {
+			event:       core.TransactionUpdated{Transaction: transaction, Actor: "tester", Date: date},
+			wantPayload: `{"transaction":{"id":3,"amount":1000,"type":1,"category":"Food","date":"2026-01-02T03:04:05Z","name":"Lunch","version":2},"actor":"tester"}`,
+		},
+		"when a transaction is deleted": {
+			event:       core.TransactionDeleted{Transaction: transaction, Actor: "tester", Date: date},
+			wantPayload: `{"transaction":{"id":3,"amount":1000,"type":1,"category":"Food","date":"2026-01-02T03:04:05Z","name":"Lunch","version":2},"actor":"tester"}`,
+		},
+		"when a category is created": {
+			event:       core.CategoryCreated{Category: core.Category{Name: "Food"}, Actor: "tester", Date: date},
+			wantPayload: `{"category":{"name":"Food"},"actor":"tester"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			got, gotErr := Encode(tt.event)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tt.event.EventName(), got.Event)
+			assert.Equal(t, date, got.Date)
+			assert.JSONEq(t, tt.wantPayload, string(got.Payload))
+		})
+	}
+}
+
+type unknownEvent struct{}
+
+func (unknownEvent) EventName() string { return "transaction.viewed" }
+
+func TestEncode_unknown(t *testing.T) {
+	// act
+	got, gotErr := Encode(unknownEvent{})
+
+	// assert
+	assert.Equal(t, Message{}, got)
+	assert.EqualError(t, gotErr, "Encode failed: unknown event outbox.unknownEvent")
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/gritt/maskada/details/sqlstore"
+	"github.com/gritt/maskada/details/sqlstore/sqlstoretest"
+)
+
+func TestConformance(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	cfg, err := mockDBConfig()
+	if err != nil {
+		t.Fatalf("mockDBConfig failed: %s", err)
+	}
+
+	sqlstoretest.Run(t, func(t *testing.T, eventSourced bool) *sqlstore.Repository {
+		cfg := cfg
+		cfg.Storage.EventSourced = eventSourced
+
+		r, err := NewRepository(&cfg)
+		if err != nil {
+			t.Fatalf("NewRepository failed: %s", err)
+		}
+
+		migrateDB(t, r.DB())
+		t.Cleanup(func() { _ = r.DB().Close() })
+
+		return r
+	})
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/details/migrate"
+	"github.com/gritt/maskada/details/sqlstore"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// lockName identifies the advisory lock held while migrating.
+const lockName = "maskada.migrate"
+
+// NewMigrator initialize the migrator of the db schema, sharing the repository connection.
+func NewMigrator(r *sqlstore.Repository) (*migrate.Migrator, error) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, errors.Wrap(err, "NewMigrator failed")
+	}
+
+	return migrate.NewMigrator(r.DB(), fsys, locker{})
+}
+
+// locker holds a session advisory lock, which Postgres releases as well when the connection closes.
+type locker struct{}
+
+func (locker) Lock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock(hashtext($1))`, lockName)
+	return err
+}
+
+func (locker) Unlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock(hashtext($1))`, lockName)
+	return err
+}
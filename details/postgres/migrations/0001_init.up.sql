@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS "category"
+(
+    "name" VARCHAR(80) NOT NULL,
+    PRIMARY KEY ("name")
+);
+
+CREATE TABLE IF NOT EXISTS "transaction"
+(
+    "id"          SERIAL      NOT NULL,
+    "amount"      INTEGER     NOT NULL DEFAULT 0,
+    "type"        INTEGER     NOT NULL,
+    "category"    VARCHAR(80) NOT NULL,
+    CONSTRAINT "fk_category"
+        FOREIGN KEY ("category") REFERENCES "category" ("name")
+            ON DELETE RESTRICT
+            ON UPDATE CASCADE,
+    "description" VARCHAR(80) NULL,
+    "date"        TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
@@ -0,0 +1,32 @@
+CREATE TABLE "audit"
+(
+    "id"        SERIAL      NOT NULL,
+    "entity"    VARCHAR(20) NOT NULL,
+    "entity_id" VARCHAR(80) NOT NULL,
+    "action"    VARCHAR(20) NOT NULL,
+    "actor"     VARCHAR(80) NOT NULL,
+    "before"    JSONB       NULL,
+    "after"     JSONB       NULL,
+    "date"      TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_audit_entity" ON "audit" ("entity", "entity_id");
+
+CREATE OR REPLACE FUNCTION "audit_append_only"() RETURNS TRIGGER
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit is append-only';
+END;
+$$;
+
+CREATE TRIGGER "audit_append_only_update"
+    BEFORE UPDATE
+    ON "audit"
+    FOR EACH ROW EXECUTE FUNCTION "audit_append_only"();
+
+CREATE TRIGGER "audit_append_only_delete"
+    BEFORE DELETE
+    ON "audit"
+    FOR EACH ROW EXECUTE FUNCTION "audit_append_only"();
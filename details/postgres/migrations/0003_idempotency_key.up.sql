@@ -0,0 +1,11 @@
+CREATE TABLE "idempotency_key"
+(
+    "key"          VARCHAR(255) NOT NULL,
+    "request_hash" VARCHAR(64)  NOT NULL,
+    "status"       INTEGER      NOT NULL DEFAULT 0,
+    "body"         BYTEA        NULL,
+    "expires_at"   TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY ("key")
+);
+
+CREATE INDEX "idx_idempotency_key_expires_at" ON "idempotency_key" ("expires_at");
@@ -0,0 +1,2 @@
+ALTER TABLE "transaction"
+    ADD COLUMN "version" INTEGER NOT NULL DEFAULT 1;
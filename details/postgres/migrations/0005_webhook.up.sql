@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS "outbox"
+(
+    "id"            SERIAL      NOT NULL,
+    "event"         VARCHAR(40) NOT NULL,
+    "payload"       JSONB       NOT NULL,
+    "date"          TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "dispatched_at" TIMESTAMPTZ NULL,
+    "relayed_at"    TIMESTAMPTZ NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_outbox_dispatched_at" ON "outbox" ("dispatched_at", "id");
+CREATE INDEX IF NOT EXISTS "idx_outbox_relayed_at" ON "outbox" ("relayed_at", "id");
+
+CREATE TABLE IF NOT EXISTS "webhook_subscription"
+(
+    "id"     SERIAL        NOT NULL,
+    "url"    VARCHAR(2048) NOT NULL,
+    "secret" VARCHAR(255)  NOT NULL,
+    "events" VARCHAR(255)  NOT NULL,
+    "date"   TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "webhook_delivery"
+(
+    "id"              SERIAL       NOT NULL,
+    "subscription_id" INTEGER      NOT NULL,
+    CONSTRAINT "fk_webhook_delivery_subscription"
+        FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscription" ("id")
+            ON DELETE CASCADE,
+    "event_id"        INTEGER      NOT NULL,
+    CONSTRAINT "fk_webhook_delivery_event"
+        FOREIGN KEY ("event_id") REFERENCES "outbox" ("id")
+            ON DELETE CASCADE,
+    "event"           VARCHAR(40)  NOT NULL,
+    "attempt"         INTEGER      NOT NULL DEFAULT 1,
+    "status"          VARCHAR(20)  NOT NULL,
+    "response_code"   INTEGER      NOT NULL DEFAULT 0,
+    "error"           VARCHAR(255) NOT NULL DEFAULT '',
+    "scheduled_at"    TIMESTAMPTZ  NOT NULL,
+    "attempted_at"    TIMESTAMPTZ  NULL,
+    "lease_until"     TIMESTAMPTZ  NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_webhook_delivery_due" ON "webhook_delivery" ("status", "scheduled_at");
+CREATE INDEX IF NOT EXISTS "idx_webhook_delivery_lease" ON "webhook_delivery" ("status", "lease_until");
+CREATE INDEX IF NOT EXISTS "idx_webhook_delivery_subscription" ON "webhook_delivery" ("subscription_id", "id");
@@ -0,0 +1,23 @@
+CREATE INDEX "idx_audit_entity_date" ON "audit" ("entity", "date");
+
+INSERT INTO "audit" ("entity", "entity_id", "action", "actor", "before", "after")
+SELECT 'transaction',
+       CAST(t."id" AS VARCHAR),
+       'create',
+       'migration',
+       NULL,
+       json_build_object(
+               'id', t."id",
+               'amount', t."amount",
+               'type', t."type",
+               'category', t."category",
+               'date', to_char(t."date" AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+               'name', COALESCE(t."description", ''),
+               'version', t."version"
+       )
+FROM "transaction" t
+WHERE NOT EXISTS(SELECT 1
+                 FROM "audit" a
+                 WHERE a."entity" = 'transaction'
+                   AND a."entity_id" = CAST(t."id" AS VARCHAR))
+ORDER BY t."id";
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "journal"
+(
+    "id"        SERIAL      NOT NULL,
+    "actor"     VARCHAR(80) NOT NULL,
+    "operation" VARCHAR(20) NOT NULL,
+    "changes"   JSONB       NOT NULL,
+    "undone"    BOOLEAN     NOT NULL DEFAULT FALSE,
+    "date"      TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_journal_actor" ON "journal" ("actor", "id");
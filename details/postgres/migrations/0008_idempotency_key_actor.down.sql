@@ -0,0 +1,7 @@
+-- the keys of different actors may collide once unscoped, so they are dropped, as expiring anyway
+DELETE FROM "idempotency_key";
+
+ALTER TABLE "idempotency_key"
+    DROP CONSTRAINT "idempotency_key_pkey",
+    DROP COLUMN "actor",
+    ADD PRIMARY KEY ("key");
@@ -0,0 +1,4 @@
+ALTER TABLE "idempotency_key"
+    ADD COLUMN "actor" VARCHAR(80) NOT NULL DEFAULT '',
+    DROP CONSTRAINT "idempotency_key_pkey",
+    ADD PRIMARY KEY ("actor", "key");
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "transaction_event";
+DROP FUNCTION IF EXISTS "transaction_event_append_only";
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS "transaction_event"
+(
+    "id"             SERIAL      NOT NULL,
+    "transaction_id" INTEGER     NOT NULL,
+    "action"         VARCHAR(20) NOT NULL,
+    "amount"         INTEGER     NOT NULL,
+    "type"           INTEGER     NOT NULL,
+    "category"       VARCHAR(80) NOT NULL,
+    "description"    VARCHAR(80) NULL,
+    "date"           TIMESTAMPTZ NOT NULL,
+    "version"        INTEGER     NOT NULL,
+    "actor"          VARCHAR(80) NOT NULL,
+    "recorded_at"    TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_transaction_event_transaction_id" ON "transaction_event" ("transaction_id", "id");
+CREATE INDEX "idx_transaction_event_recorded_at" ON "transaction_event" ("recorded_at", "transaction_id", "id");
+CREATE INDEX "idx_transaction_event_date" ON "transaction_event" ("date", "recorded_at", "transaction_id");
+
+CREATE OR REPLACE FUNCTION "transaction_event_append_only"() RETURNS TRIGGER
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    RAISE EXCEPTION 'transaction_event is append-only';
+END;
+$$;
+
+CREATE TRIGGER "transaction_event_append_only_update"
+    BEFORE UPDATE
+    ON "transaction_event"
+    FOR EACH ROW EXECUTE FUNCTION "transaction_event_append_only"();
+
+CREATE TRIGGER "transaction_event_append_only_delete"
+    BEFORE DELETE
+    ON "transaction_event"
+    FOR EACH ROW EXECUTE FUNCTION "transaction_event_append_only"();
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"time"
+
+	// imports postgres db driver
+	_ "github.com/lib/pq"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/sqlstore"
+)
+
+// dialect writes the statements as PostgreSQL does, which numbers the placeholders, eg: $1, returns the ids of the
+// inserted rows and stores the dates to microseconds.
+var dialect = sqlstore.Dialect{
+	Driver:    "postgres",
+	System:    "postgresql",
+	Quote:     `"`,
+	IDs:       sqlstore.Returning,
+	RowLocks:  true,
+	Precision: time.Microsecond,
+}
+
+// NewRepository initialize the repository of a PostgreSQL server.
+func NewRepository(cfg *details.Config) (*sqlstore.Repository, error) {
+	db, err := sqlx.Open("postgres", cfg.PostgresDNS())
+	if err != nil {
+		return nil, errors.Wrap(err, "NewRepository failed")
+	}
+
+	if cfg.Storage.EventSourced {
+		return sqlstore.NewEventSourcedRepository(db, dialect), nil
+	}
+
+	return sqlstore.NewRepository(db, dialect), nil
+}
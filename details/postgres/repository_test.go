@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/sqlstore"
+	"github.com/gritt/maskada/test"
+)
+
+func TestNewRepository(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// arrange
+	cfg, err := mockDBConfig()
+	if err != nil {
+		t.Fatalf("failed to mockDBConfig: %s", err)
+	}
+
+	// act
+	gotRepo, gotErr := NewRepository(&cfg)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.IsType(t, &sqlx.DB{}, gotRepo.DB())
+}
+
+func TestRepository_Update(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	actor := test.RandomUsername()
+
+	cfg, err := mockDBConfig()
+	if err != nil {
+		t.Fatalf("mockDBConfig failed: %s", err)
+	}
+
+	tests := map[string]func(t *testing.T, r *sqlstore.Repository){
+		"when the row stays locked past the deadline": func(t *testing.T, r *sqlstore.Repository) {
+			// arrange
+			teardown := setupDBData(t, r.DB())
+			defer teardown()
+
+			lock, err := r.DB().Beginx()
+			if err != nil {
+				t.Fatalf("when the row stays locked past the deadline failed: %s", err)
+			}
+			defer func() { _ = lock.Rollback() }()
+
+			if _, err := lock.Exec(`SELECT "id" FROM "transaction" WHERE "id" = 1 FOR UPDATE`); err != nil {
+				t.Fatalf("when the row stays locked past the deadline failed: %s", err)
+			}
+
+			given := core.Transaction{ID: 1, Amount: 100, Type: core.Debit, Category: core.Category{Name: "Food"}, Version: 1}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+
+			// act
+			_, gotErr := r.Update(ctx, given, actor)
+
+			// assert
+			assert.Error(t, gotErr)
+			assert.True(t, time.Since(start) < 5*time.Second, "the query was not aborted")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewRepository(&cfg)
+			assert.NoError(t, err)
+
+			run(t, r)
+		})
+	}
+}
+
+func mockDBConfig() (details.Config, error) {
+	type MockConfig struct {
+		Host     string `envconfig:"POSTGRES_HOST" required:"true"`
+		Port     string `envconfig:"POSTGRES_PORT" required:"true"`
+		Name     string `envconfig:"POSTGRES_NAME" required:"true"`
+		User     string `envconfig:"POSTGRES_USERNAME" required:"true"`
+		Password string `envconfig:"POSTGRES_PASSWORD" required:"true"`
+	}
+
+	var mockConfig MockConfig
+
+	if err := envconfig.Process("", &mockConfig); err != nil {
+		return details.Config{}, err
+	}
+
+	cfg := details.Config{}
+	cfg.Database.Host = mockConfig.Host
+	cfg.Database.Port = mockConfig.Port
+	cfg.Database.Name = mockConfig.Name
+	cfg.Database.User = mockConfig.User
+	cfg.Database.Password = mockConfig.Password
+	cfg.Postgres.SSLMode = "disable"
+
+	return cfg, nil
+}
+
+func setupDBData(t *testing.T, db *sqlx.DB) func() {
+	// create an empty db schema
+	migrateDB(t, db)
+
+	// setup data
+	script, err := ioutil.ReadFile("../../details/postgres/test/setup.sql")
+	if err != nil {
+		t.Fatalf("setupDBData failed: %s", err)
+	}
+	_, err = db.Exec(string(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// teardown data
+	return func() {
+		script, err := ioutil.ReadFile("../../details/postgres/test/teardown.sql")
+		if err != nil {
+			t.Fatalf("setupDBData failed: %s", err)
+		}
+		_, err = db.Exec(string(script))
+		if err != nil {
+			t.Fatalf("setupDBData failed: %s", err)
+		}
+
+		db.Close()
+	}
+}
+
+// migrateDB reverts all migrations, then applies them, so the db schema is empty.
+func migrateDB(t *testing.T, db *sqlx.DB) {
+	m, err := NewMigrator(sqlstore.NewRepository(db, dialect))
+	if err != nil {
+		t.Fatalf("migrateDB failed: %s", err)
+	}
+
+	if _, err := m.Down(math.MaxInt32); err != nil {
+		t.Fatalf("migrateDB failed: %s", err)
+	}
+
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("migrateDB failed: %s", err)
+	}
+}
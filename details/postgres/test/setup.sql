@@ -0,0 +1,15 @@
+INSERT INTO "category" ("name")
+VALUES ('Food'),
+       ('Health'),
+       ('Entertainment'),
+       ('Work'),
+       ('Home');
+
+-- types: 1 debit, 2 credit, 3 income
+INSERT INTO "transaction" ("amount", "type", "category", "description")
+VALUES (99, 2, 'Entertainment', NULL),
+       (11, 2, 'Food', NULL),
+       (32, 2, 'Food', NULL),
+       (5300, 3, 'Work', NULL),
+       (129, 1, 'Home', 'Internet'),
+       (129, 1, 'Home', 'Electricity');
@@ -0,0 +1,64 @@
+// Package recorder records the responses written by the handlers, for the middlewares reporting on them.
+package recorder
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/go-chi/chi"
+)
+
+// UnmatchedRoute is the route of the requests not matching any route, so unknown paths are reported as one.
+const UnmatchedRoute = "unmatched"
+
+// Recorder keeps the status code written by a handler, http.StatusOK unless it writes one, and its body when told to.
+type Recorder struct {
+	http.ResponseWriter
+	status int
+	body   *bytes.Buffer
+}
+
+// New wraps w, keeping the status code written.
+func New(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// WithBody wraps w, keeping the status code and the body written.
+func WithBody(w http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: w, status: http.StatusOK, body: &bytes.Buffer{}}
+}
+
+// WriteHeader keeps the status code, then writes it.
+func (r *Recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write keeps b when the body is kept, then writes it.
+func (r *Recorder) Write(b []byte) (int, error) {
+	if r.body != nil {
+		r.body.Write(b)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Status returns the status code written.
+func (r *Recorder) Status() int {
+	return r.status
+}
+
+// Body returns the body written, nil unless it is kept.
+func (r *Recorder) Body() []byte {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Bytes()
+}
+
+// Route returns the route pattern matched by the request, eg: /v1/transaction/{id}, UnmatchedRoute when none is.
+func Route(r *http.Request) string {
+	if rctx, ok := r.Context().Value(chi.RouteCtxKey).(*chi.Context); ok && rctx.RoutePattern() != "" {
+		return rctx.RoutePattern()
+	}
+	return UnmatchedRoute
+}
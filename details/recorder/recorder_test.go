@@ -0,0 +1,99 @@
+package recorder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	tests := map[string]func(t *testing.T){
+		"when no status is written, keep 200": func(t *testing.T) {
+			// arrange
+			rr := httptest.NewRecorder()
+			rec := New(rr)
+
+			// act
+			_, err := rec.Write([]byte(`{}`))
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, rec.Status())
+			assert.Nil(t, rec.Body())
+			assert.Equal(t, `{}`, rr.Body.String())
+		},
+		"when a status is written, keep it": func(t *testing.T) {
+			// arrange
+			rr := httptest.NewRecorder()
+			rec := New(rr)
+
+			// act
+			rec.WriteHeader(http.StatusNotFound)
+
+			// assert
+			assert.Equal(t, http.StatusNotFound, rec.Status())
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		},
+		"when the body is kept, keep what is written": func(t *testing.T) {
+			// arrange
+			rr := httptest.NewRecorder()
+			rec := WithBody(rr)
+
+			// act
+			rec.WriteHeader(http.StatusCreated)
+			_, _ = rec.Write([]byte(`{"id":`))
+			_, _ = rec.Write([]byte(`1}`))
+
+			// assert
+			assert.Equal(t, http.StatusCreated, rec.Status())
+			assert.Equal(t, `{"id":1}`, string(rec.Body()))
+			assert.Equal(t, `{"id":1}`, rr.Body.String())
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestRoute(t *testing.T) {
+	tests := map[string]struct {
+		path      string
+		wantRoute string
+	}{
+		"when a route matches, return its pattern": {
+			path:      "/v1/transaction/1",
+			wantRoute: "/v1/transaction/{id}",
+		},
+		"when no route matches": {
+			path:      "/unknown",
+			wantRoute: UnmatchedRoute,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			var gotRoute string
+			router := chi.NewRouter()
+			router.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					next.ServeHTTP(w, r)
+					gotRoute = Route(r)
+				})
+			})
+			router.Get("/v1/transaction/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+			// act
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// assert
+			assert.Equal(t, tt.wantRoute, gotRoute)
+		})
+	}
+}
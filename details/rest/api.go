@@ -1,31 +1,145 @@
 package rest
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/metrics"
+	"github.com/gritt/maskada/details/webhook"
 )
 
 type (
 	// TransactionCreator represents a use case able to create a transaction.
 	TransactionCreator interface {
-		Create(core.Transaction) (core.Transaction, error)
+		Create(ctx context.Context, t core.Transaction, actor string) (core.Transaction, error)
+	}
+
+	// TransactionBatchCreator represents a use case able to create many transactions at once.
+	TransactionBatchCreator interface {
+		CreateAll(ctx context.Context, ts []core.Transaction, actor string) ([]core.BatchResult, error)
+		CreateValid(ctx context.Context, ts []core.Transaction, actor string) ([]core.BatchResult, error)
 	}
 
 	// TransactionLister represents a use case able to list transactions.
 	TransactionLister interface {
-		List() ([]core.Transaction, error)
+		List(ctx context.Context) ([]core.Transaction, error)
+		ListMonth(ctx context.Context, month time.Time) ([]core.Transaction, error)
+	}
+
+	// TransactionGetter represents a use case able to get a single transaction.
+	TransactionGetter interface {
+		Get(ctx context.Context, id int) (core.Transaction, error)
+	}
+
+	// TransactionUpdater represents a use case able to change a transaction.
+	TransactionUpdater interface {
+		Update(ctx context.Context, t core.Transaction, actor string) (core.Transaction, error)
+	}
+
+	// TransactionDeleter represents a use case able to remove a transaction.
+	TransactionDeleter interface {
+		Delete(ctx context.Context, id, version int, actor string) error
+	}
+
+	// TransactionHistoryLister represents a use case able to list the changes made to a transaction.
+	TransactionHistoryLister interface {
+		List(ctx context.Context, transactionID int) ([]core.AuditEntry, error)
+	}
+
+	// WebhookManager represents a service able to manage the webhook subscriptions and list their deliveries.
+	WebhookManager interface {
+		Subscribe(ctx context.Context, sub webhook.Subscription) (webhook.Subscription, error)
+		Subscriptions(ctx context.Context) ([]webhook.Subscription, error)
+		Unsubscribe(ctx context.Context, id int) error
+		Deliveries(ctx context.Context, subscriptionID int) ([]webhook.Delivery, error)
+	}
+
+	// LedgerReader represents a use case able to rebuild the transactions as they were known at a given time.
+	LedgerReader interface {
+		Ledger(ctx context.Context, asOf time.Time) ([]core.Transaction, error)
+		Summary(ctx context.Context, month, asOf time.Time) (core.MonthlySummary, error)
+	}
+
+	// Undoer represents a use case able to undo and redo the latest operations of an actor.
+	Undoer interface {
+		Undo(ctx context.Context, actor string) (core.JournalEntry, error)
+		Redo(ctx context.Context, actor string) (core.JournalEntry, error)
+	}
+
+	// HealthChecker represents a client able to tell whether the storage is ready, and the version of its schema.
+	HealthChecker interface {
+		Ready(ctx context.Context) error
+		SchemaVersion(ctx context.Context) (int, error)
 	}
 )
 
+// BuildInfo identifies the running build of the API.
+type BuildInfo struct {
+	Commit string
+	Date   string
+}
+
 // API holds all use cases.
 type API struct {
-	TransactionCreator TransactionCreator
-	TransactionLister  TransactionLister
+	TransactionCreator       TransactionCreator
+	TransactionBatchCreator  TransactionBatchCreator
+	TransactionLister        TransactionLister
+	TransactionGetter        TransactionGetter
+	TransactionUpdater       TransactionUpdater
+	TransactionDeleter       TransactionDeleter
+	TransactionHistoryLister TransactionHistoryLister
+	Idempotency              *idempotency.Middleware
+	HealthChecker            HealthChecker
+	Metrics                  *metrics.Metrics
+
+	// GraphQL serves the GraphQL queries, mounted at /graphql when set.
+	GraphQL http.Handler
+
+	// Webhooks manages the webhook subscriptions, mounted at /v1/webhook when set.
+	Webhooks WebhookManager
+
+	// Ledger rebuilds the transactions from their events, mounted at /v1/ledger when set, eg: when event-sourced.
+	Ledger LedgerReader
+
+	// Journal undoes and redoes the latest operations of each actor, mounted at /v1/undo and /v1/redo when set.
+	Journal Undoer
+
+	// Build is reported by the version endpoint.
+	Build BuildInfo
+
+	// AllowedOrigins may call the API from a browser, any of them when empty.
+	AllowedOrigins []string
+
+	// RequestTimeout bounds each request, its queries being canceled once it is reached, unbounded when 0.
+	RequestTimeout time.Duration
 }
 
 // NewAPI initialize the API.
-func NewAPI(creator TransactionCreator, lister TransactionLister) *API {
+func NewAPI(
+	creator TransactionCreator,
+	batchCreator TransactionBatchCreator,
+	lister TransactionLister,
+	getter TransactionGetter,
+	updater TransactionUpdater,
+	deleter TransactionDeleter,
+	historyLister TransactionHistoryLister,
+	idempotency *idempotency.Middleware,
+	healthChecker HealthChecker,
+	metrics *metrics.Metrics,
+) *API {
 	return &API{
-		TransactionCreator: creator,
-		TransactionLister:  lister,
+		TransactionCreator:       creator,
+		TransactionBatchCreator:  batchCreator,
+		TransactionLister:        lister,
+		TransactionGetter:        getter,
+		TransactionUpdater:       updater,
+		TransactionDeleter:       deleter,
+		TransactionHistoryLister: historyLister,
+		Idempotency:              idempotency,
+		HealthChecker:            healthChecker,
+		Metrics:                  metrics,
 	}
 }
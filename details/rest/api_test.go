@@ -1,45 +1,135 @@
 package rest
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/metrics"
 )
 
 func TestNewAPI(t *testing.T) {
 	// arrange
 	c := new(mockTransactionCreator)
+	b := new(mockTransactionBatchCreator)
 	l := new(mockTransactionLister)
+	g := new(mockTransactionGetter)
+	u := new(mockTransactionUpdater)
+	d := new(mockTransactionDeleter)
+	h := new(mockTransactionHistoryLister)
+	i := idempotency.NewMiddleware(nil, &details.Config{})
+	hc := new(mockHealthChecker)
+	m := metrics.NewMetrics()
 
 	// act
-	got := NewAPI(c, l)
+	got := NewAPI(c, b, l, g, u, d, h, i, hc, m)
 
 	want := &API{
-		TransactionCreator: c,
-		TransactionLister:  l,
+		TransactionCreator:       c,
+		TransactionBatchCreator:  b,
+		TransactionLister:        l,
+		TransactionGetter:        g,
+		TransactionUpdater:       u,
+		TransactionDeleter:       d,
+		TransactionHistoryLister: h,
+		Idempotency:              i,
+		HealthChecker:            hc,
+		Metrics:                  m,
 	}
 
 	// assert
 	assert.Equal(t, want, got)
 }
 
+type mockHealthChecker struct {
+	mock.Mock
+}
+
+func (m *mockHealthChecker) Ready(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *mockHealthChecker) SchemaVersion(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
 type mockTransactionCreator struct {
 	mock.Mock
 }
 
-func (m *mockTransactionCreator) Create(t core.Transaction) (core.Transaction, error) {
-	args := m.Called(t)
+func (m *mockTransactionCreator) Create(_ context.Context, t core.Transaction, actor string) (core.Transaction, error) {
+	args := m.Called(t, actor)
 	return args.Get(0).(core.Transaction), args.Error(1)
 }
 
+type mockTransactionBatchCreator struct {
+	mock.Mock
+}
+
+func (m *mockTransactionBatchCreator) CreateAll(_ context.Context, ts []core.Transaction, actor string) ([]core.BatchResult, error) {
+	args := m.Called(ts, actor)
+	return args.Get(0).([]core.BatchResult), args.Error(1)
+}
+
+func (m *mockTransactionBatchCreator) CreateValid(_ context.Context, ts []core.Transaction, actor string) ([]core.BatchResult, error) {
+	args := m.Called(ts, actor)
+	return args.Get(0).([]core.BatchResult), args.Error(1)
+}
+
 type mockTransactionLister struct {
 	mock.Mock
 }
 
-func (m *mockTransactionLister) List() ([]core.Transaction, error) {
+func (m *mockTransactionLister) List(_ context.Context) ([]core.Transaction, error) {
 	args := m.Called()
 	return args.Get(0).([]core.Transaction), args.Error(1)
 }
+
+func (m *mockTransactionLister) ListMonth(_ context.Context, month time.Time) ([]core.Transaction, error) {
+	args := m.Called(month)
+	return args.Get(0).([]core.Transaction), args.Error(1)
+}
+
+type mockTransactionGetter struct {
+	mock.Mock
+}
+
+func (m *mockTransactionGetter) Get(_ context.Context, id int) (core.Transaction, error) {
+	args := m.Called(id)
+	return args.Get(0).(core.Transaction), args.Error(1)
+}
+
+type mockTransactionUpdater struct {
+	mock.Mock
+}
+
+func (m *mockTransactionUpdater) Update(_ context.Context, t core.Transaction, actor string) (core.Transaction, error) {
+	args := m.Called(t, actor)
+	return args.Get(0).(core.Transaction), args.Error(1)
+}
+
+type mockTransactionDeleter struct {
+	mock.Mock
+}
+
+func (m *mockTransactionDeleter) Delete(_ context.Context, id, version int, actor string) error {
+	args := m.Called(id, version, actor)
+	return args.Error(0)
+}
+
+type mockTransactionHistoryLister struct {
+	mock.Mock
+}
+
+func (m *mockTransactionHistoryLister) List(_ context.Context, transactionID int) ([]core.AuditEntry, error) {
+	args := m.Called(transactionID)
+	return args.Get(0).([]core.AuditEntry), args.Error(1)
+}
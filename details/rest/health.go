@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// readinessTimeout bounds how long the storage is checked for, so probes fail rather than pile up.
+const readinessTimeout = 2 * time.Second
+
+type versionSkeleton struct {
+	Commit        string `json:"commit"`
+	BuildDate     string `json:"build_date"`
+	SchemaVersion int    `json:"schema_version"`
+}
+
+// HandleHealth tells the API is alive, regardless of its storage.
+func (api *API) HandleHealth() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respond(w, `{"status": "ok"}`, http.StatusOK)
+	}
+}
+
+// HandleReadiness tells whether the API is able to serve requests, its storage being reachable and migrated.
+func (api *API) HandleReadiness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		if err := api.HealthChecker.Ready(ctx); err != nil {
+			respondError(w, r, errors.Wrap(err, "HandleReadiness failed"), http.StatusServiceUnavailable)
+			return
+		}
+
+		respond(w, `{"status": "ready"}`, http.StatusOK)
+	}
+}
+
+// HandleVersion reports the build of the API, along with the version of the storage schema.
+func (api *API) HandleVersion() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		schemaVersion, err := api.HealthChecker.SchemaVersion(ctx)
+		if err != nil {
+			respondError(w, r, errors.Wrap(err, "HandleVersion failed"), http.StatusServiceUnavailable)
+			return
+		}
+
+		res := versionSkeleton{Commit: api.Build.Commit, BuildDate: api.Build.Date, SchemaVersion: schemaVersion}
+		jsonRes, _ := json.Marshal(&res)
+		respond(w, string(jsonRes), http.StatusOK)
+	}
+}
@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAPI_handleHealth(t *testing.T) {
+	// arrange
+	api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	rr := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+
+	// act
+	api.Routes().ServeHTTP(rr, r)
+
+	// assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status": "ok"}`, rr.Body.String())
+}
+
+func TestAPI_handleReadiness(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when ready": func(t *testing.T) {
+			// arrange
+			h := new(mockHealthChecker)
+			h.On("Ready", mock.Anything).Return(nil)
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, h, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `{"status": "ready"}`, rr.Body.String())
+			h.AssertExpectations(t)
+		},
+		"when not ready": func(t *testing.T) {
+			// arrange
+			h := new(mockHealthChecker)
+			h.On("Ready", mock.Anything).Return(errors.New("migration 2 add_color is pending"))
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, h, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+			assert.JSONEq(t, `{"error": "HandleReadiness failed: migration 2 add_color is pending"}`, rr.Body.String())
+			h.AssertExpectations(t)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestAPI_handleVersion(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when the schema version is found": func(t *testing.T) {
+			// arrange
+			h := new(mockHealthChecker)
+			h.On("SchemaVersion", mock.Anything).Return(3, nil)
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, h, nil)
+			api.Build = BuildInfo{Commit: "8f51ed8", Date: "2024-05-20T12:00:00Z"}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/version", nil)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `{"commit": "8f51ed8", "build_date": "2024-05-20T12:00:00Z", "schema_version": 3}`, rr.Body.String())
+			h.AssertExpectations(t)
+		},
+		"when the schema version is not found": func(t *testing.T) {
+			// arrange
+			h := new(mockHealthChecker)
+			h.On("SchemaVersion", mock.Anything).Return(0, errors.New("connection refused"))
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, h, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/version", nil)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+			assert.JSONEq(t, `{"error": "HandleVersion failed: connection refused"}`, rr.Body.String())
+			h.AssertExpectations(t)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
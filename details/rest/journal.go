@@ -0,0 +1,100 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gritt/maskada/core"
+)
+
+type changeSkeleton struct {
+	Before *skeleton `json:"before"`
+	After  *skeleton `json:"after"`
+}
+
+type journalSkeleton struct {
+	ID        int              `json:"id"`
+	Operation string           `json:"operation"`
+	Undone    bool             `json:"undone"`
+	Date      time.Time        `json:"date"`
+	Changes   []changeSkeleton `json:"changes"`
+}
+
+// HandleUndo receives the request and call the use case to undo the latest operation of the actor, which must be
+// given, so no one undoes the changes of the anonymous ones. The ActorHeader is trusted as is, so a deployment
+// letting untrusted clients in must have it set by a proxy authenticating them.
+func (api *API) HandleUndo() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			respond(w, `{"error": "HandleUndo failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		name := r.Header.Get(ActorHeader)
+		if name == "" {
+			respond(w, `{"error": "HandleUndo failed: missing X-Actor"}`, http.StatusBadRequest)
+			return
+		}
+
+		entry, err := api.Journal.Undo(r.Context(), name)
+		if err != nil {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		res := newJournalSkeleton(entry)
+		jsonRes, _ := json.Marshal(&res)
+		respond(w, string(jsonRes), http.StatusOK)
+	}
+}
+
+// HandleRedo receives the request and call the use case to redo the latest operation of the actor undone, which must
+// be given, as HandleUndo requires.
+func (api *API) HandleRedo() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			respond(w, `{"error": "HandleRedo failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		name := r.Header.Get(ActorHeader)
+		if name == "" {
+			respond(w, `{"error": "HandleRedo failed: missing X-Actor"}`, http.StatusBadRequest)
+			return
+		}
+
+		entry, err := api.Journal.Redo(r.Context(), name)
+		if err != nil {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		res := newJournalSkeleton(entry)
+		jsonRes, _ := json.Marshal(&res)
+		respond(w, string(jsonRes), http.StatusOK)
+	}
+}
+
+func newJournalSkeleton(e core.JournalEntry) journalSkeleton {
+	res := journalSkeleton{
+		ID:        e.ID,
+		Operation: e.Operation,
+		Undone:    e.Undone,
+		Date:      e.Date,
+		Changes:   []changeSkeleton{},
+	}
+	for _, c := range e.Changes {
+		var change changeSkeleton
+		if !c.Created() {
+			before := newSkeleton(c.Before)
+			change.Before = &before
+		}
+		if !c.Deleted() {
+			after := newSkeleton(c.After)
+			change.After = &after
+		}
+		res.Changes = append(res.Changes, change)
+	}
+	return res
+}
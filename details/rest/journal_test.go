@@ -0,0 +1,260 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gritt/maskada/core"
+)
+
+func TestAPI_HandleUndo(t *testing.T) {
+	lunch := core.Transaction{
+		ID:       1,
+		Amount:   100,
+		Type:     core.Debit,
+		Category: core.Category{Name: "Food"},
+		Date:     time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		Name:     "lunch",
+		Version:  2,
+	}
+	lunchChanged := lunch
+	lunchChanged.Amount = 150
+	lunchChanged.Version = 3
+
+	tests := map[string]func(t *testing.T, m *mockUndoer){
+		"when invalid method": func(t *testing.T, m *mockUndoer) {
+			// arrange
+			api := &API{Journal: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleUndo()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleUndo failed: invalid request"}`, rr.Body.String())
+		},
+		"when actor is missing": func(t *testing.T, m *mockUndoer) {
+			// arrange
+			api := &API{Journal: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", nil)
+
+			// act
+			api.HandleUndo()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleUndo failed: missing X-Actor"}`, rr.Body.String())
+		},
+		"when nothing is left to undo": func(t *testing.T, m *mockUndoer) {
+			// arrange
+			m.On("Undo", "alice").Return(core.JournalEntry{}, pkgerrors.Wrap(core.ErrNothingToUndo, "Undo failed"))
+			api := &API{Journal: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set(ActorHeader, "alice")
+
+			// act
+			api.HandleUndo()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusConflict, rr.Code)
+			assert.Equal(t, `{"error": "Undo failed: nothing to undo"}`, rr.Body.String())
+		},
+		"when a transaction was changed since": func(t *testing.T, m *mockUndoer) {
+			// arrange
+			m.On("Undo", "alice").Return(core.JournalEntry{}, pkgerrors.Wrap(core.ErrStaleVersion, "Undo failed"))
+			api := &API{Journal: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set(ActorHeader, "alice")
+
+			// act
+			api.HandleUndo()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+			assert.Equal(t, `{"error": "Undo failed: stale version"}`, rr.Body.String())
+		},
+		"when use case undoes, respond with the changes applied": func(t *testing.T, m *mockUndoer) {
+			// arrange
+			m.On("Undo", "alice").Return(core.JournalEntry{
+				ID:        4,
+				Actor:     "alice",
+				Operation: core.JournalUpdate,
+				Changes:   []core.Change{{Before: lunchChanged, After: lunch}, {Before: lunch}, {After: lunch}},
+				Undone:    true,
+				Date:      time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC),
+			}, nil)
+			api := &API{Journal: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set(ActorHeader, "alice")
+
+			// act
+			api.HandleUndo()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `{
+				"id": 4, "operation": "update", "undone": true, "date": "2026-06-02T00:00:00Z",
+				"changes": [
+					{
+						"before": {"id": 1, "amount": 150, "type": 1, "category": "Food", "date": "2026-06-01T00:00:00Z", "name": "lunch", "version": 3},
+						"after": {"id": 1, "amount": 100, "type": 1, "category": "Food", "date": "2026-06-01T00:00:00Z", "name": "lunch", "version": 2}
+					},
+					{
+						"before": {"id": 1, "amount": 100, "type": 1, "category": "Food", "date": "2026-06-01T00:00:00Z", "name": "lunch", "version": 2},
+						"after": null
+					},
+					{
+						"before": null,
+						"after": {"id": 1, "amount": 100, "type": 1, "category": "Food", "date": "2026-06-01T00:00:00Z", "name": "lunch", "version": 2}
+					}
+				]
+			}`, rr.Body.String())
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockUndoer)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPI_HandleRedo(t *testing.T) {
+	tests := map[string]func(t *testing.T, m *mockUndoer){
+		"when invalid method": func(t *testing.T, m *mockUndoer) {
+			// arrange
+			api := &API{Journal: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleRedo()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleRedo failed: invalid request"}`, rr.Body.String())
+		},
+		"when actor is missing": func(t *testing.T, m *mockUndoer) {
+			// arrange
+			api := &API{Journal: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", nil)
+
+			// act
+			api.HandleRedo()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleRedo failed: missing X-Actor"}`, rr.Body.String())
+		},
+		"when nothing is left to redo": func(t *testing.T, m *mockUndoer) {
+			// arrange
+			m.On("Redo", "alice").Return(core.JournalEntry{}, pkgerrors.Wrap(core.ErrNothingToRedo, "Redo failed"))
+			api := &API{Journal: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set(ActorHeader, "alice")
+
+			// act
+			api.HandleRedo()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusConflict, rr.Code)
+			assert.Equal(t, `{"error": "Redo failed: nothing to redo"}`, rr.Body.String())
+		},
+		"when use case fails": func(t *testing.T, m *mockUndoer) {
+			// arrange
+			m.On("Redo", "alice").Return(core.JournalEntry{}, errors.New("Redo failed: err"))
+			api := &API{Journal: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set(ActorHeader, "alice")
+
+			// act
+			api.HandleRedo()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			assert.Equal(t, `{"error": "Redo failed: err"}`, rr.Body.String())
+		},
+		"when use case redoes, respond with the changes applied": func(t *testing.T, m *mockUndoer) {
+			// arrange
+			m.On("Redo", "alice").Return(core.JournalEntry{
+				ID:        4,
+				Actor:     "alice",
+				Operation: core.JournalCreateBatch,
+				Date:      time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC),
+			}, nil)
+			api := &API{Journal: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set(ActorHeader, "alice")
+
+			// act
+			api.HandleRedo()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `{"id": 4, "operation": "create_batch", "undone": false, "date": "2026-06-02T00:00:00Z", "changes": []}`,
+				rr.Body.String())
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockUndoer)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+type mockUndoer struct {
+	mock.Mock
+}
+
+func (m *mockUndoer) Undo(_ context.Context, actor string) (core.JournalEntry, error) {
+	args := m.Called(actor)
+	return args.Get(0).(core.JournalEntry), args.Error(1)
+}
+
+func (m *mockUndoer) Redo(_ context.Context, actor string) (core.JournalEntry, error) {
+	args := m.Called(actor)
+	return args.Get(0).(core.JournalEntry), args.Error(1)
+}
@@ -0,0 +1,137 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gritt/maskada/core"
+)
+
+// monthLayout is the layout of the month query parameter, eg: 2019-10.
+const monthLayout = "2006-01"
+
+type totalsSkeleton struct {
+	Debit  int `json:"debit"`
+	Credit int `json:"credit"`
+	Income int `json:"income"`
+	Count  int `json:"count"`
+}
+
+type categoryTotalsSkeleton struct {
+	Category string         `json:"category"`
+	Totals   totalsSkeleton `json:"totals"`
+}
+
+type summarySkeleton struct {
+	Month      string                   `json:"month"`
+	AsOf       time.Time                `json:"as_of"`
+	Totals     totalsSkeleton           `json:"totals"`
+	CreditDue  int                      `json:"credit_due"`
+	Balance    int                      `json:"balance"`
+	Categories []categoryTotalsSkeleton `json:"categories"`
+}
+
+// HandleListLedger receives the request and call the use case to rebuild the transactions as they were known at the
+// as_of query parameter, now when it is not given.
+func (api *API) HandleListLedger() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			respond(w, `{"error": "HandleListLedger failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		asOf, ok := asOf(r)
+		if !ok {
+			respond(w, `{"error": "HandleListLedger failed: invalid as_of, expected RFC 3339"}`, http.StatusBadRequest)
+			return
+		}
+
+		trsl, err := api.Ledger.Ledger(r.Context(), asOf)
+		if err != nil {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		res := []skeleton{}
+		for _, trs := range trsl {
+			res = append(res, newSkeleton(trs))
+		}
+		jsonRes, _ := json.Marshal(&res)
+		respond(w, string(jsonRes), http.StatusOK)
+	}
+}
+
+// HandleLedgerSummary receives the request and call the use case to total the transactions of the month query
+// parameter, as they were known at the as_of one, both defaulting to now.
+func (api *API) HandleLedgerSummary() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			respond(w, `{"error": "HandleLedgerSummary failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		asOf, ok := asOf(r)
+		if !ok {
+			respond(w, `{"error": "HandleLedgerSummary failed: invalid as_of, expected RFC 3339"}`, http.StatusBadRequest)
+			return
+		}
+
+		month := asOf
+		if param := r.URL.Query().Get("month"); param != "" {
+			parsed, err := time.Parse(monthLayout, param)
+			if err != nil {
+				respond(w, `{"error": "HandleLedgerSummary failed: invalid month, expected YYYY-MM"}`, http.StatusBadRequest)
+				return
+			}
+			month = parsed
+		}
+
+		s, err := api.Ledger.Summary(r.Context(), month, asOf)
+		if err != nil {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		res := newSummarySkeleton(s, asOf)
+		jsonRes, _ := json.Marshal(&res)
+		respond(w, string(jsonRes), http.StatusOK)
+	}
+}
+
+// asOf parses the as_of query parameter, now when it is not given.
+func asOf(r *http.Request) (time.Time, bool) {
+	param := r.URL.Query().Get("as_of")
+	if param == "" {
+		return time.Now().UTC(), true
+	}
+
+	t, err := time.Parse(time.RFC3339, param)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t.UTC(), true
+}
+
+func newSummarySkeleton(s core.MonthlySummary, asOf time.Time) summarySkeleton {
+	res := summarySkeleton{
+		Month:      s.Month.Format(monthLayout),
+		AsOf:       asOf,
+		Totals:     newTotalsSkeleton(s.Totals),
+		CreditDue:  s.CreditDue,
+		Balance:    s.Balance,
+		Categories: []categoryTotalsSkeleton{},
+	}
+	for _, c := range s.Categories {
+		res.Categories = append(res.Categories, categoryTotalsSkeleton{
+			Category: c.Category.Name,
+			Totals:   newTotalsSkeleton(c.Totals),
+		})
+	}
+	return res
+}
+
+func newTotalsSkeleton(t core.Totals) totalsSkeleton {
+	return totalsSkeleton{Debit: t.Debit, Credit: t.Credit, Income: t.Income, Count: t.Count}
+}
@@ -0,0 +1,277 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gritt/maskada/core"
+)
+
+func TestAPI_HandleListLedger(t *testing.T) {
+	asOf := time.Date(2026, 6, 3, 10, 0, 0, 0, time.UTC)
+	lunch := core.Transaction{
+		ID:       1,
+		Amount:   100,
+		Type:     core.Debit,
+		Category: core.Category{Name: "Food"},
+		Date:     time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		Name:     "lunch",
+		Version:  2,
+	}
+
+	tests := map[string]func(t *testing.T, m *mockLedgerReader){
+		"when invalid method": func(t *testing.T, m *mockLedgerReader) {
+			// arrange
+			api := &API{Ledger: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", nil)
+
+			// act
+			api.HandleListLedger()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleListLedger failed: invalid request"}`, rr.Body.String())
+		},
+		"when as_of is invalid": func(t *testing.T, m *mockLedgerReader) {
+			// arrange
+			api := &API{Ledger: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/?as_of=yesterday", nil)
+
+			// act
+			api.HandleListLedger()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleListLedger failed: invalid as_of, expected RFC 3339"}`, rr.Body.String())
+		},
+		"when use case fails": func(t *testing.T, m *mockLedgerReader) {
+			// arrange
+			m.On("Ledger", asOf).Return([]core.Transaction{}, errors.New("Ledger failed: err"))
+			api := &API{Ledger: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/?as_of=2026-06-03T12:00:00%2B02:00", nil)
+
+			// act
+			api.HandleListLedger()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			assert.Equal(t, `{"error": "Ledger failed: err"}`, rr.Body.String())
+		},
+		"when as_of is before the history": func(t *testing.T, m *mockLedgerReader) {
+			// arrange
+			m.On("Ledger", asOf).Return([]core.Transaction{}, core.ErrBeforeHistory)
+			api := &API{Ledger: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/?as_of=2026-06-03T12:00:00%2B02:00", nil)
+
+			// act
+			api.HandleListLedger()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Equal(t, `{"error": "as of before the history"}`, rr.Body.String())
+		},
+		"when use case rebuilds the transactions": func(t *testing.T, m *mockLedgerReader) {
+			// arrange
+			m.On("Ledger", asOf).Return([]core.Transaction{lunch}, nil)
+			api := &API{Ledger: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/?as_of=2026-06-03T10:00:00Z", nil)
+
+			// act
+			api.HandleListLedger()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `[{
+				"id": 1, "amount": 100, "type": 1, "category": "Food",
+				"date": "2026-06-01T00:00:00Z", "name": "lunch", "version": 2
+			}]`, rr.Body.String())
+		},
+		"when as_of is not given, rebuild them as of now": func(t *testing.T, m *mockLedgerReader) {
+			// arrange
+			before := time.Now()
+			m.On("Ledger", mock.MatchedBy(func(asOf time.Time) bool {
+				return !asOf.Before(before.Truncate(time.Second)) && !asOf.After(time.Now())
+			})).Return([]core.Transaction{}, nil)
+			api := &API{Ledger: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleListLedger()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, `[]`, rr.Body.String())
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockLedgerReader)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPI_HandleLedgerSummary(t *testing.T) {
+	asOf := time.Date(2026, 6, 3, 10, 0, 0, 0, time.UTC)
+	may := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]func(t *testing.T, m *mockLedgerReader){
+		"when invalid method": func(t *testing.T, m *mockLedgerReader) {
+			// arrange
+			api := &API{Ledger: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", nil)
+
+			// act
+			api.HandleLedgerSummary()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleLedgerSummary failed: invalid request"}`, rr.Body.String())
+		},
+		"when as_of is invalid": func(t *testing.T, m *mockLedgerReader) {
+			// arrange
+			api := &API{Ledger: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/?month=2026-05&as_of=2026-06-03", nil)
+
+			// act
+			api.HandleLedgerSummary()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleLedgerSummary failed: invalid as_of, expected RFC 3339"}`, rr.Body.String())
+		},
+		"when month is invalid": func(t *testing.T, m *mockLedgerReader) {
+			// arrange
+			api := &API{Ledger: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/?month=may", nil)
+
+			// act
+			api.HandleLedgerSummary()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleLedgerSummary failed: invalid month, expected YYYY-MM"}`, rr.Body.String())
+		},
+		"when use case fails": func(t *testing.T, m *mockLedgerReader) {
+			// arrange
+			m.On("Summary", may, asOf).Return(core.MonthlySummary{}, errors.New("LedgerSummary failed: err"))
+			api := &API{Ledger: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/?month=2026-05&as_of=2026-06-03T10:00:00Z", nil)
+
+			// act
+			api.HandleLedgerSummary()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			assert.Equal(t, `{"error": "LedgerSummary failed: err"}`, rr.Body.String())
+		},
+		"when use case totals the month": func(t *testing.T, m *mockLedgerReader) {
+			// arrange
+			m.On("Summary", may, asOf).Return(core.MonthlySummary{
+				Month:     may,
+				Totals:    core.Totals{Debit: 100, Income: 1000, Count: 2},
+				CreditDue: 50,
+				Balance:   850,
+				Categories: []core.CategoryTotals{
+					{Category: core.Category{Name: "Food"}, Totals: core.Totals{Debit: 100, Count: 1}},
+				},
+			}, nil)
+			api := &API{Ledger: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/?month=2026-05&as_of=2026-06-03T10:00:00Z", nil)
+
+			// act
+			api.HandleLedgerSummary()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `{
+				"month": "2026-05", "as_of": "2026-06-03T10:00:00Z",
+				"totals": {"debit": 100, "credit": 0, "income": 1000, "count": 2},
+				"credit_due": 50, "balance": 850,
+				"categories": [{"category": "Food", "totals": {"debit": 100, "credit": 0, "income": 0, "count": 1}}]
+			}`, rr.Body.String())
+		},
+		"when month is not given, total the month of as_of": func(t *testing.T, m *mockLedgerReader) {
+			// arrange
+			m.On("Summary", asOf, asOf).Return(core.MonthlySummary{Month: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)}, nil)
+			api := &API{Ledger: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/?as_of=2026-06-03T10:00:00Z", nil)
+
+			// act
+			api.HandleLedgerSummary()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `{
+				"month": "2026-06", "as_of": "2026-06-03T10:00:00Z",
+				"totals": {"debit": 0, "credit": 0, "income": 0, "count": 0},
+				"credit_due": 0, "balance": 0, "categories": []
+			}`, rr.Body.String())
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			m := new(mockLedgerReader)
+
+			// act
+			run(t, m)
+
+			// assert
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+type mockLedgerReader struct {
+	mock.Mock
+}
+
+func (m *mockLedgerReader) Ledger(_ context.Context, asOf time.Time) ([]core.Transaction, error) {
+	args := m.Called(asOf)
+	return args.Get(0).([]core.Transaction), args.Error(1)
+}
+
+func (m *mockLedgerReader) Summary(_ context.Context, month, asOf time.Time) (core.MonthlySummary, error) {
+	args := m.Called(month, asOf)
+	return args.Get(0).(core.MonthlySummary), args.Error(1)
+}
@@ -0,0 +1,671 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/webhook"
+)
+
+// schema is an OpenAPI schema, or any other object of the specification.
+type schema map[string]interface{}
+
+type errorSkeleton struct {
+	Error string `json:"error"`
+}
+
+type statusSkeleton struct {
+	Status string `json:"status"`
+}
+
+// payload describes a request or response payload, whose properties are derived from its JSON encoding.
+type payload struct {
+	value      interface{}
+	required   []string
+	properties map[string]schema
+}
+
+// payloads are the components of the specification by name, each property of a payload must be described here,
+// so a field added to a payload fails the tests until the specification is updated.
+var payloads = map[string]payload{
+	"Transaction": {
+		value:    skeleton{},
+		required: []string{"amount", "type", "category"},
+		properties: map[string]schema{
+			"id":     {"description": "Assigned on creation.", "readOnly": true},
+			"amount": {"description": "The amount in cents, eg: 2650 is 26.50, greater than 0.", "minimum": 1},
+			"type": {
+				"description": fmt.Sprintf("%d for a debit, %d for a credit, subtracted the next month, %d for an income.",
+					core.Debit, core.Credit, core.Income),
+				"enum": []int{core.Debit, core.Credit, core.Income},
+			},
+			"category": {"description": "The category name, created along with the transaction when new.", "minLength": 1},
+			"date": {
+				"description": "When the transaction happened. When missing or null, it is now on creation, " +
+					"and the current one is kept on update.",
+			},
+			"name":    {"description": "Describes the transaction, may be empty."},
+			"version": {"description": "Incremented by each change, sent as the ETag of the transaction.", "readOnly": true},
+		},
+	},
+	"BatchResult": {
+		value:    batchResultSkeleton{},
+		required: []string{"index"},
+		properties: map[string]schema{
+			"index": {"description": "The position of the transaction in the batch."},
+			"id":    {"description": "The ID of the created transaction, missing when it was not created."},
+			"error": {"description": "Why the transaction is invalid, missing when it is valid."},
+		},
+	},
+	"AuditEntry": {
+		value:    auditSkeleton{},
+		required: []string{"id", "entity", "entity_id", "action", "actor", "date", "before", "after"},
+		properties: map[string]schema{
+			"id":        {"description": "Increases with each change."},
+			"entity":    {"description": "The changed entity.", "enum": []string{core.AuditTransaction, core.AuditCategory}},
+			"entity_id": {"description": "The ID of the changed entity, its name for a category."},
+			"action":    {"description": "The change.", "enum": []string{core.AuditCreate, core.AuditUpdate, core.AuditDelete}},
+			"actor":     {"description": fmt.Sprintf("Who made the change, from the %s header.", ActorHeader)},
+			"date":      {"description": "When the change was made."},
+			"before":    {"description": "The entity before the change, null on creation."},
+			"after":     {"description": "The entity after the change, null on deletion."},
+		},
+	},
+	"MonthlySummary": {
+		value:    summarySkeleton{},
+		required: []string{"month", "as_of", "totals", "credit_due", "balance", "categories"},
+		properties: map[string]schema{
+			"month":  {"description": "The summarized month, eg: 2019-10."},
+			"as_of":  {"description": "When the transactions were known as summarized."},
+			"totals": {"description": "The sums of the debits, credits and incomes of the month, along with their count."},
+			"credit_due": {
+				"description": "The credit of the previous month, which is due in this one.",
+			},
+			"balance":    {"description": "The income minus the debit and the credit due."},
+			"categories": {"description": "The totals of each category of the month, ordered by name."},
+		},
+	},
+	"JournalEntry": {
+		value:    journalSkeleton{},
+		required: []string{"id", "operation", "undone", "date", "changes"},
+		properties: map[string]schema{
+			"id": {"description": "Increases with each operation."},
+			"operation": {
+				"description": "The operation journaled.",
+				"enum":        []string{core.JournalCreate, core.JournalCreateBatch, core.JournalUpdate, core.JournalDelete},
+			},
+			"undone": {"description": "Whether the operation is undone, so it can be redone."},
+			"date":   {"description": "When the operation was made."},
+			"changes": {
+				"description": "The changes last applied to the transactions, in order: by the undo once undone, by the " +
+					"redo once redone. Each holds the transaction before the change, null when restored or created, " +
+					"and after it, null when removed.",
+			},
+		},
+	},
+	"Subscription": {
+		value:    subscriptionSkeleton{},
+		required: []string{"url", "events"},
+		properties: map[string]schema{
+			"id":  {"description": "Assigned on creation.", "readOnly": true},
+			"url": {"description": "The HTTP(S) URL the events are posted to.", "format": "uri", "maxLength": webhook.MaxURLLength},
+			"secret": {
+				"description": fmt.Sprintf("Signs the deliveries, see the %s header, generated when missing. "+
+					"Only returned on creation.", webhook.SignatureHeader),
+			},
+			"events": {
+				"description": "The event types posted to the URL.",
+				"minItems":    1,
+				"uniqueItems": true,
+				"items":       schema{"type": "string", "enum": webhook.Events},
+			},
+			"date": {"description": "When the subscription was created.", "readOnly": true},
+		},
+	},
+	"Delivery": {
+		value:    deliverySkeleton{},
+		required: []string{"id", "event_id", "event", "attempt", "status", "response_code", "error", "scheduled_at", "attempted_at"},
+		properties: map[string]schema{
+			"id":       {"description": "Increases with each attempt."},
+			"event_id": {"description": fmt.Sprintf("The ID of the event, the same for each attempt, sent as the %s header.", webhook.EventIDHeader)},
+			"event":    {"description": "The event type.", "enum": webhook.Events},
+			"attempt":  {"description": "The attempt of the event, from 1."},
+			"status": {
+				"description": "pending until due, sending while attempted, then delivered on a 2xx response, failed otherwise.",
+				"enum":        []string{webhook.Pending, webhook.Sending, webhook.Delivered, webhook.Failed},
+			},
+			"response_code": {"description": "The status of the response, 0 while pending or when none was received."},
+			"error":         {"description": "Why the attempt failed, empty unless failed."},
+			"scheduled_at":  {"description": "When the attempt is due."},
+			"attempted_at":  {"description": "When the attempt was made, null while pending."},
+		},
+	},
+	"Version": {
+		value:    versionSkeleton{},
+		required: []string{"commit", "build_date", "schema_version"},
+		properties: map[string]schema{
+			"commit":         {"description": "The version control commit the API was built from."},
+			"build_date":     {"description": "When the API was built, empty unless stamped by the release build."},
+			"schema_version": {"description": "The last migration applied to the storage, 0 when it has none."},
+		},
+	},
+	"Status": {
+		value:    statusSkeleton{},
+		required: []string{"status"},
+		properties: map[string]schema{
+			"status": {"description": "ok when alive, ready when ready."},
+		},
+	},
+	"Error": {
+		value:    errorSkeleton{},
+		required: []string{"error"},
+		properties: map[string]schema{
+			"error": {"description": "What failed."},
+		},
+	},
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaOf derives the schema of the JSON encoding of a Go type.
+func schemaOf(t reflect.Type) schema {
+	switch t {
+	case timeType:
+		return schema{"type": "string", "format": "date-time"}
+	case rawType:
+		return schema{"type": "object", "nullable": true}
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return schema{"type": "number"}
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.String:
+		return schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return schema{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Ptr:
+		s := schemaOf(t.Elem())
+		s["nullable"] = true
+		return s
+	case reflect.Struct:
+		properties := schema{}
+		for i := 0; i < t.NumField(); i++ {
+			name, ok := jsonName(t.Field(i))
+			if ok {
+				properties[name] = schemaOf(t.Field(i).Type)
+			}
+		}
+		return schema{"type": "object", "properties": properties}
+	default:
+		return schema{}
+	}
+}
+
+// jsonName is the name of a struct field in its JSON encoding, not ok when it is not encoded.
+func jsonName(f reflect.StructField) (name string, ok bool) {
+	if f.PkgPath != "" {
+		return "", false
+	}
+
+	name, _, _ = strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	default:
+		return name, true
+	}
+}
+
+// component derives the schema of a payload, merging the description of its properties.
+func component(p payload) schema {
+	s := schemaOf(reflect.TypeOf(p.value))
+	for name, property := range s["properties"].(schema) {
+		for k, v := range p.properties[name] {
+			property.(schema)[k] = v
+		}
+	}
+	s["required"] = p.required
+	return s
+}
+
+func ref(name string) schema {
+	return schema{"$ref": "#/components/schemas/" + name}
+}
+
+func parameter(name string) schema {
+	return schema{"$ref": "#/components/parameters/" + name}
+}
+
+// content is a JSON response of the given schema.
+func content(description string, s schema) schema {
+	return schema{
+		"description": description,
+		"content":     schema{"application/json": schema{"schema": s}},
+	}
+}
+
+// failure is an error response.
+func failure(description string) schema {
+	return content(description, ref("Error"))
+}
+
+// openAPI returns the OpenAPI 3 specification of the routes of the API.
+func openAPI() schema {
+	schemas := schema{}
+	for name, p := range payloads {
+		schemas[name] = component(p)
+	}
+
+	transaction := content("The transaction.", ref("Transaction"))
+	transaction["headers"] = schema{"ETag": schema{"$ref": "#/components/headers/ETag"}}
+
+	created := content("The created transaction.", ref("Transaction"))
+	created["headers"] = schema{idempotency.ReplayedHeader: schema{"$ref": "#/components/headers/Replayed"}}
+
+	batch := content("The outcome of each transaction of the batch, by position.", schema{"type": "array", "items": ref("BatchResult")})
+	batch["headers"] = created["headers"]
+
+	undone := content("The undone operation.", ref("JournalEntry"))
+	undone["headers"] = created["headers"]
+
+	redone := content("The redone operation.", ref("JournalEntry"))
+	redone["headers"] = created["headers"]
+
+	transactionBody := schema{
+		"required": true,
+		"content":  schema{"application/json": schema{"schema": ref("Transaction")}},
+	}
+
+	failed := failure("Failed, eg: the transaction is invalid or the storage is unreachable.")
+	timedOut := failure("The request outlasted its timeout.")
+	invalid := failure("Invalid request, id or payload.")
+	notFound := failure("The transaction does not exist.")
+	subscriptionNotFound := failure("The subscription does not exist.")
+
+	return schema{
+		"openapi": "3.0.3",
+		"info": schema{
+			"title":       "Maskada",
+			"description": "Tracks the money received and expended.",
+			"version":     "v1",
+		},
+		"paths": schema{
+			"/healthz": schema{
+				"get": schema{
+					"summary":   "Tells the API is alive, regardless of its storage.",
+					"responses": schema{"200": content("Alive.", ref("Status"))},
+				},
+			},
+			"/readyz": schema{
+				"get": schema{
+					"summary": "Tells whether the storage is reachable and migrated.",
+					"responses": schema{
+						"200": content("Ready.", ref("Status")),
+						"503": failure("Not ready."),
+					},
+				},
+			},
+			"/version": schema{
+				"get": schema{
+					"summary": "Reports the build of the API, along with the version of the storage schema.",
+					"responses": schema{
+						"200": content("The build.", ref("Version")),
+						"503": failure("The storage is unreachable."),
+					},
+				},
+			},
+			"/metrics": schema{
+				"get": schema{
+					"summary": "Exposes the metrics in the Prometheus text format, when enabled.",
+					"responses": schema{
+						"200": schema{
+							"description": "The metrics.",
+							"content":     schema{"text/plain": schema{"schema": schema{"type": "string"}}},
+						},
+					},
+				},
+			},
+			"/graphql": schema{
+				"post": schema{
+					"summary": "Executes a GraphQL query, of transactions, categories and monthly summaries, when enabled.",
+					"requestBody": schema{
+						"required": true,
+						"content": schema{"application/json": schema{"schema": schema{
+							"type":     "object",
+							"required": []string{"query"},
+							"properties": schema{
+								"query":         schema{"type": "string", "description": "The GraphQL query."},
+								"operationName": schema{"type": "string", "description": "The operation to execute, when the query holds many."},
+								"variables":     schema{"type": "object", "description": "The values of the variables of the query."},
+							},
+						}}},
+					},
+					"responses": schema{
+						"200": content("The data queried, along with the errors of the fields which failed.", schema{"type": "object"}),
+						"400": failure("Invalid payload."),
+					},
+				},
+			},
+			"/v1/openapi.json": schema{
+				"get": schema{
+					"summary":   "Describes the API.",
+					"responses": schema{"200": content("This specification.", schema{"type": "object"})},
+				},
+			},
+			"/v1/transaction": schema{
+				"post": schema{
+					"summary":     "Creates a transaction.",
+					"parameters":  []schema{parameter("IdempotencyKey"), parameter("Actor")},
+					"requestBody": transactionBody,
+					"responses": schema{
+						"201": created,
+						"400": invalid,
+						"409": failure("A request with the same Idempotency-Key is in progress."),
+						"422": failure("The transaction is invalid, or the Idempotency-Key was used with a different request."),
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+				"get": schema{
+					"summary": "Lists the transactions, ordered by date.",
+					"parameters": []schema{
+						{
+							"name":        "month",
+							"in":          "query",
+							"description": "Lists only the transactions of the month, in UTC, eg: 2019-10.",
+							"schema":      schema{"type": "string", "pattern": `^\d{4}-\d{2}$`},
+						},
+					},
+					"responses": schema{
+						"200": content("The transactions.", schema{"type": "array", "items": ref("Transaction")}),
+						"400": failure("Invalid request or month."),
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+			},
+			"/v1/transactions:batch": schema{
+				"post": schema{
+					"summary": fmt.Sprintf("Creates up to %d transactions, each one validated like a single creation.", maxBatchSize),
+					"parameters": []schema{
+						{
+							"name":        "mode",
+							"in":          "query",
+							"description": "atomic creates all transactions or none, partial creates the valid ones.",
+							"schema":      schema{"type": "string", "enum": []string{"atomic", "partial"}, "default": "atomic"},
+						},
+						parameter("IdempotencyKey"),
+						parameter("Actor"),
+					},
+					"requestBody": schema{
+						"required": true,
+						"content": schema{"application/json": schema{"schema": schema{
+							"type":     "array",
+							"items":    ref("Transaction"),
+							"minItems": 1,
+							"maxItems": maxBatchSize,
+						}}},
+					},
+					"responses": schema{
+						"201": batch,
+						"207": content("Some transactions are invalid, the valid ones being created in the partial mode.",
+							schema{"type": "array", "items": ref("BatchResult")}),
+						"400": failure("Invalid request, mode, payload or batch size."),
+						"409": failure("A request with the same Idempotency-Key is in progress."),
+						"422": content("Some transactions are invalid, none being created in the atomic mode, "+
+							"or the Idempotency-Key was used with a different request.",
+							schema{"oneOf": []schema{{"type": "array", "items": ref("BatchResult")}, ref("Error")}}),
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+			},
+			"/v1/transaction/{id}": schema{
+				"parameters": []schema{parameter("ID")},
+				"get": schema{
+					"summary": "Gets a transaction.",
+					"responses": schema{
+						"200": transaction,
+						"400": invalid,
+						"404": notFound,
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+				"put": schema{
+					"summary":     "Changes a transaction, given the version being changed.",
+					"parameters":  []schema{parameter("IfMatch"), parameter("Actor")},
+					"requestBody": transactionBody,
+					"responses": schema{
+						"200": transaction,
+						"400": invalid,
+						"404": notFound,
+						"412": failure("The transaction was changed in the meantime."),
+						"422": failure("The transaction is invalid."),
+						"428": failure("The If-Match header is missing."),
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+				"delete": schema{
+					"summary":    "Removes a transaction, given the version being removed.",
+					"parameters": []schema{parameter("IfMatch"), parameter("Actor")},
+					"responses": schema{
+						"204": schema{"description": "Removed."},
+						"400": invalid,
+						"404": notFound,
+						"412": failure("The transaction was changed in the meantime."),
+						"428": failure("The If-Match header is missing."),
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+			},
+			"/v1/transaction/{id}/history": schema{
+				"parameters": []schema{parameter("ID")},
+				"get": schema{
+					"summary": "Lists the changes made to a transaction, oldest first.",
+					"responses": schema{
+						"200": content("The changes.", schema{"type": "array", "items": ref("AuditEntry")}),
+						"400": invalid,
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+			},
+			"/v1/ledger": schema{
+				"get": schema{
+					"summary":    "Rebuilds the transactions from their events, as they were known at a given time.",
+					"parameters": []schema{parameter("AsOf")},
+					"responses": schema{
+						"200": content("The transactions, ordered by date.", schema{"type": "array", "items": ref("Transaction")}),
+						"400": failure("Invalid request or as_of."),
+						"422": failure("The as_of is before the first event."),
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+			},
+			"/v1/ledger/summary": schema{
+				"get": schema{
+					"summary": "Totals the transactions of a month, as they were known at a given time.",
+					"parameters": []schema{
+						{
+							"name":        "month",
+							"in":          "query",
+							"description": "The month to total, eg: 2019-10, the month of as_of when missing.",
+							"schema":      schema{"type": "string", "pattern": `^\d{4}-\d{2}$`},
+						},
+						parameter("AsOf"),
+					},
+					"responses": schema{
+						"200": content("The summary of the month.", ref("MonthlySummary")),
+						"400": failure("Invalid request, month or as_of."),
+						"422": failure("The as_of is before the first event."),
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+			},
+			"/v1/undo": schema{
+				"post": schema{
+					"summary":    fmt.Sprintf("Undoes the latest operation of the actor, among their last %d.", core.JournalSize),
+					"parameters": []schema{parameter("IdempotencyKey"), parameter("JournalActor")},
+					"responses": schema{
+						"200": undone,
+						"400": failure("Invalid request, or missing X-Actor."),
+						"409": failure("Nothing is left to undo, or a request with the same Idempotency-Key is in progress."),
+						"412": failure("A transaction of the operation was changed or removed in the meantime, nothing is undone."),
+						"422": failure("The Idempotency-Key was used with a different request."),
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+			},
+			"/v1/redo": schema{
+				"post": schema{
+					"summary":    "Redoes the latest operation of the actor undone, until they make another one.",
+					"parameters": []schema{parameter("IdempotencyKey"), parameter("JournalActor")},
+					"responses": schema{
+						"200": redone,
+						"400": failure("Invalid request, or missing X-Actor."),
+						"409": failure("Nothing is left to redo, or a request with the same Idempotency-Key is in progress."),
+						"412": failure("A transaction of the operation was changed or removed in the meantime, nothing is redone."),
+						"422": failure("The Idempotency-Key was used with a different request."),
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+			},
+			"/v1/webhook": schema{
+				"post": schema{
+					"summary": "Subscribes a URL to the domain events, when enabled.",
+					"requestBody": schema{
+						"required": true,
+						"content":  schema{"application/json": schema{"schema": ref("Subscription")}},
+					},
+					"responses": schema{
+						"201": content("The created subscription, along with its secret.", ref("Subscription")),
+						"400": failure("Invalid request, payload, URL or events."),
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+				"get": schema{
+					"summary": "Lists the subscriptions, without their secrets, when enabled.",
+					"responses": schema{
+						"200": content("The subscriptions.", schema{"type": "array", "items": ref("Subscription")}),
+						"400": invalid,
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+			},
+			"/v1/webhook/{id}": schema{
+				"parameters": []schema{parameter("ID")},
+				"delete": schema{
+					"summary": "Removes a subscription, along with its deliveries, when enabled.",
+					"responses": schema{
+						"204": schema{"description": "Removed."},
+						"400": invalid,
+						"404": subscriptionNotFound,
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+			},
+			"/v1/webhook/{id}/deliveries": schema{
+				"parameters": []schema{parameter("ID")},
+				"get": schema{
+					"summary": "Lists the latest deliveries of a subscription, newest first, when enabled.",
+					"responses": schema{
+						"200": content("The deliveries.", schema{"type": "array", "items": ref("Delivery")}),
+						"400": invalid,
+						"404": subscriptionNotFound,
+						"500": failed,
+						"503": timedOut,
+					},
+				},
+			},
+		},
+		"components": schema{
+			"schemas": schemas,
+			"parameters": schema{
+				"ID": schema{
+					"name":     "id",
+					"in":       "path",
+					"required": true,
+					"schema":   schema{"type": "integer"},
+				},
+				"IdempotencyKey": schema{
+					"name": idempotency.Header,
+					"in":   "header",
+					"description": "Makes retries safe, the response to the first request being replayed to the next ones " +
+						"with the same " + ActorHeader + " header.",
+					"schema": schema{"type": "string", "maxLength": 255},
+				},
+				"Actor": schema{
+					"name":        ActorHeader,
+					"in":          "header",
+					"description": "Who makes the change, recorded in the history.",
+					"schema":      schema{"type": "string", "default": anonymousActor},
+				},
+				"JournalActor": schema{
+					"name":     ActorHeader,
+					"in":       "header",
+					"required": true,
+					"description": "Whose operations are undone and redone. It is trusted as given, so it must be set by " +
+						"a proxy authenticating the caller when the API is reachable by untrusted clients.",
+					"schema": schema{"type": "string"},
+				},
+				"AsOf": schema{
+					"name":        "as_of",
+					"in":          "query",
+					"description": "The RFC 3339 time the transactions are rebuilt as of, now when missing.",
+					"schema":      schema{"type": "string", "format": "date-time"},
+				},
+				"IfMatch": schema{
+					"name":        "If-Match",
+					"in":          "header",
+					"required":    true,
+					"description": "The ETag of the version being changed.",
+					"schema":      schema{"type": "string"},
+				},
+			},
+			"headers": schema{
+				"ETag": schema{
+					"description": "The version of the transaction, eg: \"1\".",
+					"schema":      schema{"type": "string"},
+				},
+				"Replayed": schema{
+					"description": "true when the response is replayed from a previous request with the same Idempotency-Key.",
+					"schema":      schema{"type": "string"},
+				},
+			},
+		},
+	}
+}
+
+// HandleOpenAPI responds with the OpenAPI specification of the API.
+func (api *API) HandleOpenAPI() http.HandlerFunc {
+	spec, _ := json.MarshalIndent(openAPI(), "", "  ")
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		respond(w, string(spec), http.StatusOK)
+	}
+}
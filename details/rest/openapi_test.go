@@ -0,0 +1,145 @@
+package rest
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/details/metrics"
+)
+
+// update rewrites the committed specification, once it is reviewed: go test ./details/rest -run OpenAPI -update
+var update = flag.Bool("update", false, "update testdata/openapi.json")
+
+const specFile = "testdata/openapi.json"
+
+func TestAPI_HandleOpenAPI(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when the specification is requested": func(t *testing.T) {
+			// arrange
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/v1/openapi.json", nil)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+
+			if *update {
+				assert.NoError(t, os.WriteFile(specFile, rr.Body.Bytes(), 0o644))
+			}
+
+			want, err := os.ReadFile(specFile)
+			assert.NoError(t, err)
+			assert.Equal(t, string(want), rr.Body.String(),
+				"the specification changed, review it then run: go test ./details/rest -run OpenAPI -update")
+		},
+		"when every route is described": func(t *testing.T) {
+			// arrange
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, nil, metrics.NewMetrics())
+			api.GraphQL = http.NotFoundHandler()
+			api.Webhooks = new(mockWebhookManager)
+			api.Ledger = new(mockLedgerReader)
+			api.Journal = new(mockUndoer)
+			paths := openAPI()["paths"].(schema)
+
+			routed := map[string]bool{}
+
+			// act
+			err := chi.Walk(api.Routes(), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+				// the routes of a sub router are walked with a wildcard, eg: /v1/*/transaction
+				route = strings.ReplaceAll(route, "/*/", "/")
+				routed[strings.ToLower(method)+" "+route] = true
+				return nil
+			})
+
+			// assert
+			assert.NoError(t, err)
+
+			described := map[string]bool{}
+			for path, operations := range paths {
+				for method := range operations.(schema) {
+					if method != "parameters" {
+						described[method+" "+path] = true
+					}
+				}
+			}
+			assert.Equal(t, routed, described)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestPayloads(t *testing.T) {
+	for name, p := range payloads {
+		t.Run(name, func(t *testing.T) {
+			var encoded []string
+			typ := reflect.TypeOf(p.value)
+			for i := 0; i < typ.NumField(); i++ {
+				if name, ok := jsonName(typ.Field(i)); ok {
+					encoded = append(encoded, name)
+				}
+			}
+
+			var described []string
+			for name, property := range p.properties {
+				described = append(described, name)
+				assert.NotEmpty(t, property["description"], "property %s is not described", name)
+			}
+
+			assert.ElementsMatch(t, encoded, described, "the payload and its described properties differ")
+			assert.Subset(t, encoded, p.required)
+		})
+	}
+}
+
+func TestSchemaOf(t *testing.T) {
+	type nested struct {
+		Tags   []string `json:"tags"`
+		Hidden string   `json:"-"`
+		Plain  bool
+		secret int
+	}
+
+	tests := map[string]struct {
+		value interface{}
+		want  schema
+	}{
+		"when an integer": {value: 0, want: schema{"type": "integer"}},
+		"when a time":     {value: testCreatedTrs.Date, want: schema{"type": "string", "format": "date-time"}},
+		"when a raw message": {
+			value: json.RawMessage{},
+			want:  schema{"type": "object", "nullable": true},
+		},
+		"when a struct": {
+			value: nested{},
+			want: schema{"type": "object", "properties": schema{
+				"tags":  schema{"type": "array", "items": schema{"type": "string"}},
+				"Plain": schema{"type": "boolean"},
+			}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, schemaOf(reflect.TypeOf(tt.value)))
+		})
+	}
+}
@@ -1,33 +1,100 @@
 package rest
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/cors"
+
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/logging"
+	"github.com/gritt/maskada/details/tracing"
 )
 
 // Routes assigns a path to a request handler.
 func (api *API) Routes() *chi.Mux {
-	// TODO @gritt: Test this!
 	r := chi.NewRouter()
 
-	mw := []func(http.Handler) http.Handler{}
+	mw := []func(http.Handler) http.Handler{logging.Handler, tracing.Handler}
+
+	origins := api.AllowedOrigins
+	if len(origins) == 0 {
+		origins = []string{"*"}
+	}
 
 	mw = append(
 		mw,
 		cors.New(cors.Options{
-			AllowedOrigins:   []string{"*"},
-			AllowedMethods:   []string{"GET", "POST"},
+			AllowedOrigins:   origins,
+			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
+			AllowedHeaders:   []string{"Accept", "Content-Type", ActorHeader, idempotency.Header, "If-Match", logging.RequestIDHeader},
+			ExposedHeaders:   []string{"ETag", idempotency.ReplayedHeader, logging.RequestIDHeader},
 			AllowCredentials: true,
 		}).Handler)
 
+	if api.Metrics != nil {
+		mw = append(mw, api.Metrics.Handler)
+	}
+
+	if api.RequestTimeout > 0 {
+		mw = append(mw, deadline(api.RequestTimeout))
+	}
+
 	r.Use(mw...)
 
+	if api.Metrics != nil {
+		r.Method(http.MethodGet, "/metrics", api.Metrics.Exposer())
+	}
+
+	if api.GraphQL != nil {
+		r.Method(http.MethodPost, "/graphql", api.GraphQL)
+	}
+
+	r.Method(http.MethodGet, "/healthz", api.HandleHealth())
+	r.Method(http.MethodGet, "/readyz", api.HandleReadiness())
+	r.Method(http.MethodGet, "/version", api.HandleVersion())
+
 	r.Route("/v1", func(r chi.Router) {
-		r.Method(http.MethodPost, "/transaction", api.HandleCreateTransaction())
+		r.Method(http.MethodPost, "/transaction", api.Idempotency.Handler(api.HandleCreateTransaction()))
+		r.Method(http.MethodPost, "/transactions:batch", api.Idempotency.Handler(api.HandleCreateTransactionBatch()))
 		r.Method(http.MethodGet, "/transaction", api.HandleListTransaction())
+		r.Method(http.MethodGet, "/transaction/{id}", api.HandleGetTransaction())
+		r.Method(http.MethodPut, "/transaction/{id}", api.HandleUpdateTransaction())
+		r.Method(http.MethodDelete, "/transaction/{id}", api.HandleDeleteTransaction())
+		r.Method(http.MethodGet, "/transaction/{id}/history", api.HandleListTransactionHistory())
+		r.Method(http.MethodGet, "/openapi.json", api.HandleOpenAPI())
+
+		if api.Webhooks != nil {
+			r.Method(http.MethodPost, "/webhook", api.HandleCreateWebhook())
+			r.Method(http.MethodGet, "/webhook", api.HandleListWebhook())
+			r.Method(http.MethodDelete, "/webhook/{id}", api.HandleDeleteWebhook())
+			r.Method(http.MethodGet, "/webhook/{id}/deliveries", api.HandleListWebhookDeliveries())
+		}
+
+		if api.Ledger != nil {
+			r.Method(http.MethodGet, "/ledger", api.HandleListLedger())
+			r.Method(http.MethodGet, "/ledger/summary", api.HandleLedgerSummary())
+		}
+
+		if api.Journal != nil {
+			r.Method(http.MethodPost, "/undo", api.Idempotency.Handler(api.HandleUndo()))
+			r.Method(http.MethodPost, "/redo", api.Idempotency.Handler(api.HandleRedo()))
+		}
 	})
 
 	return r
 }
+
+// deadline bounds the context of each request, so its queries are canceled once it can no longer be answered.
+func deadline(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
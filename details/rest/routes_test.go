@@ -1 +1,214 @@
 package rest
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/logging"
+	"github.com/gritt/maskada/details/metrics"
+)
+
+func TestAPI_Routes(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when batch is requested": func(t *testing.T) {
+			// arrange
+			b := new(mockTransactionBatchCreator)
+			b.On("CreateAll", []core.Transaction{testTrs}, "anonymous").Return([]core.BatchResult{{Transaction: testCreatedTrs}}, nil)
+			api := NewAPI(nil, b, nil, nil, nil, nil, nil, idempotency.NewMiddleware(nil, &details.Config{}), nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/v1/transactions:batch", strings.NewReader("["+testPayload+"]"))
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			b.AssertExpectations(t)
+		},
+		"when transaction is requested by id": func(t *testing.T) {
+			// arrange
+			g := new(mockTransactionGetter)
+			g.On("Get", testCreatedTrs.ID).Return(testCreatedTrs, nil)
+			api := NewAPI(nil, nil, nil, g, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/v1/transaction/"+strconv.Itoa(testCreatedTrs.ID), nil)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			g.AssertExpectations(t)
+		},
+		"when requested from an allowed origin": func(t *testing.T) {
+			// arrange
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+			api.AllowedOrigins = []string{"https://maskada.example"}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodOptions, "/v1/transaction", nil)
+			r.Header.Set("Origin", "https://maskada.example")
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, "https://maskada.example", rr.Header().Get("Access-Control-Allow-Origin"))
+		},
+		"when requested from another origin": func(t *testing.T) {
+			// arrange
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+			api.AllowedOrigins = []string{"https://maskada.example"}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodOptions, "/v1/transaction", nil)
+			r.Header.Set("Origin", "https://other.example")
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+		},
+		"when metrics are enabled, count requests by route": func(t *testing.T) {
+			// arrange
+			g := new(mockTransactionGetter)
+			g.On("Get", testCreatedTrs.ID).Return(testCreatedTrs, nil)
+			api := NewAPI(nil, nil, nil, g, nil, nil, nil, nil, nil, metrics.NewMetrics())
+			routes := api.Routes()
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/v1/transaction/"+strconv.Itoa(testCreatedTrs.ID), nil)
+			routes.ServeHTTP(rr, r)
+
+			// act
+			rr = httptest.NewRecorder()
+			r, _ = http.NewRequest(http.MethodGet, "/metrics", nil)
+			routes.ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, rr.Body.String(), `maskada_http_requests_total{code="200",method="GET",route="/v1/transaction/{id}"} 1`)
+			g.AssertExpectations(t)
+		},
+		"when a request fails, log the error with the request ID": func(t *testing.T) {
+			// arrange
+			buf := &bytes.Buffer{}
+			previous := slog.Default()
+			slog.SetDefault(logging.NewLogger(buf, "info"))
+			defer slog.SetDefault(previous)
+
+			var seen string
+			g := getterFunc(func(ctx context.Context, id int) (core.Transaction, error) {
+				seen = logging.RequestID(ctx)
+				return core.Transaction{}, errors.New("Get failed: Repository.FindByID failed: connection refused")
+			})
+			api := NewAPI(nil, nil, nil, g, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/v1/transaction/7", nil)
+			r.Header.Set(logging.RequestIDHeader, "client-id")
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			assert.Equal(t, "client-id", seen)
+			assert.Equal(t, "client-id", rr.Header().Get(logging.RequestIDHeader))
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			assert.Len(t, lines, 2)
+			assert.Contains(t, lines[0], `"level":"ERROR","msg":"request failed","error":"Get failed: Repository.FindByID failed: connection refused","status":500,"request_id":"client-id"`)
+			assert.Contains(t, lines[1], `"msg":"request handled","method":"GET","route":"/v1/transaction/{id}","status":500`)
+		},
+		"when the request outlasts its timeout, cancel the use case": func(t *testing.T) {
+			// arrange
+			g := getterFunc(func(ctx context.Context, id int) (core.Transaction, error) {
+				<-ctx.Done()
+				return core.Transaction{}, errors.Wrap(ctx.Err(), "Get failed")
+			})
+			api := NewAPI(nil, nil, nil, g, nil, nil, nil, nil, nil, nil)
+			api.RequestTimeout = 10 * time.Millisecond
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/v1/transaction/7", nil)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+			assert.JSONEq(t, `{"error": "Get failed: context deadline exceeded"}`, rr.Body.String())
+		},
+		"when ledger is not set, its routes are not found": func(t *testing.T) {
+			// arrange
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/v1/ledger", nil)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		},
+		"when journal is not set, its routes are not found": func(t *testing.T) {
+			// arrange
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/v1/undo", nil)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		},
+		"when unknown route is requested": func(t *testing.T) {
+			// arrange
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/v1/unknown", nil)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+// getterFunc gets a transaction with a function, seeing the context it is called with.
+type getterFunc func(ctx context.Context, id int) (core.Transaction, error)
+
+func (f getterFunc) Get(ctx context.Context, id int) (core.Transaction, error) {
+	return f(ctx, id)
+}
@@ -1,13 +1,35 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+
 	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/webhook"
+)
+
+const (
+	// ActorHeader identifies who is performing a change, recorded in the audit log, and scopes the idempotency keys.
+	ActorHeader = idempotency.ActorHeader
+
+	anonymousActor = "anonymous"
+
+	// maxBatchSize is the maximum number of transactions created by a single batch request.
+	maxBatchSize = 500
+
+	// statusClientClosedRequest is the non-standard status of a request the client canceled, as nginx logs it.
+	statusClientClosedRequest = 499
 )
 
 type skeleton struct {
@@ -17,6 +39,24 @@ type skeleton struct {
 	Category string    `json:"category"`
 	Date     time.Time `json:"date"`
 	Name     string    `json:"name"`
+	Version  int       `json:"version"`
+}
+
+type batchResultSkeleton struct {
+	Index int    `json:"index"`
+	ID    int    `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type auditSkeleton struct {
+	ID       int             `json:"id"`
+	Entity   string          `json:"entity"`
+	EntityID string          `json:"entity_id"`
+	Action   string          `json:"action"`
+	Actor    string          `json:"actor"`
+	Date     time.Time       `json:"date"`
+	Before   json.RawMessage `json:"before"`
+	After    json.RawMessage `json:"after"`
 }
 
 // HandleCreateTransaction receives the request and call the use case to create a transaction.
@@ -41,31 +81,99 @@ func (api *API) HandleCreateTransaction() http.HandlerFunc {
 			return
 		}
 
-		trs, err := api.TransactionCreator.Create(core.Transaction{
+		trs, err := api.TransactionCreator.Create(r.Context(), core.Transaction{
 			Amount:   payload.Amount,
 			Type:     payload.Type,
 			Category: core.Category{Name: payload.Category},
 			Date:     payload.Date,
 			Name:     payload.Name,
-		})
+		}, actor(r))
 		if err != nil {
-			respond(w, fmt.Sprintf(`{"error": "%s"}`, err.Error()), http.StatusInternalServerError)
+			respondError(w, r, err, status(err))
 			return
 		}
 
-		res := skeleton{
-			ID:       trs.ID,
-			Amount:   trs.Amount,
-			Type:     trs.Type,
-			Category: trs.Category.Name,
-			Date:     trs.Date,
-			Name:     trs.Name,
-		}
+		res := newSkeleton(trs)
 		jsonRes, _ := json.Marshal(&res)
 		respond(w, string(jsonRes), http.StatusCreated)
 	}
 }
 
+// HandleCreateTransactionBatch receives the request and call the use case to create many transactions,
+// all or none of them in the default atomic mode, or only the valid ones in the partial mode.
+func (api *API) HandleCreateTransactionBatch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Method != http.MethodPost {
+			respond(w, `{"error": "HandleCreateTransactionBatch failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		mode := r.URL.Query().Get("mode")
+		if mode != "" && mode != "atomic" && mode != "partial" {
+			respond(w, `{"error": "HandleCreateTransactionBatch failed: invalid mode"}`, http.StatusBadRequest)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			respond(w, `{"error": "HandleCreateTransactionBatch failed: could not read body"}`, http.StatusBadRequest)
+			return
+		}
+
+		payload := []skeleton{}
+		err = json.Unmarshal(body, &payload)
+		if err != nil {
+			respond(w, `{"error": "HandleCreateTransactionBatch failed: could not decode payload"}`, http.StatusBadRequest)
+			return
+		}
+
+		if len(payload) == 0 || len(payload) > maxBatchSize {
+			respond(w, `{"error": "HandleCreateTransactionBatch failed: invalid batch size"}`, http.StatusBadRequest)
+			return
+		}
+
+		trsl := make([]core.Transaction, 0, len(payload))
+		for _, p := range payload {
+			trsl = append(trsl, core.Transaction{
+				Amount:   p.Amount,
+				Type:     p.Type,
+				Category: core.Category{Name: p.Category},
+				Date:     p.Date,
+				Name:     p.Name,
+			})
+		}
+
+		create := api.TransactionBatchCreator.CreateAll
+		if mode == "partial" {
+			create = api.TransactionBatchCreator.CreateValid
+		}
+
+		results, err := create(r.Context(), trsl, actor(r))
+		if err != nil && errors.Cause(err) != core.ErrInvalidBatch {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		code := http.StatusCreated
+		res := []batchResultSkeleton{}
+		for i, result := range results {
+			item := batchResultSkeleton{Index: i, ID: result.Transaction.ID}
+			if result.Err != nil {
+				item.Error = result.Err.Error()
+				code = http.StatusMultiStatus
+			}
+			res = append(res, item)
+		}
+		if err != nil {
+			code = http.StatusUnprocessableEntity
+		}
+
+		jsonRes, _ := json.Marshal(&res)
+		respond(w, string(jsonRes), code)
+	}
+}
+
 // HandleListTransaction receives the request and call the use case to list transactions.
 func (api *API) HandleListTransaction() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -74,21 +182,174 @@ func (api *API) HandleListTransaction() http.HandlerFunc {
 			return
 		}
 
-		trsl, err := api.TransactionLister.List()
+		var trsl []core.Transaction
+		var err error
+		if param := r.URL.Query().Get("month"); param != "" {
+			month, parseErr := time.Parse(monthLayout, param)
+			if parseErr != nil {
+				respond(w, `{"error": "HandleListTransaction failed: invalid month, expected YYYY-MM"}`, http.StatusBadRequest)
+				return
+			}
+			trsl, err = api.TransactionLister.ListMonth(r.Context(), month)
+		} else {
+			trsl, err = api.TransactionLister.List(r.Context())
+		}
 		if err != nil {
-			respond(w, fmt.Sprintf(`{"error": "%s"}`, err.Error()), http.StatusInternalServerError)
+			respondError(w, r, err, status(err))
 			return
 		}
 
 		res := []skeleton{}
 		for _, trs := range trsl {
-			res = append(res, skeleton{
-				ID:       trs.ID,
-				Amount:   trs.Amount,
-				Type:     trs.Type,
-				Category: trs.Category.Name,
-				Date:     trs.Date,
-				Name:     trs.Name,
+			res = append(res, newSkeleton(trs))
+		}
+		jsonRes, _ := json.Marshal(&res)
+		respond(w, string(jsonRes), http.StatusOK)
+	}
+}
+
+// HandleGetTransaction receives the request and call the use case to get a transaction.
+func (api *API) HandleGetTransaction() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			respond(w, `{"error": "HandleGetTransaction failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			respond(w, `{"error": "HandleGetTransaction failed: invalid id"}`, http.StatusBadRequest)
+			return
+		}
+
+		trs, err := api.TransactionGetter.Get(r.Context(), id)
+		if err != nil {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		res := newSkeleton(trs)
+		jsonRes, _ := json.Marshal(&res)
+		w.Header().Set("ETag", etag(trs.Version))
+		respond(w, string(jsonRes), http.StatusOK)
+	}
+}
+
+// HandleUpdateTransaction receives the request and call the use case to change a transaction.
+func (api *API) HandleUpdateTransaction() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Method != http.MethodPut {
+			respond(w, `{"error": "HandleUpdateTransaction failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			respond(w, `{"error": "HandleUpdateTransaction failed: invalid id"}`, http.StatusBadRequest)
+			return
+		}
+
+		version, ok := ifMatch(r)
+		if !ok {
+			respond(w, `{"error": "HandleUpdateTransaction failed: missing If-Match"}`, http.StatusPreconditionRequired)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			respond(w, `{"error": "HandleUpdateTransaction failed: could not read body"}`, http.StatusBadRequest)
+			return
+		}
+
+		payload := skeleton{}
+		err = json.Unmarshal(body, &payload)
+		if err != nil {
+			respond(w, `{"error": "HandleUpdateTransaction failed: could not decode payload"}`, http.StatusBadRequest)
+			return
+		}
+
+		trs, err := api.TransactionUpdater.Update(r.Context(), core.Transaction{
+			ID:       id,
+			Amount:   payload.Amount,
+			Type:     payload.Type,
+			Category: core.Category{Name: payload.Category},
+			Date:     payload.Date,
+			Name:     payload.Name,
+			Version:  version,
+		}, actor(r))
+		if err != nil {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		res := newSkeleton(trs)
+		jsonRes, _ := json.Marshal(&res)
+		w.Header().Set("ETag", etag(trs.Version))
+		respond(w, string(jsonRes), http.StatusOK)
+	}
+}
+
+// HandleDeleteTransaction receives the request and call the use case to remove a transaction.
+func (api *API) HandleDeleteTransaction() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			respond(w, `{"error": "HandleDeleteTransaction failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			respond(w, `{"error": "HandleDeleteTransaction failed: invalid id"}`, http.StatusBadRequest)
+			return
+		}
+
+		version, ok := ifMatch(r)
+		if !ok {
+			respond(w, `{"error": "HandleDeleteTransaction failed: missing If-Match"}`, http.StatusPreconditionRequired)
+			return
+		}
+
+		if err := api.TransactionDeleter.Delete(r.Context(), id, version, actor(r)); err != nil {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// HandleListTransactionHistory receives the request and call the use case to list the changes made to a transaction.
+func (api *API) HandleListTransactionHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			respond(w, `{"error": "HandleListTransactionHistory failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			respond(w, `{"error": "HandleListTransactionHistory failed: invalid id"}`, http.StatusBadRequest)
+			return
+		}
+
+		entries, err := api.TransactionHistoryLister.List(r.Context(), id)
+		if err != nil {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		res := []auditSkeleton{}
+		for _, entry := range entries {
+			res = append(res, auditSkeleton{
+				ID:       entry.ID,
+				Entity:   entry.Entity,
+				EntityID: entry.EntityID,
+				Action:   entry.Action,
+				Actor:    entry.Actor,
+				Date:     entry.Date,
+				Before:   entry.Before,
+				After:    entry.After,
 			})
 		}
 		jsonRes, _ := json.Marshal(&res)
@@ -96,8 +357,82 @@ func (api *API) HandleListTransaction() http.HandlerFunc {
 	}
 }
 
+func newSkeleton(trs core.Transaction) skeleton {
+	return skeleton{
+		ID:       trs.ID,
+		Amount:   trs.Amount,
+		Type:     trs.Type,
+		Category: trs.Category.Name,
+		Date:     trs.Date,
+		Name:     trs.Name,
+		Version:  trs.Version,
+	}
+}
+
+// etag is the entity tag of a transaction version.
+func etag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ifMatch parses the version expected by the If-Match header, an unknown tag never matches a version.
+func ifMatch(r *http.Request) (version int, ok bool) {
+	tag := r.Header.Get("If-Match")
+	if tag == "" {
+		return 0, false
+	}
+
+	version, _ = strconv.Atoi(strings.Trim(strings.TrimPrefix(tag, "W/"), `"`))
+	return version, true
+}
+
+// status maps the cause of a use case error to the response status code.
+func status(err error) int {
+	switch errors.Cause(err) {
+	case core.ErrInvalidTransaction, core.ErrBeforeHistory:
+		return http.StatusUnprocessableEntity
+	case core.ErrNotFound:
+		return http.StatusNotFound
+	case core.ErrStaleVersion:
+		return http.StatusPreconditionFailed
+	case core.ErrNothingToUndo, core.ErrNothingToRedo:
+		return http.StatusConflict
+	case webhook.ErrInvalidSubscription:
+		return http.StatusBadRequest
+	case context.DeadlineExceeded:
+		return http.StatusServiceUnavailable
+	case context.Canceled:
+		return statusClientClosedRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// actor identifies who performs the request, anonymous unless the ActorHeader is given.
+func actor(r *http.Request) string {
+	if name := r.Header.Get(ActorHeader); name != "" {
+		return name
+	}
+	return anonymousActor
+}
+
 func respond(w http.ResponseWriter, msg string, status int) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(status)
 	_, _ = w.Write([]byte(msg))
 }
+
+// respondError responds with the error returned by a use case, logging it along with the ID of the request,
+// as a warning when the client is at fault, or as an info when it went away.
+func respondError(w http.ResponseWriter, r *http.Request, err error, status int) {
+	level := slog.LevelError
+	switch {
+	case status == statusClientClosedRequest:
+		level = slog.LevelInfo
+	case status < http.StatusInternalServerError:
+		level = slog.LevelWarn
+	}
+	slog.Log(r.Context(), level, "request failed", "error", err.Error(), "status", status)
+
+	msg, _ := json.Marshal(err.Error())
+	respond(w, `{"error": `+string(msg)+`}`, status)
+}
@@ -1,15 +1,19 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi"
+	pkgerrors "github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/gritt/maskada/core"
@@ -32,7 +36,7 @@ var (
 	}
 
 	testCreatedTrs = core.Transaction{
-		ID:       test.RandomNumber(),
+		ID:       test.RandomNumber() + 1,
 		Amount:   testTrs.Amount,
 		Type:     testTrs.Type,
 		Category: testTrs.Category,
@@ -55,7 +59,7 @@ func TestAPI_handleCreateTransaction(t *testing.T) {
 		"when invalid method": func(t *testing.T) {
 			// arrange
 			c := new(mockTransactionCreator)
-			api := NewAPI(c, nil)
+			api := NewAPI(c, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 			rr := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodGet, "/", strings.NewReader(`{}`))
@@ -72,7 +76,7 @@ func TestAPI_handleCreateTransaction(t *testing.T) {
 		"when invalid request": func(t *testing.T) {
 			// arrange
 			c := new(mockTransactionCreator)
-			api := NewAPI(c, nil)
+			api := NewAPI(c, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 			rr := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodPost, "/", nil)
@@ -89,7 +93,7 @@ func TestAPI_handleCreateTransaction(t *testing.T) {
 		"when invalid body": func(t *testing.T) {
 			// arrange
 			c := new(mockTransactionCreator)
-			api := NewAPI(c, nil)
+			api := NewAPI(c, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 			rr := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`{{}`))
@@ -106,8 +110,8 @@ func TestAPI_handleCreateTransaction(t *testing.T) {
 		"when create returns error": func(t *testing.T) {
 			// arrange
 			c := new(mockTransactionCreator)
-			c.On("Create", testTrs).Return(core.Transaction{}, errors.New("Create failed: err"))
-			api := NewAPI(c, nil)
+			c.On("Create", testTrs, "anonymous").Return(core.Transaction{}, errors.New("Create failed: err"))
+			api := NewAPI(c, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 			rr := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(testPayload))
@@ -121,11 +125,44 @@ func TestAPI_handleCreateTransaction(t *testing.T) {
 			assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
 			c.AssertExpectations(t)
 		},
+		"when transaction is invalid": func(t *testing.T) {
+			// arrange
+			c := new(mockTransactionCreator)
+			c.On("Create", testTrs, "anonymous").Return(core.Transaction{}, pkgerrors.Wrap(core.ErrInvalidTransaction, "Create failed"))
+			api := NewAPI(c, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(testPayload))
+
+			// act
+			api.HandleCreateTransaction()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Equal(t, `{"error": "Create failed: invalid transaction"}`, rr.Body.String())
+			c.AssertExpectations(t)
+		},
+		"when client goes away": func(t *testing.T) {
+			// arrange
+			c := new(mockTransactionCreator)
+			c.On("Create", testTrs, "anonymous").Return(core.Transaction{}, pkgerrors.Wrap(context.Canceled, "Create failed"))
+			api := NewAPI(c, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(testPayload))
+
+			// act
+			api.HandleCreateTransaction()(rr, r)
+
+			// assert
+			assert.Equal(t, statusClientClosedRequest, rr.Code)
+			c.AssertExpectations(t)
+		},
 		"when succeed creating transaction": func(t *testing.T) {
 			// arrange
 			c := new(mockTransactionCreator)
-			c.On("Create", testTrs).Return(testCreatedTrs, nil)
-			api := NewAPI(c, nil)
+			c.On("Create", testTrs, "anonymous").Return(testCreatedTrs, nil)
+			api := NewAPI(c, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
 			rr := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(testPayload))
@@ -148,6 +185,185 @@ func TestAPI_handleCreateTransaction(t *testing.T) {
 			assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
 			c.AssertExpectations(t)
 		},
+		"when actor is given": func(t *testing.T) {
+			// arrange
+			actor := test.RandomUsername()
+
+			c := new(mockTransactionCreator)
+			c.On("Create", testTrs, actor).Return(testCreatedTrs, nil)
+			api := NewAPI(c, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(testPayload))
+			r.Header.Set(ActorHeader, actor)
+
+			// act
+			api.HandleCreateTransaction()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			c.AssertExpectations(t)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestAPI_HandleCreateTransactionBatch(t *testing.T) {
+	invalidTrs := core.Transaction{Type: core.Credit, Category: core.Category{Name: testCategory}}
+	batchPayload := fmt.Sprintf(`[%s, {"type": 2, "category": "%s"}]`, testPayload, testCategory)
+
+	tests := map[string]func(*testing.T){
+		"when invalid method": func(t *testing.T) {
+			// arrange
+			b := new(mockTransactionBatchCreator)
+			api := NewAPI(nil, b, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", strings.NewReader(batchPayload))
+
+			// act
+			api.HandleCreateTransactionBatch()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleCreateTransactionBatch failed: invalid request"}`, rr.Body.String())
+			b.AssertExpectations(t)
+		},
+		"when invalid mode": func(t *testing.T) {
+			// arrange
+			b := new(mockTransactionBatchCreator)
+			api := NewAPI(nil, b, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/?mode=lenient", strings.NewReader(batchPayload))
+
+			// act
+			api.HandleCreateTransactionBatch()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleCreateTransactionBatch failed: invalid mode"}`, rr.Body.String())
+			b.AssertExpectations(t)
+		},
+		"when invalid body": func(t *testing.T) {
+			// arrange
+			b := new(mockTransactionBatchCreator)
+			api := NewAPI(nil, b, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(testPayload))
+
+			// act
+			api.HandleCreateTransactionBatch()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleCreateTransactionBatch failed: could not decode payload"}`, rr.Body.String())
+			b.AssertExpectations(t)
+		},
+		"when empty batch": func(t *testing.T) {
+			// arrange
+			b := new(mockTransactionBatchCreator)
+			api := NewAPI(nil, b, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`[]`))
+
+			// act
+			api.HandleCreateTransactionBatch()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleCreateTransactionBatch failed: invalid batch size"}`, rr.Body.String())
+			b.AssertExpectations(t)
+		},
+		"when create returns error": func(t *testing.T) {
+			// arrange
+			b := new(mockTransactionBatchCreator)
+			b.On("CreateAll", []core.Transaction{testTrs, invalidTrs}, "anonymous").Return([]core.BatchResult{}, errors.New("CreateAll failed: err"))
+			api := NewAPI(nil, b, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(batchPayload))
+
+			// act
+			api.HandleCreateTransactionBatch()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			assert.Equal(t, `{"error": "CreateAll failed: err"}`, rr.Body.String())
+			b.AssertExpectations(t)
+		},
+		"when atomic batch holds invalid transactions": func(t *testing.T) {
+			// arrange
+			b := new(mockTransactionBatchCreator)
+			b.On("CreateAll", []core.Transaction{testTrs, invalidTrs}, "anonymous").Return(
+				[]core.BatchResult{{}, {Err: errors.New("Transaction.Validate: invalid amount")}},
+				pkgerrors.Wrap(core.ErrInvalidBatch, "CreateAll failed"),
+			)
+			api := NewAPI(nil, b, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/?mode=atomic", strings.NewReader(batchPayload))
+
+			// act
+			api.HandleCreateTransactionBatch()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Equal(t, `[{"index":0},{"index":1,"error":"Transaction.Validate: invalid amount"}]`, rr.Body.String())
+			assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+			b.AssertExpectations(t)
+		},
+		"when atomic batch is created": func(t *testing.T) {
+			// arrange
+			b := new(mockTransactionBatchCreator)
+			b.On("CreateAll", []core.Transaction{testTrs}, "anonymous").Return(
+				[]core.BatchResult{{Transaction: testCreatedTrs}},
+				nil,
+			)
+			api := NewAPI(nil, b, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader("["+testPayload+"]"))
+
+			// act
+			api.HandleCreateTransactionBatch()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			assert.Equal(t, fmt.Sprintf(`[{"index":0,"id":%d}]`, testCreatedTrs.ID), rr.Body.String())
+			b.AssertExpectations(t)
+		},
+		"when partial batch is created": func(t *testing.T) {
+			// arrange
+			b := new(mockTransactionBatchCreator)
+			b.On("CreateValid", []core.Transaction{testTrs, invalidTrs}, "anonymous").Return(
+				[]core.BatchResult{{Transaction: testCreatedTrs}, {Err: errors.New("Transaction.Validate: invalid amount")}},
+				nil,
+			)
+			api := NewAPI(nil, b, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/?mode=partial", strings.NewReader(batchPayload))
+
+			// act
+			api.HandleCreateTransactionBatch()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusMultiStatus, rr.Code)
+			assert.Equal(
+				t,
+				fmt.Sprintf(`[{"index":0,"id":%d},{"index":1,"error":"Transaction.Validate: invalid amount"}]`, testCreatedTrs.ID),
+				rr.Body.String(),
+			)
+			b.AssertExpectations(t)
+		},
 	}
 
 	for name, run := range tests {
@@ -162,7 +378,7 @@ func TestAPI_HandleListTransaction(t *testing.T) {
 		"when invalid method": func(t *testing.T) {
 			// arrange
 			l := new(mockTransactionLister)
-			api := NewAPI(nil, l)
+			api := NewAPI(nil, nil, l, nil, nil, nil, nil, nil, nil, nil)
 
 			rr := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodPost, "/", nil)
@@ -177,7 +393,7 @@ func TestAPI_HandleListTransaction(t *testing.T) {
 			// arrange
 			l := new(mockTransactionLister)
 			l.On("List").Return([]core.Transaction{}, errors.New("List failed: err"))
-			api := NewAPI(nil, l)
+			api := NewAPI(nil, nil, l, nil, nil, nil, nil, nil, nil, nil)
 
 			rr := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodGet, "/", nil)
@@ -195,7 +411,7 @@ func TestAPI_HandleListTransaction(t *testing.T) {
 			// arrange
 			l := new(mockTransactionLister)
 			l.On("List").Return([]core.Transaction{}, nil)
-			api := NewAPI(nil, l)
+			api := NewAPI(nil, nil, l, nil, nil, nil, nil, nil, nil, nil)
 
 			rr := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodGet, "/", nil)
@@ -212,7 +428,7 @@ func TestAPI_HandleListTransaction(t *testing.T) {
 			// arrange
 			l := new(mockTransactionLister)
 			l.On("List").Return(testCreatedTrsList, nil)
-			api := NewAPI(nil, l)
+			api := NewAPI(nil, nil, l, nil, nil, nil, nil, nil, nil, nil)
 
 			rr := httptest.NewRecorder()
 			r, _ := http.NewRequest(http.MethodGet, "/", nil)
@@ -235,6 +451,43 @@ func TestAPI_HandleListTransaction(t *testing.T) {
 			assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
 			l.AssertExpectations(t)
 		},
+		"when invalid month": func(t *testing.T) {
+			// arrange
+			l := new(mockTransactionLister)
+			api := NewAPI(nil, nil, l, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/?month=10-2019", nil)
+
+			// act
+			api.HandleListTransaction()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleListTransaction failed: invalid month, expected YYYY-MM"}`, rr.Body.String())
+			l.AssertExpectations(t)
+		},
+		"when succeed with the list of a month": func(t *testing.T) {
+			// arrange
+			month := time.Date(2019, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+			l := new(mockTransactionLister)
+			l.On("ListMonth", month).Return(testCreatedTrsList, nil)
+			api := NewAPI(nil, nil, l, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/?month=2019-10", nil)
+
+			// act
+			api.HandleListTransaction()(rr, r)
+
+			want, _ := json.Marshal(&[]skeleton{newSkeleton(testCreatedTrsList[0])})
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, string(want), rr.Body.String())
+			l.AssertExpectations(t)
+		},
 	}
 
 	for name, run := range tests {
@@ -243,3 +496,497 @@ func TestAPI_HandleListTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_HandleGetTransaction(t *testing.T) {
+	id := strconv.Itoa(testCreatedTrs.ID)
+
+	tests := map[string]func(t *testing.T){
+		"when invalid method": func(t *testing.T) {
+			// arrange
+			g := new(mockTransactionGetter)
+			api := NewAPI(nil, nil, nil, g, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", nil)
+
+			// act
+			api.HandleGetTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleGetTransaction failed: invalid request"}`, rr.Body.String())
+			g.AssertExpectations(t)
+		},
+		"when invalid id": func(t *testing.T) {
+			// arrange
+			g := new(mockTransactionGetter)
+			api := NewAPI(nil, nil, nil, g, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleGetTransaction()(rr, withID(r, "abc"))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleGetTransaction failed: invalid id"}`, rr.Body.String())
+			g.AssertExpectations(t)
+		},
+		"when transaction is not found": func(t *testing.T) {
+			// arrange
+			g := new(mockTransactionGetter)
+			g.On("Get", testCreatedTrs.ID).Return(core.Transaction{}, pkgerrors.Wrap(core.ErrNotFound, "Get failed"))
+			api := NewAPI(nil, nil, nil, g, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleGetTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+			assert.Equal(t, `{"error": "Get failed: not found"}`, rr.Body.String())
+			g.AssertExpectations(t)
+		},
+		"when get returns error": func(t *testing.T) {
+			// arrange
+			g := new(mockTransactionGetter)
+			g.On("Get", testCreatedTrs.ID).Return(core.Transaction{}, errors.New("Get failed: err"))
+			api := NewAPI(nil, nil, nil, g, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleGetTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			assert.Equal(t, `{"error": "Get failed: err"}`, rr.Body.String())
+			g.AssertExpectations(t)
+		},
+		"when get returns error with quotes": func(t *testing.T) {
+			// arrange
+			g := new(mockTransactionGetter)
+			g.On("Get", testCreatedTrs.ID).Return(core.Transaction{}, errors.New(`Get failed: near "transaction"`))
+			api := NewAPI(nil, nil, nil, g, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleGetTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			assert.Equal(t, `{"error": "Get failed: near \"transaction\""}`, rr.Body.String())
+			assert.True(t, json.Valid(rr.Body.Bytes()))
+			g.AssertExpectations(t)
+		},
+		"when succeed getting transaction": func(t *testing.T) {
+			// arrange
+			trs := testCreatedTrs
+			trs.Version = 3
+
+			g := new(mockTransactionGetter)
+			g.On("Get", trs.ID).Return(trs, nil)
+			api := NewAPI(nil, nil, nil, g, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleGetTransaction()(rr, withID(r, id))
+
+			want, _ := json.Marshal(newSkeleton(trs))
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, string(want), rr.Body.String())
+			assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+			assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+			g.AssertExpectations(t)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestAPI_HandleUpdateTransaction(t *testing.T) {
+	id := strconv.Itoa(testCreatedTrs.ID)
+
+	given := testTrs
+	given.ID = testCreatedTrs.ID
+	given.Version = 3
+
+	updated := testCreatedTrs
+	updated.Version = 4
+
+	tests := map[string]func(t *testing.T){
+		"when invalid method": func(t *testing.T) {
+			// arrange
+			u := new(mockTransactionUpdater)
+			api := NewAPI(nil, nil, nil, nil, u, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(testPayload))
+
+			// act
+			api.HandleUpdateTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleUpdateTransaction failed: invalid request"}`, rr.Body.String())
+			u.AssertExpectations(t)
+		},
+		"when invalid id": func(t *testing.T) {
+			// arrange
+			u := new(mockTransactionUpdater)
+			api := NewAPI(nil, nil, nil, nil, u, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPut, "/", strings.NewReader(testPayload))
+
+			// act
+			api.HandleUpdateTransaction()(rr, withID(r, "abc"))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleUpdateTransaction failed: invalid id"}`, rr.Body.String())
+			u.AssertExpectations(t)
+		},
+		"when missing If-Match": func(t *testing.T) {
+			// arrange
+			u := new(mockTransactionUpdater)
+			api := NewAPI(nil, nil, nil, nil, u, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPut, "/", strings.NewReader(testPayload))
+
+			// act
+			api.HandleUpdateTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusPreconditionRequired, rr.Code)
+			assert.Equal(t, `{"error": "HandleUpdateTransaction failed: missing If-Match"}`, rr.Body.String())
+			u.AssertExpectations(t)
+		},
+		"when invalid body": func(t *testing.T) {
+			// arrange
+			u := new(mockTransactionUpdater)
+			api := NewAPI(nil, nil, nil, nil, u, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPut, "/", strings.NewReader(`{{}`))
+			r.Header.Set("If-Match", `"3"`)
+
+			// act
+			api.HandleUpdateTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleUpdateTransaction failed: could not decode payload"}`, rr.Body.String())
+			u.AssertExpectations(t)
+		},
+		"when version is stale": func(t *testing.T) {
+			// arrange
+			u := new(mockTransactionUpdater)
+			u.On("Update", given, "anonymous").Return(core.Transaction{}, pkgerrors.Wrap(core.ErrStaleVersion, "Update failed"))
+			api := NewAPI(nil, nil, nil, nil, u, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPut, "/", strings.NewReader(testPayload))
+			r.Header.Set("If-Match", `"3"`)
+
+			// act
+			api.HandleUpdateTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+			assert.Equal(t, `{"error": "Update failed: stale version"}`, rr.Body.String())
+			u.AssertExpectations(t)
+		},
+		"when transaction is invalid": func(t *testing.T) {
+			// arrange
+			u := new(mockTransactionUpdater)
+			u.On("Update", given, "anonymous").Return(core.Transaction{}, pkgerrors.Wrap(core.ErrInvalidTransaction, "Update failed"))
+			api := NewAPI(nil, nil, nil, nil, u, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPut, "/", strings.NewReader(testPayload))
+			r.Header.Set("If-Match", `"3"`)
+
+			// act
+			api.HandleUpdateTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+			assert.Equal(t, `{"error": "Update failed: invalid transaction"}`, rr.Body.String())
+			u.AssertExpectations(t)
+		},
+		"when If-Match is not a version": func(t *testing.T) {
+			// arrange
+			unknown := given
+			unknown.Version = 0
+
+			u := new(mockTransactionUpdater)
+			u.On("Update", unknown, "anonymous").Return(core.Transaction{}, pkgerrors.Wrap(core.ErrStaleVersion, "Update failed"))
+			api := NewAPI(nil, nil, nil, nil, u, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPut, "/", strings.NewReader(testPayload))
+			r.Header.Set("If-Match", `"abc"`)
+
+			// act
+			api.HandleUpdateTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+			u.AssertExpectations(t)
+		},
+		"when succeed updating transaction": func(t *testing.T) {
+			// arrange
+			u := new(mockTransactionUpdater)
+			u.On("Update", given, "anonymous").Return(updated, nil)
+			api := NewAPI(nil, nil, nil, nil, u, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPut, "/", strings.NewReader(testPayload))
+			r.Header.Set("If-Match", `"3"`)
+
+			// act
+			api.HandleUpdateTransaction()(rr, withID(r, id))
+
+			want, _ := json.Marshal(newSkeleton(updated))
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, string(want), rr.Body.String())
+			assert.Equal(t, `"4"`, rr.Header().Get("ETag"))
+			u.AssertExpectations(t)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestAPI_HandleDeleteTransaction(t *testing.T) {
+	id := strconv.Itoa(testCreatedTrs.ID)
+
+	tests := map[string]func(t *testing.T){
+		"when invalid method": func(t *testing.T) {
+			// arrange
+			d := new(mockTransactionDeleter)
+			api := NewAPI(nil, nil, nil, nil, nil, d, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleDeleteTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleDeleteTransaction failed: invalid request"}`, rr.Body.String())
+			d.AssertExpectations(t)
+		},
+		"when missing If-Match": func(t *testing.T) {
+			// arrange
+			d := new(mockTransactionDeleter)
+			api := NewAPI(nil, nil, nil, nil, nil, d, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodDelete, "/", nil)
+
+			// act
+			api.HandleDeleteTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusPreconditionRequired, rr.Code)
+			assert.Equal(t, `{"error": "HandleDeleteTransaction failed: missing If-Match"}`, rr.Body.String())
+			d.AssertExpectations(t)
+		},
+		"when version is stale": func(t *testing.T) {
+			// arrange
+			d := new(mockTransactionDeleter)
+			d.On("Delete", testCreatedTrs.ID, 3, "anonymous").Return(pkgerrors.Wrap(core.ErrStaleVersion, "Delete failed"))
+			api := NewAPI(nil, nil, nil, nil, nil, d, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodDelete, "/", nil)
+			r.Header.Set("If-Match", `"3"`)
+
+			// act
+			api.HandleDeleteTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+			assert.Equal(t, `{"error": "Delete failed: stale version"}`, rr.Body.String())
+			d.AssertExpectations(t)
+		},
+		"when succeed deleting transaction": func(t *testing.T) {
+			// arrange
+			d := new(mockTransactionDeleter)
+			d.On("Delete", testCreatedTrs.ID, 3, "anonymous").Return(nil)
+			api := NewAPI(nil, nil, nil, nil, nil, d, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodDelete, "/", nil)
+			r.Header.Set("If-Match", `W/"3"`)
+
+			// act
+			api.HandleDeleteTransaction()(rr, withID(r, id))
+
+			// assert
+			assert.Equal(t, http.StatusNoContent, rr.Code)
+			assert.Empty(t, rr.Body.String())
+			d.AssertExpectations(t)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestAPI_HandleListTransactionHistory(t *testing.T) {
+	transactionID := test.RandomNumber()
+
+	tests := map[string]func(t *testing.T){
+		"when invalid method": func(t *testing.T) {
+			// arrange
+			h := new(mockTransactionHistoryLister)
+			api := NewAPI(nil, nil, nil, nil, nil, nil, h, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", nil)
+
+			// act
+			api.HandleListTransactionHistory()(rr, withID(r, strconv.Itoa(transactionID)))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleListTransactionHistory failed: invalid request"}`, rr.Body.String())
+			h.AssertExpectations(t)
+		},
+		"when invalid id": func(t *testing.T) {
+			// arrange
+			h := new(mockTransactionHistoryLister)
+			api := NewAPI(nil, nil, nil, nil, nil, nil, h, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleListTransactionHistory()(rr, withID(r, "abc"))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleListTransactionHistory failed: invalid id"}`, rr.Body.String())
+			h.AssertExpectations(t)
+		},
+		"when list returns error": func(t *testing.T) {
+			// arrange
+			h := new(mockTransactionHistoryLister)
+			h.On("List", transactionID).Return([]core.AuditEntry{}, errors.New("ListHistory failed: err"))
+			api := NewAPI(nil, nil, nil, nil, nil, nil, h, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleListTransactionHistory()(rr, withID(r, strconv.Itoa(transactionID)))
+
+			// assert
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			assert.Equal(t, `{"error": "ListHistory failed: err"}`, rr.Body.String())
+			assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+			h.AssertExpectations(t)
+		},
+		"when succeed with empty history": func(t *testing.T) {
+			// arrange
+			h := new(mockTransactionHistoryLister)
+			h.On("List", transactionID).Return([]core.AuditEntry{}, nil)
+			api := NewAPI(nil, nil, nil, nil, nil, nil, h, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleListTransactionHistory()(rr, withID(r, strconv.Itoa(transactionID)))
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, `[]`, rr.Body.String())
+			h.AssertExpectations(t)
+		},
+		"when succeed with history": func(t *testing.T) {
+			// arrange
+			entry := core.AuditEntry{
+				ID:       test.RandomNumber(),
+				Entity:   core.AuditTransaction,
+				EntityID: strconv.Itoa(transactionID),
+				Action:   core.AuditCreate,
+				Actor:    test.RandomUsername(),
+				Date:     time.Now().UTC(),
+				After:    []byte(`{"id":1}`),
+			}
+
+			h := new(mockTransactionHistoryLister)
+			h.On("List", transactionID).Return([]core.AuditEntry{entry}, nil)
+			api := NewAPI(nil, nil, nil, nil, nil, nil, h, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleListTransactionHistory()(rr, withID(r, strconv.Itoa(transactionID)))
+
+			want := fmt.Sprintf(
+				`[{"id":%d,"entity":"transaction","entity_id":"%d","action":"create","actor":"%s","date":%s,"before":null,"after":{"id":1}}]`,
+				entry.ID,
+				transactionID,
+				entry.Actor,
+				mustMarshal(t, entry.Date),
+			)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, want, rr.Body.String())
+			assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+			h.AssertExpectations(t)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func withID(r *http.Request, id string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to: Marshal %v", v)
+	}
+	return string(b)
+}
@@ -0,0 +1,174 @@
+package rest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/gritt/maskada/details/webhook"
+)
+
+type subscriptionSkeleton struct {
+	ID     int       `json:"id"`
+	URL    string    `json:"url"`
+	Secret string    `json:"secret,omitempty"`
+	Events []string  `json:"events"`
+	Date   time.Time `json:"date"`
+}
+
+type deliverySkeleton struct {
+	ID           int        `json:"id"`
+	EventID      int        `json:"event_id"`
+	Event        string     `json:"event"`
+	Attempt      int        `json:"attempt"`
+	Status       string     `json:"status"`
+	ResponseCode int        `json:"response_code"`
+	Error        string     `json:"error"`
+	ScheduledAt  time.Time  `json:"scheduled_at"`
+	AttemptedAt  *time.Time `json:"attempted_at"`
+}
+
+// HandleCreateWebhook receives the request and call the service to subscribe a URL, responding with its secret.
+func (api *API) HandleCreateWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Method != http.MethodPost {
+			respond(w, `{"error": "HandleCreateWebhook failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			respond(w, `{"error": "HandleCreateWebhook failed: could not read body"}`, http.StatusBadRequest)
+			return
+		}
+
+		payload := subscriptionSkeleton{}
+		err = json.Unmarshal(body, &payload)
+		if err != nil {
+			respond(w, `{"error": "HandleCreateWebhook failed: could not decode payload"}`, http.StatusBadRequest)
+			return
+		}
+
+		sub, err := api.Webhooks.Subscribe(r.Context(), webhook.Subscription{
+			URL:    payload.URL,
+			Secret: payload.Secret,
+			Events: payload.Events,
+		})
+		if err != nil {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		res := newSubscriptionSkeleton(sub)
+		jsonRes, _ := json.Marshal(&res)
+		respond(w, string(jsonRes), http.StatusCreated)
+	}
+}
+
+// HandleListWebhook receives the request and call the service to list the subscriptions, without their secrets.
+func (api *API) HandleListWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			respond(w, `{"error": "HandleListWebhook failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		subs, err := api.Webhooks.Subscriptions(r.Context())
+		if err != nil {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		res := []subscriptionSkeleton{}
+		for _, sub := range subs {
+			res = append(res, newSubscriptionSkeleton(sub))
+		}
+		jsonRes, _ := json.Marshal(&res)
+		respond(w, string(jsonRes), http.StatusOK)
+	}
+}
+
+// HandleDeleteWebhook receives the request and call the service to remove a subscription.
+func (api *API) HandleDeleteWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			respond(w, `{"error": "HandleDeleteWebhook failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			respond(w, `{"error": "HandleDeleteWebhook failed: invalid id"}`, http.StatusBadRequest)
+			return
+		}
+
+		if err := api.Webhooks.Unsubscribe(r.Context(), id); err != nil {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// HandleListWebhookDeliveries receives the request and call the service to list the latest deliveries of a
+// subscription.
+func (api *API) HandleListWebhookDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			respond(w, `{"error": "HandleListWebhookDeliveries failed: invalid request"}`, http.StatusBadRequest)
+			return
+		}
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			respond(w, `{"error": "HandleListWebhookDeliveries failed: invalid id"}`, http.StatusBadRequest)
+			return
+		}
+
+		deliveries, err := api.Webhooks.Deliveries(r.Context(), id)
+		if err != nil {
+			respondError(w, r, err, status(err))
+			return
+		}
+
+		res := []deliverySkeleton{}
+		for _, d := range deliveries {
+			res = append(res, newDeliverySkeleton(d))
+		}
+		jsonRes, _ := json.Marshal(&res)
+		respond(w, string(jsonRes), http.StatusOK)
+	}
+}
+
+func newSubscriptionSkeleton(sub webhook.Subscription) subscriptionSkeleton {
+	return subscriptionSkeleton{
+		ID:     sub.ID,
+		URL:    sub.URL,
+		Secret: sub.Secret,
+		Events: sub.Events,
+		Date:   sub.Date,
+	}
+}
+
+func newDeliverySkeleton(d webhook.Delivery) deliverySkeleton {
+	res := deliverySkeleton{
+		ID:           d.ID,
+		EventID:      d.EventID,
+		Event:        d.Event,
+		Attempt:      d.Attempt,
+		Status:       d.Status,
+		ResponseCode: d.ResponseCode,
+		Error:        d.Error,
+		ScheduledAt:  d.ScheduledAt,
+	}
+	if !d.AttemptedAt.IsZero() {
+		res.AttemptedAt = &d.AttemptedAt
+	}
+	return res
+}
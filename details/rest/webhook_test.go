@@ -0,0 +1,417 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/webhook"
+)
+
+var testSubscription = webhook.Subscription{
+	ID:     1,
+	URL:    "https://example.com/hook",
+	Secret: "secret",
+	Events: []string{webhook.TransactionCreated},
+	Date:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+func TestAPI_HandleCreateWebhook(t *testing.T) {
+	given := webhook.Subscription{URL: testSubscription.URL, Events: testSubscription.Events}
+
+	tests := map[string]func(t *testing.T){
+		"when invalid method": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", strings.NewReader(`{}`))
+
+			// act
+			api.HandleCreateWebhook()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleCreateWebhook failed: invalid request"}`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+		"when invalid payload": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": 1}`))
+
+			// act
+			api.HandleCreateWebhook()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleCreateWebhook failed: could not decode payload"}`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+		"when subscription is invalid": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			m.On("Subscribe", webhook.Subscription{URL: "ftp://example.com", Events: given.Events}).
+				Return(webhook.Subscription{}, pkgerrors.Wrap(pkgerrors.Wrap(webhook.ErrInvalidSubscription, "invalid url ftp://example.com"), "Subscribe failed"))
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": "ftp://example.com", "events": ["transaction.created"]}`))
+
+			// act
+			api.HandleCreateWebhook()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "Subscribe failed: invalid url ftp://example.com: invalid subscription"}`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+		"when service fails": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			m.On("Subscribe", given).Return(webhook.Subscription{}, errors.New("Subscribe failed: err"))
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": "https://example.com/hook", "events": ["transaction.created"]}`))
+
+			// act
+			api.HandleCreateWebhook()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			assert.Equal(t, `{"error": "Subscribe failed: err"}`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+		"when succeed subscribing": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			m.On("Subscribe", given).Return(testSubscription, nil)
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": "https://example.com/hook", "events": ["transaction.created"]}`))
+
+			// act
+			api.HandleCreateWebhook()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			assert.JSONEq(t, `{
+				"id": 1,
+				"url": "https://example.com/hook",
+				"secret": "secret",
+				"events": ["transaction.created"],
+				"date": "2026-01-02T03:04:05Z"
+			}`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestAPI_HandleListWebhook(t *testing.T) {
+	tests := map[string]func(t *testing.T){
+		"when service fails": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			m.On("Subscriptions").Return([]webhook.Subscription{}, errors.New("Subscriptions failed: err"))
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleListWebhook()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusInternalServerError, rr.Code)
+			assert.Equal(t, `{"error": "Subscriptions failed: err"}`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+		"when no subscriptions are found": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			m.On("Subscriptions").Return([]webhook.Subscription{}, nil)
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleListWebhook()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, `[]`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+		"when succeed listing subscriptions": func(t *testing.T) {
+			// arrange
+			listed := testSubscription
+			listed.Secret = ""
+
+			m := new(mockWebhookManager)
+			m.On("Subscriptions").Return([]webhook.Subscription{listed}, nil)
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleListWebhook()(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `[{
+				"id": 1,
+				"url": "https://example.com/hook",
+				"events": ["transaction.created"],
+				"date": "2026-01-02T03:04:05Z"
+			}]`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestAPI_HandleDeleteWebhook(t *testing.T) {
+	tests := map[string]func(t *testing.T){
+		"when invalid id": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodDelete, "/", nil)
+
+			// act
+			api.HandleDeleteWebhook()(rr, withID(r, "one"))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleDeleteWebhook failed: invalid id"}`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+		"when subscription is not found": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			m.On("Unsubscribe", 1).Return(pkgerrors.Wrap(core.ErrNotFound, "Unsubscribe failed"))
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodDelete, "/", nil)
+
+			// act
+			api.HandleDeleteWebhook()(rr, withID(r, "1"))
+
+			// assert
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+			assert.Equal(t, `{"error": "Unsubscribe failed: not found"}`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+		"when succeed unsubscribing": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			m.On("Unsubscribe", 1).Return(nil)
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodDelete, "/", nil)
+
+			// act
+			api.HandleDeleteWebhook()(rr, withID(r, "1"))
+
+			// assert
+			assert.Equal(t, http.StatusNoContent, rr.Code)
+			assert.Empty(t, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestAPI_HandleListWebhookDeliveries(t *testing.T) {
+	scheduledAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := map[string]func(t *testing.T){
+		"when invalid id": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleListWebhookDeliveries()(rr, withID(r, "one"))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Equal(t, `{"error": "HandleListWebhookDeliveries failed: invalid id"}`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+		"when subscription is not found": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			m.On("Deliveries", 1).Return([]webhook.Delivery{}, pkgerrors.Wrap(core.ErrNotFound, "Deliveries failed"))
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleListWebhookDeliveries()(rr, withID(r, "1"))
+
+			// assert
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+			assert.Equal(t, `{"error": "Deliveries failed: not found"}`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+		"when succeed listing deliveries": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			m.On("Deliveries", 1).Return([]webhook.Delivery{
+				{ID: 2, SubscriptionID: 1, EventID: 5, Event: webhook.TransactionCreated, Attempt: 2, Status: webhook.Pending, ScheduledAt: scheduledAt.Add(time.Minute)},
+				{
+					ID: 1, SubscriptionID: 1, EventID: 5, Event: webhook.TransactionCreated, Attempt: 1, Status: webhook.Failed,
+					ResponseCode: 503, Error: "unexpected status 503 Service Unavailable", ScheduledAt: scheduledAt, AttemptedAt: scheduledAt,
+				},
+			}, nil)
+			api := &API{Webhooks: m}
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			// act
+			api.HandleListWebhookDeliveries()(rr, withID(r, "1"))
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, `[
+				{
+					"id": 2,
+					"event_id": 5,
+					"event": "transaction.created",
+					"attempt": 2,
+					"status": "pending",
+					"response_code": 0,
+					"error": "",
+					"scheduled_at": "2026-01-02T03:05:05Z",
+					"attempted_at": null
+				},
+				{
+					"id": 1,
+					"event_id": 5,
+					"event": "transaction.created",
+					"attempt": 1,
+					"status": "failed",
+					"response_code": 503,
+					"error": "unexpected status 503 Service Unavailable",
+					"scheduled_at": "2026-01-02T03:04:05Z",
+					"attempted_at": "2026-01-02T03:04:05Z"
+				}
+			]`, rr.Body.String())
+			m.AssertExpectations(t)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func TestAPI_Routes_webhook(t *testing.T) {
+	tests := map[string]func(t *testing.T){
+		"when webhooks are disabled": func(t *testing.T) {
+			// arrange
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/v1/webhook", nil)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		},
+		"when webhooks are enabled": func(t *testing.T) {
+			// arrange
+			m := new(mockWebhookManager)
+			m.On("Subscriptions").Return([]webhook.Subscription{}, nil)
+			api := NewAPI(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+			api.Webhooks = m
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/v1/webhook", nil)
+
+			// act
+			api.Routes().ServeHTTP(rr, r)
+
+			// assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			m.AssertExpectations(t)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+type mockWebhookManager struct {
+	mock.Mock
+}
+
+func (m *mockWebhookManager) Subscribe(_ context.Context, sub webhook.Subscription) (webhook.Subscription, error) {
+	args := m.Called(sub)
+	return args.Get(0).(webhook.Subscription), args.Error(1)
+}
+
+func (m *mockWebhookManager) Subscriptions(_ context.Context) ([]webhook.Subscription, error) {
+	args := m.Called()
+	return args.Get(0).([]webhook.Subscription), args.Error(1)
+}
+
+func (m *mockWebhookManager) Unsubscribe(_ context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *mockWebhookManager) Deliveries(_ context.Context, subscriptionID int) ([]webhook.Delivery, error) {
+	args := m.Called(subscriptionID)
+	return args.Get(0).([]webhook.Delivery), args.Error(1)
+}
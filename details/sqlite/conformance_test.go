@@ -0,0 +1,24 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/gritt/maskada/details/sqlstore"
+	"github.com/gritt/maskada/details/sqlstore/sqlstoretest"
+)
+
+func TestConformance(t *testing.T) {
+	sqlstoretest.Run(t, func(t *testing.T, eventSourced bool) *sqlstore.Repository {
+		cfg := mockDBConfig(t)
+		cfg.Storage.EventSourced = eventSourced
+
+		r, err := NewRepository(cfg)
+		if err != nil {
+			t.Fatalf("NewRepository failed: %s", err)
+		}
+		migrateDB(t, r.DB())
+		t.Cleanup(func() { _ = r.DB().Close() })
+
+		return r
+	})
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/outbox"
+	"github.com/gritt/maskada/details/sqlstore"
+	"github.com/gritt/maskada/details/webhook"
+)
+
+func TestEventStore_Purge(t *testing.T) {
+	// arrange
+	r, err := NewRepository(mockDBConfig(t))
+	if err != nil {
+		t.Fatalf("NewRepository failed: %s", err)
+	}
+	migrateDB(t, r.DB())
+	t.Cleanup(func() { _ = r.DB().Close() })
+
+	ctx := context.Background()
+	s := sqlstore.NewEventStore(r, false)
+	now := time.Now().UTC().Truncate(time.Second)
+	old := now.Add(-48 * time.Hour)
+
+	// dispatched, dispatched with a pending delivery, not dispatched, and recent
+	for _, date := range []time.Time{old, old, old, now} {
+		m := outbox.Message{Event: core.EventTransactionCreated, Date: date, Payload: json.RawMessage(`{}`)}
+		if err := s.Append(ctx, m); err != nil {
+			t.Fatalf("Append failed: %s", err)
+		}
+	}
+	r.DB().MustExec(`UPDATE "outbox" SET "dispatched_at" = ? WHERE "id" <> 3`, now)
+
+	sub, err := sqlstore.NewWebhookStore(r).CreateSubscription(ctx, webhook.Subscription{
+		URL:    "https://example.com/hook",
+		Secret: "secret",
+		Events: []string{core.EventTransactionCreated},
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscription failed: %s", err)
+	}
+	r.DB().MustExec(
+		`INSERT INTO "webhook_delivery" ("subscription_id", "event_id", "event", "status", "scheduled_at") VALUES (?, 2, ?, ?, ?)`,
+		sub.ID, core.EventTransactionCreated, webhook.Pending, now,
+	)
+
+	// act
+	gotErr := s.Purge(ctx, now.Add(-24*time.Hour))
+
+	// assert
+	assert.NoError(t, gotErr)
+
+	var ids []int
+	assert.NoError(t, r.DB().Select(&ids, `SELECT "id" FROM "outbox" ORDER BY "id"`))
+	assert.Equal(t, []int{2, 3, 4}, ids)
+}
@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/sqlstore"
+)
+
+func TestIdempotencyStore_Sweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	expired := func(key string) idempotency.Record {
+		return idempotency.Record{Actor: "alice", Key: key, RequestHash: "hash", ExpiresAt: now.Add(-time.Hour)}
+	}
+
+	tests := map[string]func(*testing.T, *sqlstore.Repository, *sqlstore.IdempotencyStore){
+		"when a key is reserved, leave the other expired keys": func(t *testing.T, r *sqlstore.Repository, s *sqlstore.IdempotencyStore) {
+			// arrange
+			_, _, _ = s.Reserve(ctx, expired("first"))
+
+			// act
+			_, gotReserved, gotErr := s.Reserve(ctx, expired("second"))
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.True(t, gotReserved)
+
+			var count int
+			assert.NoError(t, r.DB().Get(&count, `SELECT COUNT(*) FROM "idempotency_key"`))
+			assert.Equal(t, 2, count)
+		},
+		"when swept, remove the expired keys": func(t *testing.T, r *sqlstore.Repository, s *sqlstore.IdempotencyStore) {
+			// arrange
+			_, _, _ = s.Reserve(ctx, expired("first"))
+			_, _, _ = s.Reserve(ctx, idempotency.Record{Actor: "alice", Key: "second", RequestHash: "hash", ExpiresAt: now.Add(time.Hour)})
+
+			// act
+			gotErr := s.Sweep(ctx, now)
+
+			// assert
+			assert.NoError(t, gotErr)
+
+			var keys []string
+			assert.NoError(t, r.DB().Select(&keys, `SELECT "key" FROM "idempotency_key"`))
+			assert.Equal(t, []string{"second"}, keys)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewRepository(mockDBConfig(t))
+			if err != nil {
+				t.Fatalf("NewRepository failed: %s", err)
+			}
+			migrateDB(t, r.DB())
+			defer r.DB().Close()
+
+			run(t, r, sqlstore.NewIdempotencyStore(r))
+		})
+	}
+}
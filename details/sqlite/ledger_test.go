@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/sqlstore"
+)
+
+func TestRepository_CatchUp(t *testing.T) {
+	ctx := context.Background()
+	lunch := core.Transaction{Amount: 100, Type: core.Debit, Category: core.Category{Name: "Food"}}
+
+	tests := map[string]func(t *testing.T, state, events *sqlstore.Repository){
+		"when the transactions have no event, append their creation": func(t *testing.T, state, events *sqlstore.Repository) {
+			// arrange
+			created, err := state.Create(ctx, lunch, "alice")
+			if err != nil {
+				t.Fatalf("Create failed: %s", err)
+			}
+
+			// act
+			gotErr := events.CatchUp(ctx)
+
+			// assert
+			assert.NoError(t, gotErr)
+
+			got, err := events.FindAsOf(ctx, time.Now().Add(time.Hour))
+			assert.NoError(t, err)
+			assert.Equal(t, []core.Transaction{created}, got)
+		},
+		"when the transactions changed without events, append their changes": func(t *testing.T, state, events *sqlstore.Repository) {
+			// arrange
+			changed, err := events.Create(ctx, lunch, "alice")
+			if err != nil {
+				t.Fatalf("Create failed: %s", err)
+			}
+			removed, err := events.Create(ctx, lunch, "alice")
+			if err != nil {
+				t.Fatalf("Create failed: %s", err)
+			}
+
+			changed.Amount = 200
+			if changed, err = state.Update(ctx, changed, "alice"); err != nil {
+				t.Fatalf("Update failed: %s", err)
+			}
+			if _, err := state.Delete(ctx, removed.ID, removed.Version, "alice"); err != nil {
+				t.Fatalf("Delete failed: %s", err)
+			}
+
+			// act
+			gotErr := events.CatchUp(ctx)
+
+			// assert
+			assert.NoError(t, gotErr)
+
+			got, err := events.FindAsOf(ctx, time.Now().Add(time.Hour))
+			assert.NoError(t, err)
+			assert.Equal(t, []core.Transaction{changed}, got)
+		},
+		"when the event store is up to date, append nothing": func(t *testing.T, state, events *sqlstore.Repository) {
+			// arrange
+			if _, err := events.Create(ctx, lunch, "alice"); err != nil {
+				t.Fatalf("Create failed: %s", err)
+			}
+
+			// act
+			gotErr := events.CatchUp(ctx)
+
+			// assert
+			assert.NoError(t, gotErr)
+
+			var count int
+			assert.NoError(t, events.DB().Get(&count, `SELECT COUNT(*) FROM "transaction_event"`))
+			assert.Equal(t, 1, count)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			state, err := NewRepository(mockDBConfig(t))
+			if err != nil {
+				t.Fatalf("NewRepository failed: %s", err)
+			}
+			migrateDB(t, state.DB())
+			defer state.DB().Close()
+
+			run(t, state, sqlstore.NewEventSourcedRepository(state.DB(), dialect))
+		})
+	}
+}
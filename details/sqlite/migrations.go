@@ -0,0 +1,26 @@
+package sqlite
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/details/migrate"
+	"github.com/gritt/maskada/details/sqlstore"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// NewMigrator initialize the migrator of the file schema, sharing the repository connection,
+// it does not lock, as each migration runs in a transaction locking the whole file as it begins,
+// which checks again the migration is pending, or applied to revert it.
+func NewMigrator(r *sqlstore.Repository) (*migrate.Migrator, error) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, errors.Wrap(err, "NewMigrator failed")
+	}
+
+	return migrate.NewMigrator(r.DB(), fsys, migrate.NoLock{})
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "transaction";
+DROP TABLE IF EXISTS "category";
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS "category"
+(
+    "name" VARCHAR(80) NOT NULL PRIMARY KEY CHECK (length("name") <= 80)
+);
+
+CREATE TABLE IF NOT EXISTS "transaction"
+(
+    "id"          INTEGER     NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "amount"      INTEGER     NOT NULL DEFAULT 0,
+    "type"        INTEGER     NOT NULL,
+    "category"    VARCHAR(80) NOT NULL
+        CONSTRAINT "fk_category"
+            REFERENCES "category" ("name")
+            ON DELETE RESTRICT
+            ON UPDATE CASCADE,
+    "description" VARCHAR(80) NULL CHECK (length("description") <= 80),
+    "date"        TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
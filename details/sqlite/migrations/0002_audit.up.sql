@@ -0,0 +1,27 @@
+CREATE TABLE "audit"
+(
+    "id"        INTEGER     NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "entity"    VARCHAR(20) NOT NULL,
+    "entity_id" VARCHAR(80) NOT NULL,
+    "action"    VARCHAR(20) NOT NULL,
+    "actor"     VARCHAR(80) NOT NULL,
+    "before"    TEXT        NULL,
+    "after"     TEXT        NULL,
+    "date"      TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "idx_audit_entity" ON "audit" ("entity", "entity_id");
+
+CREATE TRIGGER "audit_append_only_update"
+    BEFORE UPDATE
+    ON "audit"
+BEGIN
+    SELECT RAISE(ABORT, 'audit is append-only');
+END;
+
+CREATE TRIGGER "audit_append_only_delete"
+    BEFORE DELETE
+    ON "audit"
+BEGIN
+    SELECT RAISE(ABORT, 'audit is append-only');
+END;
@@ -0,0 +1,10 @@
+CREATE TABLE "idempotency_key"
+(
+    "key"          VARCHAR(255) NOT NULL PRIMARY KEY,
+    "request_hash" CHAR(64)     NOT NULL,
+    "status"       INTEGER      NOT NULL DEFAULT 0,
+    "body"         BLOB         NULL,
+    "expires_at"   TIMESTAMP    NOT NULL
+);
+
+CREATE INDEX "idx_idempotency_key_expires_at" ON "idempotency_key" ("expires_at");
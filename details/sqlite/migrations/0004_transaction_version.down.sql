@@ -0,0 +1,2 @@
+ALTER TABLE "transaction"
+    DROP COLUMN "version";
@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS "outbox"
+(
+    "id"            INTEGER     NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "event"         VARCHAR(40) NOT NULL,
+    "payload"       TEXT        NOT NULL,
+    "date"          TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "dispatched_at" TIMESTAMP   NULL,
+    "relayed_at"    TIMESTAMP   NULL
+);
+
+CREATE INDEX IF NOT EXISTS "idx_outbox_dispatched_at" ON "outbox" ("dispatched_at", "id");
+CREATE INDEX IF NOT EXISTS "idx_outbox_relayed_at" ON "outbox" ("relayed_at", "id");
+
+CREATE TABLE IF NOT EXISTS "webhook_subscription"
+(
+    "id"     INTEGER       NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "url"    VARCHAR(2048) NOT NULL,
+    "secret" VARCHAR(255)  NOT NULL,
+    "events" VARCHAR(255)  NOT NULL,
+    "date"   TIMESTAMP     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS "webhook_delivery"
+(
+    "id"              INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "subscription_id" INTEGER      NOT NULL
+        CONSTRAINT "fk_webhook_delivery_subscription"
+            REFERENCES "webhook_subscription" ("id")
+            ON DELETE CASCADE,
+    "event_id"        INTEGER      NOT NULL
+        CONSTRAINT "fk_webhook_delivery_event"
+            REFERENCES "outbox" ("id")
+            ON DELETE CASCADE,
+    "event"           VARCHAR(40)  NOT NULL,
+    "attempt"         INTEGER      NOT NULL DEFAULT 1,
+    "status"          VARCHAR(20)  NOT NULL,
+    "response_code"   INTEGER      NOT NULL DEFAULT 0,
+    "error"           VARCHAR(255) NOT NULL DEFAULT '',
+    "scheduled_at"    TIMESTAMP    NOT NULL,
+    "attempted_at"    TIMESTAMP    NULL,
+    "lease_until"     TIMESTAMP    NULL
+);
+
+CREATE INDEX IF NOT EXISTS "idx_webhook_delivery_due" ON "webhook_delivery" ("status", "scheduled_at");
+CREATE INDEX IF NOT EXISTS "idx_webhook_delivery_lease" ON "webhook_delivery" ("status", "lease_until");
+CREATE INDEX IF NOT EXISTS "idx_webhook_delivery_subscription" ON "webhook_delivery" ("subscription_id", "id");
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS "journal"
+(
+    "id"        INTEGER     NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "actor"     VARCHAR(80) NOT NULL,
+    "operation" VARCHAR(20) NOT NULL,
+    "changes"   TEXT        NOT NULL,
+    "undone"    BOOLEAN     NOT NULL DEFAULT FALSE,
+    "date"      TIMESTAMP   NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS "idx_journal_actor" ON "journal" ("actor", "id");
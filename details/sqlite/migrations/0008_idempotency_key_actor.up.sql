@@ -0,0 +1,21 @@
+CREATE TABLE "idempotency_key_actor"
+(
+    "actor"        VARCHAR(80)  NOT NULL DEFAULT '',
+    "key"          VARCHAR(255) NOT NULL,
+    "request_hash" CHAR(64)     NOT NULL,
+    "status"       INTEGER      NOT NULL DEFAULT 0,
+    "body"         BLOB         NULL,
+    "expires_at"   TIMESTAMP    NOT NULL,
+    PRIMARY KEY ("actor", "key")
+);
+
+INSERT INTO "idempotency_key_actor" ("key", "request_hash", "status", "body", "expires_at")
+SELECT "key", "request_hash", "status", "body", "expires_at"
+FROM "idempotency_key";
+
+DROP TABLE "idempotency_key";
+
+ALTER TABLE "idempotency_key_actor"
+    RENAME TO "idempotency_key";
+
+CREATE INDEX "idx_idempotency_key_expires_at" ON "idempotency_key" ("expires_at");
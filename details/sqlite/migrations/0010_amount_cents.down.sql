@@ -0,0 +1,30 @@
+-- the amounts are back in whole units, eg: 2650 is 26, losing the cents
+INSERT INTO "audit" ("entity", "entity_id", "action", "actor", "before", "after")
+SELECT 'transaction',
+       CAST(t."id" AS TEXT),
+       'update',
+       'migration',
+       json_object(
+               'id', t."id",
+               'amount', t."amount",
+               'type', t."type",
+               'category', t."category",
+               'date', strftime('%Y-%m-%dT%H:%M:%fZ', t."date"),
+               'name', COALESCE(t."description", ''),
+               'version', t."version"
+       ),
+       json_object(
+               'id', t."id",
+               'amount', t."amount" / 100,
+               'type', t."type",
+               'category', t."category",
+               'date', strftime('%Y-%m-%dT%H:%M:%fZ', t."date"),
+               'name', COALESCE(t."description", ''),
+               'version', t."version" + 1
+       )
+FROM "transaction" t
+ORDER BY t."id";
+
+UPDATE "transaction"
+SET "amount"  = "amount" / 100,
+    "version" = "version" + 1;
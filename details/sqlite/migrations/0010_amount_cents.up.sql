@@ -0,0 +1,31 @@
+-- the amounts are in cents from now on, eg: 26 is 2600, each transaction being updated by the actor migration,
+-- so the older versions are not undone, and the event store catches up on start
+INSERT INTO "audit" ("entity", "entity_id", "action", "actor", "before", "after")
+SELECT 'transaction',
+       CAST(t."id" AS TEXT),
+       'update',
+       'migration',
+       json_object(
+               'id', t."id",
+               'amount', t."amount",
+               'type', t."type",
+               'category', t."category",
+               'date', strftime('%Y-%m-%dT%H:%M:%fZ', t."date"),
+               'name', COALESCE(t."description", ''),
+               'version', t."version"
+       ),
+       json_object(
+               'id', t."id",
+               'amount', t."amount" * 100,
+               'type', t."type",
+               'category', t."category",
+               'date', strftime('%Y-%m-%dT%H:%M:%fZ', t."date"),
+               'name', COALESCE(t."description", ''),
+               'version', t."version" + 1
+       )
+FROM "transaction" t
+ORDER BY t."id";
+
+UPDATE "transaction"
+SET "amount"  = "amount" * 100,
+    "version" = "version" + 1;
@@ -0,0 +1,35 @@
+package sqlite
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	// imports sqlite db driver
+	_ "modernc.org/sqlite"
+
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/sqlstore"
+)
+
+// dialect writes the statements as SQLite does, which locks the whole file as a write transaction begins
+// immediately, and stores the dates as given.
+var dialect = sqlstore.Dialect{
+	Driver: "sqlite",
+	System: "sqlite",
+	Quote:  `"`,
+	IDs:    sqlstore.LastInsertID,
+}
+
+// NewRepository initialize the repository of a SQLite file, the file schema is created by its Migrator.
+func NewRepository(cfg *details.Config) (*sqlstore.Repository, error) {
+	db, err := sqlx.Open("sqlite", cfg.SQLiteDNS())
+	if err != nil {
+		return nil, errors.Wrap(err, "NewRepository failed")
+	}
+
+	if cfg.Storage.EventSourced {
+		return sqlstore.NewEventSourcedRepository(db, dialect), nil
+	}
+
+	return sqlstore.NewRepository(db, dialect), nil
+}
@@ -0,0 +1,138 @@
+package sqlite
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/sqlstore"
+)
+
+func TestNewRepository(t *testing.T) {
+	// arrange
+	cfg := mockDBConfig(t)
+
+	// act
+	gotRepo, gotErr := NewRepository(cfg)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.IsType(t, &sqlx.DB{}, gotRepo.DB())
+}
+
+func TestRepository_Close(t *testing.T) {
+	// arrange
+	r, err := NewRepository(mockDBConfig(t))
+	if err != nil {
+		t.Fatalf("NewRepository failed: %s", err)
+	}
+	migrateDB(t, r.DB())
+
+	// act
+	gotErr := r.Close()
+
+	// assert
+	assert.NoError(t, gotErr)
+
+	_, err = r.Find(context.Background())
+	assert.EqualError(t, err, "Repository.Find failed: sql: database is closed")
+}
+
+func TestRepository_Find(t *testing.T) {
+	tests := map[string]func(t *testing.T, r *sqlstore.Repository){
+		"when the context is canceled": func(t *testing.T, r *sqlstore.Repository) {
+			// arrange
+			teardown := setupDBData(t, r.DB())
+			defer teardown()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			// act
+			_, gotErr := r.Find(ctx)
+
+			// assert
+			assert.Equal(t, context.Canceled, errors.Cause(gotErr))
+		},
+		"when the query outlasts the deadline": func(t *testing.T, r *sqlstore.Repository) {
+			// arrange
+			teardown := setupDBData(t, r.DB())
+			defer teardown()
+
+			// every transaction is read along with a count taking seconds
+			_, err := r.DB().Exec(`
+				ALTER TABLE "transaction" RENAME TO "transaction_data";
+				CREATE VIEW "transaction" AS SELECT t.* FROM "transaction_data" t, (
+					WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 100000000) SELECT count(*) FROM n
+				)`)
+			if err != nil {
+				t.Fatalf("when the query outlasts the deadline failed: %s", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+
+			// act
+			_, gotErr := r.Find(ctx)
+
+			// assert
+			assert.Equal(t, context.DeadlineExceeded, errors.Cause(gotErr))
+			assert.True(t, time.Since(start) < time.Second, "the query was not aborted")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewRepository(mockDBConfig(t))
+			assert.NoError(t, err)
+
+			run(t, r)
+		})
+	}
+}
+
+func mockDBConfig(t *testing.T) *details.Config {
+	cfg := &details.Config{}
+	cfg.SQLite.Path = filepath.Join(t.TempDir(), "maskada.db")
+	return cfg
+}
+
+func setupDBData(t *testing.T, db *sqlx.DB) func() {
+	// create an empty file schema
+	migrateDB(t, db)
+
+	// setup data
+	script, err := ioutil.ReadFile("test/setup.sql")
+	if err != nil {
+		t.Fatalf("setupDBData failed: %s", err)
+	}
+	_, err = db.Exec(string(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// teardown data, along with the file
+	return func() {
+		db.Close()
+	}
+}
+
+// migrateDB applies all migrations to the new file.
+func migrateDB(t *testing.T, db *sqlx.DB) {
+	m, err := NewMigrator(sqlstore.NewRepository(db, dialect))
+	if err != nil {
+		t.Fatalf("migrateDB failed: %s", err)
+	}
+
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("migrateDB failed: %s", err)
+	}
+}
@@ -0,0 +1,98 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+)
+
+// audit appends entries to the audit log, within the transaction of the changes they record.
+func (r *Repository) audit(ctx context.Context, tx *sqlx.Tx, entries ...core.AuditEntry) error {
+	query := `INSERT INTO "audit" ("entity", "entity_id", "action", "actor", "before", "after", "date") VALUES ` +
+		values(len(entries), 7)
+
+	now := time.Now().UTC()
+
+	args := make([]interface{}, 0, len(entries)*7)
+	for _, entry := range entries {
+		args = append(
+			args,
+			entry.Entity,
+			entry.EntityID,
+			entry.Action,
+			entry.Actor,
+			nullableJSON(entry.Before),
+			nullableJSON(entry.After),
+			now,
+		)
+	}
+
+	if _, err := r.statements.Exec(ctx, tx, query, args...); err != nil {
+		return errors.Wrap(err, "Repository.audit failed")
+	}
+
+	return nil
+}
+
+// snapshot is how a transaction is recorded in the audit log.
+type snapshot struct {
+	ID       int       `json:"id"`
+	Amount   int       `json:"amount"`
+	Type     int       `json:"type"`
+	Category string    `json:"category"`
+	Date     time.Time `json:"date"`
+	Name     string    `json:"name"`
+	Version  int       `json:"version"`
+}
+
+func transactionSnapshot(t core.Transaction) json.RawMessage {
+	encoded, _ := json.Marshal(snapshot{
+		ID:       t.ID,
+		Amount:   t.Amount,
+		Type:     t.Type,
+		Category: t.Category.Name,
+		Date:     t.Date.UTC(),
+		Name:     t.Name,
+		Version:  t.Version,
+	})
+	return encoded
+}
+
+// transactionFromSnapshot decodes a transaction as recorded in the audit log.
+func transactionFromSnapshot(encoded []byte) (core.Transaction, error) {
+	var s snapshot
+	if err := json.Unmarshal(encoded, &s); err != nil {
+		return core.Transaction{}, err
+	}
+
+	return core.Transaction{
+		ID:       s.ID,
+		Amount:   s.Amount,
+		Type:     s.Type,
+		Category: core.Category{Name: s.Category},
+		Date:     s.Date.UTC(),
+		Name:     s.Name,
+		Version:  s.Version,
+	}, nil
+}
+
+func categorySnapshot(c core.Category) json.RawMessage {
+	snapshot, _ := json.Marshal(struct {
+		Name string `json:"name"`
+	}{
+		Name: c.Name,
+	})
+	return snapshot
+}
+
+func nullableJSON(snapshot json.RawMessage) interface{} {
+	if len(snapshot) == 0 {
+		return nil
+	}
+	return string(snapshot)
+}
@@ -0,0 +1,171 @@
+// Package sqlstore persists the transactions, along with their audit log, event store, outbox, journal and stores, in a
+// SQL database, writing each statement once with "quoted" identifiers and ? placeholders, as a Dialect rewrites it.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/gritt/maskada/details/tracing"
+)
+
+// IDs tells how a database returns the ids of the rows inserted by a statement.
+type IDs int
+
+const (
+	// Returning returns the ids of the inserted rows in order, with a RETURNING clause.
+	Returning IDs = iota
+	// RowInsertID returns the id of a single inserted row, the ids of a multi-row insert not being consecutive, eg:
+	// given an auto_increment_increment or the interleaved lock mode of MySQL, so the rows are inserted one at a time.
+	RowInsertID
+	// LastInsertID returns the id of the last inserted row, the preceding ones being consecutive.
+	LastInsertID
+)
+
+// Dialect is what differs in the statements of a database system.
+type Dialect struct {
+	// Driver is the name of the database/sql driver, which tells the placeholders, eg: $1 for postgres.
+	Driver string
+	// System names the database system in the traces, eg: postgresql.
+	System string
+	// Quote quotes the identifiers, eg: a backtick for MySQL.
+	Quote string
+	// IDs tells how the ids of the inserted rows are returned.
+	IDs IDs
+	// InsertIgnore ignores a conflicting insert with INSERT IGNORE, rather than ON CONFLICT DO NOTHING.
+	InsertIgnore bool
+	// RowLocks locks the selected rows until the transaction ends with FOR UPDATE, rather than relying on the
+	// transaction holding the write lock of the whole database as it begins.
+	RowLocks bool
+	// Precision is the precision of the stored dates, eg: time.Second, when they are not stored as given.
+	Precision time.Duration
+}
+
+// statements runs the statements written once on the database, as its dialect writes them, tracing them.
+type statements struct {
+	Dialect
+	traced tracing.Statements
+}
+
+func newStatements(d Dialect) statements {
+	return statements{Dialect: d, traced: tracing.NewStatements(d.System)}
+}
+
+// rewrite writes a query as the dialect does, quoting its identifiers and numbering its placeholders.
+func (s statements) rewrite(query string) string {
+	if s.Quote != `"` {
+		query = strings.ReplaceAll(query, `"`, s.Quote)
+	}
+	return sqlx.Rebind(sqlx.BindType(s.Driver), query)
+}
+
+// Exec executes a statement with e, eg: a *sqlx.Tx.
+func (s statements) Exec(ctx context.Context, e sqlx.ExecerContext, query string, args ...interface{}) (sql.Result, error) {
+	return s.traced.Exec(ctx, e, s.rewrite(query), args...)
+}
+
+// Get queries a single row with q, scanning it into dest.
+func (s statements) Get(ctx context.Context, q sqlx.QueryerContext, dest interface{}, query string, args ...interface{}) error {
+	return s.traced.Get(ctx, q, dest, s.rewrite(query), args...)
+}
+
+// Select queries rows with q, scanning them into dest.
+func (s statements) Select(ctx context.Context, q sqlx.QueryerContext, dest interface{}, query string, args ...interface{}) error {
+	return s.traced.Select(ctx, q, dest, s.rewrite(query), args...)
+}
+
+// insert executes an insert of the rows of args, of columns values each, with q, returning their ids in order, the
+// query ending with VALUES, eg: INSERT INTO "category" ("name") VALUES.
+func (s statements) insert(ctx context.Context, q sqlx.ExtContext, columns int, query string, args ...interface{}) ([]int, error) {
+	n := len(args) / columns
+
+	switch s.IDs {
+	case Returning:
+		var ids []int
+		if err := s.Select(ctx, q, &ids, query+" "+values(n, columns)+` RETURNING "id"`, args...); err != nil {
+			return nil, err
+		}
+		return ids, nil
+	case RowInsertID:
+		ids := make([]int, 0, n)
+		for i := 0; i < n; i++ {
+			id, err := s.insertID(ctx, q, query+" "+values(1, columns), args[i*columns:(i+1)*columns]...)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	}
+
+	// writes are serialized, so a multi-row insert gets consecutive ids
+	last, err := s.insertID(ctx, q, query+" "+values(n, columns), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		ids = append(ids, last-n+1+i)
+	}
+	return ids, nil
+}
+
+// insertID executes an insert with q, returning the id of the last inserted row.
+func (s statements) insertID(ctx context.Context, q sqlx.ExtContext, query string, args ...interface{}) (int, error) {
+	result, err := s.Exec(ctx, q, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// ignore makes an insert ignore the rows conflicting on the key columns.
+func (s statements) ignore(query string, key ...string) string {
+	if s.InsertIgnore {
+		return strings.Replace(query, "INSERT INTO", "INSERT IGNORE INTO", 1)
+	}
+	return query + ` ON CONFLICT ("` + strings.Join(key, `", "`) + `") DO NOTHING`
+}
+
+// forUpdate makes a query lock the selected rows until the transaction ends, when the dialect locks rows.
+func (s statements) forUpdate(query string) string {
+	if !s.RowLocks {
+		return query
+	}
+	return query + `
+				FOR UPDATE`
+}
+
+// skipLocked makes a query lock the selected rows until the transaction ends, skipping the rows locked already, when
+// the dialect locks rows.
+func (s statements) skipLocked(query string) string {
+	if !s.RowLocks {
+		return query
+	}
+	return query + `
+				FOR UPDATE SKIP LOCKED`
+}
+
+// round rounds a date to the precision it is stored with.
+func (s statements) round(t time.Time) time.Time {
+	if s.Precision == 0 {
+		return t
+	}
+	return t.Round(s.Precision)
+}
+
+// values builds the placeholders of a multi-row insert, eg: (?, ?), (?, ?).
+func values(rows, columns int) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", columns), ", ") + ")"
+	return strings.TrimSuffix(strings.Repeat(row+", ", rows), ", ")
+}
@@ -0,0 +1,212 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	// imports sqlite db driver
+	_ "modernc.org/sqlite"
+)
+
+var (
+	mysql    = Dialect{Driver: "mysql", Quote: "`", IDs: RowInsertID, InsertIgnore: true, RowLocks: true, Precision: time.Second}
+	postgres = Dialect{Driver: "postgres", Quote: `"`, IDs: Returning}
+	sqlite   = Dialect{Driver: "sqlite", Quote: `"`, IDs: LastInsertID}
+)
+
+func TestStatements_rewrite(t *testing.T) {
+	query := `SELECT "id" FROM "transaction" WHERE "category" = ? AND "type" = ?`
+
+	tests := map[string]struct {
+		dialect   Dialect
+		wantQuery string
+	}{
+		"when mysql, quote with backticks": {
+			dialect:   mysql,
+			wantQuery: "SELECT `id` FROM `transaction` WHERE `category` = ? AND `type` = ?",
+		},
+		"when postgres, number the placeholders": {
+			dialect:   postgres,
+			wantQuery: `SELECT "id" FROM "transaction" WHERE "category" = $1 AND "type" = $2`,
+		},
+		"when sqlite, keep the query": {
+			dialect:   sqlite,
+			wantQuery: query,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			gotQuery := newStatements(tt.dialect).rewrite(query)
+
+			// assert
+			assert.Equal(t, tt.wantQuery, gotQuery)
+		})
+	}
+}
+
+func TestStatements_ignore(t *testing.T) {
+	query := `INSERT INTO "category" ("name") VALUES (?)`
+
+	tests := map[string]struct {
+		dialect   Dialect
+		wantQuery string
+	}{
+		"when the dialect inserts ignoring": {
+			dialect:   mysql,
+			wantQuery: `INSERT IGNORE INTO "category" ("name") VALUES (?)`,
+		},
+		"when the dialect inserts on conflict": {
+			dialect:   postgres,
+			wantQuery: `INSERT INTO "category" ("name") VALUES (?) ON CONFLICT ("actor", "key") DO NOTHING`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			gotQuery := newStatements(tt.dialect).ignore(query, "actor", "key")
+
+			// assert
+			assert.Equal(t, tt.wantQuery, gotQuery)
+		})
+	}
+}
+
+func TestStatements_locks(t *testing.T) {
+	query := `SELECT "id" FROM "outbox"`
+
+	tests := map[string]struct {
+		dialect        Dialect
+		wantForUpdate  string
+		wantSkipLocked string
+	}{
+		"when the dialect locks rows": {
+			dialect:        mysql,
+			wantForUpdate:  query + "\n\t\t\t\tFOR UPDATE",
+			wantSkipLocked: query + "\n\t\t\t\tFOR UPDATE SKIP LOCKED",
+		},
+		"when the dialect locks the database": {
+			dialect:        sqlite,
+			wantForUpdate:  query,
+			wantSkipLocked: query,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			s := newStatements(tt.dialect)
+
+			// act
+			gotForUpdate := s.forUpdate(query)
+			gotSkipLocked := s.skipLocked(query)
+
+			// assert
+			assert.Equal(t, tt.wantForUpdate, gotForUpdate)
+			assert.Equal(t, tt.wantSkipLocked, gotSkipLocked)
+		})
+	}
+}
+
+func TestStatements_round(t *testing.T) {
+	date := time.Date(2024, 3, 1, 12, 0, 0, 600*int(time.Millisecond), time.UTC)
+
+	tests := map[string]struct {
+		dialect  Dialect
+		wantDate time.Time
+	}{
+		"when the dialect stores seconds, round to the second": {
+			dialect:  mysql,
+			wantDate: time.Date(2024, 3, 1, 12, 0, 1, 0, time.UTC),
+		},
+		"when the dialect stores the dates as given, keep them": {
+			dialect:  sqlite,
+			wantDate: date,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			gotDate := newStatements(tt.dialect).round(date)
+
+			// assert
+			assert.Equal(t, tt.wantDate, gotDate)
+		})
+	}
+}
+
+func TestValues(t *testing.T) {
+	assert.Equal(t, "(?)", values(1, 1))
+	assert.Equal(t, "(?, ?, ?), (?, ?, ?)", values(2, 3))
+}
+
+func TestStatements_insert(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]IDs{
+		"when the ids are returned":                 Returning,
+		"when the rows are inserted one at a time":  RowInsertID,
+		"when the ids precede the last inserted id": LastInsertID,
+	}
+
+	for name, ids := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			db := sqlx.MustOpen("sqlite", ":memory:")
+			db.SetMaxOpenConns(1)
+			t.Cleanup(func() { _ = db.Close() })
+			db.MustExec(`CREATE TABLE "item" ("id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, "name" TEXT NOT NULL, "rank" INTEGER NOT NULL)`)
+			db.MustExec(`INSERT INTO "item" ("name", "rank") VALUES ('first', 0)`)
+
+			d := sqlite
+			d.IDs = ids
+			s := newStatements(d)
+
+			// act
+			gotIDs, gotErr := s.insert(ctx, db, 2, `INSERT INTO "item" ("name", "rank") VALUES`, "second", 1, "third", 2, "fourth", 3)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []int{2, 3, 4}, gotIDs)
+
+			var names []string
+			assert.NoError(t, db.Select(&names, `SELECT "name" FROM "item" WHERE "id" IN (?, ?, ?) ORDER BY "id"`, gotIDs[0], gotIDs[1], gotIDs[2]))
+			assert.Equal(t, []string{"second", "third", "fourth"}, names)
+		})
+	}
+}
+
+func TestStatements_insert_failure(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]IDs{
+		"when the ids are returned":                 Returning,
+		"when the rows are inserted one at a time":  RowInsertID,
+		"when the ids precede the last inserted id": LastInsertID,
+	}
+
+	for name, ids := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			db := sqlx.MustOpen("sqlite", ":memory:")
+			t.Cleanup(func() { _ = db.Close() })
+
+			d := sqlite
+			d.IDs = ids
+
+			// act
+			gotIDs, gotErr := newStatements(d).insert(ctx, db, 1, `INSERT INTO "missing" ("name") VALUES`, "first")
+
+			// assert
+			assert.Error(t, gotErr)
+			assert.Nil(t, gotIDs)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/details/outbox"
+	"github.com/gritt/maskada/details/webhook"
+)
+
+// EventStore is able to append the events to the outbox, to read them until they are relayed, and to purge them.
+type EventStore struct {
+	db         *sqlx.DB
+	statements statements
+	relay      bool
+}
+
+// NewEventStore initialize the store, sharing the repository connection, relay telling whether the events are relayed.
+func NewEventStore(r *Repository, relay bool) *EventStore {
+	return &EventStore{db: r.db, statements: r.statements, relay: relay}
+}
+
+// Append appends messages to the outbox in db, in order, as relayed at their date unless the store relays them.
+func (s *EventStore) Append(ctx context.Context, messages ...outbox.Message) error {
+	query := `INSERT INTO "outbox" ("event", "payload", "date", "relayed_at") VALUES ` +
+		values(len(messages), 4)
+
+	args := make([]interface{}, 0, len(messages)*4)
+	for _, m := range messages {
+		var relayedAt *time.Time
+		if !s.relay {
+			date := m.Date.UTC()
+			relayedAt = &date
+		}
+		args = append(args, m.Event, string(m.Payload), m.Date.UTC(), relayedAt)
+	}
+
+	if _, err := s.statements.Exec(ctx, s.db, query, args...); err != nil {
+		return errors.Wrap(err, "EventStore.Append failed")
+	}
+
+	return nil
+}
+
+type messageRow struct {
+	ID      int       `db:"id"`
+	Event   string    `db:"event"`
+	Payload string    `db:"payload"`
+	Date    time.Time `db:"date"`
+}
+
+// FindPending finds up to limit events of the outbox in db yet to be relayed, oldest first.
+func (s *EventStore) FindPending(ctx context.Context, limit int) ([]outbox.Message, error) {
+	query := `SELECT o.id "id", o.event "event", o.payload "payload", o.date "date"
+				FROM outbox o
+				WHERE o.relayed_at IS NULL
+				ORDER BY o.id
+				LIMIT ?`
+
+	var rows []messageRow
+	if err := s.statements.Select(ctx, s.db, &rows, query, limit); err != nil {
+		return []outbox.Message{}, errors.Wrap(err, "EventStore.FindPending failed")
+	}
+
+	messages := make([]outbox.Message, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, outbox.Message{
+			ID:      row.ID,
+			Event:   row.Event,
+			Date:    row.Date.UTC(),
+			Payload: json.RawMessage(row.Payload),
+		})
+	}
+
+	return messages, nil
+}
+
+// MarkRelayed marks the events of the outbox in db relayed at now.
+func (s *EventStore) MarkRelayed(ctx context.Context, ids []int, now time.Time) error {
+	query, args, err := sqlx.In(`UPDATE "outbox" SET "relayed_at" = ? WHERE "id" IN (?)`, now, ids)
+	if err != nil {
+		return errors.Wrap(err, "EventStore.MarkRelayed failed")
+	}
+
+	if _, err := s.statements.Exec(ctx, s.db, query, args...); err != nil {
+		return errors.Wrap(err, "EventStore.MarkRelayed failed")
+	}
+
+	return nil
+}
+
+// Purge removes the events of the outbox in db appended before, once relayed and dispatched, along with their
+// deliveries, unless one is pending or being sent.
+func (s *EventStore) Purge(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM "outbox"
+				WHERE "date" < ? AND "relayed_at" IS NOT NULL AND "dispatched_at" IS NOT NULL
+				AND NOT EXISTS (SELECT 1 FROM "webhook_delivery" d WHERE d.event_id = "outbox"."id" AND d.status IN (?, ?))`
+
+	if _, err := s.statements.Exec(ctx, s.db, query, before.UTC(), webhook.Pending, webhook.Sending); err != nil {
+		return errors.Wrap(err, "EventStore.Purge failed")
+	}
+
+	return nil
+}
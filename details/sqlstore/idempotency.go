@@ -0,0 +1,117 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/details/idempotency"
+)
+
+// IdempotencyStore is able to reserve, complete, release and sweep idempotency keys.
+type IdempotencyStore struct {
+	db         *sqlx.DB
+	statements statements
+}
+
+// NewIdempotencyStore initialize the store, sharing the repository connection.
+func NewIdempotencyStore(r *Repository) *IdempotencyStore {
+	return &IdempotencyStore{db: r.db, statements: r.statements}
+}
+
+// Reserve persists a pending key in db, unless an unexpired one exists, which is returned instead.
+func (s *IdempotencyStore) Reserve(ctx context.Context, rec idempotency.Record) (idempotency.Record, bool, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return idempotency.Record{}, false, errors.Wrap(err, "IdempotencyStore.Reserve failed")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// only this key is removed when expired, the others being left to Sweep
+	expired := `DELETE FROM "idempotency_key" WHERE "actor" = ? AND "key" = ? AND "expires_at" <= ?`
+	if _, err := s.statements.Exec(ctx, tx, expired, rec.Actor, rec.Key, time.Now().UTC()); err != nil {
+		return idempotency.Record{}, false, errors.Wrap(err, "IdempotencyStore.Reserve failed")
+	}
+
+	query := s.statements.ignore(
+		`INSERT INTO "idempotency_key" ("actor", "key", "request_hash", "expires_at") VALUES (?, ?, ?, ?)`,
+		"actor", "key",
+	)
+
+	result, err := s.statements.Exec(ctx, tx, query, rec.Actor, rec.Key, rec.RequestHash, rec.ExpiresAt.UTC())
+	if err != nil {
+		return idempotency.Record{}, false, errors.Wrap(err, "IdempotencyStore.Reserve failed")
+	}
+
+	reserved, err := result.RowsAffected()
+	if err != nil {
+		return idempotency.Record{}, false, errors.Wrap(err, "IdempotencyStore.Reserve failed")
+	}
+
+	if reserved == 0 {
+		type row struct {
+			Actor       string    `db:"actor"`
+			Key         string    `db:"key"`
+			RequestHash string    `db:"request_hash"`
+			Status      int       `db:"status"`
+			Body        []byte    `db:"body"`
+			ExpiresAt   time.Time `db:"expires_at"`
+		}
+
+		query := `SELECT "actor", "key", "request_hash", "status", "body", "expires_at" FROM "idempotency_key"
+					WHERE "actor" = ? AND "key" = ?`
+
+		var existing row
+		if err := s.statements.Get(ctx, tx, &existing, query, rec.Actor, rec.Key); err != nil {
+			return idempotency.Record{}, false, errors.Wrap(err, "IdempotencyStore.Reserve failed")
+		}
+
+		rec = idempotency.Record{
+			Actor:       existing.Actor,
+			Key:         existing.Key,
+			RequestHash: existing.RequestHash,
+			Status:      existing.Status,
+			Body:        existing.Body,
+			ExpiresAt:   existing.ExpiresAt.UTC(),
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return idempotency.Record{}, false, errors.Wrap(err, "IdempotencyStore.Reserve failed")
+	}
+
+	return rec, reserved == 1, nil
+}
+
+// Complete persists the response of a reserved key in db.
+func (s *IdempotencyStore) Complete(ctx context.Context, rec idempotency.Record) error {
+	query := `UPDATE "idempotency_key" SET "status" = ?, "body" = ? WHERE "actor" = ? AND "key" = ?`
+
+	if _, err := s.statements.Exec(ctx, s.db, query, rec.Status, rec.Body, rec.Actor, rec.Key); err != nil {
+		return errors.Wrap(err, "IdempotencyStore.Complete failed")
+	}
+
+	return nil
+}
+
+// Release removes a reserved key from db, so the request can be retried.
+func (s *IdempotencyStore) Release(ctx context.Context, rec idempotency.Record) error {
+	query := `DELETE FROM "idempotency_key" WHERE "actor" = ? AND "key" = ?`
+
+	if _, err := s.statements.Exec(ctx, s.db, query, rec.Actor, rec.Key); err != nil {
+		return errors.Wrap(err, "IdempotencyStore.Release failed")
+	}
+
+	return nil
+}
+
+// Sweep removes the keys expired at now from db.
+func (s *IdempotencyStore) Sweep(ctx context.Context, now time.Time) error {
+	if _, err := s.statements.Exec(ctx, s.db, `DELETE FROM "idempotency_key" WHERE "expires_at" <= ?`, now.UTC()); err != nil {
+		return errors.Wrap(err, "IdempotencyStore.Sweep failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,380 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/logging"
+)
+
+type journalTransaction struct {
+	ID       int       `json:"id"`
+	Amount   int       `json:"amount"`
+	Type     int       `json:"type"`
+	Category string    `json:"category"`
+	Date     time.Time `json:"date"`
+	Name     string    `json:"name"`
+	Version  int       `json:"version"`
+}
+
+type journalChange struct {
+	Before *journalTransaction `json:"before"`
+	After  *journalTransaction `json:"after"`
+}
+
+// journalSnapshot rounds the date to the precision it is stored with, so the transaction is as in db.
+func (s statements) journalSnapshot(t core.Transaction) *journalTransaction {
+	if t.ID == 0 {
+		return nil
+	}
+	return &journalTransaction{
+		ID:       t.ID,
+		Amount:   t.Amount,
+		Type:     t.Type,
+		Category: t.Category.Name,
+		Date:     s.round(t.Date.UTC()),
+		Name:     t.Name,
+		Version:  t.Version,
+	}
+}
+
+func (t *journalTransaction) transaction() core.Transaction {
+	if t == nil {
+		return core.Transaction{}
+	}
+	return core.Transaction{
+		ID:       t.ID,
+		Amount:   t.Amount,
+		Type:     t.Type,
+		Category: core.Category{Name: t.Category},
+		Date:     t.Date,
+		Name:     t.Name,
+		Version:  t.Version,
+	}
+}
+
+func (s statements) encodeChanges(changes []core.Change) ([]byte, error) {
+	rows := make([]journalChange, 0, len(changes))
+	for _, c := range changes {
+		rows = append(rows, journalChange{Before: s.journalSnapshot(c.Before), After: s.journalSnapshot(c.After)})
+	}
+	return json.Marshal(rows)
+}
+
+func decodeChanges(data []byte) ([]core.Change, error) {
+	var rows []journalChange
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	changes := make([]core.Change, 0, len(rows))
+	for _, row := range rows {
+		changes = append(changes, core.Change{Before: row.Before.transaction(), After: row.After.transaction()})
+	}
+	return changes, nil
+}
+
+// journal records an operation of an actor, within the transaction of its changes, discarding their undone ones and
+// their oldest beyond the journal size.
+func (r *Repository) journal(ctx context.Context, tx *sqlx.Tx, operation string, changes []core.Change, actor string) error {
+	data, err := r.statements.encodeChanges(changes)
+	if err != nil {
+		return errors.Wrap(err, "Repository.journal failed")
+	}
+
+	if _, err := r.statements.Exec(ctx, tx, `DELETE FROM "journal" WHERE "actor" = ? AND "undone"`, actor); err != nil {
+		return errors.Wrap(err, "Repository.journal failed")
+	}
+
+	query := `INSERT INTO "journal" ("actor", "operation", "changes", "date") VALUES (?, ?, ?, ?)`
+
+	if _, err := r.statements.Exec(ctx, tx, query, actor, operation, string(data), time.Now().UTC()); err != nil {
+		return errors.Wrap(err, "Repository.journal failed")
+	}
+
+	query = `SELECT j.id FROM "journal" j WHERE j.actor = ? ORDER BY j.id DESC LIMIT 1 OFFSET ?`
+
+	var oldest int
+	if err := r.statements.Get(ctx, tx, &oldest, query, actor, core.JournalSize); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return errors.Wrap(err, "Repository.journal failed")
+	}
+
+	if _, err := r.statements.Exec(ctx, tx, `DELETE FROM "journal" WHERE "actor" = ? AND "id" <= ?`, actor, oldest); err != nil {
+		return errors.Wrap(err, "Repository.journal failed")
+	}
+
+	return nil
+}
+
+// selectJournal is the base query of a journal row.
+const selectJournal = `SELECT
+				j.id "id",
+				j.actor "actor",
+				j.operation "operation",
+				j.changes "changes",
+				j.undone "undone",
+				j.date "date"
+				FROM "journal" j`
+
+type journalRow struct {
+	ID        int       `db:"id"`
+	Actor     string    `db:"actor"`
+	Operation string    `db:"operation"`
+	Changes   []byte    `db:"changes"`
+	Undone    bool      `db:"undone"`
+	Date      time.Time `db:"date"`
+}
+
+// Undo applies the inverse of the latest operation of the actor journaled in db not undone yet, within a single
+// transaction.
+func (r *Repository) Undo(ctx context.Context, actor string) (_ core.JournalEntry, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	query := r.statements.forUpdate(selectJournal + `
+				WHERE j.actor = ? AND NOT j.undone
+				ORDER BY j.id DESC
+				LIMIT 1`)
+
+	entry, err := r.revert(ctx, query, actor, core.ErrNothingToUndo)
+	if err != nil {
+		return core.JournalEntry{}, errors.Wrap(err, "Repository.Undo failed")
+	}
+
+	return entry, nil
+}
+
+// Redo applies the inverse of the latest operation of the actor undone in db, within a single transaction.
+func (r *Repository) Redo(ctx context.Context, actor string) (_ core.JournalEntry, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	query := r.statements.forUpdate(selectJournal + `
+				WHERE j.actor = ? AND j.undone
+				ORDER BY j.id
+				LIMIT 1`)
+
+	entry, err := r.revert(ctx, query, actor, core.ErrNothingToRedo)
+	if err != nil {
+		return core.JournalEntry{}, errors.Wrap(err, "Repository.Redo failed")
+	}
+
+	return entry, nil
+}
+
+// revert applies the inverse of the journal entry found by query, or nothing when none is found, then toggles the
+// entry and rebases the other entries of the actor.
+func (r *Repository) revert(ctx context.Context, query, actor string, nothing error) (core.JournalEntry, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return core.JournalEntry{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var row journalRow
+	if err := r.statements.Get(ctx, tx, &row, query, actor); err != nil {
+		if err == sql.ErrNoRows {
+			return core.JournalEntry{}, nothing
+		}
+		return core.JournalEntry{}, err
+	}
+
+	changes, err := decodeChanges(row.Changes)
+	if err != nil {
+		return core.JournalEntry{}, err
+	}
+
+	entry := core.JournalEntry{
+		ID:        row.ID,
+		Actor:     row.Actor,
+		Operation: row.Operation,
+		Changes:   changes,
+		Undone:    !row.Undone,
+		Date:      row.Date,
+	}
+
+	inverse := entry.Inverse()
+	applied := make([]core.Change, 0, len(inverse))
+	for _, c := range inverse {
+		c, err := r.apply(ctx, tx, c, actor)
+		if err != nil {
+			return core.JournalEntry{}, err
+		}
+		applied = append(applied, c)
+	}
+	entry.Changes = applied
+
+	if err := r.rebase(ctx, tx, entry.ID, actor, inverse, applied); err != nil {
+		return core.JournalEntry{}, err
+	}
+
+	data, err := r.statements.encodeChanges(applied)
+	if err != nil {
+		return core.JournalEntry{}, err
+	}
+
+	if _, err := r.statements.Exec(ctx, tx, `UPDATE "journal" SET "changes" = ?, "undone" = ? WHERE "id" = ?`, string(data), entry.Undone, entry.ID); err != nil {
+		return core.JournalEntry{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return core.JournalEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// rebase rebases the journal entries of the actor but the reverted one on the changes applied by its inverse, as
+// core.JournalEntry.Rebase does.
+func (r *Repository) rebase(ctx context.Context, tx *sqlx.Tx, reverted int, actor string, inverse, applied []core.Change) error {
+	query := r.statements.forUpdate(selectJournal + `
+				WHERE j.actor = ? AND j.id <> ?`)
+
+	var rows []journalRow
+	if err := r.statements.Select(ctx, tx, &rows, query, actor, reverted); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		changes, err := decodeChanges(row.Changes)
+		if err != nil {
+			return err
+		}
+
+		entry := core.JournalEntry{Changes: changes}
+		if !entry.Rebase(inverse, applied) {
+			continue
+		}
+
+		data, err := r.statements.encodeChanges(entry.Changes)
+		if err != nil {
+			return err
+		}
+
+		if _, err := r.statements.Exec(ctx, tx, `UPDATE "journal" SET "changes" = ? WHERE "id" = ?`, string(data), row.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apply applies a change of a journal entry, along with its audit entry and event, returning it as applied, or
+// fails with core.ErrStaleVersion when the transaction is not as the change expects. A removed transaction is
+// restored with its id, at the next version.
+func (r *Repository) apply(ctx context.Context, tx *sqlx.Tx, c core.Change, actor string) (core.Change, error) {
+	if c.Created() {
+		_, err := r.lockTransaction(ctx, tx, c.After.ID)
+		if err == nil {
+			return core.Change{}, core.ErrStaleVersion
+		}
+		if err != core.ErrNotFound {
+			return core.Change{}, err
+		}
+
+		return r.restore(ctx, tx, c.After, actor)
+	}
+
+	before, err := r.lockTransaction(ctx, tx, c.Before.ID)
+	if err == core.ErrNotFound {
+		return core.Change{}, core.ErrStaleVersion
+	}
+	if err != nil {
+		return core.Change{}, err
+	}
+
+	if before.Version != c.Before.Version {
+		return core.Change{}, core.ErrStaleVersion
+	}
+
+	if c.Deleted() {
+		if _, err := r.statements.Exec(ctx, tx, `DELETE FROM "transaction" WHERE "id" = ?`, before.ID); err != nil {
+			return core.Change{}, err
+		}
+
+		if err := r.audit(ctx, tx, core.AuditEntry{
+			Entity:   core.AuditTransaction,
+			EntityID: strconv.Itoa(before.ID),
+			Action:   core.AuditDelete,
+			Actor:    actor,
+			Before:   transactionSnapshot(before),
+		}); err != nil {
+			return core.Change{}, err
+		}
+
+		if err := r.appendEvents(ctx, tx, core.AuditDelete, actor, before); err != nil {
+			return core.Change{}, err
+		}
+
+		return core.Change{Before: before}, nil
+	}
+
+	if _, err := r.createCategory(ctx, tx, c.After.Category, actor); err != nil {
+		return core.Change{}, err
+	}
+
+	t := c.After
+	t.Version = before.Version + 1
+
+	query := `UPDATE "transaction" SET "amount" = ?, "type" = ?, "category" = ?, "description" = ?, "date" = ?, "version" = ? WHERE "id" = ?`
+
+	if _, err := r.statements.Exec(ctx, tx, query, t.Amount, t.Type, t.Category.Name, t.Name, t.Date.UTC(), t.Version, t.ID); err != nil {
+		return core.Change{}, err
+	}
+
+	if err := r.audit(ctx, tx, core.AuditEntry{
+		Entity:   core.AuditTransaction,
+		EntityID: strconv.Itoa(t.ID),
+		Action:   core.AuditUpdate,
+		Actor:    actor,
+		Before:   transactionSnapshot(before),
+		After:    transactionSnapshot(t),
+	}); err != nil {
+		return core.Change{}, err
+	}
+
+	if err := r.appendEvents(ctx, tx, core.AuditUpdate, actor, t); err != nil {
+		return core.Change{}, err
+	}
+
+	return core.Change{Before: before, After: t}, nil
+}
+
+// restore inserts a removed transaction back with its id, at the next version.
+func (r *Repository) restore(ctx context.Context, tx *sqlx.Tx, t core.Transaction, actor string) (core.Change, error) {
+	if _, err := r.createCategory(ctx, tx, t.Category, actor); err != nil {
+		return core.Change{}, err
+	}
+
+	t.Version++
+
+	query := `INSERT INTO "transaction" ("id", "amount", "type", "category", "description", "date", "version") VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	if _, err := r.statements.Exec(ctx, tx, query, t.ID, t.Amount, t.Type, t.Category.Name, t.Name, t.Date.UTC(), t.Version); err != nil {
+		return core.Change{}, err
+	}
+
+	if err := r.audit(ctx, tx, core.AuditEntry{
+		Entity:   core.AuditTransaction,
+		EntityID: strconv.Itoa(t.ID),
+		Action:   core.AuditCreate,
+		Actor:    actor,
+		After:    transactionSnapshot(t),
+	}); err != nil {
+		return core.Change{}, err
+	}
+
+	if err := r.appendEvents(ctx, tx, core.AuditCreate, actor, t); err != nil {
+		return core.Change{}, err
+	}
+
+	return core.Change{After: t}, nil
+}
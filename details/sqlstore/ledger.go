@@ -0,0 +1,216 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/logging"
+)
+
+// catchUpActor is the actor of the events appended by CatchUp, which records changes made without events.
+const catchUpActor = "migration"
+
+// appendEvents appends an event of each transaction as changed by action, or as removed, to the event store, within
+// the transaction projecting them into the transaction table, when the repository is event-sourced.
+func (r *Repository) appendEvents(ctx context.Context, tx *sqlx.Tx, action, actor string, ts ...core.Transaction) error {
+	if !r.eventSourced || len(ts) == 0 {
+		return nil
+	}
+
+	actions := make([]string, len(ts))
+	for i := range ts {
+		actions[i] = action
+	}
+
+	return r.insertEvents(ctx, tx, actions, actor, ts)
+}
+
+// insertEvents inserts the event of each transaction, changed by the action of the same index.
+func (r *Repository) insertEvents(ctx context.Context, tx *sqlx.Tx, actions []string, actor string, ts []core.Transaction) error {
+	now := time.Now().UTC()
+
+	for start := 0; start < len(ts); start += batchSize {
+		end := start + batchSize
+		if end > len(ts) {
+			end = len(ts)
+		}
+
+		query := `INSERT INTO "transaction_event" ("transaction_id", "action", "amount", "type", "category", "description", "date", "version", "actor", "recorded_at") VALUES ` +
+			values(end-start, 10)
+
+		args := make([]interface{}, 0, (end-start)*10)
+		for i := start; i < end; i++ {
+			t := ts[i]
+			args = append(args, t.ID, actions[i], t.Amount, t.Type, t.Category.Name, t.Name, t.Date.UTC(), t.Version, actor, now)
+		}
+
+		if _, err := r.statements.Exec(ctx, tx, query, args...); err != nil {
+			return errors.Wrap(err, "Repository.appendEvents failed")
+		}
+	}
+
+	return nil
+}
+
+// selectLatestEvent is the base query of the latest event of each transaction, recorded at or before asOf, as a
+// transaction row, the events of a removed one included.
+const selectLatestEvent = `SELECT
+				e.transaction_id "id",
+				e.amount "amount",
+				e.type "type",
+				e.category "category",
+				e.date "date",
+				e.description "name",
+				e.version "version",
+				e.action "action"
+				FROM (SELECT MAX(l.id) "id" FROM "transaction_event" l WHERE l.recorded_at <= ?%s GROUP BY l.transaction_id) latest
+				JOIN "transaction_event" e ON e.id = latest.id`
+
+type eventRow struct {
+	transactionRow
+	Action string `db:"action"`
+}
+
+// CatchUp appends the events of the changes made while the repository was not event-sourced, before serving, so the
+// event store projects into the transaction table again: a removal for each transaction removed since its latest
+// event, then an update, or a creation, for each transaction changed since, or without any event.
+func (r *Repository) CatchUp(ctx context.Context) (err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "Repository.CatchUp failed")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	removedQuery := fmtLatest("") + `
+				WHERE e.action <> ? AND NOT EXISTS (SELECT 1 FROM "transaction" t WHERE t.id = e.transaction_id)
+				ORDER BY e.transaction_id`
+
+	var removed []eventRow
+	if err := r.statements.Select(ctx, tx, &removed, removedQuery, time.Now().UTC(), core.AuditDelete); err != nil {
+		return errors.Wrap(err, "Repository.CatchUp failed")
+	}
+
+	changedQuery := `SELECT
+				t.id "id",
+				t.amount "amount",
+				t.type "type",
+				t.category "category",
+				t.date "date",
+				t.description "name",
+				t.version "version",
+				COALESCE(e.action, ?) "action"
+				FROM "transaction" t
+				LEFT JOIN (SELECT MAX(l.id) "id", l.transaction_id FROM "transaction_event" l GROUP BY l.transaction_id) latest
+					ON latest.transaction_id = t.id
+				LEFT JOIN "transaction_event" e ON e.id = latest.id
+				WHERE e.id IS NULL OR e.action = ? OR e.version <> t.version
+				ORDER BY t.id`
+
+	var changed []eventRow
+	if err := r.statements.Select(ctx, tx, &changed, changedQuery, core.AuditDelete, core.AuditDelete); err != nil {
+		return errors.Wrap(err, "Repository.CatchUp failed")
+	}
+
+	actions := make([]string, 0, len(removed)+len(changed))
+	ts := make([]core.Transaction, 0, len(removed)+len(changed))
+	for _, row := range removed {
+		actions = append(actions, core.AuditDelete)
+		ts = append(ts, row.transaction())
+	}
+	for _, row := range changed {
+		// a transaction without an event, or restored since its removal, is created again
+		action := core.AuditUpdate
+		if row.Action == core.AuditDelete {
+			action = core.AuditCreate
+		}
+		actions = append(actions, action)
+		ts = append(ts, row.transaction())
+	}
+
+	if err := r.insertEvents(ctx, tx, actions, catchUpActor, ts); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "Repository.CatchUp failed")
+	}
+
+	return nil
+}
+
+// FindAsOf finds the transactions as they were known at asOf, from the latest event of each recorded then.
+func (r *Repository) FindAsOf(ctx context.Context, asOf time.Time) (_ []core.Transaction, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	query := fmtLatest("") + `
+				WHERE e.action <> ?
+				ORDER BY e.date, e.transaction_id`
+
+	var rows []eventRow
+	if err := r.statements.Select(ctx, r.db, &rows, query, asOf.UTC(), core.AuditDelete); err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Repository.FindAsOf failed")
+	}
+
+	return eventTransactions(rows), nil
+}
+
+// FindMonthAsOf finds the transactions dated in the month starting at from as they were known at asOf, from the
+// latest event of each recorded then, among the transactions once dated in the month.
+func (r *Repository) FindMonthAsOf(ctx context.Context, from, asOf time.Time) (_ []core.Transaction, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	to := from.UTC().AddDate(0, 1, 0)
+
+	query := fmtLatest(` AND l.transaction_id IN (SELECT m.transaction_id FROM "transaction_event" m WHERE m.date >= ? AND m.date < ? AND m.recorded_at <= ?)`) + `
+				WHERE e.action <> ? AND e.date >= ? AND e.date < ?
+				ORDER BY e.date, e.transaction_id`
+
+	var rows []eventRow
+	if err := r.statements.Select(
+		ctx, r.db, &rows, query,
+		asOf.UTC(), from.UTC(), to, asOf.UTC(),
+		core.AuditDelete, from.UTC(), to,
+	); err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Repository.FindMonthAsOf failed")
+	}
+
+	return eventTransactions(rows), nil
+}
+
+// FindStart finds when the first event was appended to the event store, the zero time when none was.
+func (r *Repository) FindStart(ctx context.Context) (_ time.Time, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	query := `SELECT e.recorded_at FROM "transaction_event" e ORDER BY e.id LIMIT 1`
+
+	var start time.Time
+	if err := r.statements.Get(ctx, r.db, &start, query); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+		return time.Time{}, errors.Wrap(err, "Repository.FindStart failed")
+	}
+
+	return start.UTC(), nil
+}
+
+// fmtLatest completes the query of the latest events with a condition on the events of the subquery.
+func fmtLatest(condition string) string {
+	return fmt.Sprintf(selectLatestEvent, condition)
+}
+
+func eventTransactions(rows []eventRow) []core.Transaction {
+	ts := make([]core.Transaction, 0, len(rows))
+	for _, row := range rows {
+		ts = append(ts, row.transaction())
+	}
+	return ts
+}
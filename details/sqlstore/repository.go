@@ -0,0 +1,710 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/logging"
+)
+
+// Repository is able to save and find a transaction(s) in a SQL database.
+type Repository struct {
+	db           *sqlx.DB
+	statements   statements
+	eventSourced bool
+}
+
+// NewRepository initialize the repository on db, writing its statements in the dialect of db.
+func NewRepository(db *sqlx.DB, dialect Dialect) *Repository {
+	return &Repository{db: db, statements: newStatements(dialect)}
+}
+
+// NewEventSourcedRepository initialize the repository on db, appending an event of each change of a transaction to
+// the event store, the transaction table being their projection.
+func NewEventSourcedRepository(db *sqlx.DB, dialect Dialect) *Repository {
+	r := NewRepository(db, dialect)
+	r.eventSourced = true
+	return r
+}
+
+// DB returns the connection of the repository, shared by its stores and migrator.
+func (r *Repository) DB() *sqlx.DB {
+	return r.db
+}
+
+// Close closes the connections to the database, waiting for the running queries to finish.
+func (r *Repository) Close() error {
+	return errors.Wrap(r.db.Close(), "Repository.Close failed")
+}
+
+// Ping checks the database is reachable, connecting to it when needed.
+func (r *Repository) Ping(ctx context.Context) error {
+	return errors.Wrap(r.db.PingContext(ctx), "Repository.Ping failed")
+}
+
+// Stats reports the usage of the connection pool.
+func (r *Repository) Stats() sql.DBStats {
+	return r.db.Stats()
+}
+
+// Create persists a transaction in db, along with its audit entry, journal entry and event, if event-sourced.
+func (r *Repository) Create(ctx context.Context, t core.Transaction, actor string) (_ core.Transaction, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Create failed")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := r.createCategory(ctx, tx, t.Category, actor); err != nil {
+		return core.Transaction{}, err
+	}
+
+	if t.Date.IsZero() {
+		t.Date = time.Now().UTC()
+	}
+
+	query := `INSERT INTO "transaction" ("amount", "type", "category", "description", "date") VALUES`
+
+	ids, err := r.statements.insert(ctx, tx, 5, query, t.Amount, t.Type, t.Category.Name, t.Name, t.Date.UTC())
+	if err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Create failed")
+	}
+	t.ID = ids[0]
+	t.Version = 1
+
+	if err := r.audit(ctx, tx, core.AuditEntry{
+		Entity:   core.AuditTransaction,
+		EntityID: strconv.Itoa(t.ID),
+		Action:   core.AuditCreate,
+		Actor:    actor,
+		After:    transactionSnapshot(t),
+	}); err != nil {
+		return core.Transaction{}, err
+	}
+
+	if err := r.journal(ctx, tx, core.JournalCreate, []core.Change{{After: t}}, actor); err != nil {
+		return core.Transaction{}, err
+	}
+
+	if err := r.appendEvents(ctx, tx, core.AuditCreate, actor, t); err != nil {
+		return core.Transaction{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Create failed")
+	}
+
+	return t, nil
+}
+
+// batchSize is the maximum number of rows inserted by a single statement.
+const batchSize = 500
+
+// CreateBatch persists all transactions in db within a single transaction, along with their audit entries, journal
+// entry and events, if event-sourced.
+func (r *Repository) CreateBatch(ctx context.Context, ts []core.Transaction, actor string) (_ []core.Transaction, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Repository.CreateBatch failed")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	created := make([]core.Transaction, 0, len(ts))
+	categories := map[string]bool{}
+	now := time.Now().UTC()
+
+	for _, t := range ts {
+		if !categories[t.Category.Name] {
+			if _, err := r.createCategory(ctx, tx, t.Category, actor); err != nil {
+				return []core.Transaction{}, err
+			}
+			categories[t.Category.Name] = true
+		}
+
+		if t.Date.IsZero() {
+			t.Date = now
+		}
+		t.Version = 1
+		created = append(created, t)
+	}
+
+	for start := 0; start < len(created); start += batchSize {
+		end := start + batchSize
+		if end > len(created) {
+			end = len(created)
+		}
+		chunk := created[start:end]
+
+		query := `INSERT INTO "transaction" ("amount", "type", "category", "description", "date") VALUES`
+
+		args := make([]interface{}, 0, len(chunk)*5)
+		for _, t := range chunk {
+			args = append(args, t.Amount, t.Type, t.Category.Name, t.Name, t.Date.UTC())
+		}
+
+		ids, err := r.statements.insert(ctx, tx, 5, query, args...)
+		if err != nil {
+			return []core.Transaction{}, errors.Wrap(err, "Repository.CreateBatch failed")
+		}
+
+		entries := make([]core.AuditEntry, 0, len(chunk))
+		for i := range chunk {
+			chunk[i].ID = ids[i]
+			entries = append(entries, core.AuditEntry{
+				Entity:   core.AuditTransaction,
+				EntityID: strconv.Itoa(chunk[i].ID),
+				Action:   core.AuditCreate,
+				Actor:    actor,
+				After:    transactionSnapshot(chunk[i]),
+			})
+		}
+
+		if err := r.audit(ctx, tx, entries...); err != nil {
+			return []core.Transaction{}, err
+		}
+
+		if err := r.appendEvents(ctx, tx, core.AuditCreate, actor, chunk...); err != nil {
+			return []core.Transaction{}, err
+		}
+	}
+
+	changes := make([]core.Change, 0, len(created))
+	for _, t := range created {
+		changes = append(changes, core.Change{After: t})
+	}
+
+	if err := r.journal(ctx, tx, core.JournalCreateBatch, changes, actor); err != nil {
+		return []core.Transaction{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Repository.CreateBatch failed")
+	}
+
+	return created, nil
+}
+
+// CreateCategory persists a category in db, along with its audit entry, when it did not exist, telling whether it
+// did not.
+func (r *Repository) CreateCategory(ctx context.Context, category core.Category, actor string) (_ bool, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "Repository.CreateCategory failed")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	created, err := r.createCategory(ctx, tx, category, actor)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, errors.Wrap(err, "Repository.CreateCategory failed")
+	}
+
+	return created, nil
+}
+
+// createCategory inserts a category unless it exists, telling whether it did not, so a single one of concurrent
+// writes creates it.
+func (r *Repository) createCategory(ctx context.Context, tx *sqlx.Tx, category core.Category, actor string) (bool, error) {
+	query := r.statements.ignore(`INSERT INTO "category" ("name") VALUES (?)`, "name")
+
+	result, err := r.statements.Exec(ctx, tx, query, category.Name)
+	if err != nil {
+		return false, errors.Wrap(err, "Repository.CreateCategory failed")
+	}
+
+	created, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "Repository.CreateCategory failed")
+	}
+	if created == 0 {
+		return false, nil
+	}
+
+	if err := r.audit(ctx, tx, core.AuditEntry{
+		Entity:   core.AuditCategory,
+		EntityID: category.Name,
+		Action:   core.AuditCreate,
+		Actor:    actor,
+		After:    categorySnapshot(category),
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// selectTransaction is the base query of a transaction row.
+const selectTransaction = `SELECT
+				t.id "id",
+				t.amount "amount",
+				t.type "type",
+				t.category "category",
+				t.date "date",
+				t.description "name",
+				t.version "version"
+				FROM "transaction" t`
+
+type transactionRow struct {
+	ID       int            `db:"id"`
+	Amount   int            `db:"amount"`
+	Type     int            `db:"type"`
+	Category string         `db:"category"`
+	Date     time.Time      `db:"date"`
+	Name     sql.NullString `db:"name"`
+	Version  int            `db:"version"`
+}
+
+func (row transactionRow) transaction() core.Transaction {
+	return core.Transaction{
+		ID:       row.ID,
+		Amount:   row.Amount,
+		Type:     row.Type,
+		Category: core.Category{Name: row.Category},
+		Date:     row.Date.UTC(),
+		Name:     row.Name.String,
+		Version:  row.Version,
+	}
+}
+
+// Find transactions in db.
+func (r *Repository) Find(ctx context.Context) (_ []core.Transaction, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	query := selectTransaction + `
+				ORDER by t.date, t.id`
+
+	var rows []transactionRow
+	if err := r.statements.Select(ctx, r.db, &rows, query); err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Repository.Find failed")
+	}
+
+	var trs []core.Transaction
+	for _, row := range rows {
+		trs = append(trs, row.transaction())
+	}
+
+	return trs, nil
+}
+
+// FindMonth finds the transactions dated in the month starting at from in db.
+func (r *Repository) FindMonth(ctx context.Context, from time.Time) (_ []core.Transaction, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	query := selectTransaction + `
+				WHERE t.date >= ? AND t.date < ?
+				ORDER by t.date, t.id`
+
+	var rows []transactionRow
+	if err := r.statements.Select(ctx, r.db, &rows, query, from.UTC(), from.UTC().AddDate(0, 1, 0)); err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Repository.FindMonth failed")
+	}
+
+	var trs []core.Transaction
+	for _, row := range rows {
+		trs = append(trs, row.transaction())
+	}
+
+	return trs, nil
+}
+
+// FindPage finds up to limit transactions matching the filter listed after the cursor in db.
+func (r *Repository) FindPage(ctx context.Context, f core.Filter, after core.Cursor, limit int) (_ []core.Transaction, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	conditions, args := where(f)
+	if after.ID != 0 {
+		conditions = append(conditions, `(t.date > ? OR (t.date = ? AND t.id > ?))`)
+		args = append(args, after.Date.UTC(), after.Date.UTC(), after.ID)
+	}
+
+	query := selectTransaction + fmtWhere(conditions) + `
+				ORDER by t.date, t.id
+				LIMIT ?`
+	args = append(args, limit)
+
+	var rows []transactionRow
+	if err := r.statements.Select(ctx, r.db, &rows, query, args...); err != nil {
+		return []core.Transaction{}, errors.Wrap(err, "Repository.FindPage failed")
+	}
+
+	var trs []core.Transaction
+	for _, row := range rows {
+		trs = append(trs, row.transaction())
+	}
+
+	return trs, nil
+}
+
+// Count counts the transactions in db matching the filter.
+func (r *Repository) Count(ctx context.Context, f core.Filter) (_ int, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	conditions, args := where(f)
+	query := `SELECT COUNT(*) FROM "transaction" t` + fmtWhere(conditions)
+
+	var count int
+	if err := r.statements.Get(ctx, r.db, &count, query, args...); err != nil {
+		return 0, errors.Wrap(err, "Repository.Count failed")
+	}
+
+	return count, nil
+}
+
+// where returns the conditions of the transactions matching the filter, along with their arguments.
+func where(f core.Filter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if f.Category != "" {
+		conditions = append(conditions, `t.category = ?`)
+		args = append(args, f.Category)
+	}
+	if f.Type != 0 {
+		conditions = append(conditions, `t.type = ?`)
+		args = append(args, f.Type)
+	}
+	if !f.From.IsZero() {
+		conditions = append(conditions, `t.date >= ?`)
+		args = append(args, f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		conditions = append(conditions, `t.date <= ?`)
+		args = append(args, f.To.UTC())
+	}
+
+	return conditions, args
+}
+
+// fmtWhere joins the conditions into a WHERE clause, none when there are none.
+func fmtWhere(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return `
+				WHERE ` + strings.Join(conditions, " AND ")
+}
+
+// FindByID finds a transaction in db.
+func (r *Repository) FindByID(ctx context.Context, id int) (_ core.Transaction, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	query := selectTransaction + `
+				WHERE t.id = ?`
+
+	var row transactionRow
+	if err := r.statements.Get(ctx, r.db, &row, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			err = core.ErrNotFound
+		}
+		return core.Transaction{}, errors.Wrap(err, "Repository.FindByID failed")
+	}
+
+	return row.transaction(), nil
+}
+
+// Update changes a transaction in db, along with its audit entry, journal entry and event, if event-sourced, given its
+// Version is the current one.
+func (r *Repository) Update(ctx context.Context, t core.Transaction, actor string) (_ core.Transaction, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Update failed")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before, err := r.findForUpdate(ctx, tx, t.ID, t.Version)
+	if err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Update failed")
+	}
+
+	if _, err := r.createCategory(ctx, tx, t.Category, actor); err != nil {
+		return core.Transaction{}, err
+	}
+
+	if t.Date.IsZero() {
+		t.Date = before.Date
+	}
+
+	query := `UPDATE "transaction" SET "amount" = ?, "type" = ?, "category" = ?, "description" = ?, "date" = ?, "version" = "version" + 1 WHERE "id" = ? AND "version" = ?`
+
+	result, err := r.statements.Exec(ctx, tx, query, t.Amount, t.Type, t.Category.Name, t.Name, t.Date.UTC(), t.ID, t.Version)
+	if err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Update failed")
+	}
+
+	if err := affected(result); err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Update failed")
+	}
+	t.Version++
+
+	if err := r.audit(ctx, tx, core.AuditEntry{
+		Entity:   core.AuditTransaction,
+		EntityID: strconv.Itoa(t.ID),
+		Action:   core.AuditUpdate,
+		Actor:    actor,
+		Before:   transactionSnapshot(before),
+		After:    transactionSnapshot(t),
+	}); err != nil {
+		return core.Transaction{}, err
+	}
+
+	if err := r.journal(ctx, tx, core.JournalUpdate, []core.Change{{Before: before, After: t}}, actor); err != nil {
+		return core.Transaction{}, err
+	}
+
+	if err := r.appendEvents(ctx, tx, core.AuditUpdate, actor, t); err != nil {
+		return core.Transaction{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Update failed")
+	}
+
+	return t, nil
+}
+
+// Delete removes a transaction from db, along with its audit entry, journal entry and event, if event-sourced, given
+// the version is the current one, returning it as removed.
+func (r *Repository) Delete(ctx context.Context, id, version int, actor string) (_ core.Transaction, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Delete failed")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before, err := r.findForUpdate(ctx, tx, id, version)
+	if err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Delete failed")
+	}
+
+	result, err := r.statements.Exec(ctx, tx, `DELETE FROM "transaction" WHERE "id" = ? AND "version" = ?`, id, version)
+	if err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Delete failed")
+	}
+
+	if err := affected(result); err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Delete failed")
+	}
+
+	if err := r.audit(ctx, tx, core.AuditEntry{
+		Entity:   core.AuditTransaction,
+		EntityID: strconv.Itoa(id),
+		Action:   core.AuditDelete,
+		Actor:    actor,
+		Before:   transactionSnapshot(before),
+	}); err != nil {
+		return core.Transaction{}, err
+	}
+
+	if err := r.journal(ctx, tx, core.JournalDelete, []core.Change{{Before: before}}, actor); err != nil {
+		return core.Transaction{}, err
+	}
+
+	if err := r.appendEvents(ctx, tx, core.AuditDelete, actor, before); err != nil {
+		return core.Transaction{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return core.Transaction{}, errors.Wrap(err, "Repository.Delete failed")
+	}
+
+	return before, nil
+}
+
+// findForUpdate locks a transaction row until tx ends, given it is at the expected version.
+func (r *Repository) findForUpdate(ctx context.Context, tx *sqlx.Tx, id, version int) (core.Transaction, error) {
+	t, err := r.lockTransaction(ctx, tx, id)
+	if err != nil {
+		return core.Transaction{}, err
+	}
+
+	if t.Version != version {
+		return core.Transaction{}, core.ErrStaleVersion
+	}
+
+	return t, nil
+}
+
+// lockTransaction finds a transaction row, locking it until tx ends, unless tx already holds the write lock of the
+// whole database.
+func (r *Repository) lockTransaction(ctx context.Context, tx *sqlx.Tx, id int) (core.Transaction, error) {
+	query := r.statements.forUpdate(selectTransaction + `
+				WHERE t.id = ?`)
+
+	var row transactionRow
+	if err := r.statements.Get(ctx, tx, &row, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return core.Transaction{}, core.ErrNotFound
+		}
+		return core.Transaction{}, err
+	}
+
+	return row.transaction(), nil
+}
+
+// affected ensures a versioned statement changed a row, which it does not when the version is stale.
+func affected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return core.ErrStaleVersion
+	}
+	return nil
+}
+
+// FindHistory finds the audit entries of a transaction in db, oldest first.
+func (r *Repository) FindHistory(ctx context.Context, transactionID int) (_ []core.AuditEntry, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	type row struct {
+		ID       int       `db:"id"`
+		Entity   string    `db:"entity"`
+		EntityID string    `db:"entity_id"`
+		Action   string    `db:"action"`
+		Actor    string    `db:"actor"`
+		Date     time.Time `db:"date"`
+		Before   []byte    `db:"before"`
+		After    []byte    `db:"after"`
+	}
+
+	query := `SELECT
+				a.id "id",
+				a.entity "entity",
+				a.entity_id "entity_id",
+				a.action "action",
+				a.actor "actor",
+				a.date "date",
+				a."before" "before",
+				a."after" "after"
+				FROM audit a
+				WHERE a.entity = ? AND a.entity_id = ?
+				ORDER by a.id`
+
+	var rows []row
+	if err := r.statements.Select(ctx, r.db, &rows, query, core.AuditTransaction, strconv.Itoa(transactionID)); err != nil {
+		return []core.AuditEntry{}, errors.Wrap(err, "Repository.FindHistory failed")
+	}
+
+	var entries []core.AuditEntry
+	for _, row := range rows {
+		entries = append(entries, core.AuditEntry{
+			ID:       row.ID,
+			Entity:   row.Entity,
+			EntityID: row.EntityID,
+			Action:   row.Action,
+			Actor:    row.Actor,
+			Date:     row.Date.UTC(),
+			Before:   row.Before,
+			After:    row.After,
+		})
+	}
+
+	return entries, nil
+}
+
+// FindCategories finds the categories in db, ordered by name.
+func (r *Repository) FindCategories(ctx context.Context) (_ []core.Category, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	query := `SELECT c.name FROM "category" c ORDER BY c.name`
+
+	var names []string
+	if err := r.statements.Select(ctx, r.db, &names, query); err != nil {
+		return []core.Category{}, errors.Wrap(err, "Repository.FindCategories failed")
+	}
+
+	var categories []core.Category
+	for _, name := range names {
+		categories = append(categories, core.Category{Name: name})
+	}
+
+	return categories, nil
+}
+
+// SumByCategory sums the transactions of the named categories in db, by category name, in a single query,
+// the categories without transactions are left out.
+func (r *Repository) SumByCategory(ctx context.Context, names []string) (_ map[string]core.Totals, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	totals := map[string]core.Totals{}
+	if len(names) == 0 {
+		return totals, nil
+	}
+
+	type row struct {
+		Category string `db:"category"`
+		Type     int    `db:"type"`
+		Amount   int    `db:"amount"`
+		Count    int    `db:"count"`
+	}
+
+	query, args, err := sqlx.In(`SELECT
+				t.category "category",
+				t.type "type",
+				SUM(t.amount) "amount",
+				COUNT(*) "count"
+				FROM "transaction" t
+				WHERE t.category IN (?)
+				GROUP BY t.category, t.type`, names)
+	if err != nil {
+		return totals, errors.Wrap(err, "Repository.SumByCategory failed")
+	}
+
+	var rows []row
+	if err := r.statements.Select(ctx, r.db, &rows, query, args...); err != nil {
+		return map[string]core.Totals{}, errors.Wrap(err, "Repository.SumByCategory failed")
+	}
+
+	for _, row := range rows {
+		total := totals[row.Category]
+		total.AddAll(row.Type, row.Amount, row.Count)
+		totals[row.Category] = total
+	}
+
+	return totals, nil
+}
+
+// CountByType counts the transactions in db of each type.
+func (r *Repository) CountByType(ctx context.Context) (_ map[int]int, err error) {
+	defer func() { logging.Failure(ctx, err) }()
+
+	type row struct {
+		Type  int `db:"type"`
+		Count int `db:"count"`
+	}
+
+	query := `SELECT t.type "type", COUNT(*) "count" FROM "transaction" t GROUP BY t.type`
+
+	var rows []row
+	if err := r.statements.Select(ctx, r.db, &rows, query); err != nil {
+		return map[int]int{}, errors.Wrap(err, "Repository.CountByType failed")
+	}
+
+	counts := make(map[int]int, len(rows))
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,77 @@
+// Package sqlstoretest runs every conformance suite against a SQL backend, as each backend serves every store.
+package sqlstoretest
+
+import (
+	"testing"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/core/repotest"
+	"github.com/gritt/maskada/details/events"
+	"github.com/gritt/maskada/details/events/eventstest"
+	"github.com/gritt/maskada/details/idempotency"
+	"github.com/gritt/maskada/details/idempotency/idempotencytest"
+	"github.com/gritt/maskada/details/sqlstore"
+	"github.com/gritt/maskada/details/webhook"
+	"github.com/gritt/maskada/details/webhook/webhooktest"
+)
+
+// Backend returns an empty repository of the backend, event-sourced when told to, with its schema migrated,
+// releasing it with t.Cleanup.
+type Backend func(t *testing.T, eventSourced bool) *sqlstore.Repository
+
+// Run runs the conformance suite of each store against the backend, each test against a new repository.
+func Run(t *testing.T, open Backend) {
+	suites := map[string]func(*testing.T, Backend){
+		"Repository":       runRepository,
+		"EventLog":         runEventLog,
+		"Journal":          runJournal,
+		"IdempotencyStore": runIdempotencyStore,
+		"EventStore":       runEventStore,
+		"WebhookStore":     runWebhookStore,
+	}
+
+	for name, run := range suites {
+		t.Run(name, func(t *testing.T) {
+			run(t, open)
+		})
+	}
+}
+
+func runRepository(t *testing.T, open Backend) {
+	repotest.Run(t, func(t *testing.T) core.Repository {
+		return open(t, false)
+	})
+}
+
+func runEventLog(t *testing.T, open Backend) {
+	repotest.RunEvents(t, func(t *testing.T) (core.Repository, core.EventLog) {
+		r := open(t, true)
+		return r, r
+	})
+}
+
+func runJournal(t *testing.T, open Backend) {
+	repotest.RunJournal(t, func(t *testing.T) (core.Repository, core.Journal) {
+		r := open(t, false)
+		return r, r
+	})
+}
+
+func runIdempotencyStore(t *testing.T, open Backend) {
+	idempotencytest.Run(t, func(t *testing.T) idempotency.Store {
+		return sqlstore.NewIdempotencyStore(open(t, false))
+	})
+}
+
+func runEventStore(t *testing.T, open Backend) {
+	eventstest.Run(t, func(t *testing.T, relay bool) events.Store {
+		return sqlstore.NewEventStore(open(t, false), relay)
+	})
+}
+
+func runWebhookStore(t *testing.T, open Backend) {
+	webhooktest.Run(t, func(t *testing.T) (core.Repository, core.Publisher, webhook.Store) {
+		r := open(t, false)
+		return r, events.NewOutbox(sqlstore.NewEventStore(r, true)), sqlstore.NewWebhookStore(r)
+	})
+}
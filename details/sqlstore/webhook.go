@@ -0,0 +1,365 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/outbox"
+	"github.com/gritt/maskada/details/webhook"
+)
+
+// WebhookStore is able to persist the webhook subscriptions and deliveries, and to read the outbox.
+type WebhookStore struct {
+	db         *sqlx.DB
+	statements statements
+}
+
+// NewWebhookStore initialize the store, sharing the repository connection.
+func NewWebhookStore(r *Repository) *WebhookStore {
+	return &WebhookStore{db: r.db, statements: r.statements}
+}
+
+type subscriptionRow struct {
+	ID     int       `db:"id"`
+	URL    string    `db:"url"`
+	Secret string    `db:"secret"`
+	Events string    `db:"events"`
+	Date   time.Time `db:"date"`
+}
+
+func (row subscriptionRow) subscription() webhook.Subscription {
+	return webhook.Subscription{
+		ID:     row.ID,
+		URL:    row.URL,
+		Secret: row.Secret,
+		Events: strings.Split(row.Events, ","),
+		Date:   row.Date,
+	}
+}
+
+type deliveryRow struct {
+	ID             int          `db:"id"`
+	SubscriptionID int          `db:"subscription_id"`
+	EventID        int          `db:"event_id"`
+	Event          string       `db:"event"`
+	Attempt        int          `db:"attempt"`
+	Status         string       `db:"status"`
+	ResponseCode   int          `db:"response_code"`
+	Error          string       `db:"error"`
+	ScheduledAt    time.Time    `db:"scheduled_at"`
+	AttemptedAt    sql.NullTime `db:"attempted_at"`
+}
+
+func (row deliveryRow) delivery() webhook.Delivery {
+	return webhook.Delivery{
+		ID:             row.ID,
+		SubscriptionID: row.SubscriptionID,
+		EventID:        row.EventID,
+		Event:          row.Event,
+		Attempt:        row.Attempt,
+		Status:         row.Status,
+		ResponseCode:   row.ResponseCode,
+		Error:          row.Error,
+		ScheduledAt:    row.ScheduledAt,
+		AttemptedAt:    row.AttemptedAt.Time,
+	}
+}
+
+// selectDelivery is the base query of a delivery row.
+const selectDelivery = `SELECT
+				d.id "id",
+				d.subscription_id "subscription_id",
+				d.event_id "event_id",
+				d.event "event",
+				d.attempt "attempt",
+				d.status "status",
+				d.response_code "response_code",
+				d.error "error",
+				d.scheduled_at "scheduled_at",
+				d.attempted_at "attempted_at"
+				FROM webhook_delivery d`
+
+// CreateSubscription persists a subscription in db.
+func (s *WebhookStore) CreateSubscription(ctx context.Context, sub webhook.Subscription) (webhook.Subscription, error) {
+	sub.Date = time.Now().UTC()
+
+	query := `INSERT INTO "webhook_subscription" ("url", "secret", "events", "date") VALUES`
+
+	ids, err := s.statements.insert(ctx, s.db, 4, query, sub.URL, sub.Secret, strings.Join(sub.Events, ","), sub.Date)
+	if err != nil {
+		return webhook.Subscription{}, errors.Wrap(err, "WebhookStore.CreateSubscription failed")
+	}
+	sub.ID = ids[0]
+
+	return sub, nil
+}
+
+// FindSubscriptions finds the subscriptions in db, oldest first.
+func (s *WebhookStore) FindSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	query := `SELECT s.id "id", s.url "url", s.secret "secret", s.events "events", s.date "date"
+				FROM webhook_subscription s
+				ORDER BY s.id`
+
+	var rows []subscriptionRow
+	if err := s.statements.Select(ctx, s.db, &rows, query); err != nil {
+		return []webhook.Subscription{}, errors.Wrap(err, "WebhookStore.FindSubscriptions failed")
+	}
+
+	subs := make([]webhook.Subscription, 0, len(rows))
+	for _, row := range rows {
+		subs = append(subs, row.subscription())
+	}
+
+	return subs, nil
+}
+
+// DeleteSubscription removes a subscription from db, along with its deliveries.
+func (s *WebhookStore) DeleteSubscription(ctx context.Context, id int) error {
+	result, err := s.statements.Exec(ctx, s.db, `DELETE FROM "webhook_subscription" WHERE "id" = ?`, id)
+	if err != nil {
+		return errors.Wrap(err, "WebhookStore.DeleteSubscription failed")
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "WebhookStore.DeleteSubscription failed")
+	}
+	if n == 0 {
+		return errors.Wrap(core.ErrNotFound, "WebhookStore.DeleteSubscription failed")
+	}
+
+	return nil
+}
+
+// FindDeliveries finds the latest deliveries of a subscription in db, newest first.
+func (s *WebhookStore) FindDeliveries(ctx context.Context, subscriptionID, limit int) ([]webhook.Delivery, error) {
+	var id int
+	if err := s.statements.Get(ctx, s.db, &id, `SELECT s.id FROM webhook_subscription s WHERE s.id = ?`, subscriptionID); err != nil {
+		if err == sql.ErrNoRows {
+			err = core.ErrNotFound
+		}
+		return []webhook.Delivery{}, errors.Wrap(err, "WebhookStore.FindDeliveries failed")
+	}
+
+	query := selectDelivery + `
+				WHERE d.subscription_id = ?
+				ORDER BY d.id DESC
+				LIMIT ?`
+
+	var rows []deliveryRow
+	if err := s.statements.Select(ctx, s.db, &rows, query, subscriptionID, limit); err != nil {
+		return []webhook.Delivery{}, errors.Wrap(err, "WebhookStore.FindDeliveries failed")
+	}
+
+	deliveries := make([]webhook.Delivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, row.delivery())
+	}
+
+	return deliveries, nil
+}
+
+// Fanout schedules a delivery of the undispatched events in db to their subscriptions, marking them dispatched.
+func (s *WebhookStore) Fanout(ctx context.Context, now time.Time, limit int) (int, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "WebhookStore.Fanout failed")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := s.statements.forUpdate(`SELECT o.id "id", o.event "event"
+				FROM outbox o
+				WHERE o.dispatched_at IS NULL
+				ORDER BY o.id
+				LIMIT ?`)
+
+	var events []struct {
+		ID    int    `db:"id"`
+		Event string `db:"event"`
+	}
+	if err := s.statements.Select(ctx, tx, &events, query, limit); err != nil {
+		return 0, errors.Wrap(err, "WebhookStore.Fanout failed")
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	var subs []subscriptionRow
+	if err := s.statements.Select(ctx, tx, &subs, `SELECT s.id "id", s.url "url", s.secret "secret", s.events "events", s.date "date" FROM webhook_subscription s`); err != nil {
+		return 0, errors.Wrap(err, "WebhookStore.Fanout failed")
+	}
+
+	ids := make([]int, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+
+		for _, row := range subs {
+			if !row.subscription().Subscribed(event.Event) {
+				continue
+			}
+
+			if err := s.insertDelivery(ctx, tx, webhook.Delivery{
+				SubscriptionID: row.ID,
+				EventID:        event.ID,
+				Event:          event.Event,
+				Attempt:        1,
+				Status:         webhook.Pending,
+				ScheduledAt:    now,
+			}); err != nil {
+				return 0, errors.Wrap(err, "WebhookStore.Fanout failed")
+			}
+		}
+	}
+
+	update, args, err := sqlx.In(`UPDATE "outbox" SET "dispatched_at" = ? WHERE "id" IN (?)`, now, ids)
+	if err != nil {
+		return 0, errors.Wrap(err, "WebhookStore.Fanout failed")
+	}
+	if _, err := s.statements.Exec(ctx, tx, update, args...); err != nil {
+		return 0, errors.Wrap(err, "WebhookStore.Fanout failed")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "WebhookStore.Fanout failed")
+	}
+
+	return len(events), nil
+}
+
+// ClaimDue claims the pending deliveries in db scheduled at or before now, and the sending ones whose lease ended,
+// marking them sending until now+lease, along with their subscription and event. Another instance skips the claimed
+// rows rather than waiting for them, unless the transaction holds the write lock of the whole database.
+func (s *WebhookStore) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]webhook.Job, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return []webhook.Job{}, errors.Wrap(err, "WebhookStore.ClaimDue failed")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := s.statements.skipLocked(`SELECT d.id
+				FROM webhook_delivery d
+				WHERE (d.status = ? AND d.scheduled_at <= ?) OR (d.status = ? AND d.lease_until <= ?)
+				ORDER BY d.scheduled_at, d.id
+				LIMIT ?`)
+
+	var ids []int
+	if err := s.statements.Select(ctx, tx, &ids, query, webhook.Pending, now.UTC(), webhook.Sending, now.UTC(), limit); err != nil {
+		return []webhook.Job{}, errors.Wrap(err, "WebhookStore.ClaimDue failed")
+	}
+	if len(ids) == 0 {
+		return []webhook.Job{}, nil
+	}
+
+	update, args, err := sqlx.In(`UPDATE "webhook_delivery" SET "status" = ?, "lease_until" = ? WHERE "id" IN (?)`,
+		webhook.Sending, now.Add(lease).UTC(), ids)
+	if err != nil {
+		return []webhook.Job{}, errors.Wrap(err, "WebhookStore.ClaimDue failed")
+	}
+	if _, err := s.statements.Exec(ctx, tx, update, args...); err != nil {
+		return []webhook.Job{}, errors.Wrap(err, "WebhookStore.ClaimDue failed")
+	}
+
+	query, args, err = sqlx.In(`SELECT
+				d.id "id",
+				d.subscription_id "subscription_id",
+				d.event_id "event_id",
+				d.event "event",
+				d.attempt "attempt",
+				d.status "status",
+				d.scheduled_at "scheduled_at",
+				s.url "url",
+				s.secret "secret",
+				s.events "events",
+				s.date "subscription_date",
+				o.payload "payload",
+				o.date "event_date"
+				FROM webhook_delivery d
+				JOIN webhook_subscription s ON s.id = d.subscription_id
+				JOIN outbox o ON o.id = d.event_id
+				WHERE d.id IN (?)
+				ORDER BY d.scheduled_at, d.id`, ids)
+	if err != nil {
+		return []webhook.Job{}, errors.Wrap(err, "WebhookStore.ClaimDue failed")
+	}
+
+	var rows []struct {
+		deliveryRow
+		URL              string    `db:"url"`
+		Secret           string    `db:"secret"`
+		Events           string    `db:"events"`
+		SubscriptionDate time.Time `db:"subscription_date"`
+		Payload          string    `db:"payload"`
+		EventDate        time.Time `db:"event_date"`
+	}
+	if err := s.statements.Select(ctx, tx, &rows, query, args...); err != nil {
+		return []webhook.Job{}, errors.Wrap(err, "WebhookStore.ClaimDue failed")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return []webhook.Job{}, errors.Wrap(err, "WebhookStore.ClaimDue failed")
+	}
+
+	jobs := make([]webhook.Job, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, webhook.Job{
+			Delivery: row.delivery(),
+			Subscription: subscriptionRow{
+				ID:     row.SubscriptionID,
+				URL:    row.URL,
+				Secret: row.Secret,
+				Events: row.Events,
+				Date:   row.SubscriptionDate,
+			}.subscription(),
+			Event: outbox.Message{
+				ID:      row.EventID,
+				Event:   row.Event,
+				Payload: json.RawMessage(row.Payload),
+				Date:    row.EventDate,
+			},
+		})
+	}
+
+	return jobs, nil
+}
+
+// RecordAttempt saves the outcome of a delivery in db, along with its retry when given.
+func (s *WebhookStore) RecordAttempt(ctx context.Context, attempted webhook.Delivery, retry *webhook.Delivery) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "WebhookStore.RecordAttempt failed")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `UPDATE "webhook_delivery" SET "status" = ?, "response_code" = ?, "error" = ?, "attempted_at" = ? WHERE "id" = ?`
+
+	if _, err := s.statements.Exec(ctx, tx, query,
+		attempted.Status, attempted.ResponseCode, attempted.Error, attempted.AttemptedAt.UTC(), attempted.ID); err != nil {
+		return errors.Wrap(err, "WebhookStore.RecordAttempt failed")
+	}
+
+	if retry != nil {
+		if err := s.insertDelivery(ctx, tx, *retry); err != nil {
+			return errors.Wrap(err, "WebhookStore.RecordAttempt failed")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "WebhookStore.RecordAttempt failed")
+	}
+
+	return nil
+}
+
+func (s *WebhookStore) insertDelivery(ctx context.Context, tx *sqlx.Tx, d webhook.Delivery) error {
+	query := `INSERT INTO "webhook_delivery" ("subscription_id", "event_id", "event", "attempt", "status", "scheduled_at") VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := s.statements.Exec(ctx, tx, query, d.SubscriptionID, d.EventID, d.Event, d.Attempt, d.Status, d.ScheduledAt.UTC())
+	return err
+}
@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gritt/maskada/details/logging"
+	"github.com/gritt/maskada/details/recorder"
+)
+
+func TestHandler(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when a route is matched": func(t *testing.T) {
+			// arrange
+			spans := recorded(t)
+
+			router := chi.NewRouter()
+			router.Use(logging.Handler, Handler)
+			router.Get("/v1/transaction/{id}", func(w http.ResponseWriter, r *http.Request) {
+				assert.True(t, trace.SpanContextFromContext(r.Context()).IsValid())
+			})
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/v1/transaction/7", nil)
+			r.Header.Set(logging.RequestIDHeader, "client-id")
+
+			// act
+			router.ServeHTTP(rr, r)
+
+			// assert
+			got := spans()
+			assert.Len(t, got, 1)
+			assert.Equal(t, "GET /v1/transaction/{id}", got[0].Name)
+			assert.Equal(t, trace.SpanKindServer, got[0].SpanKind)
+			assert.Subset(t, got[0].Attributes, []attribute.KeyValue{
+				attribute.String("http.request.method", http.MethodGet),
+				attribute.String("http.route", "/v1/transaction/{id}"),
+				attribute.Int("http.response.status_code", http.StatusOK),
+				attribute.String("request_id", "client-id"),
+			})
+			assert.Equal(t, codes.Unset, got[0].Status.Code)
+		},
+		"when the client sends a traceparent": func(t *testing.T) {
+			// arrange
+			spans := recorded(t)
+
+			router := chi.NewRouter()
+			router.Use(Handler)
+			router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/healthz", nil)
+			r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+			// act
+			router.ServeHTTP(rr, r)
+
+			// assert
+			got := spans()
+			assert.Len(t, got, 1)
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", got[0].SpanContext.TraceID().String())
+			assert.Equal(t, "00f067aa0ba902b7", got[0].Parent.SpanID().String())
+		},
+		"when the request fails": func(t *testing.T) {
+			// arrange
+			spans := recorded(t)
+
+			router := chi.NewRouter()
+			router.Use(Handler)
+			router.Get("/readyz", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+
+			// act
+			router.ServeHTTP(rr, r)
+
+			// assert
+			got := spans()
+			assert.Len(t, got, 1)
+			assert.Equal(t, codes.Error, got[0].Status.Code)
+			assert.Equal(t, "Service Unavailable", got[0].Status.Description)
+		},
+		"when the route is not found": func(t *testing.T) {
+			// arrange
+			spans := recorded(t)
+
+			router := chi.NewRouter()
+			router.Use(Handler)
+			router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+
+			rr := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "/unknown", nil)
+
+			// act
+			router.ServeHTTP(rr, r)
+
+			// assert
+			got := spans()
+			assert.Len(t, got, 1)
+			assert.Equal(t, "GET "+recorder.UnmatchedRoute, got[0].Name)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Statements runs the SQL statements of a database, each in a span named after its type, eg: INSERT.
+type Statements struct {
+	system attribute.KeyValue
+}
+
+// NewStatements initialize the statements of a database system, eg: mysql, postgresql or sqlite.
+func NewStatements(system string) Statements {
+	return Statements{system: semconv.DBSystemKey.String(system)}
+}
+
+// Exec executes a statement with e, eg: a *sqlx.Tx.
+func (s Statements) Exec(ctx context.Context, e sqlx.ExecerContext, query string, args ...interface{}) (_ sql.Result, err error) {
+	ctx, span := s.start(ctx, query)
+	defer func() { end(span, err) }()
+
+	return e.ExecContext(ctx, query, args...)
+}
+
+// Get queries a single row with q, scanning it into dest.
+func (s Statements) Get(ctx context.Context, q sqlx.QueryerContext, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, span := s.start(ctx, query)
+	defer func() { end(span, err) }()
+
+	return sqlx.GetContext(ctx, q, dest, query, args...)
+}
+
+// Select queries rows with q, scanning them into dest.
+func (s Statements) Select(ctx context.Context, q sqlx.QueryerContext, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, span := s.start(ctx, query)
+	defer func() { end(span, err) }()
+
+	return sqlx.SelectContext(ctx, q, dest, query, args...)
+}
+
+func (s Statements) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation := operation(query)
+
+	return tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(s.system, semconv.DBOperationName(operation)),
+	)
+}
+
+// operation returns the type of a statement, eg: SELECT, from its first keyword.
+func operation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "UNKNOWN"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,127 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	_ "modernc.org/sqlite"
+)
+
+func TestStatements(t *testing.T) {
+	statements := NewStatements("sqlite")
+
+	tests := map[string]func(*testing.T, *sqlx.DB){
+		"when a statement is executed": func(t *testing.T, db *sqlx.DB) {
+			// arrange
+			spans := recorded(t)
+
+			// act
+			_, gotErr := statements.Exec(context.Background(), db, `INSERT INTO "category" ("name") VALUES (?)`, "Food")
+
+			// assert
+			assert.NoError(t, gotErr)
+
+			got := spans()
+			assert.Len(t, got, 1)
+			assert.Equal(t, "INSERT", got[0].Name)
+			assert.Equal(t, trace.SpanKindClient, got[0].SpanKind)
+			assert.Equal(t, []attribute.KeyValue{
+				attribute.String("db.system", "sqlite"),
+				attribute.String("db.operation.name", "INSERT"),
+			}, got[0].Attributes)
+		},
+		"when a row is got": func(t *testing.T, db *sqlx.DB) {
+			// arrange
+			spans := recorded(t)
+
+			// act
+			var name string
+			gotErr := statements.Get(context.Background(), db, &name, `
+				select "name" FROM "category" WHERE "name" = ?`, "Home")
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, "Home", name)
+			assert.Equal(t, "SELECT", spans()[0].Name)
+		},
+		"when rows are selected": func(t *testing.T, db *sqlx.DB) {
+			// arrange
+			spans := recorded(t)
+
+			// act
+			var names []string
+			gotErr := statements.Select(context.Background(), db, &names, `SELECT "name" FROM "category"`)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []string{"Home"}, names)
+			assert.Equal(t, "SELECT", spans()[0].Name)
+		},
+		"when the statement is run within a span": func(t *testing.T, db *sqlx.DB) {
+			// arrange
+			spans := recorded(t)
+			ctx, parent := tracer.Start(context.Background(), "ListTransactionUseCase.List")
+
+			// act
+			var names []string
+			gotErr := statements.Select(ctx, db, &names, `SELECT "name" FROM "category"`)
+			parent.End()
+
+			// assert
+			assert.NoError(t, gotErr)
+
+			got := spans()
+			assert.Len(t, got, 2)
+			assert.Equal(t, parent.SpanContext().SpanID(), got[0].Parent.SpanID())
+		},
+		"when the statement fails": func(t *testing.T, db *sqlx.DB) {
+			// arrange
+			spans := recorded(t)
+
+			// act
+			_, gotErr := statements.Exec(context.Background(), db, `DELETE FROM "unknown"`)
+
+			// assert
+			assert.Error(t, gotErr)
+
+			got := spans()
+			assert.Len(t, got, 1)
+			assert.Equal(t, "DELETE", got[0].Name)
+			assert.Equal(t, codes.Error, got[0].Status.Code)
+			assert.Equal(t, gotErr.Error(), got[0].Status.Description)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := sqlx.MustOpen("sqlite", ":memory:")
+			db.MustExec(`CREATE TABLE "category" ("name" TEXT PRIMARY KEY); INSERT INTO "category" VALUES ('Home')`)
+			t.Cleanup(func() { _ = db.Close() })
+
+			run(t, db)
+		})
+	}
+}
+
+func TestOperation(t *testing.T) {
+	tests := map[string]struct {
+		query string
+		want  string
+	}{
+		"when the keyword is upper case":     {query: "SELECT 1", want: "SELECT"},
+		"when the keyword is lower case":     {query: "insert into t values (1)", want: "INSERT"},
+		"when the query starts with a space": {query: "\n\t\tUPDATE t SET v = 1", want: "UPDATE"},
+		"when the query is empty":            {query: "", want: "UNKNOWN"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, operation(tt.query))
+		})
+	}
+}
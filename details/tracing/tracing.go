@@ -0,0 +1,71 @@
+// Package tracing traces the requests of the API, through its use cases down to the SQL statements,
+// exporting the spans over OTLP.
+package tracing
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gritt/maskada/details"
+)
+
+const (
+	// serviceName identifies the API in the traces.
+	serviceName = "maskada"
+
+	// tracesPath is where the collector receives traces, unless the endpoint has a path of its own.
+	tracesPath = "/v1/traces"
+)
+
+// tracer starts all spans, from the global tracer provider, a no-op one unless Init exports the traces.
+var tracer = otel.Tracer("github.com/gritt/maskada")
+
+// Init exports the traces to the collector at the configured endpoint, keeping the no-op tracer provider when
+// none is given. The returned func flushes the pending spans, to be called on exit.
+func Init(cfg *details.Config, version string) (func(context.Context) error, error) {
+	if cfg.Tracing.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	endpoint, err := url.Parse(cfg.Tracing.Endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "Init failed")
+	}
+	if endpoint.Path == "" || endpoint.Path == "/" {
+		endpoint.Path = tracesPath
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(endpoint.String()))
+	if err != nil {
+		return nil, errors.Wrap(err, "Init failed")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName), semconv.ServiceVersion(version))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return provider.Shutdown, nil
+}
+
+// end ends a span, recording the error it failed with, if any.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gritt/maskada/details"
+)
+
+// exporter records the spans ended by the tests, the global tracer provider being set once, as tracers keep
+// delegating to the first one set.
+var exporter = tracetest.NewInMemoryExporter()
+
+func init() {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+// recorded returns the spans ended so far by a test, which starts with none.
+func recorded(t *testing.T) func() tracetest.SpanStubs {
+	exporter.Reset()
+	t.Cleanup(exporter.Reset)
+	return exporter.GetSpans
+}
+
+func TestInit(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when no endpoint is given": func(t *testing.T) {
+			// arrange
+			provider := otel.GetTracerProvider()
+
+			// act
+			shutdown, gotErr := Init(&details.Config{}, "8f51ed8")
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.NoError(t, shutdown(context.Background()))
+			assert.Equal(t, provider, otel.GetTracerProvider())
+		},
+		"when an endpoint is given": func(t *testing.T) {
+			// arrange
+			provider := otel.GetTracerProvider()
+			t.Cleanup(func() { otel.SetTracerProvider(provider) })
+
+			cfg := &details.Config{}
+			cfg.Tracing.Endpoint = "http://localhost:4318"
+
+			// act
+			shutdown, gotErr := Init(cfg, "8f51ed8")
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+			assert.NotEqual(t, provider, otel.GetTracerProvider())
+			assert.NoError(t, shutdown(context.Background()))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/gritt/maskada/core"
+)
+
+type (
+	// Creator represents a use case able to create a transaction.
+	Creator interface {
+		Create(ctx context.Context, t core.Transaction, actor string) (core.Transaction, error)
+	}
+
+	// BatchCreator represents a use case able to create many transactions at once.
+	BatchCreator interface {
+		CreateAll(ctx context.Context, ts []core.Transaction, actor string) ([]core.BatchResult, error)
+		CreateValid(ctx context.Context, ts []core.Transaction, actor string) ([]core.BatchResult, error)
+	}
+
+	// Lister represents a use case able to list transactions.
+	Lister interface {
+		List(ctx context.Context) ([]core.Transaction, error)
+		ListMonth(ctx context.Context, month time.Time) ([]core.Transaction, error)
+		ListPage(ctx context.Context, f core.Filter, after core.Cursor, limit int) ([]core.Transaction, error)
+		Count(ctx context.Context, f core.Filter) (int, error)
+	}
+
+	// Getter represents a use case able to get a single transaction.
+	Getter interface {
+		Get(ctx context.Context, id int) (core.Transaction, error)
+	}
+
+	// Updater represents a use case able to change a transaction.
+	Updater interface {
+		Update(ctx context.Context, t core.Transaction, actor string) (core.Transaction, error)
+	}
+
+	// Deleter represents a use case able to remove a transaction.
+	Deleter interface {
+		Delete(ctx context.Context, id, version int, actor string) error
+	}
+
+	// HistoryLister represents a use case able to list the changes made to a transaction.
+	HistoryLister interface {
+		List(ctx context.Context, transactionID int) ([]core.AuditEntry, error)
+	}
+
+	// CategoriesLister represents a use case able to list categories.
+	CategoriesLister interface {
+		List(ctx context.Context) ([]core.Category, error)
+	}
+
+	// Totaler represents a use case able to sum the transactions of categories.
+	Totaler interface {
+		Totals(ctx context.Context, names []string) (map[string]core.Totals, error)
+	}
+
+	// LedgerReader represents a use case able to rebuild the transactions as they were known at a given time.
+	LedgerReader interface {
+		Ledger(ctx context.Context, asOf time.Time) ([]core.Transaction, error)
+		Summary(ctx context.Context, month, asOf time.Time) (core.MonthlySummary, error)
+	}
+
+	// Undoer represents a use case able to undo and redo the latest operations of an actor.
+	Undoer interface {
+		Undo(ctx context.Context, actor string) (core.JournalEntry, error)
+		Redo(ctx context.Context, actor string) (core.JournalEntry, error)
+	}
+
+	// TransactionCreator traces the transactions created by a use case.
+	TransactionCreator struct{ next Creator }
+
+	// TransactionBatchCreator traces the batches created by a use case.
+	TransactionBatchCreator struct{ next BatchCreator }
+
+	// TransactionLister traces the transactions listed by a use case.
+	TransactionLister struct{ next Lister }
+
+	// TransactionGetter traces the transactions got by a use case.
+	TransactionGetter struct{ next Getter }
+
+	// TransactionUpdater traces the transactions changed by a use case.
+	TransactionUpdater struct{ next Updater }
+
+	// TransactionDeleter traces the transactions removed by a use case.
+	TransactionDeleter struct{ next Deleter }
+
+	// TransactionHistoryLister traces the changes listed by a use case.
+	TransactionHistoryLister struct{ next HistoryLister }
+
+	// CategoryLister traces the categories listed by a use case.
+	CategoryLister struct{ next CategoriesLister }
+
+	// CategoryTotaler traces the category totals summed by a use case.
+	CategoryTotaler struct{ next Totaler }
+
+	// Ledger traces the transactions rebuilt by a use case.
+	Ledger struct{ next LedgerReader }
+
+	// Undo traces the operations undone and redone by a use case.
+	Undo struct{ next Undoer }
+)
+
+// NewTransactionCreator initialize the use case decorator.
+func NewTransactionCreator(next Creator) *TransactionCreator {
+	return &TransactionCreator{next: next}
+}
+
+// Create a transaction with the decorated use case, in a span of its own.
+func (c *TransactionCreator) Create(ctx context.Context, t core.Transaction, actor string) (_ core.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "CreateTransactionUseCase.Create")
+	defer func() { end(span, err) }()
+
+	return c.next.Create(ctx, t, actor)
+}
+
+// NewTransactionBatchCreator initialize the use case decorator.
+func NewTransactionBatchCreator(next BatchCreator) *TransactionBatchCreator {
+	return &TransactionBatchCreator{next: next}
+}
+
+// CreateAll creates a batch with the decorated use case, in a span of its own.
+func (c *TransactionBatchCreator) CreateAll(ctx context.Context, ts []core.Transaction, actor string) (_ []core.BatchResult, err error) {
+	ctx, span := tracer.Start(ctx, "CreateTransactionBatchUseCase.CreateAll")
+	defer func() { end(span, err) }()
+
+	return c.next.CreateAll(ctx, ts, actor)
+}
+
+// CreateValid creates the valid transactions of a batch with the decorated use case, in a span of its own.
+func (c *TransactionBatchCreator) CreateValid(ctx context.Context, ts []core.Transaction, actor string) (_ []core.BatchResult, err error) {
+	ctx, span := tracer.Start(ctx, "CreateTransactionBatchUseCase.CreateValid")
+	defer func() { end(span, err) }()
+
+	return c.next.CreateValid(ctx, ts, actor)
+}
+
+// NewTransactionLister initialize the use case decorator.
+func NewTransactionLister(next Lister) *TransactionLister {
+	return &TransactionLister{next: next}
+}
+
+// List the transactions with the decorated use case, in a span of its own.
+func (l *TransactionLister) List(ctx context.Context) (_ []core.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "ListTransactionUseCase.List")
+	defer func() { end(span, err) }()
+
+	return l.next.List(ctx)
+}
+
+// ListMonth lists the transactions of a month with the decorated use case, in a span of its own.
+func (l *TransactionLister) ListMonth(ctx context.Context, month time.Time) (_ []core.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "ListTransactionUseCase.ListMonth")
+	defer func() { end(span, err) }()
+
+	return l.next.ListMonth(ctx, month)
+}
+
+// ListPage lists a page of transactions with the decorated use case, in a span of its own.
+func (l *TransactionLister) ListPage(ctx context.Context, f core.Filter, after core.Cursor, limit int) (_ []core.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "ListTransactionUseCase.ListPage")
+	defer func() { end(span, err) }()
+
+	return l.next.ListPage(ctx, f, after, limit)
+}
+
+// Count counts the transactions matching the filter with the decorated use case, in a span of its own.
+func (l *TransactionLister) Count(ctx context.Context, f core.Filter) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "ListTransactionUseCase.Count")
+	defer func() { end(span, err) }()
+
+	return l.next.Count(ctx, f)
+}
+
+// NewTransactionGetter initialize the use case decorator.
+func NewTransactionGetter(next Getter) *TransactionGetter {
+	return &TransactionGetter{next: next}
+}
+
+// Get a transaction with the decorated use case, in a span of its own.
+func (g *TransactionGetter) Get(ctx context.Context, id int) (_ core.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "GetTransactionUseCase.Get")
+	defer func() { end(span, err) }()
+
+	return g.next.Get(ctx, id)
+}
+
+// NewTransactionUpdater initialize the use case decorator.
+func NewTransactionUpdater(next Updater) *TransactionUpdater {
+	return &TransactionUpdater{next: next}
+}
+
+// Update a transaction with the decorated use case, in a span of its own.
+func (u *TransactionUpdater) Update(ctx context.Context, t core.Transaction, actor string) (_ core.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "UpdateTransactionUseCase.Update")
+	defer func() { end(span, err) }()
+
+	return u.next.Update(ctx, t, actor)
+}
+
+// NewTransactionDeleter initialize the use case decorator.
+func NewTransactionDeleter(next Deleter) *TransactionDeleter {
+	return &TransactionDeleter{next: next}
+}
+
+// Delete a transaction with the decorated use case, in a span of its own.
+func (d *TransactionDeleter) Delete(ctx context.Context, id, version int, actor string) (err error) {
+	ctx, span := tracer.Start(ctx, "DeleteTransactionUseCase.Delete")
+	defer func() { end(span, err) }()
+
+	return d.next.Delete(ctx, id, version, actor)
+}
+
+// NewTransactionHistoryLister initialize the use case decorator.
+func NewTransactionHistoryLister(next HistoryLister) *TransactionHistoryLister {
+	return &TransactionHistoryLister{next: next}
+}
+
+// List the changes made to a transaction with the decorated use case, in a span of its own.
+func (l *TransactionHistoryLister) List(ctx context.Context, transactionID int) (_ []core.AuditEntry, err error) {
+	ctx, span := tracer.Start(ctx, "ListTransactionHistoryUseCase.List")
+	defer func() { end(span, err) }()
+
+	return l.next.List(ctx, transactionID)
+}
+
+// NewCategoryLister initialize the use case decorator.
+func NewCategoryLister(next CategoriesLister) *CategoryLister {
+	return &CategoryLister{next: next}
+}
+
+// List the categories with the decorated use case, in a span of its own.
+func (l *CategoryLister) List(ctx context.Context) (_ []core.Category, err error) {
+	ctx, span := tracer.Start(ctx, "ListCategoryUseCase.List")
+	defer func() { end(span, err) }()
+
+	return l.next.List(ctx)
+}
+
+// NewCategoryTotaler initialize the use case decorator.
+func NewCategoryTotaler(next Totaler) *CategoryTotaler {
+	return &CategoryTotaler{next: next}
+}
+
+// Totals sums the transactions of categories with the decorated use case, in a span of its own.
+func (t *CategoryTotaler) Totals(ctx context.Context, names []string) (_ map[string]core.Totals, err error) {
+	ctx, span := tracer.Start(ctx, "TotalCategoryUseCase.Totals")
+	defer func() { end(span, err) }()
+
+	return t.next.Totals(ctx, names)
+}
+
+// NewLedger initialize the use case decorator.
+func NewLedger(next LedgerReader) *Ledger {
+	return &Ledger{next: next}
+}
+
+// Ledger rebuilds the transactions with the decorated use case, in a span of its own.
+func (l *Ledger) Ledger(ctx context.Context, asOf time.Time) (_ []core.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "LedgerUseCase.Ledger")
+	defer func() { end(span, err) }()
+
+	return l.next.Ledger(ctx, asOf)
+}
+
+// Summary totals the rebuilt transactions of a month with the decorated use case, in a span of its own.
+func (l *Ledger) Summary(ctx context.Context, month, asOf time.Time) (_ core.MonthlySummary, err error) {
+	ctx, span := tracer.Start(ctx, "LedgerUseCase.Summary")
+	defer func() { end(span, err) }()
+
+	return l.next.Summary(ctx, month, asOf)
+}
+
+// NewUndo initialize the use case decorator.
+func NewUndo(next Undoer) *Undo {
+	return &Undo{next: next}
+}
+
+// Undo the latest operation of the actor with the decorated use case, in a span of its own.
+func (u *Undo) Undo(ctx context.Context, actor string) (_ core.JournalEntry, err error) {
+	ctx, span := tracer.Start(ctx, "UndoUseCase.Undo")
+	defer func() { end(span, err) }()
+
+	return u.next.Undo(ctx, actor)
+}
+
+// Redo the latest operation of the actor undone with the decorated use case, in a span of its own.
+func (u *Undo) Redo(ctx context.Context, actor string) (_ core.JournalEntry, err error) {
+	ctx, span := tracer.Start(ctx, "UndoUseCase.Redo")
+	defer func() { end(span, err) }()
+
+	return u.next.Redo(ctx, actor)
+}
@@ -0,0 +1,268 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gritt/maskada/core"
+)
+
+func TestUseCases(t *testing.T) {
+	tests := map[string]struct {
+		call     func(ctx context.Context, next *stubUseCase) error
+		wantSpan string
+	}{
+		"when a transaction is created": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewTransactionCreator(next).Create(ctx, core.Transaction{}, "tester")
+				return err
+			},
+			wantSpan: "CreateTransactionUseCase.Create",
+		},
+		"when a batch is created": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewTransactionBatchCreator(next).CreateAll(ctx, nil, "tester")
+				return err
+			},
+			wantSpan: "CreateTransactionBatchUseCase.CreateAll",
+		},
+		"when the valid transactions of a batch are created": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewTransactionBatchCreator(next).CreateValid(ctx, nil, "tester")
+				return err
+			},
+			wantSpan: "CreateTransactionBatchUseCase.CreateValid",
+		},
+		"when transactions are listed": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewTransactionLister(next).List(ctx)
+				return err
+			},
+			wantSpan: "ListTransactionUseCase.List",
+		},
+		"when the transactions of a month are listed": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewTransactionLister(next).ListMonth(ctx, time.Now())
+				return err
+			},
+			wantSpan: "ListTransactionUseCase.ListMonth",
+		},
+		"when a page of transactions is listed": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewTransactionLister(next).ListPage(ctx, core.Filter{}, core.Cursor{}, 100)
+				return err
+			},
+			wantSpan: "ListTransactionUseCase.ListPage",
+		},
+		"when transactions are counted": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewTransactionLister(next).Count(ctx, core.Filter{})
+				return err
+			},
+			wantSpan: "ListTransactionUseCase.Count",
+		},
+		"when a transaction is got": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewTransactionGetter(next).Get(ctx, 7)
+				return err
+			},
+			wantSpan: "GetTransactionUseCase.Get",
+		},
+		"when a transaction is updated": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewTransactionUpdater(next).Update(ctx, core.Transaction{}, "tester")
+				return err
+			},
+			wantSpan: "UpdateTransactionUseCase.Update",
+		},
+		"when a transaction is deleted": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				return NewTransactionDeleter(next).Delete(ctx, 7, 1, "tester")
+			},
+			wantSpan: "DeleteTransactionUseCase.Delete",
+		},
+		"when the history is listed": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewTransactionHistoryLister(historyLister{next}).List(ctx, 7)
+				return err
+			},
+			wantSpan: "ListTransactionHistoryUseCase.List",
+		},
+		"when the categories are listed": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewCategoryLister(categoryLister{next}).List(ctx)
+				return err
+			},
+			wantSpan: "ListCategoryUseCase.List",
+		},
+		"when the categories are totaled": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewCategoryTotaler(next).Totals(ctx, []string{"Food"})
+				return err
+			},
+			wantSpan: "TotalCategoryUseCase.Totals",
+		},
+		"when the ledger is rebuilt": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewLedger(next).Ledger(ctx, time.Now())
+				return err
+			},
+			wantSpan: "LedgerUseCase.Ledger",
+		},
+		"when the ledger is summarized": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewLedger(next).Summary(ctx, time.Now(), time.Now())
+				return err
+			},
+			wantSpan: "LedgerUseCase.Summary",
+		},
+		"when an operation is undone": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewUndo(next).Undo(ctx, "alice")
+				return err
+			},
+			wantSpan: "UndoUseCase.Undo",
+		},
+		"when an operation is redone": {
+			call: func(ctx context.Context, next *stubUseCase) error {
+				_, err := NewUndo(next).Redo(ctx, "alice")
+				return err
+			},
+			wantSpan: "UndoUseCase.Redo",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Run("when it succeeds", func(t *testing.T) {
+				// arrange
+				spans := recorded(t)
+				next := &stubUseCase{}
+
+				// act
+				gotErr := tt.call(context.Background(), next)
+
+				// assert
+				assert.NoError(t, gotErr)
+
+				got := spans()
+				assert.Len(t, got, 1)
+				assert.Equal(t, tt.wantSpan, got[0].Name)
+				assert.Equal(t, codes.Unset, got[0].Status.Code)
+				assert.Equal(t, got[0].SpanContext, next.spanContext)
+			})
+
+			t.Run("when it fails", func(t *testing.T) {
+				// arrange
+				spans := recorded(t)
+				next := &stubUseCase{err: errors.New("Repository.Find failed: connection refused")}
+
+				// act
+				gotErr := tt.call(context.Background(), next)
+
+				// assert
+				assert.Equal(t, next.err, gotErr)
+
+				got := spans()
+				assert.Len(t, got, 1)
+				assert.Equal(t, codes.Error, got[0].Status.Code)
+				assert.Equal(t, "Repository.Find failed: connection refused", got[0].Status.Description)
+			})
+		})
+	}
+}
+
+// stubUseCase fails with err, keeping the span it was called within.
+type stubUseCase struct {
+	err         error
+	spanContext trace.SpanContext
+}
+
+func (s *stubUseCase) called(ctx context.Context) error {
+	s.spanContext = trace.SpanContextFromContext(ctx)
+	return s.err
+}
+
+func (s *stubUseCase) Create(ctx context.Context, t core.Transaction, actor string) (core.Transaction, error) {
+	return t, s.called(ctx)
+}
+
+func (s *stubUseCase) CreateAll(ctx context.Context, ts []core.Transaction, actor string) ([]core.BatchResult, error) {
+	return nil, s.called(ctx)
+}
+
+func (s *stubUseCase) CreateValid(ctx context.Context, ts []core.Transaction, actor string) ([]core.BatchResult, error) {
+	return nil, s.called(ctx)
+}
+
+func (s *stubUseCase) List(ctx context.Context) ([]core.Transaction, error) {
+	return nil, s.called(ctx)
+}
+
+func (s *stubUseCase) ListMonth(ctx context.Context, month time.Time) ([]core.Transaction, error) {
+	return nil, s.called(ctx)
+}
+
+func (s *stubUseCase) ListPage(ctx context.Context, f core.Filter, after core.Cursor, limit int) ([]core.Transaction, error) {
+	return nil, s.called(ctx)
+}
+
+func (s *stubUseCase) Count(ctx context.Context, f core.Filter) (int, error) {
+	return 0, s.called(ctx)
+}
+
+func (s *stubUseCase) Get(ctx context.Context, id int) (core.Transaction, error) {
+	return core.Transaction{}, s.called(ctx)
+}
+
+func (s *stubUseCase) Update(ctx context.Context, t core.Transaction, actor string) (core.Transaction, error) {
+	return t, s.called(ctx)
+}
+
+func (s *stubUseCase) Delete(ctx context.Context, id, version int, actor string) error {
+	return s.called(ctx)
+}
+
+func (s *stubUseCase) Totals(ctx context.Context, names []string) (map[string]core.Totals, error) {
+	return nil, s.called(ctx)
+}
+
+func (s *stubUseCase) Ledger(ctx context.Context, asOf time.Time) ([]core.Transaction, error) {
+	return nil, s.called(ctx)
+}
+
+func (s *stubUseCase) Summary(ctx context.Context, month, asOf time.Time) (core.MonthlySummary, error) {
+	return core.MonthlySummary{}, s.called(ctx)
+}
+
+func (s *stubUseCase) Undo(ctx context.Context, actor string) (core.JournalEntry, error) {
+	return core.JournalEntry{}, s.called(ctx)
+}
+
+func (s *stubUseCase) Redo(ctx context.Context, actor string) (core.JournalEntry, error) {
+	return core.JournalEntry{}, s.called(ctx)
+}
+
+// historyLister adapts the stub to the history use case, whose List takes the transaction ID.
+type historyLister struct {
+	*stubUseCase
+}
+
+func (h historyLister) List(ctx context.Context, transactionID int) ([]core.AuditEntry, error) {
+	return nil, h.called(ctx)
+}
+
+// categoryLister adapts the stub to the category use case, whose List returns categories.
+type categoryLister struct {
+	*stubUseCase
+}
+
+func (c categoryLister) List(ctx context.Context) ([]core.Category, error) {
+	return nil, c.called(ctx)
+}
@@ -0,0 +1,416 @@
+// Package tui implements a full-screen terminal UI to browse, edit and categorize transactions.
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/cli"
+)
+
+const (
+	formPage = "form"
+
+	allCategories = "All"
+
+	browseHelp = "[::b]a[::-] add  [::b]e[::-] edit  [::b]c[::-] category  [::b][ ][::-] month  " +
+		"[::b]t[::-] triage  [::b]tab[::-] sidebar  [::b]r[::-] reload  [::b]q[::-] quit"
+	triageHelp = "[::b]1-9[::-] assign  [::b]c[::-] other category  [::b]e[::-] edit  [::b]t[::-] done  [::b]q[::-] quit"
+)
+
+// App is the terminal UI of a Store.
+type App struct {
+	store Store
+	model *model
+
+	app     *tview.Application
+	pages   *tview.Pages
+	header  *tview.TextView
+	sidebar *tview.List
+	table   *tview.Table
+	footer  *tview.TextView
+	status  *tview.TextView
+}
+
+// NewApp initialize the UI, showing the month of now.
+func NewApp(store Store, now time.Time) *App {
+	a := &App{
+		store:   store,
+		model:   newModel(now),
+		app:     tview.NewApplication(),
+		pages:   tview.NewPages(),
+		header:  tview.NewTextView().SetDynamicColors(true),
+		sidebar: tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true),
+		table:   tview.NewTable().SetSelectable(true, false).SetFixed(1, 0),
+		footer:  tview.NewTextView().SetDynamicColors(true),
+		status:  tview.NewTextView().SetDynamicColors(true),
+	}
+
+	a.sidebar.SetBorder(true).SetTitle(" Categories ")
+	a.sidebar.SetSelectedFunc(func(index int, main, secondary string, shortcut rune) {
+		a.model.category = ""
+		if index > 0 {
+			a.model.category = a.model.totals()[index-1].Name
+		}
+		a.refresh()
+		a.app.SetFocus(a.table)
+	})
+
+	a.table.SetBorder(true)
+	a.table.SetSelectedFunc(func(row, column int) {
+		a.edit()
+	})
+
+	body := tview.NewFlex().
+		AddItem(a.sidebar, 34, 0, false).
+		AddItem(a.table, 0, 1, true)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.header, 1, 0, false).
+		AddItem(body, 0, 1, true).
+		AddItem(a.status, 1, 0, false).
+		AddItem(a.footer, 1, 0, false)
+
+	a.pages.AddPage("main", layout, true, true)
+	a.app.SetRoot(a.pages, true).SetInputCapture(a.capture)
+
+	return a
+}
+
+// Run loads the transactions and shows the UI until it is quit.
+func (a *App) Run() error {
+	if err := a.reload(); err != nil {
+		return err
+	}
+	a.app.SetFocus(a.table)
+	return a.app.Run()
+}
+
+// capture handles the keys of the main page, leaving the form keys alone.
+func (a *App) capture(event *tcell.EventKey) *tcell.EventKey {
+	if name, _ := a.pages.GetFrontPage(); name == formPage {
+		return event
+	}
+
+	switch event.Key() {
+	case tcell.KeyTab, tcell.KeyBacktab:
+		if a.sidebar.HasFocus() {
+			a.app.SetFocus(a.table)
+		} else {
+			a.app.SetFocus(a.sidebar)
+		}
+		return nil
+	case tcell.KeyRune:
+	default:
+		return event
+	}
+
+	switch key := event.Rune(); {
+	case key == 'q':
+		a.app.Stop()
+	case key == 'r':
+		a.setStatus(a.reload())
+	case key == '[' && !a.model.triage:
+		a.model.previousMonth()
+		a.refresh()
+	case key == ']' && !a.model.triage:
+		a.model.nextMonth()
+		a.refresh()
+	case key == 't':
+		a.model.triage = !a.model.triage
+		a.table.Select(1, 0)
+		a.refresh()
+	case key == 'a':
+		a.add()
+	case key == 'e':
+		a.edit()
+	case key == 'c':
+		a.categorize()
+	case key >= '1' && key <= '9':
+		quick := a.model.quickCategories()
+		if i := int(key - '1'); i < len(quick) {
+			a.assign(quick[i])
+		}
+	default:
+		return event
+	}
+
+	return nil
+}
+
+func (a *App) reload() error {
+	trsl, err := a.store.List()
+	if err != nil {
+		return err
+	}
+	a.model.load(trsl)
+	a.refresh()
+	return nil
+}
+
+// refresh draws the model.
+func (a *App) refresh() {
+	a.drawHeader()
+	a.drawSidebar()
+	a.drawTable()
+	a.drawFooter()
+}
+
+func (a *App) drawHeader() {
+	income, expenses := 0, 0
+	for _, total := range a.model.totals() {
+		income += total.Income
+		expenses += total.Expenses
+	}
+
+	uncategorized := ""
+	if n := a.model.uncategorized(); n > 0 {
+		uncategorized = fmt.Sprintf("   [yellow]%d uncategorized[-]", n)
+	}
+
+	a.header.SetText(fmt.Sprintf(" [::b]%s[::-]   income %s   expenses %s   balance %s%s",
+		a.model.month.Format("January 2006"), cli.FormatAmount(income), cli.FormatAmount(expenses), cli.FormatAmount(income-expenses), uncategorized))
+}
+
+func (a *App) drawSidebar() {
+	current := 0
+
+	a.sidebar.Clear()
+	a.sidebar.AddItem(allCategories, "", 0, nil)
+	for i, total := range a.model.totals() {
+		a.sidebar.AddItem(fmt.Sprintf("%-18s %13s", tview.Escape(total.Name), cli.FormatAmount(total.Income-total.Expenses)), "", 0, nil)
+		if total.Name == a.model.category {
+			current = i + 1
+		}
+	}
+	a.sidebar.SetCurrentItem(current)
+}
+
+func (a *App) drawTable() {
+	row, _ := a.table.GetSelection()
+
+	title := fmt.Sprintf(" %s ", a.model.month.Format("January 2006"))
+	if a.model.category != "" {
+		title = fmt.Sprintf(" %s, %s ", a.model.month.Format("January 2006"), tview.Escape(a.model.category))
+	}
+	if a.model.triage {
+		title = " Uncategorized, of every month "
+	}
+	a.table.SetTitle(title)
+
+	a.table.Clear()
+	for column, name := range []string{"DATE", "TYPE", "CATEGORY", "AMOUNT", "NAME"} {
+		a.table.SetCell(0, column, tview.NewTableCell(name).SetSelectable(false).SetAttributes(tcell.AttrBold))
+	}
+
+	for i, t := range a.model.visible() {
+		category := tview.NewTableCell(tview.Escape(t.Category.Name))
+		if t.Category.Name == core.Uncategorized {
+			category.SetTextColor(tcell.ColorYellow)
+		}
+
+		a.table.SetCell(i+1, 0, tview.NewTableCell(t.Date.UTC().Format(dateLayout)))
+		a.table.SetCell(i+1, 1, tview.NewTableCell(typeName(t.Type)))
+		a.table.SetCell(i+1, 2, category)
+		a.table.SetCell(i+1, 3, tview.NewTableCell(cli.FormatAmount(t.Amount)).SetAlign(tview.AlignRight))
+		a.table.SetCell(i+1, 4, tview.NewTableCell(tview.Escape(t.Name)).SetExpansion(1))
+	}
+
+	if count := a.table.GetRowCount(); row >= count {
+		row = count - 1
+	}
+	if row < 1 {
+		row = 1
+	}
+	a.table.Select(row, 0)
+}
+
+func (a *App) drawFooter() {
+	if !a.model.triage {
+		a.footer.SetText(" " + browseHelp)
+		return
+	}
+
+	var keys []string
+	for i, name := range a.model.quickCategories() {
+		keys = append(keys, fmt.Sprintf("[::b]%d[::-] %s", i+1, tview.Escape(name)))
+	}
+	a.footer.SetText(" " + triageHelp + "   " + strings.Join(keys, "  "))
+}
+
+func (a *App) setStatus(err error) {
+	if err != nil {
+		a.status.SetText(" [red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	a.status.SetText("")
+}
+
+// selected returns the transaction of the selected row, if any.
+func (a *App) selected() (core.Transaction, bool) {
+	row, _ := a.table.GetSelection()
+	visible := a.model.visible()
+	if row < 1 || row > len(visible) {
+		return core.Transaction{}, false
+	}
+	return visible[row-1], true
+}
+
+// add shows the form of a new transaction, of the selected category and month.
+func (a *App) add() {
+	t := core.Transaction{Type: core.Debit, Category: core.Category{Name: a.model.category}}
+	if now := time.Now().UTC(); !a.model.inMonth(core.Transaction{Date: now}) {
+		t.Date = a.model.month
+	}
+
+	a.showForm(" Add ", t, a.store.Create)
+}
+
+// edit shows the form of the selected transaction.
+func (a *App) edit() {
+	t, ok := a.selected()
+	if !ok {
+		return
+	}
+
+	a.showForm(" Edit ", t, a.store.Update)
+}
+
+// categorize asks the category of the selected transaction, autocompleting the known ones.
+func (a *App) categorize() {
+	t, ok := a.selected()
+	if !ok {
+		return
+	}
+
+	form := tview.NewForm()
+	field := a.categoryField("Category", "")
+	form.AddFormItem(field)
+	form.AddButton("Save", func() {
+		if name := strings.TrimSpace(field.GetText()); name != "" {
+			a.closeForm()
+			a.assign(name)
+		}
+	})
+	form.AddButton("Cancel", a.closeForm)
+	form.SetCancelFunc(a.closeForm)
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" Category of %s ", tview.Escape(describe(t))))
+
+	a.openForm(form, 60, 7)
+}
+
+// assign changes the category of the selected transaction, keeping the selection on the same row,
+// which holds the next uncategorized transaction while triaging.
+func (a *App) assign(category string) {
+	t, ok := a.selected()
+	if !ok {
+		return
+	}
+
+	t.Category = core.Category{Name: category}
+	updated, err := a.store.Update(t)
+	a.setStatus(err)
+	if err != nil {
+		return
+	}
+
+	a.model.put(updated)
+	a.refresh()
+}
+
+func (a *App) showForm(title string, t core.Transaction, save func(core.Transaction) (core.Transaction, error)) {
+	f := newFields(t)
+
+	form := tview.NewForm()
+	form.AddInputField("Amount", f.Amount, 14, nil, nil)
+	form.AddDropDown("Type", typeNames, f.Type, nil)
+	form.AddFormItem(a.categoryField("Category", f.Category))
+	form.AddInputField("Date", f.Date, 12, nil, nil)
+	form.AddInputField("Name", f.Name, 40, nil, nil)
+
+	message := tview.NewTextView().SetDynamicColors(true)
+
+	form.AddButton("Save", func() {
+		typed := fields{
+			Amount:   form.GetFormItemByLabel("Amount").(*tview.InputField).GetText(),
+			Category: form.GetFormItemByLabel("Category").(*tview.InputField).GetText(),
+			Date:     form.GetFormItemByLabel("Date").(*tview.InputField).GetText(),
+			Name:     form.GetFormItemByLabel("Name").(*tview.InputField).GetText(),
+		}
+		typed.Type, _ = form.GetFormItemByLabel("Type").(*tview.DropDown).GetCurrentOption()
+
+		changed, err := typed.apply(t)
+		if err == nil {
+			changed, err = save(changed)
+		}
+		if err != nil {
+			message.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+			return
+		}
+
+		a.closeForm()
+		a.setStatus(nil)
+		a.model.put(changed)
+		a.refresh()
+	})
+	form.AddButton("Cancel", a.closeForm)
+	form.SetCancelFunc(a.closeForm)
+
+	content := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(form, 0, 1, true).
+		AddItem(message, 2, 0, false)
+	content.SetBorder(true).SetTitle(title)
+
+	a.openForm(content, 64, 17)
+}
+
+// categoryField is an input of a category, autocompleting the known ones.
+func (a *App) categoryField(label, value string) *tview.InputField {
+	field := tview.NewInputField().SetLabel(label).SetText(value).SetFieldWidth(30)
+	field.SetAutocompleteFunc(func(current string) []string {
+		if current == "" {
+			return nil
+		}
+		return a.model.categories(current)
+	})
+	field.SetAutocompletedFunc(func(text string, index, source int) bool {
+		field.SetText(text)
+		return source != tview.AutocompletedNavigate
+	})
+	return field
+}
+
+func (a *App) openForm(form tview.Primitive, width, height int) {
+	centered := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(form, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	a.pages.AddPage(formPage, centered, true, true)
+	a.app.SetFocus(form)
+}
+
+func (a *App) closeForm() {
+	a.pages.RemovePage(formPage)
+	a.app.SetFocus(a.table)
+}
+
+func typeName(typ int) string {
+	if typ < core.Debit || typ > core.Income {
+		return ""
+	}
+	return typeNames[typ-1]
+}
+
+// describe names a transaction in a title, eg: 2024-05-02 26.50 Family Flavor.
+func describe(t core.Transaction) string {
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s", t.Date.UTC().Format(dateLayout), cli.FormatAmount(t.Amount), t.Name))
+}
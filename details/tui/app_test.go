@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/events"
+	"github.com/gritt/maskada/details/memory"
+)
+
+func TestApp_triage(t *testing.T) {
+	// arrange
+	r := memory.NewRepository()
+	if _, err := r.CreateBatch(context.Background(), withoutIDs(testTrsList), "importer"); err != nil {
+		t.Fatalf("CreateBatch failed: %s", err)
+	}
+
+	a := NewApp(NewRepositoryStore(r, events.NewBus(), "tester"), testNow)
+	if err := a.reload(); err != nil {
+		t.Fatalf("reload failed: %s", err)
+	}
+
+	// act
+	a.capture(key('t'))
+	a.capture(key('2'))
+
+	// assert
+	assert.Equal(t, []int{4}, ids(a.model.visible()), "the next uncategorized transaction is left")
+
+	got, err := r.FindByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, core.Category{Name: "Home"}, got.Category)
+	assert.Equal(t, 2, got.Version)
+
+	history, err := r.FindHistory(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "tester", history[len(history)-1].Actor)
+
+	// act
+	a.capture(key('1'))
+	a.capture(key('t'))
+
+	// assert
+	assert.Equal(t, 0, a.model.uncategorized())
+	assert.Equal(t, []int{2, 3, 4, 5}, ids(a.model.visible()))
+}
+
+func TestApp_month(t *testing.T) {
+	// arrange
+	a := NewApp(NewRepositoryStore(memory.NewRepository(), events.NewBus(), "tester"), testNow)
+
+	// act
+	a.capture(key(']'))
+	a.capture(key(']'))
+	a.capture(key('['))
+
+	// assert
+	assert.Equal(t, "2024-06", a.model.month.Format("2006-01"))
+}
+
+func key(r rune) *tcell.EventKey {
+	return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+}
+
+func withoutIDs(trsl []core.Transaction) []core.Transaction {
+	var created []core.Transaction
+	for _, t := range trsl {
+		t.ID, t.Version = 0, 0
+		created = append(created, t)
+	}
+	return created
+}
@@ -0,0 +1,234 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/cli"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// maxQuickCategories is how many categories are assigned by a single key, 1 to 9.
+	maxQuickCategories = 9
+)
+
+// typeNames are the names of the transaction types, ordered as core.Debit, core.Credit and core.Income.
+var typeNames = []string{"debit", "credit", "income"}
+
+// categoryTotal is how much was spent and received in a category during a month.
+type categoryTotal struct {
+	Name     string
+	Count    int
+	Expenses int
+	Income   int
+}
+
+// model is the state of the UI, regardless of how it is drawn.
+type model struct {
+	transactions []core.Transaction
+	month        time.Time
+	category     string
+	triage       bool
+}
+
+func newModel(now time.Time) *model {
+	now = now.UTC()
+	return &model{month: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// load replaces the transactions, which must be ordered by date.
+func (m *model) load(trsl []core.Transaction) {
+	m.transactions = trsl
+}
+
+// put adds a new transaction, or replaces the previous version of a changed one, keeping them ordered by date.
+func (m *model) put(t core.Transaction) {
+	for i := range m.transactions {
+		if m.transactions[i].ID == t.ID {
+			m.transactions = append(m.transactions[:i], m.transactions[i+1:]...)
+			break
+		}
+	}
+
+	m.transactions = append(m.transactions, t)
+	sort.SliceStable(m.transactions, func(i, j int) bool {
+		if m.transactions[i].Date.Equal(m.transactions[j].Date) {
+			return m.transactions[i].ID < m.transactions[j].ID
+		}
+		return m.transactions[i].Date.Before(m.transactions[j].Date)
+	})
+}
+
+func (m *model) previousMonth() {
+	m.month = m.month.AddDate(0, -1, 0)
+}
+
+func (m *model) nextMonth() {
+	m.month = m.month.AddDate(0, 1, 0)
+}
+
+// inMonth tells whether a transaction happened in the current month, in UTC.
+func (m *model) inMonth(t core.Transaction) bool {
+	return core.InMonth(t, m.month)
+}
+
+// visible returns the transactions to list: the uncategorized ones of every month when triaging,
+// otherwise the ones of the current month, of the selected category if any.
+func (m *model) visible() []core.Transaction {
+	var trsl []core.Transaction
+	for _, t := range m.transactions {
+		if m.triage {
+			if t.Category.Name == core.Uncategorized {
+				trsl = append(trsl, t)
+			}
+			continue
+		}
+		if !m.inMonth(t) {
+			continue
+		}
+		if m.category != "" && t.Category.Name != m.category {
+			continue
+		}
+		trsl = append(trsl, t)
+	}
+	return trsl
+}
+
+// totals returns the totals of each category of the current month, by name.
+func (m *model) totals() []categoryTotal {
+	byName := map[string]*categoryTotal{}
+	for _, t := range m.transactions {
+		if !m.inMonth(t) {
+			continue
+		}
+
+		total, ok := byName[t.Category.Name]
+		if !ok {
+			total = &categoryTotal{Name: t.Category.Name}
+			byName[t.Category.Name] = total
+		}
+
+		total.Count++
+		if t.Type == core.Income {
+			total.Income += t.Amount
+		} else {
+			total.Expenses += t.Amount
+		}
+	}
+
+	totals := make([]categoryTotal, 0, len(byName))
+	for _, total := range byName {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		return totals[i].Name < totals[j].Name
+	})
+
+	return totals
+}
+
+// uncategorized counts the transactions to triage, of every month.
+func (m *model) uncategorized() int {
+	n := 0
+	for _, t := range m.transactions {
+		if t.Category.Name == core.Uncategorized {
+			n++
+		}
+	}
+	return n
+}
+
+// quickCategories returns the most used categories, which are assigned by the keys 1 to 9 while triaging.
+func (m *model) quickCategories() []string {
+	counts := map[string]int{}
+	for _, t := range m.transactions {
+		if t.Category.Name != core.Uncategorized {
+			counts[t.Category.Name]++
+		}
+	}
+
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] == counts[names[j]] {
+			return names[i] < names[j]
+		}
+		return counts[names[i]] > counts[names[j]]
+	})
+
+	if len(names) > maxQuickCategories {
+		names = names[:maxQuickCategories]
+	}
+	return names
+}
+
+// categories returns the known categories starting with prefix, regardless of case, to autocomplete it.
+func (m *model) categories(prefix string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, t := range m.transactions {
+		name := t.Category.Name
+		if seen[name] || name == core.Uncategorized || !strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fields are the editable values of a transaction, as typed in a form.
+type fields struct {
+	Amount   string
+	Type     int
+	Category string
+	Date     string
+	Name     string
+}
+
+func newFields(t core.Transaction) fields {
+	f := fields{Category: t.Category.Name, Name: t.Name}
+	if t.Amount != 0 {
+		f.Amount = cli.FormatAmount(t.Amount)
+	}
+	if t.Type != 0 {
+		f.Type = t.Type - 1
+	}
+	if !t.Date.IsZero() {
+		f.Date = t.Date.UTC().Format(dateLayout)
+	}
+	return f
+}
+
+// apply changes a transaction with the typed fields, validating the result.
+func (f fields) apply(t core.Transaction) (core.Transaction, error) {
+	amount, err := cli.ParseAmount(strings.TrimSpace(f.Amount))
+	if err != nil {
+		return core.Transaction{}, err
+	}
+	t.Amount = amount
+	t.Type = f.Type + 1
+	t.Category = core.Category{Name: strings.TrimSpace(f.Category)}
+	t.Name = strings.TrimSpace(f.Name)
+
+	t.Date = time.Time{}
+	if date := strings.TrimSpace(f.Date); date != "" {
+		if t.Date, err = time.Parse(dateLayout, date); err != nil {
+			return core.Transaction{}, fmt.Errorf("invalid date %s, expected YYYY-MM-DD", date)
+		}
+	}
+
+	if err := t.Validate(); err != nil {
+		return core.Transaction{}, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,209 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+)
+
+var (
+	testNow = time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+
+	testTrsList = []core.Transaction{
+		{ID: 1, Amount: 4000, Type: core.Credit, Category: core.Category{Name: core.Uncategorized}, Date: time.Date(2024, 4, 28, 0, 0, 0, 0, time.UTC), Name: "Market", Version: 1},
+		{ID: 2, Amount: 2650, Type: core.Credit, Category: core.Category{Name: "Food"}, Date: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), Name: "Family Flavor", Version: 1},
+		{ID: 3, Amount: 500000, Type: core.Income, Category: core.Category{Name: "Work"}, Date: time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC), Name: "Salary", Version: 1},
+		{ID: 4, Amount: 1230, Type: core.Debit, Category: core.Category{Name: core.Uncategorized}, Date: time.Date(2024, 5, 9, 0, 0, 0, 0, time.UTC), Name: "Bakery", Version: 1},
+		{ID: 5, Amount: 900, Type: core.Debit, Category: core.Category{Name: "Food"}, Date: time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC), Name: "Bakery", Version: 1},
+		{ID: 6, Amount: 120000, Type: core.Debit, Category: core.Category{Name: "Home"}, Date: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Name: "Rent", Version: 1},
+	}
+)
+
+func TestModel_visible(t *testing.T) {
+	tests := map[string]func(*testing.T, *model){
+		"when browsing, list the current month": func(t *testing.T, m *model) {
+			// assert
+			assert.Equal(t, []int{2, 3, 4, 5}, ids(m.visible()))
+		},
+		"when browsing another month": func(t *testing.T, m *model) {
+			// act
+			m.nextMonth()
+
+			// assert
+			assert.Equal(t, []int{6}, ids(m.visible()))
+		},
+		"when a category is selected": func(t *testing.T, m *model) {
+			// act
+			m.category = "Food"
+
+			// assert
+			assert.Equal(t, []int{2, 5}, ids(m.visible()))
+		},
+		"when triaging, list the uncategorized ones of every month": func(t *testing.T, m *model) {
+			// act
+			m.triage = true
+			m.category = "Food"
+
+			// assert
+			assert.Equal(t, []int{1, 4}, ids(m.visible()))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newModel(testNow)
+			m.load(testTrsList)
+
+			run(t, m)
+		})
+	}
+}
+
+func TestModel_totals(t *testing.T) {
+	// arrange
+	m := newModel(testNow)
+	m.load(testTrsList)
+
+	// act
+	got := m.totals()
+
+	// assert
+	assert.Equal(t, []categoryTotal{
+		{Name: "Food", Count: 2, Expenses: 3550},
+		{Name: core.Uncategorized, Count: 1, Expenses: 1230},
+		{Name: "Work", Count: 1, Income: 500000},
+	}, got)
+	assert.Equal(t, 2, m.uncategorized())
+}
+
+func TestModel_put(t *testing.T) {
+	tests := map[string]func(*testing.T, *model){
+		"when changed, replace it": func(t *testing.T, m *model) {
+			// arrange
+			changed := testTrsList[3]
+			changed.Category = core.Category{Name: "Food"}
+			changed.Version = 2
+
+			// act
+			m.put(changed)
+
+			// assert
+			assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, ids(m.transactions))
+			assert.Equal(t, changed, m.transactions[3])
+		},
+		"when the date changed, keep them ordered": func(t *testing.T, m *model) {
+			// arrange
+			changed := testTrsList[0]
+			changed.Date = time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)
+
+			// act
+			m.put(changed)
+
+			// assert
+			assert.Equal(t, []int{2, 1, 3, 4, 5, 6}, ids(m.transactions))
+		},
+		"when new, add it": func(t *testing.T, m *model) {
+			// act
+			m.put(core.Transaction{ID: 7, Date: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)})
+
+			// assert
+			assert.Equal(t, []int{1, 2, 7, 3, 4, 5, 6}, ids(m.transactions))
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newModel(testNow)
+			m.load(append([]core.Transaction{}, testTrsList...))
+
+			run(t, m)
+		})
+	}
+}
+
+func TestModel_categories(t *testing.T) {
+	// arrange
+	m := newModel(testNow)
+	m.load(append(testTrsList, core.Transaction{ID: 7, Category: core.Category{Name: "Health"}}))
+
+	// act & assert
+	assert.Equal(t, []string{"Food", "Health", "Home", "Work"}, m.quickCategories())
+	assert.Equal(t, []string{"Health", "Home"}, m.categories("h"))
+	assert.Empty(t, m.categories("unc"))
+}
+
+func TestFields_apply(t *testing.T) {
+	tests := map[string]func(*testing.T){
+		"when editing, keep the id and version": func(t *testing.T) {
+			// arrange
+			given := newFields(testTrsList[3])
+			given.Amount = "15.5"
+			given.Type = 1
+			given.Category = " Food "
+
+			// act
+			got, gotErr := given.apply(testTrsList[3])
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, core.Transaction{
+				ID:       4,
+				Amount:   1550,
+				Type:     core.Credit,
+				Category: core.Category{Name: "Food"},
+				Date:     testTrsList[3].Date,
+				Name:     "Bakery",
+				Version:  1,
+			}, got)
+		},
+		"when no date is given, leave it zero": func(t *testing.T) {
+			// arrange
+			given := fields{Amount: "1", Category: "Food"}
+
+			// act
+			got, gotErr := given.apply(core.Transaction{})
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.True(t, got.Date.IsZero())
+			assert.Equal(t, core.Debit, got.Type)
+		},
+		"when invalid": func(t *testing.T) {
+			// arrange
+			given := fields{Amount: "1", Category: ""}
+
+			// act
+			_, gotErr := given.apply(core.Transaction{})
+
+			// assert
+			assert.EqualError(t, gotErr, "Transaction.Validate: invalid category")
+		},
+		"when the date is invalid": func(t *testing.T) {
+			// arrange
+			given := fields{Amount: "1", Category: "Food", Date: "May 2"}
+
+			// act
+			_, gotErr := given.apply(core.Transaction{})
+
+			// assert
+			assert.EqualError(t, gotErr, "invalid date May 2, expected YYYY-MM-DD")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t)
+		})
+	}
+}
+
+func ids(trsl []core.Transaction) []int {
+	var ids []int
+	for _, t := range trsl {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"context"
+
+	"github.com/gritt/maskada/core"
+)
+
+// Store represents a client able to list, create and change transactions,
+// such as the API client, or a local repository through NewRepositoryStore.
+type Store interface {
+	List() ([]core.Transaction, error)
+	Create(t core.Transaction) (core.Transaction, error)
+	Update(t core.Transaction) (core.Transaction, error)
+}
+
+// repositoryStore changes a local repository through the use cases, on behalf of an actor.
+type repositoryStore struct {
+	creator *core.CreateTransactionUseCase
+	lister  *core.ListTransactionUseCase
+	updater *core.UpdateTransactionUseCase
+	actor   string
+}
+
+// NewRepositoryStore initialize a store of a local repository, eg: a SQLite file, without a running API, publishing
+// the events of its changes with p.
+func NewRepositoryStore(r core.Repository, p core.Publisher, actor string) Store {
+	return &repositoryStore{
+		creator: core.NewCreateTransactionUseCase(r, p),
+		lister:  core.NewListTransactionUseCase(r),
+		updater: core.NewUpdateTransactionUseCase(r, p),
+		actor:   actor,
+	}
+}
+
+// List all transactions, ordered by date.
+func (s *repositoryStore) List() ([]core.Transaction, error) {
+	return s.lister.List(context.Background())
+}
+
+// Create a transaction on behalf of the actor.
+func (s *repositoryStore) Create(t core.Transaction) (core.Transaction, error) {
+	return s.creator.Create(context.Background(), t, s.actor)
+}
+
+// Update a transaction on behalf of the actor, given its Version is the current one.
+func (s *repositoryStore) Update(t core.Transaction) (core.Transaction, error) {
+	return s.updater.Update(context.Background(), t, s.actor)
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/events"
+	"github.com/gritt/maskada/details/memory"
+)
+
+func TestRepositoryStore(t *testing.T) {
+	tests := map[string]func(*testing.T, Store, *memory.Repository){
+		"when created, list it": func(t *testing.T, s Store, r *memory.Repository) {
+			// act
+			created, gotErr := s.Create(core.Transaction{Amount: 100, Type: core.Debit, Category: core.Category{Name: "Food"}})
+
+			// assert
+			assert.NoError(t, gotErr)
+
+			got, err := s.List()
+			assert.NoError(t, err)
+			assert.Equal(t, []core.Transaction{created}, got)
+		},
+		"when updated, record the actor": func(t *testing.T, s Store, r *memory.Repository) {
+			// arrange
+			created, err := s.Create(core.Transaction{Amount: 100, Type: core.Debit, Category: core.Category{Name: core.Uncategorized}})
+			if err != nil {
+				t.Fatalf("Create failed: %s", err)
+			}
+
+			given := created
+			given.Category = core.Category{Name: "Food"}
+
+			// act
+			got, gotErr := s.Update(given)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, 2, got.Version)
+
+			history, err := r.FindHistory(context.Background(), created.ID)
+			assert.NoError(t, err)
+			if assert.Len(t, history, 2) {
+				assert.Equal(t, "tester", history[1].Actor)
+			}
+		},
+		"when invalid, validate it as the API does": func(t *testing.T, s Store, r *memory.Repository) {
+			// act
+			_, gotErr := s.Create(core.Transaction{Amount: 100, Type: core.Debit})
+
+			// assert
+			assert.EqualError(t, gotErr, "Create failed: Transaction.Validate: invalid category")
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := memory.NewRepository()
+			run(t, NewRepositoryStore(r, events.NewBus(), "tester"), r)
+		})
+	}
+}
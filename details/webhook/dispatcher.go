@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gritt/maskada/details"
+)
+
+const (
+	// batchSize is the maximum number of events fanned out, and deliveries attempted, by a single query.
+	batchSize = 100
+
+	// maxBackoff caps the delay between the attempts of a delivery.
+	maxBackoff = time.Hour
+
+	// maxErrorLength is the size limit of the error of a delivery, as enforced by the db schemas.
+	maxErrorLength = 255
+
+	userAgent = "maskada-webhook"
+)
+
+// Dispatcher delivers the events of the outbox to their subscriptions, retrying the failed deliveries with an
+// exponential backoff until they succeed or run out of attempts.
+type Dispatcher struct {
+	store       Store
+	client      *http.Client
+	lease       time.Duration
+	interval    time.Duration
+	backoff     time.Duration
+	maxAttempts int
+	now         func() time.Time
+}
+
+// NewDispatcher initialize the dispatcher.
+func NewDispatcher(store Store, cfg *details.Config) *Dispatcher {
+	return &Dispatcher{
+		store:       store,
+		client:      &http.Client{Timeout: cfg.Webhook.Timeout},
+		lease:       cfg.Webhook.Timeout * batchSize,
+		interval:    cfg.Webhook.Interval,
+		backoff:     cfg.Webhook.Backoff,
+		maxAttempts: cfg.Webhook.MaxAttempts,
+		now:         func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run dispatches the outbox every interval until ctx is done, logging the failed rounds.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "dispatching the webhooks failed", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch fans the outbox out to the subscriptions, then attempts the due deliveries, once.
+func (d *Dispatcher) Dispatch(ctx context.Context) error {
+	now := d.now()
+
+	for {
+		n, err := d.store.Fanout(ctx, now, batchSize)
+		if err != nil {
+			return errors.Wrap(err, "Dispatch failed")
+		}
+		if n < batchSize {
+			break
+		}
+	}
+
+	// the lease outlasts the attempts of a whole batch, so another instance claims them again only when this one is gone
+	jobs, err := d.store.ClaimDue(ctx, now, d.lease, batchSize)
+	if err != nil {
+		return errors.Wrap(err, "Dispatch failed")
+	}
+
+	for _, job := range jobs {
+		attempted := d.deliver(ctx, job)
+
+		var retry *Delivery
+		if attempted.Status == Failed && attempted.Attempt < d.maxAttempts {
+			retry = &Delivery{
+				SubscriptionID: attempted.SubscriptionID,
+				EventID:        attempted.EventID,
+				Event:          attempted.Event,
+				Attempt:        attempted.Attempt + 1,
+				Status:         Pending,
+				ScheduledAt:    attempted.AttemptedAt.Add(d.delay(attempted.Attempt)),
+			}
+		}
+
+		if err := d.store.RecordAttempt(ctx, attempted, retry); err != nil {
+			return errors.Wrap(err, "Dispatch failed")
+		}
+
+		logAttempt(ctx, attempted, retry)
+	}
+
+	return nil
+}
+
+// deliver posts the signed event of a job to its subscription, returning the attempted delivery.
+func (d *Dispatcher) deliver(ctx context.Context, job Job) Delivery {
+	attempted := job.Delivery
+	attempted.AttemptedAt = d.now()
+	attempted.Status = Failed
+
+	body, _ := json.Marshal(job.Event)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.Subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		attempted.Error = truncate(err.Error())
+		return attempted
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(EventHeader, job.Event.Event)
+	req.Header.Set(EventIDHeader, strconv.Itoa(job.Event.ID))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(attempted.AttemptedAt.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(job.Subscription.Secret, attempted.AttemptedAt, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		attempted.Error = truncate(err.Error())
+		return attempted
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	attempted.ResponseCode = res.StatusCode
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		attempted.Error = truncate("unexpected status " + res.Status)
+		return attempted
+	}
+
+	attempted.Status = Delivered
+	return attempted
+}
+
+// delay is how long to wait after a failed attempt before the next one, doubling each attempt up to maxBackoff.
+func (d *Dispatcher) delay(attempt int) time.Duration {
+	delay := d.backoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+func logAttempt(ctx context.Context, attempted Delivery, retry *Delivery) {
+	attrs := []interface{}{
+		"subscription_id", attempted.SubscriptionID,
+		"event_id", attempted.EventID,
+		"event", attempted.Event,
+		"attempt", attempted.Attempt,
+		"status_code", attempted.ResponseCode,
+	}
+
+	if attempted.Status == Delivered {
+		slog.InfoContext(ctx, "webhook delivered", attrs...)
+		return
+	}
+
+	attrs = append(attrs, "error", attempted.Error)
+	if retry != nil {
+		attrs = append(attrs, "retry_at", retry.ScheduledAt)
+	}
+	slog.WarnContext(ctx, "webhook delivery failed", attrs...)
+}
+
+func truncate(msg string) string {
+	if len(msg) > maxErrorLength {
+		return msg[:maxErrorLength]
+	}
+	return msg
+}
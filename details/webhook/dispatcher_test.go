@@ -0,0 +1,244 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gritt/maskada/details"
+	"github.com/gritt/maskada/details/outbox"
+)
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cfg := &details.Config{}
+	cfg.Webhook.Timeout = time.Second
+	cfg.Webhook.Interval = time.Second
+	cfg.Webhook.Backoff = time.Minute
+	cfg.Webhook.MaxAttempts = 3
+
+	newJob := func(url string, attempt int) Job {
+		return Job{
+			Delivery: Delivery{
+				ID:             7,
+				SubscriptionID: 1,
+				EventID:        5,
+				Event:          TransactionCreated,
+				Attempt:        attempt,
+				Status:         Sending,
+				ScheduledAt:    now,
+			},
+			Subscription: Subscription{ID: 1, URL: url, Secret: "secret", Events: []string{TransactionCreated}},
+			Event:        outbox.Message{ID: 5, Event: TransactionCreated, Payload: json.RawMessage(`{"id":3}`), Date: now},
+		}
+	}
+
+	newDispatcher := func(m *mockStore) *Dispatcher {
+		d := NewDispatcher(m, cfg)
+		d.now = func() time.Time { return now }
+		return d
+	}
+
+	respond := func(status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+	}
+
+	tests := map[string]func(t *testing.T, m *mockStore){
+		"when fanout fails": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Fanout", now, batchSize).Return(0, errors.New("WebhookStore.Fanout failed: err"))
+
+			// act
+			gotErr := newDispatcher(m).Dispatch(context.Background())
+
+			// assert
+			assert.EqualError(t, gotErr, "Dispatch failed: WebhookStore.Fanout failed: err")
+		},
+		"when outbox exceeds a batch": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Fanout", now, batchSize).Return(batchSize, nil).Twice()
+			m.On("Fanout", now, batchSize).Return(1, nil).Once()
+			m.On("ClaimDue", now, 100*time.Second, batchSize).Return([]Job{}, nil)
+
+			// act
+			gotErr := newDispatcher(m).Dispatch(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			m.AssertNumberOfCalls(t, "Fanout", 3)
+		},
+		"when finding due deliveries fails": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("Fanout", now, batchSize).Return(0, nil)
+			m.On("ClaimDue", now, 100*time.Second, batchSize).Return([]Job{}, errors.New("WebhookStore.ClaimDue failed: err"))
+
+			// act
+			gotErr := newDispatcher(m).Dispatch(context.Background())
+
+			// assert
+			assert.EqualError(t, gotErr, "Dispatch failed: WebhookStore.ClaimDue failed: err")
+		},
+		"when delivery succeeds": func(t *testing.T, m *mockStore) {
+			// arrange
+			var got *http.Request
+			var gotBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			job := newJob(server.URL, 1)
+
+			want := job.Delivery
+			want.Status = Delivered
+			want.ResponseCode = http.StatusNoContent
+			want.AttemptedAt = now
+
+			m.On("Fanout", now, batchSize).Return(1, nil)
+			m.On("ClaimDue", now, 100*time.Second, batchSize).Return([]Job{job}, nil)
+			m.On("RecordAttempt", want, (*Delivery)(nil)).Return(nil)
+
+			// act
+			gotErr := newDispatcher(m).Dispatch(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			m.AssertExpectations(t)
+
+			assert.Equal(t, http.MethodPost, got.Method)
+			assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+			assert.Equal(t, TransactionCreated, got.Header.Get(EventHeader))
+			assert.Equal(t, "5", got.Header.Get(EventIDHeader))
+			assert.Equal(t, strconv.FormatInt(now.Unix(), 10), got.Header.Get(TimestampHeader))
+			assert.Equal(t, Sign("secret", now, gotBody), got.Header.Get(SignatureHeader))
+			assert.JSONEq(t, `{"id": 5, "event": "transaction.created", "date": "2026-01-02T03:04:05Z", "data": {"id": 3}}`, string(gotBody))
+		},
+		"when delivery fails": func(t *testing.T, m *mockStore) {
+			// arrange
+			server := respond(http.StatusServiceUnavailable)
+			defer server.Close()
+
+			job := newJob(server.URL, 2)
+
+			want := job.Delivery
+			want.Status = Failed
+			want.ResponseCode = http.StatusServiceUnavailable
+			want.Error = "unexpected status 503 Service Unavailable"
+			want.AttemptedAt = now
+
+			retry := &Delivery{
+				SubscriptionID: 1,
+				EventID:        5,
+				Event:          TransactionCreated,
+				Attempt:        3,
+				Status:         Pending,
+				ScheduledAt:    now.Add(2 * time.Minute),
+			}
+
+			m.On("Fanout", now, batchSize).Return(1, nil)
+			m.On("ClaimDue", now, 100*time.Second, batchSize).Return([]Job{job}, nil)
+			m.On("RecordAttempt", want, retry).Return(nil)
+
+			// act
+			gotErr := newDispatcher(m).Dispatch(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			m.AssertExpectations(t)
+		},
+		"when delivery fails the last attempt": func(t *testing.T, m *mockStore) {
+			// arrange
+			server := respond(http.StatusInternalServerError)
+			defer server.Close()
+
+			job := newJob(server.URL, cfg.Webhook.MaxAttempts)
+
+			want := job.Delivery
+			want.Status = Failed
+			want.ResponseCode = http.StatusInternalServerError
+			want.Error = "unexpected status 500 Internal Server Error"
+			want.AttemptedAt = now
+
+			m.On("Fanout", now, batchSize).Return(1, nil)
+			m.On("ClaimDue", now, 100*time.Second, batchSize).Return([]Job{job}, nil)
+			m.On("RecordAttempt", want, (*Delivery)(nil)).Return(nil)
+
+			// act
+			gotErr := newDispatcher(m).Dispatch(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			m.AssertExpectations(t)
+		},
+		"when subscriber is unreachable": func(t *testing.T, m *mockStore) {
+			// arrange
+			server := respond(http.StatusOK)
+			server.Close()
+
+			job := newJob(server.URL, 1)
+
+			m.On("Fanout", now, batchSize).Return(1, nil)
+			m.On("ClaimDue", now, 100*time.Second, batchSize).Return([]Job{job}, nil)
+			m.On("RecordAttempt", mock.MatchedBy(func(d Delivery) bool {
+				return d.Status == Failed && d.ResponseCode == 0 && d.Error != ""
+			}), mock.MatchedBy(func(d *Delivery) bool {
+				return d != nil && d.Attempt == 2 && d.ScheduledAt.Equal(now.Add(time.Minute))
+			})).Return(nil)
+
+			// act
+			gotErr := newDispatcher(m).Dispatch(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			m.AssertExpectations(t)
+		},
+		"when recording the attempt fails": func(t *testing.T, m *mockStore) {
+			// arrange
+			server := respond(http.StatusOK)
+			defer server.Close()
+
+			m.On("Fanout", now, batchSize).Return(1, nil)
+			m.On("ClaimDue", now, 100*time.Second, batchSize).Return([]Job{newJob(server.URL, 1), newJob(server.URL, 1)}, nil)
+			m.On("RecordAttempt", mock.Anything, mock.Anything).Return(errors.New("WebhookStore.RecordAttempt failed: err"))
+
+			// act
+			gotErr := newDispatcher(m).Dispatch(context.Background())
+
+			// assert
+			assert.EqualError(t, gotErr, "Dispatch failed: WebhookStore.RecordAttempt failed: err")
+			m.AssertNumberOfCalls(t, "RecordAttempt", 1)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, new(mockStore))
+		})
+	}
+}
+
+func TestDispatcher_delay(t *testing.T) {
+	// arrange
+	d := &Dispatcher{backoff: 30 * time.Second}
+
+	// act & assert
+	assert.Equal(t, 30*time.Second, d.delay(1))
+	assert.Equal(t, time.Minute, d.delay(2))
+	assert.Equal(t, 4*time.Minute, d.delay(4))
+	assert.Equal(t, maxBackoff, d.delay(8))
+	assert.Equal(t, maxBackoff, d.delay(100))
+}
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxDeliveries is the number of latest deliveries listed by subscription.
+	maxDeliveries = 50
+
+	// MaxURLLength is the size limit of the URL of a subscription, as enforced by the db schemas.
+	MaxURLLength = 2048
+)
+
+// Service manages the subscriptions and lists their deliveries.
+type Service struct {
+	store Store
+}
+
+// NewService initialize the service.
+func NewService(store Store) *Service {
+	return &Service{store: store}
+}
+
+// Subscribe a URL to the given event types, generating its secret unless given.
+func (s *Service) Subscribe(ctx context.Context, sub Subscription) (Subscription, error) {
+	if err := validate(sub); err != nil {
+		return Subscription{}, errors.Wrap(err, "Subscribe failed")
+	}
+
+	if sub.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return Subscription{}, errors.Wrap(err, "Subscribe failed")
+		}
+		sub.Secret = hex.EncodeToString(secret)
+	}
+
+	created, err := s.store.CreateSubscription(ctx, sub)
+	if err != nil {
+		return Subscription{}, errors.Wrap(err, "Subscribe failed")
+	}
+
+	return created, nil
+}
+
+// Subscriptions lists the subscriptions, without their secrets.
+func (s *Service) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	subs, err := s.store.FindSubscriptions(ctx)
+	if err != nil {
+		return []Subscription{}, errors.Wrap(err, "Subscriptions failed")
+	}
+
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+// Unsubscribe removes a subscription, along with its deliveries.
+func (s *Service) Unsubscribe(ctx context.Context, id int) error {
+	return errors.Wrap(s.store.DeleteSubscription(ctx, id), "Unsubscribe failed")
+}
+
+// Deliveries lists the latest deliveries of a subscription, newest first.
+func (s *Service) Deliveries(ctx context.Context, subscriptionID int) ([]Delivery, error) {
+	deliveries, err := s.store.FindDeliveries(ctx, subscriptionID, maxDeliveries)
+	if err != nil {
+		return []Delivery{}, errors.Wrap(err, "Deliveries failed")
+	}
+
+	return deliveries, nil
+}
+
+// validate a subscription, whose URL must be absolute over HTTP(S) and events known, without duplicates.
+func validate(sub Subscription) error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || len(sub.URL) > MaxURLLength || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Wrapf(ErrInvalidSubscription, "invalid url %s", sub.URL)
+	}
+
+	if len(sub.Events) == 0 {
+		return errors.Wrap(ErrInvalidSubscription, "missing events")
+	}
+
+	seen := map[string]bool{}
+	for _, event := range sub.Events {
+		if !known(event) || seen[event] {
+			return errors.Wrapf(ErrInvalidSubscription, "invalid event %s", event)
+		}
+		seen[event] = true
+	}
+
+	return nil
+}
+
+func known(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
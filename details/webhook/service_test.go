@@ -0,0 +1,207 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestService_Subscribe(t *testing.T) {
+	sub := Subscription{URL: "https://example.com/hook", Events: []string{TransactionCreated}}
+
+	tests := map[string]func(t *testing.T, m *mockStore){
+		"when url is invalid": func(t *testing.T, m *mockStore) {
+			// arrange
+			s := NewService(m)
+
+			for _, url := range []string{"", "example.com/hook", "ftp://example.com/hook", "https://", "https://example.com/" + strings.Repeat("a", MaxURLLength)} {
+				invalid := sub
+				invalid.URL = url
+
+				// act
+				_, gotErr := s.Subscribe(context.Background(), invalid)
+
+				// assert
+				assert.Equal(t, ErrInvalidSubscription, errors.Cause(gotErr), url)
+			}
+			m.AssertNotCalled(t, "CreateSubscription", mock.Anything)
+		},
+		"when events are invalid": func(t *testing.T, m *mockStore) {
+			// arrange
+			s := NewService(m)
+
+			for _, events := range [][]string{nil, {"transaction.viewed"}, {TransactionCreated, TransactionCreated}} {
+				invalid := sub
+				invalid.Events = events
+
+				// act
+				_, gotErr := s.Subscribe(context.Background(), invalid)
+
+				// assert
+				assert.Equal(t, ErrInvalidSubscription, errors.Cause(gotErr), events)
+			}
+			m.AssertNotCalled(t, "CreateSubscription", mock.Anything)
+		},
+		"when store fails": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("CreateSubscription", mock.Anything).Return(Subscription{}, errors.New("WebhookStore.CreateSubscription failed: err"))
+			s := NewService(m)
+
+			// act
+			got, gotErr := s.Subscribe(context.Background(), sub)
+
+			// assert
+			assert.Equal(t, Subscription{}, got)
+			assert.EqualError(t, gotErr, "Subscribe failed: WebhookStore.CreateSubscription failed: err")
+		},
+		"when secret is given": func(t *testing.T, m *mockStore) {
+			// arrange
+			given := sub
+			given.Secret = "secret"
+
+			want := given
+			want.ID = 1
+			m.On("CreateSubscription", given).Return(want, nil)
+			s := NewService(m)
+
+			// act
+			got, gotErr := s.Subscribe(context.Background(), given)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, want, got)
+		},
+		"when secret is generated": func(t *testing.T, m *mockStore) {
+			// arrange
+			var secrets []string
+			m.On("CreateSubscription", mock.Anything).Return(Subscription{ID: 1}, nil).Run(func(args mock.Arguments) {
+				secrets = append(secrets, args.Get(0).(Subscription).Secret)
+			})
+			s := NewService(m)
+
+			// act
+			_, firstErr := s.Subscribe(context.Background(), sub)
+			_, secondErr := s.Subscribe(context.Background(), sub)
+
+			// assert
+			assert.NoError(t, firstErr)
+			assert.NoError(t, secondErr)
+			assert.Len(t, secrets[0], 64)
+			assert.NotEqual(t, secrets[0], secrets[1])
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, new(mockStore))
+		})
+	}
+}
+
+func TestService_Subscriptions(t *testing.T) {
+	tests := map[string]func(t *testing.T, m *mockStore){
+		"when store fails": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("FindSubscriptions").Return([]Subscription{}, errors.New("WebhookStore.FindSubscriptions failed: err"))
+			s := NewService(m)
+
+			// act
+			got, gotErr := s.Subscriptions(context.Background())
+
+			// assert
+			assert.Empty(t, got)
+			assert.EqualError(t, gotErr, "Subscriptions failed: WebhookStore.FindSubscriptions failed: err")
+		},
+		"when subscriptions are found": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("FindSubscriptions").Return([]Subscription{{ID: 1, URL: "https://example.com/hook", Secret: "secret"}}, nil)
+			s := NewService(m)
+
+			// act
+			got, gotErr := s.Subscriptions(context.Background())
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, []Subscription{{ID: 1, URL: "https://example.com/hook"}}, got)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, new(mockStore))
+		})
+	}
+}
+
+func TestService_Unsubscribe(t *testing.T) {
+	tests := map[string]func(t *testing.T, m *mockStore){
+		"when store fails": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("DeleteSubscription", 1).Return(errors.New("WebhookStore.DeleteSubscription failed: err"))
+			s := NewService(m)
+
+			// act
+			gotErr := s.Unsubscribe(context.Background(), 1)
+
+			// assert
+			assert.EqualError(t, gotErr, "Unsubscribe failed: WebhookStore.DeleteSubscription failed: err")
+		},
+		"when subscription is removed": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("DeleteSubscription", 1).Return(nil)
+			s := NewService(m)
+
+			// act
+			gotErr := s.Unsubscribe(context.Background(), 1)
+
+			// assert
+			assert.NoError(t, gotErr)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, new(mockStore))
+		})
+	}
+}
+
+func TestService_Deliveries(t *testing.T) {
+	tests := map[string]func(t *testing.T, m *mockStore){
+		"when store fails": func(t *testing.T, m *mockStore) {
+			// arrange
+			m.On("FindDeliveries", 1, maxDeliveries).Return([]Delivery{}, errors.New("WebhookStore.FindDeliveries failed: err"))
+			s := NewService(m)
+
+			// act
+			got, gotErr := s.Deliveries(context.Background(), 1)
+
+			// assert
+			assert.Empty(t, got)
+			assert.EqualError(t, gotErr, "Deliveries failed: WebhookStore.FindDeliveries failed: err")
+		},
+		"when deliveries are found": func(t *testing.T, m *mockStore) {
+			// arrange
+			want := []Delivery{{ID: 2, SubscriptionID: 1}, {ID: 1, SubscriptionID: 1}}
+			m.On("FindDeliveries", 1, maxDeliveries).Return(want, nil)
+			s := NewService(m)
+
+			// act
+			got, gotErr := s.Deliveries(context.Background(), 1)
+
+			// assert
+			assert.NoError(t, gotErr)
+			assert.Equal(t, want, got)
+		},
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			run(t, new(mockStore))
+		})
+	}
+}
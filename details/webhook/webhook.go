@@ -0,0 +1,140 @@
+// Package webhook notifies subscribed URLs of the domain events, read from the outbox the use cases publish them to
+// once each change is saved, so an event is delivered at least once after it is appended.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/outbox"
+)
+
+const (
+	// TransactionCreated is the event of a created transaction, its payload being the transaction and the actor.
+	TransactionCreated = core.EventTransactionCreated
+
+	// TransactionUpdated is the event of a changed transaction, its payload being the transaction once changed and
+	// the actor.
+	TransactionUpdated = core.EventTransactionUpdated
+
+	// TransactionDeleted is the event of a removed transaction, its payload being the transaction once removed and
+	// the actor.
+	TransactionDeleted = core.EventTransactionDeleted
+
+	// CategoryCreated is the event of a created category, its payload being the category and the actor.
+	CategoryCreated = core.EventCategoryCreated
+)
+
+// Events are the event types a subscription may subscribe to.
+var Events = core.Events
+
+const (
+	// Pending is the status of a delivery yet to be attempted.
+	Pending = "pending"
+
+	// Sending is the status of a delivery claimed by an instance attempting it, until its lease ends.
+	Sending = "sending"
+
+	// Delivered is the status of a delivery the subscriber responded with a 2xx status to.
+	Delivered = "delivered"
+
+	// Failed is the status of a delivery the subscriber did not respond with a 2xx status to.
+	Failed = "failed"
+)
+
+const (
+	// EventHeader holds the event type of a delivery.
+	EventHeader = "X-Maskada-Event"
+
+	// EventIDHeader holds the ID of the event, the same for each attempt, so a subscriber can skip duplicates.
+	EventIDHeader = "X-Maskada-Event-ID"
+
+	// TimestampHeader holds the Unix time a delivery was attempted at, which is signed along with the body.
+	TimestampHeader = "X-Maskada-Timestamp"
+
+	// SignatureHeader holds the signature of a delivery, eg: sha256=<hex>, see Sign.
+	SignatureHeader = "X-Maskada-Signature"
+)
+
+// ErrInvalidSubscription is the cause of the errors returned when subscribing with an invalid URL or event.
+var ErrInvalidSubscription = errors.New("invalid subscription")
+
+type (
+	// Subscription notifies a URL of the events of the given types, signing the deliveries with its Secret.
+	Subscription struct {
+		ID     int
+		URL    string
+		Secret string
+		Events []string
+		Date   time.Time
+	}
+
+	// Delivery is an attempt to notify a subscription of an event, a failed one being retried by a new delivery.
+	Delivery struct {
+		ID             int
+		SubscriptionID int
+		EventID        int
+		Event          string
+		Attempt        int
+		Status         string
+		ResponseCode   int
+		Error          string
+
+		// ScheduledAt is when the delivery is due, AttemptedAt when it was attempted, zero while pending.
+		ScheduledAt time.Time
+		AttemptedAt time.Time
+	}
+
+	// Job is a claimed delivery, along with its subscription and event.
+	Job struct {
+		Delivery     Delivery
+		Subscription Subscription
+		Event        outbox.Message
+	}
+
+	// Store represents a client able to persist the subscriptions and deliveries, and to read the outbox.
+	Store interface {
+		CreateSubscription(ctx context.Context, s Subscription) (Subscription, error)
+		FindSubscriptions(ctx context.Context) ([]Subscription, error)
+		DeleteSubscription(ctx context.Context, id int) error
+
+		// FindDeliveries finds the latest deliveries of a subscription, newest first.
+		FindDeliveries(ctx context.Context, subscriptionID, limit int) ([]Delivery, error)
+
+		// Fanout schedules a pending delivery at now of up to limit events of the outbox to each subscription of
+		// their type, marking them dispatched, and returns how many events it dispatched.
+		Fanout(ctx context.Context, now time.Time, limit int) (int, error)
+
+		// ClaimDue claims up to limit pending deliveries scheduled at or before now, oldest first, marking them
+		// Sending until now+lease, so no other instance claims them meanwhile, unless the lease ends first.
+		ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Job, error)
+
+		// RecordAttempt saves the outcome of an attempted delivery, scheduling its retry at once when given.
+		RecordAttempt(ctx context.Context, attempted Delivery, retry *Delivery) error
+	}
+)
+
+// Subscribed tells whether the subscription is notified of an event type.
+func (s Subscription) Subscribed(event string) bool {
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign computes the signature of a delivery, the hex HMAC-SHA256 of the timestamp, a dot and the body, keyed
+// by the secret of the subscription, eg: sha256=5257a869...
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSign(t *testing.T) {
+	// arrange
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"id":1}`)
+
+	// act
+	got := Sign("secret", timestamp, body)
+
+	// assert
+	assert.Equal(t, "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11", got)
+	assert.NotEqual(t, got, Sign("other", timestamp, body))
+	assert.NotEqual(t, got, Sign("secret", timestamp.Add(time.Second), body))
+	assert.NotEqual(t, got, Sign("secret", timestamp, []byte(`{"id":2}`)))
+}
+
+func TestSubscription_Subscribed(t *testing.T) {
+	// arrange
+	sub := Subscription{Events: []string{TransactionCreated, TransactionDeleted}}
+
+	// act & assert
+	assert.True(t, sub.Subscribed(TransactionCreated))
+	assert.True(t, sub.Subscribed(TransactionDeleted))
+	assert.False(t, sub.Subscribed(TransactionUpdated))
+}
+
+type mockStore struct {
+	mock.Mock
+}
+
+func (m *mockStore) CreateSubscription(_ context.Context, s Subscription) (Subscription, error) {
+	args := m.Called(s)
+	return args.Get(0).(Subscription), args.Error(1)
+}
+
+func (m *mockStore) FindSubscriptions(_ context.Context) ([]Subscription, error) {
+	args := m.Called()
+	return args.Get(0).([]Subscription), args.Error(1)
+}
+
+func (m *mockStore) DeleteSubscription(_ context.Context, id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *mockStore) FindDeliveries(_ context.Context, subscriptionID, limit int) ([]Delivery, error) {
+	args := m.Called(subscriptionID, limit)
+	return args.Get(0).([]Delivery), args.Error(1)
+}
+
+func (m *mockStore) Fanout(_ context.Context, now time.Time, limit int) (int, error) {
+	args := m.Called(now, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockStore) ClaimDue(_ context.Context, now time.Time, lease time.Duration, limit int) ([]Job, error) {
+	args := m.Called(now, lease, limit)
+	return args.Get(0).([]Job), args.Error(1)
+}
+
+func (m *mockStore) RecordAttempt(_ context.Context, attempted Delivery, retry *Delivery) error {
+	args := m.Called(attempted, retry)
+	return args.Error(0)
+}
@@ -0,0 +1,480 @@
+// Package webhooktest defines what a correct webhook.Store does, along with the outbox the events of the changes are
+// published to, as a test suite any backend can run.
+package webhooktest
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gritt/maskada/core"
+	"github.com/gritt/maskada/details/webhook"
+)
+
+// Factory returns an empty repository, a publisher appending to its outbox, eg: events.NewOutbox, and the webhook
+// store sharing its storage, releasing them with t.Cleanup when needed.
+type Factory func(t *testing.T) (core.Repository, core.Publisher, webhook.Store)
+
+// changes makes the changes of the suite through the use cases, so their events are published.
+type changes struct {
+	create *core.CreateTransactionUseCase
+	batch  *core.CreateTransactionBatchUseCase
+	update *core.UpdateTransactionUseCase
+	delete *core.DeleteTransactionUseCase
+}
+
+// actor is the actor of all changes made by the suite.
+const actor = "webhooktest"
+
+// ctx is the context of all calls made by the suite.
+var ctx = context.Background()
+
+// now is a time every backend stores without losing precision.
+var now = time.Now().UTC().Truncate(time.Second)
+
+// Run runs the conformance suite, each test against a new repository and store.
+func Run(t *testing.T, newStore Factory) {
+	tests := map[string]func(*testing.T, *changes, webhook.Store){
+		"CreateSubscription assigns sequential ids":                      testCreateSubscription,
+		"FindSubscriptions returns the subscriptions, oldest first":      testFindSubscriptions,
+		"DeleteSubscription fails with ErrNotFound":                      testDeleteSubscriptionNotFound,
+		"Fanout schedules the events to the subscriptions of their type": testFanout,
+		"Fanout dispatches each event once":                              testFanoutOnce,
+		"Fanout is limited":                                              testFanoutLimit,
+		"the outbox holds an event of each change":                       testOutbox,
+		"the outbox holds no event of a failed change":                   testOutboxRollback,
+		"ClaimDue skips the deliveries scheduled later":                  testClaimDueLater,
+		"ClaimDue claims each delivery once":                             testClaimDueOnce,
+		"ClaimDue claims a delivery again once its lease ends":           testClaimDueLeaseEnded,
+		"RecordAttempt saves the outcome, along with the retry":          testRecordAttempt,
+		"FindDeliveries returns the latest deliveries, newest first":     testFindDeliveries,
+		"FindDeliveries fails with ErrNotFound":                          testFindDeliveriesNotFound,
+		"DeleteSubscription removes the subscription and its deliveries": testDeleteSubscription,
+	}
+
+	for name, run := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, p, s := newStore(t)
+			run(t, &changes{
+				create: core.NewCreateTransactionUseCase(r, p),
+				batch:  core.NewCreateTransactionBatchUseCase(r, p),
+				update: core.NewUpdateTransactionUseCase(r, p),
+				delete: core.NewDeleteTransactionUseCase(r, p),
+			}, s)
+		})
+	}
+}
+
+func testCreateSubscription(t *testing.T, _ *changes, s webhook.Store) {
+	// act
+	first, firstErr := s.CreateSubscription(ctx, subscription(webhook.TransactionCreated))
+	second, secondErr := s.CreateSubscription(ctx, subscription(webhook.TransactionCreated, webhook.TransactionDeleted))
+
+	// assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.True(t, first.ID > 0)
+	assert.Equal(t, first.ID+1, second.ID)
+	assert.Equal(t, "https://example.com/hook", second.URL)
+	assert.Equal(t, "secret", second.Secret)
+	assert.Equal(t, []string{webhook.TransactionCreated, webhook.TransactionDeleted}, second.Events)
+	assert.False(t, second.Date.IsZero())
+}
+
+func testFindSubscriptions(t *testing.T, _ *changes, s webhook.Store) {
+	// arrange
+	first := createSubscription(t, s, webhook.TransactionCreated)
+	second := createSubscription(t, s, webhook.TransactionUpdated, webhook.TransactionDeleted)
+
+	// act
+	got, gotErr := s.FindSubscriptions(ctx)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Len(t, got, 2)
+	assert.Equal(t, []int{first.ID, second.ID}, []int{got[0].ID, got[1].ID})
+	assert.Equal(t, second.Events, got[1].Events)
+	assert.Equal(t, second.Secret, got[1].Secret)
+	assert.WithinDuration(t, second.Date, got[1].Date, time.Second)
+}
+
+func testDeleteSubscriptionNotFound(t *testing.T, _ *changes, s webhook.Store) {
+	// act
+	gotErr := s.DeleteSubscription(ctx, 999)
+
+	// assert
+	assert.Equal(t, core.ErrNotFound, errors.Cause(gotErr))
+}
+
+func testFanout(t *testing.T, r *changes, s webhook.Store) {
+	// arrange
+	created := createSubscription(t, s, webhook.TransactionCreated)
+	deleted := createSubscription(t, s, webhook.TransactionDeleted)
+
+	trs := create(t, r)
+
+	// act
+	gotN, gotErr := s.Fanout(ctx, now, 100)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Equal(t, 2, gotN, "the events of the category and the transaction")
+
+	jobs, err := s.ClaimDue(ctx, now, time.Minute, 100)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+
+	job := jobs[0]
+	assert.Equal(t, created.ID, job.Delivery.SubscriptionID)
+	assert.Equal(t, webhook.TransactionCreated, job.Delivery.Event)
+	assert.Equal(t, job.Event.ID, job.Delivery.EventID)
+	assert.Equal(t, 1, job.Delivery.Attempt)
+	assert.Equal(t, webhook.Sending, job.Delivery.Status)
+	assert.True(t, job.Delivery.ScheduledAt.Equal(now), "scheduled at %s", job.Delivery.ScheduledAt)
+	assert.Equal(t, created.URL, job.Subscription.URL)
+	assert.Equal(t, created.Secret, job.Subscription.Secret)
+	assert.Equal(t, webhook.TransactionCreated, job.Event.Event)
+	assert.False(t, job.Event.Date.IsZero())
+	assertPayload(t, trs, job.Event.Payload)
+
+	deliveries, err := s.FindDeliveries(ctx, deleted.ID, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func testFanoutOnce(t *testing.T, r *changes, s webhook.Store) {
+	// arrange
+	createSubscription(t, s, webhook.TransactionCreated)
+	create(t, r)
+
+	_, err := s.Fanout(ctx, now, 100)
+	assert.NoError(t, err)
+
+	// act
+	gotN, gotErr := s.Fanout(ctx, now, 100)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Equal(t, 0, gotN)
+
+	jobs, err := s.ClaimDue(ctx, now, time.Minute, 100)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func testFanoutLimit(t *testing.T, r *changes, s webhook.Store) {
+	// arrange
+	createSubscription(t, s, webhook.TransactionCreated)
+	for i := 0; i < 3; i++ {
+		create(t, r)
+	}
+
+	// act
+	firstN, firstErr := s.Fanout(ctx, now, 3)
+	secondN, secondErr := s.Fanout(ctx, now, 3)
+
+	// assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, 3, firstN)
+	assert.Equal(t, 1, secondN)
+
+	jobs, err := s.ClaimDue(ctx, now, time.Minute, 100)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 3)
+	assert.True(t, jobs[0].Event.ID < jobs[1].Event.ID && jobs[1].Event.ID < jobs[2].Event.ID, "the events are not in order")
+}
+
+func testOutbox(t *testing.T, r *changes, s webhook.Store) {
+	// arrange
+	createSubscription(t, s, webhook.Events...)
+
+	trs := create(t, r)
+
+	batch, err := r.batch.CreateAll(ctx, []core.Transaction{transaction(), transaction()}, actor)
+	assert.NoError(t, err)
+
+	trs.Amount = 99
+	updated, err := r.update.Update(ctx, trs, actor)
+	assert.NoError(t, err)
+	assert.NoError(t, r.delete.Delete(ctx, updated.ID, updated.Version, actor))
+
+	// act
+	_, gotErr := s.Fanout(ctx, now, 100)
+
+	// assert
+	assert.NoError(t, gotErr)
+
+	jobs, err := s.ClaimDue(ctx, now, time.Minute, 100)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 6)
+
+	var events []string
+	for _, job := range jobs {
+		events = append(events, job.Event.Event)
+	}
+	assert.Equal(t, []string{
+		webhook.CategoryCreated,
+		webhook.TransactionCreated,
+		webhook.TransactionCreated,
+		webhook.TransactionCreated,
+		webhook.TransactionUpdated,
+		webhook.TransactionDeleted,
+	}, events)
+
+	assert.JSONEq(t, `{"category":{"name":"Food"},"actor":"webhooktest"}`, string(jobs[0].Event.Payload))
+	assertPayload(t, batch[1].Transaction, jobs[3].Event.Payload)
+	assertPayload(t, updated, jobs[4].Event.Payload)
+	assertPayload(t, updated, jobs[5].Event.Payload)
+}
+
+func testOutboxRollback(t *testing.T, r *changes, s webhook.Store) {
+	// arrange
+	createSubscription(t, s, webhook.Events...)
+	trs := create(t, r)
+
+	// act
+	_, updateErr := r.update.Update(ctx, core.Transaction{ID: trs.ID, Amount: 1, Type: core.Debit, Category: trs.Category, Version: 7}, actor)
+	deleteErr := r.delete.Delete(ctx, 999, 1, actor)
+
+	// assert
+	assert.Error(t, updateErr)
+	assert.Error(t, deleteErr)
+
+	n, err := s.Fanout(ctx, now, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n, "the events of the category and the created transaction")
+}
+
+func testClaimDueLater(t *testing.T, r *changes, s webhook.Store) {
+	// arrange
+	createSubscription(t, s, webhook.TransactionCreated)
+	create(t, r)
+
+	_, err := s.Fanout(ctx, now, 100)
+	assert.NoError(t, err)
+
+	// act
+	got, gotErr := s.ClaimDue(ctx, now.Add(-time.Second), time.Minute, 100)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Empty(t, got)
+}
+
+func testClaimDueOnce(t *testing.T, r *changes, s webhook.Store) {
+	// arrange
+	createSubscription(t, s, webhook.TransactionCreated)
+	create(t, r)
+
+	_, err := s.Fanout(ctx, now, 100)
+	assert.NoError(t, err)
+
+	first, err := s.ClaimDue(ctx, now, time.Minute, 100)
+	assert.NoError(t, err)
+	assert.Len(t, first, 1)
+
+	// act
+	got, gotErr := s.ClaimDue(ctx, now.Add(time.Second), time.Minute, 100)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Empty(t, got)
+}
+
+func testClaimDueLeaseEnded(t *testing.T, r *changes, s webhook.Store) {
+	// arrange
+	createSubscription(t, s, webhook.TransactionCreated)
+	create(t, r)
+
+	_, err := s.Fanout(ctx, now, 100)
+	assert.NoError(t, err)
+
+	first, err := s.ClaimDue(ctx, now, time.Minute, 100)
+	assert.NoError(t, err)
+	assert.Len(t, first, 1)
+
+	// act
+	got, gotErr := s.ClaimDue(ctx, now.Add(time.Minute), time.Minute, 100)
+
+	// assert
+	assert.NoError(t, gotErr)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, first[0].Delivery.ID, got[0].Delivery.ID)
+		assert.Equal(t, 1, got[0].Delivery.Attempt)
+	}
+}
+
+func testRecordAttempt(t *testing.T, r *changes, s webhook.Store) {
+	// arrange
+	sub := createSubscription(t, s, webhook.TransactionCreated)
+	create(t, r)
+
+	_, err := s.Fanout(ctx, now, 100)
+	assert.NoError(t, err)
+
+	jobs, err := s.ClaimDue(ctx, now, time.Minute, 100)
+	assert.NoError(t, err)
+
+	attempted := jobs[0].Delivery
+	attempted.Status = webhook.Failed
+	attempted.ResponseCode = 503
+	attempted.Error = "unexpected status 503 Service Unavailable"
+	attempted.AttemptedAt = now
+
+	retry := attempted
+	retry.ID = 0
+	retry.Attempt = 2
+	retry.Status = webhook.Pending
+	retry.ResponseCode = 0
+	retry.Error = ""
+	retry.ScheduledAt = now.Add(time.Minute)
+	retry.AttemptedAt = time.Time{}
+
+	// act
+	gotErr := s.RecordAttempt(ctx, attempted, &retry)
+
+	// assert
+	assert.NoError(t, gotErr)
+
+	due, err := s.ClaimDue(ctx, now, time.Minute, 100)
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, err = s.ClaimDue(ctx, now.Add(time.Minute), time.Minute, 100)
+	assert.NoError(t, err)
+	assert.Len(t, due, 1)
+	assert.Equal(t, 2, due[0].Delivery.Attempt)
+	assert.Equal(t, attempted.EventID, due[0].Delivery.EventID)
+
+	deliveries, err := s.FindDeliveries(ctx, sub.ID, 10)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 2)
+	assert.Equal(t, webhook.Failed, deliveries[1].Status)
+	assert.Equal(t, 503, deliveries[1].ResponseCode)
+	assert.Equal(t, attempted.Error, deliveries[1].Error)
+	assert.True(t, deliveries[1].AttemptedAt.Equal(now), "attempted at %s", deliveries[1].AttemptedAt)
+	assert.True(t, deliveries[0].AttemptedAt.IsZero())
+}
+
+func testFindDeliveries(t *testing.T, r *changes, s webhook.Store) {
+	// arrange
+	sub := createSubscription(t, s, webhook.TransactionCreated)
+	for i := 0; i < 3; i++ {
+		create(t, r)
+	}
+
+	_, err := s.Fanout(ctx, now, 100)
+	assert.NoError(t, err)
+
+	jobs, err := s.ClaimDue(ctx, now, time.Minute, 100)
+	assert.NoError(t, err)
+
+	delivered := jobs[0].Delivery
+	delivered.Status = webhook.Delivered
+	delivered.ResponseCode = 200
+	delivered.AttemptedAt = now
+	assert.NoError(t, s.RecordAttempt(ctx, delivered, nil))
+
+	// act
+	got, gotErr := s.FindDeliveries(ctx, sub.ID, 2)
+
+	// assert
+	assert.NoError(t, gotErr)
+	assert.Len(t, got, 2)
+	assert.Equal(t, jobs[2].Delivery.ID, got[0].ID)
+	assert.Equal(t, jobs[1].Delivery.ID, got[1].ID)
+
+	all, err := s.FindDeliveries(ctx, sub.ID, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, webhook.Delivered, all[2].Status)
+	assert.Equal(t, 200, all[2].ResponseCode)
+}
+
+func testFindDeliveriesNotFound(t *testing.T, _ *changes, s webhook.Store) {
+	// act
+	_, gotErr := s.FindDeliveries(ctx, 999, 10)
+
+	// assert
+	assert.Equal(t, core.ErrNotFound, errors.Cause(gotErr))
+}
+
+func testDeleteSubscription(t *testing.T, r *changes, s webhook.Store) {
+	// arrange
+	sub := createSubscription(t, s, webhook.TransactionCreated)
+	create(t, r)
+
+	_, err := s.Fanout(ctx, now, 100)
+	assert.NoError(t, err)
+
+	// act
+	gotErr := s.DeleteSubscription(ctx, sub.ID)
+
+	// assert
+	assert.NoError(t, gotErr)
+
+	subs, err := s.FindSubscriptions(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, subs)
+
+	jobs, err := s.ClaimDue(ctx, now, time.Minute, 100)
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func subscription(events ...string) webhook.Subscription {
+	return webhook.Subscription{URL: "https://example.com/hook", Secret: "secret", Events: events}
+}
+
+func createSubscription(t *testing.T, s webhook.Store, events ...string) webhook.Subscription {
+	sub, err := s.CreateSubscription(ctx, subscription(events...))
+	if err != nil {
+		t.Fatalf("CreateSubscription failed: %s", err)
+	}
+	return sub
+}
+
+func transaction() core.Transaction {
+	return core.Transaction{Amount: 26, Type: core.Credit, Category: core.Category{Name: "Food"}, Date: now, Name: "Lunch"}
+}
+
+func create(t *testing.T, r *changes) core.Transaction {
+	trs, err := r.create.Create(ctx, transaction(), actor)
+	if err != nil {
+		t.Fatalf("Create failed: %s", err)
+	}
+	return trs
+}
+
+// assertPayload asserts the payload of an event is the snapshot of a transaction, changed by the actor.
+func assertPayload(t *testing.T, want core.Transaction, payload json.RawMessage) {
+	var data struct {
+		Transaction struct {
+			ID       int       `json:"id"`
+			Amount   int       `json:"amount"`
+			Type     int       `json:"type"`
+			Category string    `json:"category"`
+			Date     time.Time `json:"date"`
+			Name     string    `json:"name"`
+			Version  int       `json:"version"`
+		} `json:"transaction"`
+		Actor string `json:"actor"`
+	}
+	assert.NoError(t, json.Unmarshal(payload, &data), "payload %s", payload)
+	assert.Equal(t, actor, data.Actor)
+
+	got := data.Transaction
+
+	assert.Equal(t, strconv.Itoa(want.ID), strconv.Itoa(got.ID))
+	assert.Equal(t, want.Amount, got.Amount)
+	assert.Equal(t, want.Type, got.Type)
+	assert.Equal(t, want.Category.Name, got.Category)
+	assert.True(t, want.Date.Equal(got.Date), "date %s", got.Date)
+	assert.Equal(t, want.Name, got.Name)
+	assert.Equal(t, want.Version, got.Version)
+}